@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hardikphalet/go-redis/internal/sentinel"
+)
+
+// monitorFlags collects repeated -monitor flags.
+type monitorFlags []sentinel.MonitorConfig
+
+func (m *monitorFlags) String() string {
+	names := make([]string, len(*m))
+	for i, mc := range *m {
+		names[i] = mc.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (m *monitorFlags) Set(value string) error {
+	mc, err := sentinel.ParseMonitor(value)
+	if err != nil {
+		return err
+	}
+	*m = append(*m, mc)
+	return nil
+}
+
+// main is the entry point for the sentinel. It monitors the configured
+// primaries until it receives SIGINT or SIGTERM.
+func main() {
+	var cfg sentinel.Config
+	var monitors monitorFlags
+	var peers string
+
+	flag.StringVar(&cfg.Addr, "addr", ":26379", "address to listen on")
+	flag.StringVar(&cfg.ID, "id", "", "run ID used in leader elections (default: random)")
+	flag.Var(&monitors, "monitor", `primary to monitor as "<name> <host> <port> <quorum>"; may be repeated`)
+	flag.StringVar(&peers, "sentinels", "", "comma-separated addresses of the other sentinels")
+	flag.DurationVar(&cfg.PingInterval, "ping-interval", 0, "how often instances and sentinels are contacted (default 1s)")
+	flag.DurationVar(&cfg.DownAfter, "down-after", 0, "silence after which an instance is considered down (default 5s)")
+	flag.DurationVar(&cfg.FailoverTimeout, "failover-timeout", 0, "minimum time between failover attempts for a primary (default 30s)")
+	flag.Parse()
+
+	if len(monitors) == 0 {
+		log.Fatal("At least one -monitor is required")
+	}
+	cfg.Monitors = monitors
+	for _, peer := range strings.Split(peers, ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			cfg.Peers = append(cfg.Peers, peer)
+		}
+	}
+
+	s, err := sentinel.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure sentinel: %v", err)
+	}
+	if err := s.Start(); err != nil {
+		log.Fatalf("Failed to start sentinel: %v", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigChan
+	log.Printf("Received signal %v, shutting down...", sig)
+
+	if err := s.Stop(); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -13,13 +14,31 @@ import (
 // It creates a new server instance and starts it.
 // It also sets up signal handling for graceful shutdown.
 func main() {
-	srv := server.New(":6379")
+	cfg := server.DefaultConfig(":6379")
+	flag.StringVar(&cfg.Addr, "addr", cfg.Addr, "address to listen on")
+	flag.StringVar(&cfg.Dir, "dir", cfg.Dir, "directory for persistence files")
+	flag.StringVar(&cfg.DBFilename, "dbfilename", cfg.DBFilename, "snapshot file name")
+	flag.BoolVar(&cfg.AppendOnly, "appendonly", cfg.AppendOnly, "log every write command to the append-only file")
+	flag.StringVar(&cfg.AppendFilename, "appendfilename", cfg.AppendFilename, "append-only file name")
+	flag.StringVar(&cfg.AppendFsync, "appendfsync", cfg.AppendFsync, "AOF fsync policy: always, everysec or no")
+	flag.IntVar(&cfg.AutoAOFRewritePercentage, "auto-aof-rewrite-percentage", cfg.AutoAOFRewritePercentage, "rewrite the AOF once it grows by this percentage, 0 disables")
+	flag.Int64Var(&cfg.AutoAOFRewriteMinSize, "auto-aof-rewrite-min-size", cfg.AutoAOFRewriteMinSize, "minimum AOF size in bytes for automatic rewrites")
+	flag.IntVar(&cfg.ReplBacklogSize, "repl-backlog-size", cfg.ReplBacklogSize, "bytes of replication stream kept for partial resynchronization")
+	flag.BoolVar(&cfg.ClusterEnabled, "cluster-enabled", cfg.ClusterEnabled, "shard the keyspace across nodes by hash slot")
+	flag.StringVar(&cfg.ClusterLayout, "cluster-layout", cfg.ClusterLayout, "slot ownership, e.g. 127.0.0.1:7000=0-8191,127.0.0.1:7001=8192-16383")
+	flag.StringVar(&cfg.ClusterAnnounceAddr, "cluster-announce-addr", cfg.ClusterAnnounceAddr, "address other nodes and clients reach this node at")
+	flag.StringVar(&cfg.RaftAddr, "raft-addr", cfg.RaftAddr, "address the Raft listener binds to; enables Raft mode")
+	flag.StringVar(&cfg.RaftID, "raft-id", cfg.RaftID, "node ID within the Raft group (default: the Raft address)")
+	flag.StringVar(&cfg.RaftPeers, "raft-peers", cfg.RaftPeers, "Raft group members, e.g. n1=127.0.0.1:7100,n2=127.0.0.1:7101,n3=127.0.0.1:7102")
+	flag.Parse()
+
+	srv := server.NewWithConfig(cfg)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("Starting Redis server on port 6379...")
+		log.Printf("Starting Redis server on %s...", cfg.Addr)
 		if err := srv.Start(); err != nil {
 			log.Fatalf("Failed to start server: %v", err)
 		}
@@ -0,0 +1,460 @@
+package cluster
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+var (
+	errCrossSlot   = &types.Error{Code: "CROSSSLOT", Message: "Keys in request don't hash to the same slot"}
+	errClusterDown = &types.Error{Code: "CLUSTERDOWN", Message: "Hash slot not served"}
+)
+
+// Node is a member of the cluster.
+type Node struct {
+	ID   string
+	Host string
+	Port int
+}
+
+// Addr returns the host:port clients use to reach the node.
+func (n *Node) Addr() string {
+	return net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+}
+
+// NodeID derives the ID of the node listening on addr. IDs are derived from
+// the address so that every node started with the same layout agrees on
+// them without exchanging messages.
+func NodeID(addr string) string {
+	sum := sha1.Sum([]byte(addr))
+	return hex.EncodeToString(sum[:])
+}
+
+// SlotRange is an inclusive range of hash slots.
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// Assignment gives the slots in Ranges to the node at Addr.
+type Assignment struct {
+	Addr   string
+	Ranges []SlotRange
+}
+
+// ParseLayout parses a slot layout of the form
+//
+//	host:port=0-5460,host:port=5461-10922,host:port=10923-16383
+//
+// A range may be a single slot, and a node may appear several times to own
+// several ranges.
+func ParseLayout(spec string) ([]Assignment, error) {
+	var layout []Assignment
+	index := make(map[string]int)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		addr, slots, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid layout entry %q: expected host:port=slots", part)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("invalid layout entry %q: %w", part, err)
+		}
+		r, err := parseRange(slots)
+		if err != nil {
+			return nil, fmt.Errorf("invalid layout entry %q: %w", part, err)
+		}
+
+		i, ok := index[addr]
+		if !ok {
+			i = len(layout)
+			index[addr] = i
+			layout = append(layout, Assignment{Addr: addr})
+		}
+		layout[i].Ranges = append(layout[i].Ranges, r)
+	}
+
+	return layout, nil
+}
+
+func parseRange(s string) (SlotRange, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := ParseSlot(startStr)
+	if err != nil {
+		return SlotRange{}, err
+	}
+	end := start
+	if isRange {
+		if end, err = ParseSlot(endStr); err != nil {
+			return SlotRange{}, err
+		}
+	}
+	if start > end {
+		return SlotRange{}, fmt.Errorf("slot range %d-%d is empty", start, end)
+	}
+	return SlotRange{Start: start, End: end}, nil
+}
+
+// ParseSlot parses a hash slot number.
+func ParseSlot(s string) (int, error) {
+	slot, err := strconv.Atoi(s)
+	if err != nil || slot < 0 || slot >= Slots {
+		return 0, fmt.Errorf("Invalid or out of range slot")
+	}
+	return slot, nil
+}
+
+// Cluster is this node's view of the cluster: the known nodes and which of
+// them owns each hash slot.
+type Cluster struct {
+	mu        sync.RWMutex
+	myself    *Node
+	nodes     []*Node
+	owners    [Slots]*Node
+	migrating [Slots]*Node // Target of slots we are moving away
+	importing [Slots]*Node // Source of slots we are receiving
+}
+
+// New builds the cluster view of the node announced as myAddr. An empty
+// layout makes it the only node, owning every slot.
+func New(myAddr string, layout []Assignment) (*Cluster, error) {
+	if len(layout) == 0 {
+		layout = []Assignment{{Addr: myAddr, Ranges: []SlotRange{{0, Slots - 1}}}}
+	}
+
+	c := &Cluster{}
+	for _, a := range layout {
+		node, err := newNode(a.Addr)
+		if err != nil {
+			return nil, err
+		}
+		c.nodes = append(c.nodes, node)
+		if a.Addr == myAddr {
+			c.myself = node
+		}
+
+		for _, r := range a.Ranges {
+			for slot := r.Start; slot <= r.End; slot++ {
+				if owner := c.owners[slot]; owner != nil {
+					return nil, fmt.Errorf("slot %d is assigned to both %s and %s", slot, owner.Addr(), a.Addr)
+				}
+				c.owners[slot] = node
+			}
+		}
+	}
+
+	if c.myself == nil {
+		node, err := newNode(myAddr)
+		if err != nil {
+			return nil, err
+		}
+		c.myself = node
+		c.nodes = append(c.nodes, node)
+	}
+	return c, nil
+}
+
+func newNode(addr string) (*Node, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node port %q", portStr)
+	}
+	return &Node{ID: NodeID(addr), Host: host, Port: port}, nil
+}
+
+// Myself returns the local node.
+func (c *Cluster) Myself() *Node {
+	return c.myself
+}
+
+// Route checks that keys can be served by this node. It returns a CROSSSLOT
+// error if they hash to different slots, CLUSTERDOWN if their slot has no
+// owner and a MOVED redirection if another node owns it.
+//
+// While a slot is migrating, keys missing here are redirected with ASK to
+// the target, where exists reports whether a key is present. A node
+// importing a slot serves it to clients that sent ASKING.
+func (c *Cluster) Route(keys []string, asking bool, exists func(key string) bool) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	slot := KeySlot(keys[0])
+	for _, key := range keys[1:] {
+		if KeySlot(key) != slot {
+			return errCrossSlot
+		}
+	}
+
+	c.mu.RLock()
+	owner, migrating, importing := c.owners[slot], c.migrating[slot], c.importing[slot]
+	c.mu.RUnlock()
+
+	if owner == c.myself {
+		if migrating != nil {
+			for _, key := range keys {
+				if !exists(key) {
+					return askError(slot, migrating)
+				}
+			}
+		}
+		return nil
+	}
+	if importing != nil && asking {
+		return nil
+	}
+	if owner == nil {
+		return errClusterDown
+	}
+	return movedError(slot, owner)
+}
+
+func askError(slot int, node *Node) error {
+	return &types.Error{Code: "ASK", Message: fmt.Sprintf("%d %s", slot, node.Addr())}
+}
+
+func movedError(slot int, node *Node) error {
+	return &types.Error{Code: "MOVED", Message: fmt.Sprintf("%d %s", slot, node.Addr())}
+}
+
+// Owner returns the node serving slot, or nil if the slot is unassigned.
+func (c *Cluster) Owner(slot int) *Node {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.owners[slot]
+}
+
+// Meet adds the node at host:port to the known nodes and returns it.
+func (c *Cluster) Meet(host string, port int) *Node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	for _, node := range c.nodes {
+		if node.Addr() == addr {
+			return node
+		}
+	}
+	node := &Node{ID: NodeID(addr), Host: host, Port: port}
+	c.nodes = append(c.nodes, node)
+	return node
+}
+
+// Slot states accepted by SetSlot.
+const (
+	SlotImporting = "IMPORTING"
+	SlotMigrating = "MIGRATING"
+	SlotNode      = "NODE"
+	SlotStable    = "STABLE"
+)
+
+// SetSlot changes how this node serves slot, as CLUSTER SETSLOT does.
+// IMPORTING and MIGRATING start moving the slot from or to the node nodeID,
+// NODE assigns the slot to it and ends the migration, and STABLE cancels the
+// migration. nodeID is ignored for STABLE.
+func (c *Cluster) SetSlot(slot int, state, nodeID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if state == SlotStable {
+		c.migrating[slot] = nil
+		c.importing[slot] = nil
+		return nil
+	}
+
+	node := c.nodeLocked(nodeID)
+	if node == nil {
+		return fmt.Errorf("I don't know about node %s", nodeID)
+	}
+
+	switch state {
+	case SlotImporting:
+		if c.owners[slot] == c.myself {
+			return fmt.Errorf("I'm already the owner of hash slot %d", slot)
+		}
+		if node == c.myself {
+			return fmt.Errorf("I'm trying to import hash slot %d from myself", slot)
+		}
+		c.importing[slot] = node
+	case SlotMigrating:
+		if c.owners[slot] != c.myself {
+			return fmt.Errorf("I'm not the owner of hash slot %d", slot)
+		}
+		if node == c.myself {
+			return fmt.Errorf("I'm trying to migrate hash slot %d to myself", slot)
+		}
+		c.migrating[slot] = node
+	case SlotNode:
+		c.owners[slot] = node
+		c.migrating[slot] = nil
+		if node == c.myself {
+			c.importing[slot] = nil
+		}
+	default:
+		return fmt.Errorf("Invalid CLUSTER SETSLOT action or number of arguments")
+	}
+	return nil
+}
+
+func (c *Cluster) nodeLocked(id string) *Node {
+	for _, node := range c.nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+// ownedRangesLocked returns the contiguous slot ranges owned by each node.
+// It must be called with c.mu held.
+func (c *Cluster) ownedRangesLocked() map[*Node][]SlotRange {
+	ranges := make(map[*Node][]SlotRange)
+	for slot := 0; slot < Slots; {
+		owner := c.owners[slot]
+		end := slot
+		for end+1 < Slots && c.owners[end+1] == owner {
+			end++
+		}
+		if owner != nil {
+			ranges[owner] = append(ranges[owner], SlotRange{Start: slot, End: end})
+		}
+		slot = end + 1
+	}
+	return ranges
+}
+
+// SlotsReply returns the CLUSTER SLOTS reply: one entry per contiguous slot
+// range with the address and ID of the node serving it.
+func (c *Cluster) SlotsReply() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var entries []SlotRange
+	owners := make(map[SlotRange]*Node)
+	for node, ranges := range c.ownedRangesLocked() {
+		for _, r := range ranges {
+			entries = append(entries, r)
+			owners[r] = node
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Start < entries[j].Start })
+
+	reply := make([]interface{}, 0, len(entries))
+	for _, r := range entries {
+		node := owners[r]
+		reply = append(reply, []interface{}{
+			r.Start,
+			r.End,
+			[]interface{}{node.Host, node.Port, node.ID},
+		})
+	}
+	return reply
+}
+
+// ShardsReply returns the CLUSTER SHARDS reply. Every node forms its own
+// shard.
+func (c *Cluster) ShardsReply() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ranges := c.ownedRangesLocked()
+	reply := make([]interface{}, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		slots := make([]interface{}, 0, 2*len(ranges[node]))
+		for _, r := range ranges[node] {
+			slots = append(slots, r.Start, r.End)
+		}
+		reply = append(reply, []interface{}{
+			"slots", slots,
+			"nodes", []interface{}{
+				[]interface{}{
+					"id", node.ID,
+					"port", node.Port,
+					"ip", node.Host,
+					"endpoint", node.Host,
+					"role", "master",
+					"replication-offset", 0,
+					"health", "online",
+				},
+			},
+		})
+	}
+	return reply
+}
+
+// NodesReply returns the CLUSTER NODES reply, one line per node.
+func (c *Cluster) NodesReply() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ranges := c.ownedRangesLocked()
+	var b strings.Builder
+	for _, node := range c.nodes {
+		flags := "master"
+		if node == c.myself {
+			flags = "myself,master"
+		}
+		fmt.Fprintf(&b, "%s %s@%d %s - 0 0 0 connected", node.ID, node.Addr(), node.Port+10000, flags)
+		for _, r := range ranges[node] {
+			if r.Start == r.End {
+				fmt.Fprintf(&b, " %d", r.Start)
+			} else {
+				fmt.Fprintf(&b, " %d-%d", r.Start, r.End)
+			}
+		}
+		if node == c.myself {
+			for slot := 0; slot < Slots; slot++ {
+				if target := c.migrating[slot]; target != nil {
+					fmt.Fprintf(&b, " [%d->-%s]", slot, target.ID)
+				}
+				if source := c.importing[slot]; source != nil {
+					fmt.Fprintf(&b, " [%d-<-%s]", slot, source.ID)
+				}
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// InfoReply returns the CLUSTER INFO reply.
+func (c *Cluster) InfoReply() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	assigned := 0
+	for _, owner := range c.owners {
+		if owner != nil {
+			assigned++
+		}
+	}
+	state := "ok"
+	if assigned < Slots {
+		state = "fail"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "cluster_enabled:1\r\n")
+	fmt.Fprintf(&b, "cluster_state:%s\r\n", state)
+	fmt.Fprintf(&b, "cluster_slots_assigned:%d\r\n", assigned)
+	fmt.Fprintf(&b, "cluster_known_nodes:%d\r\n", len(c.nodes))
+	fmt.Fprintf(&b, "cluster_size:%d\r\n", len(c.ownedRangesLocked()))
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestKeySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"123456789", 0x31C3},
+		{"foo", 12182},
+		{"somekey", 11058},
+		{"{user1000}.following", KeySlot("user1000")},
+		{"{user1000}.followers", KeySlot("user1000")},
+		{"foo{}{bar}", KeySlot("foo{}{bar}")},
+		{"foo{{bar}}zap", KeySlot("{bar")},
+		{"foo{bar}{zap}", KeySlot("bar")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := KeySlot(tt.key); got != tt.want {
+				t.Errorf("KeySlot(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	layout, err := ParseLayout("127.0.0.1:7000=0-100, 127.0.0.1:7001=101-16383,127.0.0.1:7000=5")
+	if err != nil {
+		t.Fatalf("ParseLayout() error = %v", err)
+	}
+	if len(layout) != 2 || len(layout[0].Ranges) != 2 || layout[0].Ranges[1] != (SlotRange{5, 5}) {
+		t.Errorf("ParseLayout() = %+v", layout)
+	}
+	if _, err := New("127.0.0.1:7000", layout); err == nil {
+		t.Error("New() with a slot assigned twice succeeded, want error")
+	}
+
+	for _, spec := range []string{"127.0.0.1:7000", "7000=0-10", "127.0.0.1:7000=10-5", "127.0.0.1:7000=0-16384"} {
+		if _, err := ParseLayout(spec); err == nil {
+			t.Errorf("ParseLayout(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestCluster_Route(t *testing.T) {
+	layout, err := ParseLayout("127.0.0.1:7000=0-8191,127.0.0.1:7001=8192-15000")
+	if err != nil {
+		t.Fatalf("ParseLayout() error = %v", err)
+	}
+	c, err := New("127.0.0.1:7000", layout)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// "foo" hashes to 12182, "{a}" to 15495, "{b}" to 3300 and "{c}" to 7365.
+	tests := []struct {
+		name string
+		keys []string
+		code string
+		msg  string
+	}{
+		{"no keys", nil, "", ""},
+		{"local", []string{"{b}1", "{b}2"}, "", ""},
+		{"moved", []string{"foo"}, "MOVED", "12182 127.0.0.1:7001"},
+		{"cross slot", []string{"{b}", "{c}"}, "CROSSSLOT", "Keys in request don't hash to the same slot"},
+		{"unassigned", []string{"{a}"}, "CLUSTERDOWN", "Hash slot not served"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Route(tt.keys, false, func(string) bool { return true })
+			if tt.code == "" {
+				if err != nil {
+					t.Errorf("Route(%v) error = %v, want nil", tt.keys, err)
+				}
+				return
+			}
+			var e *types.Error
+			if !errors.As(err, &e) || e.Code != tt.code || e.Message != tt.msg {
+				t.Errorf("Route(%v) error = %v, want %s %s", tt.keys, err, tt.code, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCluster_SlotsReply(t *testing.T) {
+	c, err := New("127.0.0.1:7000", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	reply := c.SlotsReply()
+	if len(reply) != 1 {
+		t.Fatalf("SlotsReply() = %v, want one range", reply)
+	}
+	entry := reply[0].([]interface{})
+	node := entry[2].([]interface{})
+	if entry[0] != 0 || entry[1] != Slots-1 || node[0] != "127.0.0.1" || node[1] != 7000 || node[2] != NodeID("127.0.0.1:7000") {
+		t.Errorf("SlotsReply() = %v", reply)
+	}
+}
+
+func TestCluster_Migration(t *testing.T) {
+	layout, err := ParseLayout("127.0.0.1:7000=0-16383")
+	if err != nil {
+		t.Fatalf("ParseLayout() error = %v", err)
+	}
+	source, err := New("127.0.0.1:7000", layout)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	target, err := New("127.0.0.1:7001", layout)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	source.Meet("127.0.0.1", 7001)
+
+	sourceID, targetID := source.Myself().ID, target.Myself().ID
+	slot := KeySlot("foo")
+	missing := func(string) bool { return false }
+	present := func(string) bool { return true }
+
+	if err := target.SetSlot(slot, SlotMigrating, sourceID); err == nil {
+		t.Error("SetSlot(MIGRATING) on a node not owning the slot succeeded")
+	}
+	if err := source.SetSlot(slot, SlotMigrating, "unknown"); err == nil {
+		t.Error("SetSlot(MIGRATING) to an unknown node succeeded")
+	}
+	if err := target.SetSlot(slot, SlotImporting, sourceID); err != nil {
+		t.Fatalf("SetSlot(IMPORTING) error = %v", err)
+	}
+	if err := source.SetSlot(slot, SlotMigrating, targetID); err != nil {
+		t.Fatalf("SetSlot(MIGRATING) error = %v", err)
+	}
+
+	if err := source.Route([]string{"foo"}, false, present); err != nil {
+		t.Errorf("Route() of a key still on the source error = %v", err)
+	}
+	if err := source.Route([]string{"foo"}, false, missing); err == nil || err.Error() != fmt.Sprintf("ASK %d 127.0.0.1:7001", slot) {
+		t.Errorf("Route() of a moved key error = %v, want ASK", err)
+	}
+	if err := target.Route([]string{"foo"}, false, present); err == nil || !strings.HasPrefix(err.Error(), "MOVED") {
+		t.Errorf("Route() on the target without ASKING error = %v, want MOVED", err)
+	}
+	if err := target.Route([]string{"foo"}, true, missing); err != nil {
+		t.Errorf("Route() on the target with ASKING error = %v", err)
+	}
+
+	for _, c := range []*Cluster{source, target} {
+		if err := c.SetSlot(slot, SlotNode, targetID); err != nil {
+			t.Fatalf("SetSlot(NODE) error = %v", err)
+		}
+	}
+	if err := target.Route([]string{"foo"}, false, missing); err != nil {
+		t.Errorf("Route() on the new owner error = %v", err)
+	}
+	if err := source.Route([]string{"foo"}, false, present); err == nil || err.Error() != fmt.Sprintf("MOVED %d 127.0.0.1:7001", slot) {
+		t.Errorf("Route() on the old owner error = %v, want MOVED", err)
+	}
+}
@@ -0,0 +1,34 @@
+package cluster
+
+import "strings"
+
+// Slots is the number of hash slots the keyspace is divided into.
+const Slots = 16384
+
+// KeySlot returns the hash slot of key. If the key contains a non-empty
+// {hashtag}, only the tag is hashed, so related keys can be forced into the
+// same slot.
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) & (Slots - 1)
+}
+
+// crc16 implements CRC-16/XMODEM (polynomial 0x1021, initial value 0).
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
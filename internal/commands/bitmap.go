@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+)
+
+type SetBitCommand struct {
+	Key    string
+	Offset int64
+	Value  int
+}
+
+func (c *SetBitCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SetBit(c.Key, c.Offset, c.Value)
+}
+
+func (c *SetBitCommand) Propagate() []string {
+	return []string{"SETBIT", c.Key, strconv.FormatInt(c.Offset, 10), strconv.Itoa(c.Value)}
+}
+
+func (c *SetBitCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GetBitCommand struct {
+	Key    string
+	Offset int64
+}
+
+func (c *GetBitCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GetBit(c.Key, c.Offset)
+}
+
+func (c *GetBitCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type BitCountCommand struct {
+	Key   string
+	Range *options.BitRange // nil for the whole string
+}
+
+func (c *BitCountCommand) Execute(store store.Store) (interface{}, error) {
+	return store.BitCount(c.Key, c.Range)
+}
+
+func (c *BitCountCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type BitPosCommand struct {
+	Key   string
+	Bit   int
+	Range *options.BitRange // nil for the whole string
+}
+
+func (c *BitPosCommand) Execute(store store.Store) (interface{}, error) {
+	return store.BitPos(c.Key, c.Bit, c.Range)
+}
+
+func (c *BitPosCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type BitOpCommand struct {
+	Op          string // "AND", "OR", "XOR" or "NOT"
+	Destination string
+	Keys        []string
+}
+
+func (c *BitOpCommand) Execute(store store.Store) (interface{}, error) {
+	return store.BitOp(c.Op, c.Destination, c.Keys)
+}
+
+func (c *BitOpCommand) Propagate() []string {
+	return append([]string{"BITOP", c.Op, c.Destination}, c.Keys...)
+}
+
+func (c *BitOpCommand) KeyArgs() []string {
+	return append([]string{c.Destination}, c.Keys...)
+}
+
+// BitFieldCommand runs the operations of BITFIELD. It is propagated only
+// when it has operations that write, and without its GETs.
+type BitFieldCommand struct {
+	Key string
+	Ops []options.BitFieldOp
+}
+
+func (c *BitFieldCommand) Execute(store store.Store) (interface{}, error) {
+	return store.BitField(c.Key, c.Ops)
+}
+
+func (c *BitFieldCommand) Propagate() []string {
+	args := []string{"BITFIELD", c.Key}
+	overflow := "WRAP"
+	write := false
+	for _, op := range c.Ops {
+		if op.Op == "GET" {
+			continue
+		}
+		write = true
+		if op.Overflow != overflow {
+			overflow = op.Overflow
+			args = append(args, "OVERFLOW", overflow)
+		}
+		typ := "u"
+		if op.Signed {
+			typ = "i"
+		}
+		args = append(args, op.Op, typ+strconv.Itoa(op.Bits), strconv.FormatInt(op.Offset, 10), strconv.FormatInt(op.Value, 10))
+	}
+	if !write {
+		return nil
+	}
+	return args
+}
+
+func (c *BitFieldCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// BitFieldRoCommand is BITFIELD limited to GET, which unlike BITFIELD is
+// not a write command and so is served by replicas.
+type BitFieldRoCommand struct {
+	Key string
+	Ops []options.BitFieldOp
+}
+
+func (c *BitFieldRoCommand) Execute(store store.Store) (interface{}, error) {
+	return store.BitField(c.Key, c.Ops)
+}
+
+func (c *BitFieldRoCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"github.com/hardikphalet/go-redis/internal/cluster"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// ClusterKeySlotCommand returns the hash slot of Key.
+type ClusterKeySlotCommand struct {
+	Key string
+}
+
+func (c *ClusterKeySlotCommand) Execute(store store.Store) (interface{}, error) {
+	return cluster.KeySlot(c.Key), nil
+}
+
+// ClusterSlotsCommand lists the slot ranges and the nodes serving them.
+type ClusterSlotsCommand struct{}
+
+func (c *ClusterSlotsCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER SLOTS")
+}
+
+func (c *ClusterSlotsCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cl.SlotsReply(), nil
+}
+
+// ClusterShardsCommand lists the shards of the cluster with their slots and
+// nodes.
+type ClusterShardsCommand struct{}
+
+func (c *ClusterShardsCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER SHARDS")
+}
+
+func (c *ClusterShardsCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cl.ShardsReply(), nil
+}
+
+// ClusterNodesCommand describes every known node in the cluster bus format.
+type ClusterNodesCommand struct{}
+
+func (c *ClusterNodesCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER NODES")
+}
+
+func (c *ClusterNodesCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cl.NodesReply(), nil
+}
+
+// ClusterInfoCommand reports the state of the cluster.
+type ClusterInfoCommand struct{}
+
+func (c *ClusterInfoCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER INFO")
+}
+
+func (c *ClusterInfoCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cl.InfoReply(), nil
+}
+
+// ClusterMyIDCommand returns the ID of the node.
+type ClusterMyIDCommand struct{}
+
+func (c *ClusterMyIDCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER MYID")
+}
+
+func (c *ClusterMyIDCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cl.Myself().ID, nil
+}
+
+// ClusterMeetCommand adds the node at Host:Port to the known nodes.
+type ClusterMeetCommand struct {
+	Host string
+	Port int
+}
+
+func (c *ClusterMeetCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER MEET")
+}
+
+func (c *ClusterMeetCommand) ExecuteServer(srv Server) (interface{}, error) {
+	cl, err := srv.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	cl.Meet(c.Host, c.Port)
+	return types.SimpleString("OK"), nil
+}
+
+// ClusterSetSlotCommand changes the state of Slot, see cluster.SetSlot.
+type ClusterSetSlotCommand struct {
+	Slot   int
+	State  string
+	NodeID string
+}
+
+func (c *ClusterSetSlotCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER SETSLOT")
+}
+
+func (c *ClusterSetSlotCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.ClusterSetSlot(c.Slot, c.State, c.NodeID); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+// ClusterGetKeysInSlotCommand returns up to Count keys stored in Slot.
+type ClusterGetKeysInSlotCommand struct {
+	Slot  int
+	Count int
+}
+
+func (c *ClusterGetKeysInSlotCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER GETKEYSINSLOT")
+}
+
+func (c *ClusterGetKeysInSlotCommand) ExecuteServer(srv Server) (interface{}, error) {
+	keys, err := srv.ClusterKeysInSlot(c.Slot, c.Count)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		result[i] = key
+	}
+	return result, nil
+}
+
+// ClusterCountKeysInSlotCommand returns the number of keys stored in Slot.
+type ClusterCountKeysInSlotCommand struct {
+	Slot int
+}
+
+func (c *ClusterCountKeysInSlotCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("CLUSTER COUNTKEYSINSLOT")
+}
+
+func (c *ClusterCountKeysInSlotCommand) ExecuteServer(srv Server) (interface{}, error) {
+	keys, err := srv.ClusterKeysInSlot(c.Slot, -1)
+	if err != nil {
+		return nil, err
+	}
+	return len(keys), nil
+}
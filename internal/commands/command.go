@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"time"
+
 	"github.com/hardikphalet/go-redis/internal/store"
 )
 
@@ -31,3 +33,62 @@ func (c *CommandCommand) Execute(store store.Store) (interface{}, error) {
 		// ...
 	}, nil
 }
+
+// WriteCommand is implemented by commands that mutate the keyspace. Propagate
+// returns the command arguments in a form that replays deterministically,
+// for example with relative expiries rewritten as absolute timestamps, or
+// nil if the command turned out to change nothing.
+type WriteCommand interface {
+	Command
+	Propagate() []string
+}
+
+// MultiWriteCommand is implemented instead of WriteCommand by commands whose
+// change takes more than one command to replay, one for each key or aspect
+// it changed. PropagateAll returns them in order, or nil if nothing changed.
+type MultiWriteCommand interface {
+	Command
+	PropagateAll() [][]string
+}
+
+// IsWrite reports whether cmd is a WriteCommand or a MultiWriteCommand.
+func IsWrite(cmd Command) bool {
+	switch cmd.(type) {
+	case WriteCommand, MultiWriteCommand:
+		return true
+	}
+	return false
+}
+
+// Propagation returns the commands replaying the change the write command
+// cmd made: those of PropagateAll for a MultiWriteCommand, otherwise the one
+// of Propagate.
+func Propagation(cmd Command) [][]string {
+	switch cmd := cmd.(type) {
+	case MultiWriteCommand:
+		return cmd.PropagateAll()
+	case WriteCommand:
+		if args := cmd.Propagate(); args != nil {
+			return [][]string{args}
+		}
+	}
+	return nil
+}
+
+// KeyedCommand is implemented by commands that access keys. KeyArgs returns
+// the keys the command reads or writes, which cluster mode uses to route the
+// command to the node serving them.
+type KeyedCommand interface {
+	Command
+	KeyArgs() []string
+}
+
+// BlockingCommand is implemented by commands that can wait for data to
+// arrive, such as XREAD with BLOCK. Block reports whether the command
+// should wait when it replies with a null array, and for how long; a
+// timeout of 0 waits forever. The server runs the command again whenever
+// the data it waits for may have arrived.
+type BlockingCommand interface {
+	Command
+	Block() (timeout time.Duration, ok bool)
+}
@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"math"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -37,25 +40,25 @@ func TestSetCommand_Execute(t *testing.T) {
 			name: "simple set",
 			cmd: &SetCommand{
 				Key:     "key1",
-				Value:   "value1",
+				Value:   []byte("value1"),
 				Options: nil,
 			},
-			want: nil,
+			want: types.SimpleString("OK"),
 		},
 		{
 			name: "set with NX on non-existing key",
 			cmd: &SetCommand{
 				Key:     "key2",
-				Value:   "value2",
+				Value:   []byte("value2"),
 				Options: func() *options.SetOptions { o := options.NewSetOptions(); o.Set("NX"); return o }(),
 			},
-			want: nil,
+			want: types.SimpleString("OK"),
 		},
 		{
 			name: "set with GET",
 			cmd: &SetCommand{
 				Key:     "key1",
-				Value:   "newvalue",
+				Value:   []byte("newvalue"),
 				Options: func() *options.SetOptions { o := options.NewSetOptions(); o.Set("GET"); return o }(),
 			},
 			want: "value1",
@@ -399,6 +402,36 @@ func TestZRangeCommand_Execute(t *testing.T) {
 			},
 			want: []interface{}{"one", "two"},
 		},
+		{
+			name: "range by exclusive score in reverse",
+			cmd: &ZRangeCommand{
+				Key:   "zset1",
+				Start: options.ScoreBound{Score: 1, Exclusive: true},
+				Stop:  options.ScoreBound{Score: math.Inf(1)},
+				Options: func() *options.ZRangeOptions {
+					o := options.NewZRangeOptions()
+					o.SetRangeType("BYSCORE")
+					o.Rev = true
+					return o
+				}(),
+			},
+			want: []interface{}{"three", "two"},
+		},
+		{
+			name: "range by lex in reverse",
+			cmd: &ZRangeCommand{
+				Key:   "zset1",
+				Start: options.LexBound{Inf: -1},
+				Stop:  options.LexBound{Inf: 1},
+				Options: func() *options.ZRangeOptions {
+					o := options.NewZRangeOptions()
+					o.SetRangeType("BYLEX")
+					o.Rev = true
+					return o
+				}(),
+			},
+			want: []interface{}{"three", "two", "one"},
+		},
 		{
 			name: "range with scores",
 			cmd: &ZRangeCommand{
@@ -445,3 +478,519 @@ func TestZRangeCommand_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestZRankCommand_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.ZAdd("z", []types.ScoreMember{{Score: 1, Member: "a"}, {Score: 2.5, Member: "b"}}, nil)
+
+	if got, _ := (&ZRankCommand{Key: "z", Member: "b"}).Execute(s); got != 1 {
+		t.Errorf("ZRANK = %v, want 1", got)
+	}
+	got, _ := (&ZRankCommand{Key: "z", Member: "b", Rev: true, WithScore: true}).Execute(s)
+	if want := []interface{}{0, 2.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ZREVRANK WITHSCORE = %v, want %v", got, want)
+	}
+	if got, err := (&ZRankCommand{Key: "z", Member: "c", WithScore: true}).Execute(s); got != nil || err != nil {
+		t.Errorf("ZRANK of a missing member = %v, %v, want nil", got, err)
+	}
+	if got, _ := (&ZCountCommand{Key: "z", Min: options.ScoreBound{Score: 1, Exclusive: true}, Max: options.ScoreBound{Score: math.Inf(1)}}).Execute(s); got != 1 {
+		t.Errorf("ZCOUNT = %v, want 1", got)
+	}
+}
+
+func TestListCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	if got, err := (&PushCommand{Key: "l", Values: []string{"a", "b", "a"}}).Execute(s); err != nil || got != 3 {
+		t.Fatalf("RPUSH = %v, %v, want 3", got, err)
+	}
+	if got, _ := (&PopCommand{Key: "missing", Left: true}).Execute(s); got != nil {
+		t.Errorf("LPOP on a missing key = %v, want nil", got)
+	}
+	if got, _ := (&PopCommand{Key: "missing", Left: true, HasCount: true, Count: 1}).Execute(s); got.([]string) != nil {
+		t.Errorf("LPOP with count on a missing key = %v, want a null array", got)
+	}
+	if got, _ := (&LPosCommand{Key: "l", Element: "a", Rank: 1}).Execute(s); got != 0 {
+		t.Errorf("LPOS = %v, want 0", got)
+	}
+	if got, _ := (&LPosCommand{Key: "l", Element: "x", Rank: 1}).Execute(s); got != nil {
+		t.Errorf("LPOS without a match = %v, want nil", got)
+	}
+	got, _ := (&LPosCommand{Key: "l", Element: "a", Rank: 1, HasCount: true}).Execute(s)
+	if matches, ok := got.([]interface{}); !ok || len(matches) != 2 || matches[1] != 2 {
+		t.Errorf("LPOS COUNT 0 = %v, want [0 2]", got)
+	}
+	if got, _ := (&LSetCommand{Key: "l", Index: 0, Value: "z"}).Execute(s); got != types.SimpleString("OK") {
+		t.Errorf("LSET = %v, want OK", got)
+	}
+	if got, _ := (&PopCommand{Key: "l", Left: true}).Execute(s); got != "z" {
+		t.Errorf("LPOP = %v, want z", got)
+	}
+	if got, _ := (&DelCommand{Keys: []string{"l"}}).Execute(s); got != 1 {
+		t.Errorf("DEL of a list = %v, want 1", got)
+	}
+}
+
+func TestHashCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	if got, err := (&HSetCommand{Key: "h", Pairs: []types.FieldValue{{Field: "f", Value: "v"}}}).Execute(s); err != nil || got != 1 {
+		t.Fatalf("HSET = %v, %v, want 1", got, err)
+	}
+	if got, _ := (&HSetNXCommand{Key: "h", Field: "f", Value: "x"}).Execute(s); got != 0 {
+		t.Errorf("HSETNX on an existing field = %v, want 0", got)
+	}
+	if got, _ := (&HExistsCommand{Key: "h", Field: "f"}).Execute(s); got != 1 {
+		t.Errorf("HEXISTS = %v, want 1", got)
+	}
+	if got, _ := (&HGetAllCommand{Key: "h"}).Execute(s); !reflect.DeepEqual(got, []string{"f", "v"}) {
+		t.Errorf("HGETALL = %v, want [f v]", got)
+	}
+	if got, _ := (&HRandFieldCommand{Key: "h"}).Execute(s); got != "f" {
+		t.Errorf("HRANDFIELD = %v, want f", got)
+	}
+	if got, _ := (&HRandFieldCommand{Key: "h", Count: 1, HasCount: true, WithValues: true}).Execute(s); !reflect.DeepEqual(got, []string{"f", "v"}) {
+		t.Errorf("HRANDFIELD 1 WITHVALUES = %v, want [f v]", got)
+	}
+	if got, _ := (&HScanCommand{Key: "h", NoValues: true}).Execute(s); !reflect.DeepEqual(got, []interface{}{"0", []string{"f"}}) {
+		t.Errorf("HSCAN NOVALUES = %v, want [0 [f]]", got)
+	}
+
+	(&HExpireCommand{Key: "h", At: time.Now().Add(time.Minute), Fields: []string{"f"}}).Execute(s)
+	got, _ := (&HTTLCommand{Key: "h", Fields: []string{"f", "missing"}}).Execute(s)
+	if ttls, ok := got.([]interface{}); !ok || len(ttls) != 2 || ttls[0].(int64) < 58 || ttls[1] != int64(-2) {
+		t.Errorf("HTTL = %v, want [~60 -2]", got)
+	}
+	got, _ = (&HTTLCommand{Key: "h", Fields: []string{"f"}, Millis: true}).Execute(s)
+	if ttls, ok := got.([]interface{}); !ok || ttls[0].(int64) < 58000 {
+		t.Errorf("HPTTL = %v, want ~60000", got)
+	}
+}
+
+func TestSetCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	if got, err := (&SAddCommand{Key: "s", Members: []string{"a", "b"}}).Execute(s); err != nil || got != 2 {
+		t.Fatalf("SADD = %v, %v, want 2", got, err)
+	}
+	if got, _ := (&SMIsMemberCommand{Key: "s", Members: []string{"a", "x"}}).Execute(s); !reflect.DeepEqual(got, []interface{}{1, 0}) {
+		t.Errorf("SMISMEMBER = %v, want [1 0]", got)
+	}
+	if got, _ := (&SPopCommand{Key: "missing"}).Execute(s); got != nil {
+		t.Errorf("SPOP on a missing key = %v, want nil", got)
+	}
+	if got, _ := (&SPopCommand{Key: "missing", Count: 1, HasCount: true}).Execute(s); !reflect.DeepEqual(got, []string{}) {
+		t.Errorf("SPOP with count on a missing key = %v, want an empty array", got)
+	}
+
+	// SPOP propagates the members it removed, so that replicas and the AOF
+	// remove the same ones.
+	pop := &SPopCommand{Key: "s"}
+	popped, _ := pop.Execute(s)
+	if got := pop.Propagate(); !reflect.DeepEqual(got, []string{"SREM", "s", popped.(string)}) {
+		t.Errorf("SPOP Propagate() = %v, want SREM of %v", got, popped)
+	}
+	pop = &SPopCommand{Key: "missing", Count: 3, HasCount: true}
+	pop.Execute(s)
+	if got := pop.Propagate(); got != nil {
+		t.Errorf("SPOP Propagate() without members = %v, want nil", got)
+	}
+
+	if got, _ := (&SRandMemberCommand{Key: "missing"}).Execute(s); got != nil {
+		t.Errorf("SRANDMEMBER on a missing key = %v, want nil", got)
+	}
+	if got, _ := (&SInterCardCommand{Keys: []string{"s", "s"}}).Execute(s); got != 1 {
+		t.Errorf("SINTERCARD = %v, want 1", got)
+	}
+	if got, _ := (&SetOpStoreCommand{Op: "SUNION", Destination: "d", Keys: []string{"s"}}).Execute(s); got != 1 {
+		t.Errorf("SUNIONSTORE = %v, want 1", got)
+	}
+	if got, _ := (&SScanCommand{Key: "d"}).Execute(s); len(got.([]interface{})[1].([]string)) != 1 {
+		t.Errorf("SSCAN = %v, want one member", got)
+	}
+}
+
+func TestStreamCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	// XADD propagates the ID it generated, so that replicas and the AOF add
+	// the entry under the same ID.
+	add := &XAddCommand{Key: "st", ID: "*", Fields: []string{"f", "v"}, Options: &options.XAddOptions{}}
+	id, err := add.Execute(s)
+	if err != nil {
+		t.Fatalf("XADD error = %v", err)
+	}
+	if got := add.Propagate(); !reflect.DeepEqual(got, []string{"XADD", "st", id.(string), "f", "v"}) {
+		t.Errorf("XADD Propagate() = %v, want the generated ID %v", got, id)
+	}
+
+	if got, _ := (&XRangeCommand{Key: "st", End: types.MaxStreamID, Count: -1}).Execute(s); !reflect.DeepEqual(got, []interface{}{[]interface{}{id, []string{"f", "v"}}}) {
+		t.Errorf("XRANGE = %v", got)
+	}
+	if got, _ := (&XReadCommand{Keys: []string{"st"}, IDs: []string{"$"}}).Execute(s); got.([]interface{}) != nil {
+		t.Errorf("XREAD $ = %v, want a null array", got)
+	}
+
+	if _, err := (&XGroupCreateCommand{Key: "st", Group: "g", ID: "0", EntriesRead: -1}).Execute(s); err != nil {
+		t.Fatalf("XGROUP CREATE error = %v", err)
+	}
+	read := &XReadGroupCommand{Group: "g", Consumer: "c", Keys: []string{"st"}, IDs: []string{">"}}
+	if got, _ := read.Execute(s); len(got.([]interface{})) != 1 {
+		t.Errorf("XREADGROUP = %v, want one stream", got)
+	}
+	wantAll := [][]string{
+		{"XCLAIM", "st", "g", "c", "0", id.(string), "TIME", strconv.FormatInt(read.now.UnixMilli(), 10), "RETRYCOUNT", "1", "FORCE", "JUSTID"},
+		{"XGROUP", "SETID", "st", "g", id.(string), "ENTRIESREAD", "1"},
+	}
+	if got := Propagation(read); !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("XREADGROUP Propagation() = %v, want %v", got, wantAll)
+	}
+	read = &XReadGroupCommand{Group: "g", Consumer: "c", Keys: []string{"st"}, IDs: []string{">"}}
+	read.Execute(s)
+	if got := Propagation(read); got != nil {
+		t.Errorf("XREADGROUP Propagation() without new entries = %v, want nil", got)
+	}
+	history := &XReadGroupCommand{Group: "g", Consumer: "c", Keys: []string{"st"}, IDs: []string{"0"}}
+	history.Execute(s)
+	wantHistory := [][]string{{"XCLAIM", "st", "g", "c", "0", id.(string), "TIME", strconv.FormatInt(history.now.UnixMilli(), 10)}}
+	if got := Propagation(history); !reflect.DeepEqual(got, wantHistory) {
+		t.Errorf("XREADGROUP 0 Propagation() = %v, want %v", got, wantHistory)
+	}
+	extended := &XPendingCommand{Key: "st", Group: "g", Extended: true, End: types.MaxStreamID, Count: 10}
+	if got, _ := extended.Execute(s); len(got.([]interface{})) != 1 || got.([]interface{})[0].([]interface{})[3] != int64(2) {
+		t.Errorf("XPENDING after reading history = %v, want a delivery count of 2", got)
+	}
+
+	if got, _ := (&XPendingCommand{Key: "st", Group: "g"}).Execute(s); !reflect.DeepEqual(got, []interface{}{1, id, id, []interface{}{[]interface{}{"c", "1"}}}) {
+		t.Errorf("XPENDING = %v", got)
+	}
+
+	claimID, _ := types.ParseStreamID(id.(string), 0)
+	opts := options.NewXClaimOptions()
+	opts.JustID = true
+	claim := &XClaimCommand{Key: "st", Group: "g", Consumer: "d", IDs: []types.StreamID{claimID}, Options: opts}
+	if got, _ := claim.Execute(s); !reflect.DeepEqual(got, []string{id.(string)}) {
+		t.Errorf("XCLAIM JUSTID = %v", got)
+	}
+	want := []string{"XCLAIM", "st", "g", "d", "0", id.(string), "TIME", strconv.FormatInt(opts.Time.UnixMilli(), 10), "JUSTID"}
+	if got := claim.Propagate(); !reflect.DeepEqual(got, want) {
+		t.Errorf("XCLAIM Propagate() = %v, want %v", got, want)
+	}
+
+	if got, _ := (&XAckCommand{Key: "st", Group: "g", IDs: []types.StreamID{claimID}}).Execute(s); got != 1 {
+		t.Errorf("XACK = %v, want 1", got)
+	}
+	if got, _ := (&XPendingCommand{Key: "st", Group: "g"}).Execute(s); !reflect.DeepEqual(got, []interface{}{0, nil, nil, []interface{}(nil)}) {
+		t.Errorf("XPENDING with nothing pending = %v", got)
+	}
+}
+
+func TestGeoCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	add := &GeoAddCommand{Key: "Sicily", Locations: []types.GeoLocation{
+		{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo"},
+		{Longitude: 15.087269, Latitude: 37.502669, Member: "Catania"},
+	}}
+	if got, err := add.Execute(s); err != nil || got != 2 {
+		t.Fatalf("GEOADD = %v, %v, want 2", got, err)
+	}
+	if got, _ := (&GeoDistCommand{Key: "Sicily", Member1: "Palermo", Member2: "Catania", Unit: 1000}).Execute(s); got != "166.2742" {
+		t.Errorf("GEODIST km = %v, want 166.2742", got)
+	}
+	if got, _ := (&GeoDistCommand{Key: "Sicily", Member1: "Palermo", Member2: "missing", Unit: 1}).Execute(s); got != nil {
+		t.Errorf("GEODIST with a missing member = %v, want nil", got)
+	}
+	if got, _ := (&GeoHashCommand{Key: "Sicily", Members: []string{"Palermo", "missing"}}).Execute(s); !reflect.DeepEqual(got, []interface{}{"sqc8b49rny0", nil}) {
+		t.Errorf("GEOHASH = %v", got)
+	}
+	if got, _ := (&GeoPosCommand{Key: "Sicily", Members: []string{"missing"}}).Execute(s); !reflect.DeepEqual(got, []interface{}{[]interface{}(nil)}) {
+		t.Errorf("GEOPOS of a missing member = %v, want a null array", got)
+	}
+
+	search := &GeoSearchCommand{
+		Key:      "Sicily",
+		Options:  &options.GeoSearchOptions{FromLonLat: true, Longitude: 15, Latitude: 37, Radius: 200000, Sort: "ASC"},
+		Unit:     1000,
+		WithDist: true,
+	}
+	want := []interface{}{[]interface{}{"Catania", "56.4413"}, []interface{}{"Palermo", "190.4424"}}
+	if got, _ := search.Execute(s); !reflect.DeepEqual(got, want) {
+		t.Errorf("GEOSEARCH WITHDIST = %v, want %v", got, want)
+	}
+}
+
+func TestHyperLogLogCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	if got, err := (&PFAddCommand{Key: "a", Elements: []string{"x", "y"}}).Execute(s); err != nil || got != 1 {
+		t.Fatalf("PFADD = %v, %v, want 1", got, err)
+	}
+	if got, _ := (&PFAddCommand{Key: "a", Elements: []string{"x"}}).Execute(s); got != 0 {
+		t.Errorf("PFADD of an existing element = %v, want 0", got)
+	}
+	(&PFAddCommand{Key: "b", Elements: []string{"y", "z"}}).Execute(s)
+	if got, _ := (&PFMergeCommand{Destination: "c", Keys: []string{"a", "b"}}).Execute(s); got != types.SimpleString("OK") {
+		t.Errorf("PFMERGE = %v, want OK", got)
+	}
+	if got, _ := (&PFCountCommand{Keys: []string{"c"}}).Execute(s); got != int64(3) {
+		t.Errorf("PFCOUNT = %v, want 3", got)
+	}
+}
+
+func TestBitmapCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	if got, err := (&SetBitCommand{Key: "k", Offset: 7, Value: 1}).Execute(s); err != nil || got != 0 {
+		t.Fatalf("SETBIT = %v, %v, want 0", got, err)
+	}
+	if got, _ := (&BitCountCommand{Key: "k"}).Execute(s); got != int64(1) {
+		t.Errorf("BITCOUNT = %v, want 1", got)
+	}
+	if got, _ := (&BitPosCommand{Key: "k", Bit: 1}).Execute(s); got != int64(7) {
+		t.Errorf("BITPOS = %v, want 7", got)
+	}
+
+	get := []options.BitFieldOp{{Op: "GET", Bits: 8, Overflow: "WRAP"}}
+	if got, _ := (&BitFieldRoCommand{Key: "k", Ops: get}).Execute(s); !reflect.DeepEqual(got, []interface{}{int64(1)}) {
+		t.Errorf("BITFIELD_RO = %v, want [1]", got)
+	}
+	if got := (&BitFieldCommand{Key: "k", Ops: get}).Propagate(); got != nil {
+		t.Errorf("BITFIELD Propagate() with GET alone = %v, want nil", got)
+	}
+}
+
+func TestStringCommands_Execute(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	mset := &MSetCommand{Pairs: []types.KeyValue{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2")}}}
+	if got, err := mset.Execute(s); err != nil || got != types.SimpleString("OK") {
+		t.Fatalf("MSET = %v, %v, want OK", got, err)
+	}
+	if got, _ := (&MSetCommand{Pairs: []types.KeyValue{{Key: "b", Value: []byte("3")}, {Key: "c", Value: []byte("3")}}, NX: true}).Execute(s); got != 0 {
+		t.Errorf("MSETNX with an existing key = %v, want 0", got)
+	}
+	if got, _ := (&MGetCommand{Keys: []string{"a", "c", "b"}}).Execute(s); !reflect.DeepEqual(got, []interface{}{"1", nil, "2"}) {
+		t.Errorf("MGET = %v, want [1 <nil> 2]", got)
+	}
+
+	(&MSetCommand{Pairs: []types.KeyValue{{Key: "k1", Value: []byte("ohmytext")}, {Key: "k2", Value: []byte("mynewtext")}}}).Execute(s)
+	if got, _ := (&LCSCommand{Key1: "k1", Key2: "k2"}).Execute(s); got != "mytext" {
+		t.Errorf("LCS = %v, want mytext", got)
+	}
+	if got, _ := (&LCSCommand{Key1: "k1", Key2: "k2", Len: true}).Execute(s); got != 6 {
+		t.Errorf("LCS LEN = %v, want 6", got)
+	}
+	want := []interface{}{
+		"matches", []interface{}{
+			[]interface{}{[]interface{}{4, 7}, []interface{}{5, 8}, 4},
+		},
+		"len", 6,
+	}
+	if got, _ := (&LCSCommand{Key1: "k1", Key2: "k2", Idx: true, MinMatchLen: 4, WithMatchLen: true}).Execute(s); !reflect.DeepEqual(got, want) {
+		t.Errorf("LCS IDX = %v, want %v", got, want)
+	}
+	if _, err := (&LCSCommand{Key1: "k1", Key2: "k2", Len: true, Idx: true}).Execute(s); err == nil {
+		t.Errorf("LCS with LEN and IDX succeeded")
+	}
+
+	if got := (&GetExCommand{Key: "a", Options: options.NewSetOptions()}).Propagate(); got != nil {
+		t.Errorf("GETEX Propagate() without options = %v, want nil", got)
+	}
+}
+
+func TestWriteCommand_Propagate(t *testing.T) {
+	exOpts := options.NewSetOptions()
+	exOpts.SetExpiry("EX", 100)
+	at := exOpts.ExpiryTime.UnixMilli()
+
+	tests := []struct {
+		name string
+		cmd  WriteCommand
+		want []string
+	}{
+		{
+			name: "plain set",
+			cmd:  &SetCommand{Key: "k", Value: []byte("v")},
+			want: []string{"SET", "k", "v"},
+		},
+		{
+			name: "set with relative expiry becomes PXAT",
+			cmd:  &SetCommand{Key: "k", Value: []byte("v"), Options: exOpts},
+			want: []string{"SET", "k", "v", "PXAT", strconv.FormatInt(at, 10)},
+		},
+		{
+			name: "expire at",
+			cmd:  &ExpireAtCommand{Key: "k", At: time.UnixMilli(1700000000123)},
+			want: []string{"PEXPIREAT", "k", "1700000000123"},
+		},
+		{
+			name: "del",
+			cmd:  &DelCommand{Keys: []string{"a", "b"}},
+			want: []string{"DEL", "a", "b"},
+		},
+		{
+			name: "decr becomes INCRBY",
+			cmd:  &IncrByCommand{Key: "k", Delta: -1},
+			want: []string{"INCRBY", "k", "-1"},
+		},
+		{
+			name: "incrbyfloat",
+			cmd:  &IncrByFloatCommand{Key: "k", Delta: 0.1},
+			want: []string{"INCRBYFLOAT", "k", "0.1"},
+		},
+		{
+			name: "setnx becomes MSETNX",
+			cmd:  &MSetCommand{Pairs: []types.KeyValue{{Key: "k", Value: []byte("v")}}, NX: true},
+			want: []string{"MSETNX", "k", "v"},
+		},
+		{
+			name: "getex persist",
+			cmd: &GetExCommand{Key: "k", Options: func() *options.SetOptions {
+				o := options.NewSetOptions()
+				o.SetExpiry("PERSIST", 0)
+				return o
+			}()},
+			want: []string{"GETEX", "k", "PERSIST"},
+		},
+		{
+			name: "getex with relative expiry becomes PXAT",
+			cmd:  &GetExCommand{Key: "k", Options: exOpts},
+			want: []string{"GETEX", "k", "PXAT", strconv.FormatInt(at, 10)},
+		},
+		{
+			name: "zadd",
+			cmd:  &ZAddCommand{Key: "z", Members: []types.ScoreMember{{Score: 1.5, Member: "m"}}},
+			want: []string{"ZADD", "z", "1.5", "m"},
+		},
+		{
+			name: "zremrangebylex",
+			cmd:  &ZRemRangeByLexCommand{Key: "z", Min: options.LexBound{Value: "a", Exclusive: true}, Max: options.LexBound{Inf: 1}, removed: 2},
+			want: []string{"ZREMRANGEBYLEX", "z", "(a", "+"},
+		},
+		{
+			name: "lpushx",
+			cmd:  &PushCommand{Key: "l", Values: []string{"a", "b"}, Left: true, OnlyIfExists: true},
+			want: []string{"LPUSHX", "l", "a", "b"},
+		},
+		{
+			name: "rpop with count",
+			cmd:  &PopCommand{Key: "l", Count: 2, HasCount: true},
+			want: []string{"RPOP", "l", "2"},
+		},
+		{
+			name: "hset",
+			cmd:  &HSetCommand{Key: "h", Pairs: []types.FieldValue{{Field: "f", Value: "v"}}},
+			want: []string{"HSET", "h", "f", "v"},
+		},
+		{
+			name: "hincrbyfloat",
+			cmd:  &HIncrByFloatCommand{Key: "h", Field: "f", Delta: 0.5},
+			want: []string{"HINCRBYFLOAT", "h", "f", "0.5"},
+		},
+		{
+			name: "hexpire becomes HPEXPIREAT",
+			cmd:  &HExpireCommand{Key: "h", At: time.UnixMilli(1700000000123), Fields: []string{"a", "b"}},
+			want: []string{"HPEXPIREAT", "h", "1700000000123", "FIELDS", "2", "a", "b"},
+		},
+		{
+			name: "hsetex",
+			cmd: &HSetExCommand{Key: "h", Pairs: []types.FieldValue{{Field: "f", Value: "v"}}, Options: func() *options.HSetExOptions {
+				o := options.NewHSetExOptions()
+				o.SetExpiry("PXAT", 1700000000123)
+				return o
+			}()},
+			want: []string{"HSETEX", "h", "PXAT", "1700000000123", "FIELDS", "1", "f", "v"},
+		},
+		{
+			name: "sinterstore",
+			cmd:  &SetOpStoreCommand{Op: "SINTER", Destination: "d", Keys: []string{"a", "b"}},
+			want: []string{"SINTERSTORE", "d", "a", "b"},
+		},
+		{
+			name: "smove",
+			cmd:  &SMoveCommand{Source: "a", Destination: "b", Member: "m"},
+			want: []string{"SMOVE", "a", "b", "m"},
+		},
+		{
+			name: "lmove",
+			cmd:  &LMoveCommand{Source: "a", Destination: "b", FromLeft: true},
+			want: []string{"LMOVE", "a", "b", "LEFT", "RIGHT"},
+		},
+		{
+			name: "xtrim",
+			cmd:  &XTrimCommand{Key: "st", Options: &options.XTrimOptions{Strategy: "MAXLEN", Approx: true, MaxLen: 10, Limit: 5}},
+			want: []string{"XTRIM", "st", "MAXLEN", "~", "10", "LIMIT", "5"},
+		},
+		{
+			name: "xgroup create",
+			cmd:  &XGroupCreateCommand{Key: "st", Group: "g", ID: "$", MkStream: true, EntriesRead: -1},
+			want: []string{"XGROUP", "CREATE", "st", "g", "$", "MKSTREAM"},
+		},
+		{
+			name: "xsetid",
+			cmd:  &XSetIDCommand{Key: "st", LastID: types.StreamID{Ms: 5, Seq: 1}, EntriesAdded: 3},
+			want: []string{"XSETID", "st", "5-1", "ENTRIESADDED", "3"},
+		},
+		{
+			name: "geoadd",
+			cmd: &GeoAddCommand{Key: "g", Locations: []types.GeoLocation{{Longitude: 13.361389, Latitude: 38.115556, Member: "m"}}, Options: func() *options.GeoAddOptions {
+				o := options.NewGeoAddOptions()
+				o.Set("XX")
+				return o
+			}()},
+			want: []string{"GEOADD", "g", "XX", "13.361389", "38.115556", "m"},
+		},
+		{
+			name: "geosearchstore in meters",
+			cmd:  &GeoSearchStoreCommand{Destination: "d", Source: "g", Options: &options.GeoSearchOptions{FromMember: "m", Radius: 1500, Count: 2}, Unit: 0.3048},
+			want: []string{"GEOSEARCHSTORE", "d", "g", "FROMMEMBER", "m", "BYRADIUS", "1500", "m", "COUNT", "2"},
+		},
+		{
+			name: "geosearchstore of distances in kilometers",
+			cmd:  &GeoSearchStoreCommand{Destination: "d", Source: "g", Options: &options.GeoSearchOptions{FromMember: "m", ByBox: true, Width: 1500, Height: 200e3}, Unit: 1000, StoreDist: true},
+			want: []string{"GEOSEARCHSTORE", "d", "g", "FROMMEMBER", "m", "BYBOX", "1.5", "200", "km", "STOREDIST"},
+		},
+		{
+			name: "pfmerge",
+			cmd:  &PFMergeCommand{Destination: "d", Keys: []string{"a", "b"}},
+			want: []string{"PFMERGE", "d", "a", "b"},
+		},
+		{
+			name: "bitfield drops GET",
+			cmd: &BitFieldCommand{Key: "k", Ops: []options.BitFieldOp{
+				{Op: "INCRBY", Signed: true, Bits: 5, Offset: 100, Value: 1, Overflow: "WRAP"},
+				{Op: "GET", Bits: 4, Overflow: "WRAP"},
+				{Op: "SET", Bits: 8, Offset: 8, Value: 255, Overflow: "FAIL"},
+			}},
+			want: []string{"BITFIELD", "k", "INCRBY", "i5", "100", "1", "OVERFLOW", "FAIL", "SET", "u8", "8", "255"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cmd.Propagate()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Propagate() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Propagate() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	remove := &ZRemRangeByLexCommand{Key: "missing", Min: options.LexBound{Inf: -1}, Max: options.LexBound{Inf: 1}}
+	if n, _ := remove.Execute(store.NewMemoryStore()); n != 0 || remove.Propagate() != nil {
+		t.Errorf("ZREMRANGEBYLEX removing nothing = %v, propagated %v", n, remove.Propagate())
+	}
+
+	expire := &ExpireCommand{Key: "k", TTL: time.Minute}
+	got := expire.Propagate()
+	ms, err := strconv.ParseInt(got[2], 10, 64)
+	if got[0] != "PEXPIREAT" || err != nil || time.Until(time.UnixMilli(ms)) <= 0 {
+		t.Errorf("ExpireCommand.Propagate() = %v, want PEXPIREAT in the future", got)
+	}
+}
@@ -9,8 +9,7 @@ type DelCommand struct {
 func (c *DelCommand) Execute(store store.Store) (interface{}, error) {
 	var deleted int
 	for _, key := range c.Keys {
-		val, _ := store.Get(key)
-		if val != nil {
+		if store.Exists(key) {
 			err := store.Del(key)
 			if err == nil {
 				deleted++
@@ -19,3 +18,11 @@ func (c *DelCommand) Execute(store store.Store) (interface{}, error) {
 	}
 	return deleted, nil
 }
+
+func (c *DelCommand) Propagate() []string {
+	return append([]string{"DEL"}, c.Keys...)
+}
+
+func (c *DelCommand) KeyArgs() []string {
+	return c.Keys
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/hardikphalet/go-redis/internal/commands/options"
@@ -16,3 +17,31 @@ type ExpireCommand struct {
 func (c *ExpireCommand) Execute(store store.Store) (interface{}, error) {
 	return nil, store.Expire(c.Key, c.TTL, c.Options)
 }
+
+func (c *ExpireCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+func (c *ExpireCommand) Propagate() []string {
+	at := time.Now().Add(c.TTL)
+	return []string{"PEXPIREAT", c.Key, strconv.FormatInt(at.UnixMilli(), 10)}
+}
+
+// ExpireAtCommand sets an absolute expiry time, as used by EXPIREAT and PEXPIREAT.
+type ExpireAtCommand struct {
+	Key     string
+	At      time.Time
+	Options *options.ExpireOptions
+}
+
+func (c *ExpireAtCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, store.Expire(c.Key, time.Until(c.At), c.Options)
+}
+
+func (c *ExpireAtCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+func (c *ExpireAtCommand) Propagate() []string {
+	return []string{"PEXPIREAT", c.Key, strconv.FormatInt(c.At.UnixMilli(), 10)}
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type GeoAddCommand struct {
+	Key       string
+	Locations []types.GeoLocation
+	Options   *options.GeoAddOptions
+}
+
+func (c *GeoAddCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GeoAdd(c.Key, c.Locations, c.Options)
+}
+
+func (c *GeoAddCommand) Propagate() []string {
+	args := []string{"GEOADD", c.Key}
+	if c.Options != nil {
+		args = append(args, c.Options.GetActive()...)
+	}
+	for _, loc := range c.Locations {
+		args = append(args, formatCoord(loc.Longitude), formatCoord(loc.Latitude), loc.Member)
+	}
+	return args
+}
+
+func (c *GeoAddCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GeoPosCommand struct {
+	Key     string
+	Members []string
+}
+
+func (c *GeoPosCommand) Execute(store store.Store) (interface{}, error) {
+	locations, err := store.GeoPos(c.Key, c.Members)
+	if err != nil {
+		return nil, err
+	}
+	reply := make([]interface{}, len(locations))
+	for i, loc := range locations {
+		if loc == nil {
+			reply[i] = []interface{}(nil)
+			continue
+		}
+		reply[i] = []interface{}{formatCoord(loc.Longitude), formatCoord(loc.Latitude)}
+	}
+	return reply, nil
+}
+
+func (c *GeoPosCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GeoDistCommand struct {
+	Key     string
+	Member1 string
+	Member2 string
+	Unit    float64 // Meters per unit of the reply
+}
+
+func (c *GeoDistCommand) Execute(store store.Store) (interface{}, error) {
+	dist, ok, err := store.GeoDist(c.Key, c.Member1, c.Member2)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return formatDist(dist / c.Unit), nil
+}
+
+func (c *GeoDistCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GeoHashCommand struct {
+	Key     string
+	Members []string
+}
+
+func (c *GeoHashCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GeoHash(c.Key, c.Members)
+}
+
+func (c *GeoHashCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// GeoSearchCommand finds the members of a geo set within a radius or a box.
+// Each member is replied as its name alone, or with any WITH option as an
+// array of the name followed by the distance, the geohash and the
+// coordinates, in that order.
+type GeoSearchCommand struct {
+	Key       string
+	Options   *options.GeoSearchOptions
+	Unit      float64 // Meters per unit of distances, both given and replied
+	WithCoord bool
+	WithDist  bool
+	WithHash  bool
+}
+
+func (c *GeoSearchCommand) Execute(store store.Store) (interface{}, error) {
+	results, err := store.GeoSearch(c.Key, c.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make([]interface{}, len(results))
+	for i, r := range results {
+		if !c.WithCoord && !c.WithDist && !c.WithHash {
+			reply[i] = r.Member
+			continue
+		}
+		item := []interface{}{r.Member}
+		if c.WithDist {
+			item = append(item, formatDist(r.Distance/c.Unit))
+		}
+		if c.WithHash {
+			item = append(item, int64(r.Hash))
+		}
+		if c.WithCoord {
+			item = append(item, []interface{}{formatCoord(r.Longitude), formatCoord(r.Latitude)})
+		}
+		reply[i] = item
+	}
+	return reply, nil
+}
+
+func (c *GeoSearchCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// GeoSearchStoreCommand stores the members GEOSEARCH would find in a sorted
+// set. It is propagated with distances in meters, unless it stores distances
+// in another unit.
+type GeoSearchStoreCommand struct {
+	Destination string
+	Source      string
+	Options     *options.GeoSearchOptions
+	Unit        float64 // Meters per unit of distances, both given and stored
+	StoreDist   bool
+}
+
+func (c *GeoSearchStoreCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GeoSearchStore(c.Destination, c.Source, c.Options, c.StoreDist, c.Unit)
+}
+
+func (c *GeoSearchStoreCommand) Propagate() []string {
+	o := c.Options
+	args := []string{"GEOSEARCHSTORE", c.Destination, c.Source}
+	if o.FromLonLat {
+		args = append(args, "FROMLONLAT", formatCoord(o.Longitude), formatCoord(o.Latitude))
+	} else {
+		args = append(args, "FROMMEMBER", o.FromMember)
+	}
+	unit, name := 1.0, "m"
+	if c.StoreDist {
+		unit, name = c.Unit, geoUnitName(c.Unit)
+	}
+	if o.ByBox {
+		args = append(args, "BYBOX", formatCoord(o.Width/unit), formatCoord(o.Height/unit), name)
+	} else {
+		args = append(args, "BYRADIUS", formatCoord(o.Radius/unit), name)
+	}
+	if o.Sort != "" {
+		args = append(args, o.Sort)
+	}
+	if o.Count > 0 {
+		args = append(args, "COUNT", strconv.Itoa(o.Count))
+		if o.Any {
+			args = append(args, "ANY")
+		}
+	}
+	if c.StoreDist {
+		args = append(args, "STOREDIST")
+	}
+	return args
+}
+
+func (c *GeoSearchStoreCommand) KeyArgs() []string {
+	return []string{c.Destination, c.Source}
+}
+
+// geoUnitName returns the name of the distance unit of unit meters.
+func geoUnitName(unit float64) string {
+	for _, name := range []string{"km", "ft", "mi"} {
+		if factor, _ := options.GeoUnitFactor(name); factor == unit {
+			return name
+		}
+	}
+	return "m"
+}
+
+// formatCoord formats a coordinate, or a distance given as an argument,
+// without losing precision.
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatDist formats a distance in a reply, which Redis rounds to four
+// decimal places.
+func formatDist(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
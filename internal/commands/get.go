@@ -9,3 +9,7 @@ type GetCommand struct {
 func (c *GetCommand) Execute(store store.Store) (interface{}, error) {
 	return store.Get(c.Key)
 }
+
+func (c *GetCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
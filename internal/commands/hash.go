@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type HSetCommand struct {
+	Key   string
+	Pairs []types.FieldValue
+}
+
+func (c *HSetCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HSet(c.Key, c.Pairs)
+}
+
+func (c *HSetCommand) Propagate() []string {
+	args := make([]string, 0, 2+2*len(c.Pairs))
+	args = append(args, "HSET", c.Key)
+	return append(args, flattenPairs(c.Pairs, true)...)
+}
+
+func (c *HSetCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HSetNXCommand struct {
+	Key   string
+	Field string
+	Value string
+}
+
+func (c *HSetNXCommand) Execute(store store.Store) (interface{}, error) {
+	set, err := store.HSetNX(c.Key, c.Field, c.Value)
+	return boolReply(set), err
+}
+
+func (c *HSetNXCommand) Propagate() []string {
+	return []string{"HSETNX", c.Key, c.Field, c.Value}
+}
+
+func (c *HSetNXCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HGetCommand struct {
+	Key   string
+	Field string
+}
+
+func (c *HGetCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HGet(c.Key, c.Field)
+}
+
+func (c *HGetCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HMGetCommand struct {
+	Key    string
+	Fields []string
+}
+
+func (c *HMGetCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HMGet(c.Key, c.Fields)
+}
+
+func (c *HMGetCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HDelCommand struct {
+	Key    string
+	Fields []string
+}
+
+func (c *HDelCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HDel(c.Key, c.Fields)
+}
+
+func (c *HDelCommand) Propagate() []string {
+	return append([]string{"HDEL", c.Key}, c.Fields...)
+}
+
+func (c *HDelCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HExistsCommand struct {
+	Key   string
+	Field string
+}
+
+func (c *HExistsCommand) Execute(store store.Store) (interface{}, error) {
+	exists, err := store.HExists(c.Key, c.Field)
+	return boolReply(exists), err
+}
+
+func (c *HExistsCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HLenCommand struct {
+	Key string
+}
+
+func (c *HLenCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HLen(c.Key)
+}
+
+func (c *HLenCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HKeysCommand struct {
+	Key string
+}
+
+func (c *HKeysCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HKeys(c.Key)
+}
+
+func (c *HKeysCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HValsCommand struct {
+	Key string
+}
+
+func (c *HValsCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HVals(c.Key)
+}
+
+func (c *HValsCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HGetAllCommand struct {
+	Key string
+}
+
+func (c *HGetAllCommand) Execute(store store.Store) (interface{}, error) {
+	pairs, err := store.HGetAll(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	return flattenPairs(pairs, true), nil
+}
+
+func (c *HGetAllCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HIncrByCommand struct {
+	Key   string
+	Field string
+	Delta int64
+}
+
+func (c *HIncrByCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HIncrBy(c.Key, c.Field, c.Delta)
+}
+
+func (c *HIncrByCommand) Propagate() []string {
+	return []string{"HINCRBY", c.Key, c.Field, strconv.FormatInt(c.Delta, 10)}
+}
+
+func (c *HIncrByCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HIncrByFloatCommand struct {
+	Key   string
+	Field string
+	Delta float64
+}
+
+func (c *HIncrByFloatCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HIncrByFloat(c.Key, c.Field, c.Delta)
+}
+
+func (c *HIncrByFloatCommand) Propagate() []string {
+	return []string{"HINCRBYFLOAT", c.Key, c.Field, strconv.FormatFloat(c.Delta, 'g', -1, 64)}
+}
+
+func (c *HIncrByFloatCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HStrLenCommand struct {
+	Key   string
+	Field string
+}
+
+func (c *HStrLenCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HStrLen(c.Key, c.Field)
+}
+
+func (c *HStrLenCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HRandFieldCommand struct {
+	Key        string
+	Count      int
+	HasCount   bool // Reply with an array even for a single field
+	WithValues bool
+}
+
+func (c *HRandFieldCommand) Execute(store store.Store) (interface{}, error) {
+	count := 1
+	if c.HasCount {
+		count = c.Count
+	}
+
+	pairs, err := store.HRandField(c.Key, count)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.HasCount {
+		return flattenPairs(pairs, c.WithValues), nil
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	return pairs[0].Field, nil
+}
+
+func (c *HRandFieldCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HScanCommand struct {
+	Key      string
+	Cursor   uint64
+	Pattern  string
+	Count    int
+	NoValues bool
+}
+
+func (c *HScanCommand) Execute(store store.Store) (interface{}, error) {
+	next, pairs, err := store.HScan(c.Key, c.Cursor, c.Pattern, c.Count)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{strconv.FormatUint(next, 10), flattenPairs(pairs, !c.NoValues)}, nil
+}
+
+func (c *HScanCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// flattenPairs returns the fields of pairs, each followed by its value if
+// withValues is set.
+func flattenPairs(pairs []types.FieldValue, withValues bool) []string {
+	size := len(pairs)
+	if withValues {
+		size *= 2
+	}
+	flat := make([]string, 0, size)
+	for _, p := range pairs {
+		flat = append(flat, p.Field)
+		if withValues {
+			flat = append(flat, p.Value)
+		}
+	}
+	return flat
+}
+
+// boolReply converts b to the integer reply 1 or 0.
+func boolReply(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
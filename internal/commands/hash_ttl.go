@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// HExpireCommand sets the expiry time of hash fields, as used by HEXPIRE,
+// HPEXPIRE, HEXPIREAT and HPEXPIREAT. Relative TTLs are converted to an
+// absolute time when the command is parsed.
+type HExpireCommand struct {
+	Key     string
+	At      time.Time
+	Fields  []string
+	Options *options.ExpireOptions
+}
+
+func (c *HExpireCommand) Execute(store store.Store) (interface{}, error) {
+	codes, err := store.HExpire(c.Key, c.At, c.Fields, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	return intsReply(codes), nil
+}
+
+func (c *HExpireCommand) Propagate() []string {
+	args := []string{"HPEXPIREAT", c.Key, strconv.FormatInt(c.At.UnixMilli(), 10)}
+	if c.Options != nil {
+		args = append(args, c.Options.GetActive()...)
+	}
+	return append(args, fieldsArgs(c.Fields)...)
+}
+
+func (c *HExpireCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// HTTLCommand returns the remaining TTL of hash fields in seconds, or in
+// milliseconds for HPTTL.
+type HTTLCommand struct {
+	Key    string
+	Fields []string
+	Millis bool
+}
+
+func (c *HTTLCommand) Execute(store store.Store) (interface{}, error) {
+	times, err := store.HExpireTime(c.Key, c.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	result := make([]interface{}, len(times))
+	for i, at := range times {
+		if at < 0 {
+			result[i] = at
+			continue
+		}
+		remaining := max(at-now, 0)
+		if !c.Millis {
+			remaining /= 1000
+		}
+		result[i] = remaining
+	}
+	return result, nil
+}
+
+func (c *HTTLCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HPersistCommand struct {
+	Key    string
+	Fields []string
+}
+
+func (c *HPersistCommand) Execute(store store.Store) (interface{}, error) {
+	codes, err := store.HPersist(c.Key, c.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return intsReply(codes), nil
+}
+
+func (c *HPersistCommand) Propagate() []string {
+	return append([]string{"HPERSIST", c.Key}, fieldsArgs(c.Fields)...)
+}
+
+func (c *HPersistCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HGetExCommand struct {
+	Key     string
+	Fields  []string
+	Options *options.HGetExOptions
+}
+
+func (c *HGetExCommand) Execute(store store.Store) (interface{}, error) {
+	return store.HGetEx(c.Key, c.Fields, c.Options)
+}
+
+func (c *HGetExCommand) Propagate() []string {
+	args := []string{"HGETEX", c.Key}
+	if c.Options != nil {
+		if c.Options.IsPERSIST() {
+			args = append(args, "PERSIST")
+		} else if c.Options.ExpiryType != "" {
+			args = append(args, "PXAT", strconv.FormatInt(c.Options.ExpiryTime.UnixMilli(), 10))
+		}
+	}
+	return append(args, fieldsArgs(c.Fields)...)
+}
+
+func (c *HGetExCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type HSetExCommand struct {
+	Key     string
+	Pairs   []types.FieldValue
+	Options *options.HSetExOptions
+}
+
+func (c *HSetExCommand) Execute(store store.Store) (interface{}, error) {
+	set, err := store.HSetEx(c.Key, c.Pairs, c.Options)
+	return boolReply(set), err
+}
+
+func (c *HSetExCommand) Propagate() []string {
+	args := []string{"HSETEX", c.Key}
+	if c.Options != nil {
+		args = append(args, c.Options.GetActive()...)
+		if c.Options.IsKEEPTTL() {
+			args = append(args, "KEEPTTL")
+		} else if c.Options.ExpiryType != "" {
+			args = append(args, "PXAT", strconv.FormatInt(c.Options.ExpiryTime.UnixMilli(), 10))
+		}
+	}
+	args = append(args, "FIELDS", strconv.Itoa(len(c.Pairs)))
+	return append(args, flattenPairs(c.Pairs, true)...)
+}
+
+func (c *HSetExCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// fieldsArgs returns the "FIELDS numfields field ..." arguments naming
+// fields.
+func fieldsArgs(fields []string) []string {
+	args := make([]string, 0, 2+len(fields))
+	args = append(args, "FIELDS", strconv.Itoa(len(fields)))
+	return append(args, fields...)
+}
+
+// intsReply converts codes to an array reply.
+func intsReply(codes []int) []interface{} {
+	result := make([]interface{}, len(codes))
+	for i, code := range codes {
+		result[i] = code
+	}
+	return result
+}
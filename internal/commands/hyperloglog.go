@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type PFAddCommand struct {
+	Key      string
+	Elements []string
+}
+
+func (c *PFAddCommand) Execute(store store.Store) (interface{}, error) {
+	updated, err := store.PFAdd(c.Key, c.Elements)
+	return boolReply(updated), err
+}
+
+func (c *PFAddCommand) Propagate() []string {
+	return append([]string{"PFADD", c.Key}, c.Elements...)
+}
+
+func (c *PFAddCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// PFCountCommand estimates the number of distinct elements in the union of
+// one or more HyperLogLogs.
+type PFCountCommand struct {
+	Keys []string
+}
+
+func (c *PFCountCommand) Execute(store store.Store) (interface{}, error) {
+	return store.PFCount(c.Keys)
+}
+
+func (c *PFCountCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+type PFMergeCommand struct {
+	Destination string
+	Keys        []string
+}
+
+func (c *PFMergeCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.PFMerge(c.Destination, c.Keys); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *PFMergeCommand) Propagate() []string {
+	return append([]string{"PFMERGE", c.Destination}, c.Keys...)
+}
+
+func (c *PFMergeCommand) KeyArgs() []string {
+	return append([]string{c.Destination}, c.Keys...)
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+)
+
+// IncrByCommand adds Delta to an integer string, as used by INCR, DECR,
+// INCRBY and DECRBY, all of which are propagated as INCRBY.
+type IncrByCommand struct {
+	Key   string
+	Delta int64
+}
+
+func (c *IncrByCommand) Execute(store store.Store) (interface{}, error) {
+	return store.IncrBy(c.Key, c.Delta)
+}
+
+func (c *IncrByCommand) Propagate() []string {
+	return []string{"INCRBY", c.Key, strconv.FormatInt(c.Delta, 10)}
+}
+
+func (c *IncrByCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type IncrByFloatCommand struct {
+	Key   string
+	Delta float64
+}
+
+func (c *IncrByFloatCommand) Execute(store store.Store) (interface{}, error) {
+	return store.IncrByFloat(c.Key, c.Delta)
+}
+
+func (c *IncrByFloatCommand) Propagate() []string {
+	return []string{"INCRBYFLOAT", c.Key, strconv.FormatFloat(c.Delta, 'g', -1, 64)}
+}
+
+func (c *IncrByFloatCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
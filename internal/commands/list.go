@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// PushCommand implements LPUSH, RPUSH, LPUSHX and RPUSHX.
+type PushCommand struct {
+	Key          string
+	Values       []string
+	Left         bool // Push onto the head rather than the tail
+	OnlyIfExists bool // Do nothing unless the list already exists
+}
+
+func (c *PushCommand) Execute(store store.Store) (interface{}, error) {
+	if c.Left {
+		return store.LPush(c.Key, c.Values, c.OnlyIfExists)
+	}
+	return store.RPush(c.Key, c.Values, c.OnlyIfExists)
+}
+
+func (c *PushCommand) Propagate() []string {
+	name := "RPUSH"
+	if c.Left {
+		name = "LPUSH"
+	}
+	if c.OnlyIfExists {
+		name += "X"
+	}
+	return append([]string{name, c.Key}, c.Values...)
+}
+
+func (c *PushCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// PopCommand implements LPOP and RPOP.
+type PopCommand struct {
+	Key      string
+	Left     bool
+	Count    int
+	HasCount bool // Reply with an array even when popping one element
+}
+
+func (c *PopCommand) Execute(store store.Store) (interface{}, error) {
+	count := 1
+	if c.HasCount {
+		count = c.Count
+	}
+
+	var popped []string
+	var err error
+	if c.Left {
+		popped, err = store.LPop(c.Key, count)
+	} else {
+		popped, err = store.RPop(c.Key, count)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.HasCount {
+		return popped, nil
+	}
+	if len(popped) == 0 {
+		return nil, nil
+	}
+	return popped[0], nil
+}
+
+func (c *PopCommand) Propagate() []string {
+	args := []string{"RPOP", c.Key}
+	if c.Left {
+		args[0] = "LPOP"
+	}
+	if c.HasCount {
+		args = append(args, strconv.Itoa(c.Count))
+	}
+	return args
+}
+
+func (c *PopCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LLenCommand struct {
+	Key string
+}
+
+func (c *LLenCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LLen(c.Key)
+}
+
+func (c *LLenCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LRangeCommand struct {
+	Key   string
+	Start int
+	Stop  int
+}
+
+func (c *LRangeCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LRange(c.Key, c.Start, c.Stop)
+}
+
+func (c *LRangeCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LIndexCommand struct {
+	Key   string
+	Index int
+}
+
+func (c *LIndexCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LIndex(c.Key, c.Index)
+}
+
+func (c *LIndexCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LSetCommand struct {
+	Key   string
+	Index int
+	Value string
+}
+
+func (c *LSetCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.LSet(c.Key, c.Index, c.Value); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *LSetCommand) Propagate() []string {
+	return []string{"LSET", c.Key, strconv.Itoa(c.Index), c.Value}
+}
+
+func (c *LSetCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LInsertCommand struct {
+	Key    string
+	Before bool
+	Pivot  string
+	Value  string
+}
+
+func (c *LInsertCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LInsert(c.Key, c.Before, c.Pivot, c.Value)
+}
+
+func (c *LInsertCommand) Propagate() []string {
+	where := "AFTER"
+	if c.Before {
+		where = "BEFORE"
+	}
+	return []string{"LINSERT", c.Key, where, c.Pivot, c.Value}
+}
+
+func (c *LInsertCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LRemCommand struct {
+	Key   string
+	Count int
+	Value string
+}
+
+func (c *LRemCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LRem(c.Key, c.Count, c.Value)
+}
+
+func (c *LRemCommand) Propagate() []string {
+	return []string{"LREM", c.Key, strconv.Itoa(c.Count), c.Value}
+}
+
+func (c *LRemCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LTrimCommand struct {
+	Key   string
+	Start int
+	Stop  int
+}
+
+func (c *LTrimCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.LTrim(c.Key, c.Start, c.Stop); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *LTrimCommand) Propagate() []string {
+	return []string{"LTRIM", c.Key, strconv.Itoa(c.Start), strconv.Itoa(c.Stop)}
+}
+
+func (c *LTrimCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LPosCommand struct {
+	Key      string
+	Element  string
+	Rank     int
+	Count    int
+	HasCount bool // Reply with an array of every match up to Count
+	MaxLen   int
+}
+
+func (c *LPosCommand) Execute(store store.Store) (interface{}, error) {
+	count := 1
+	if c.HasCount {
+		count = c.Count
+	}
+
+	matches, err := store.LPos(c.Key, c.Element, c.Rank, count, c.MaxLen)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.HasCount {
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		return matches[0], nil
+	}
+	result := make([]interface{}, len(matches))
+	for i, index := range matches {
+		result[i] = index
+	}
+	return result, nil
+}
+
+func (c *LPosCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type LMoveCommand struct {
+	Source      string
+	Destination string
+	FromLeft    bool
+	ToLeft      bool
+}
+
+func (c *LMoveCommand) Execute(store store.Store) (interface{}, error) {
+	return store.LMove(c.Source, c.Destination, c.FromLeft, c.ToLeft)
+}
+
+func (c *LMoveCommand) Propagate() []string {
+	return []string{"LMOVE", c.Source, c.Destination, listSide(c.FromLeft), listSide(c.ToLeft)}
+}
+
+func (c *LMoveCommand) KeyArgs() []string {
+	return []string{c.Source, c.Destination}
+}
+
+func listSide(left bool) string {
+	if left {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// DumpCommand serializes the value at Key so that it can be recreated with
+// RESTORE.
+type DumpCommand struct {
+	Key string
+}
+
+func (c *DumpCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("DUMP")
+}
+
+func (c *DumpCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return srv.Dump(c.Key)
+}
+
+func (c *DumpCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// RestoreCommand creates Key from a DUMP payload. TTL is in milliseconds,
+// relative unless AbsTTL is set, and zero for no expiry.
+type RestoreCommand struct {
+	Key     string
+	TTL     int64
+	Payload string
+	Replace bool
+	AbsTTL  bool
+}
+
+func (c *RestoreCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("RESTORE")
+}
+
+func (c *RestoreCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.RestoreKey(c.Key, c.TTL, c.Payload, c.Replace, c.AbsTTL); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *RestoreCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// MigrateCommand moves Keys to the server at Host:Port. With Copy the keys
+// are kept locally, and with Replace existing keys on the target are
+// overwritten.
+type MigrateCommand struct {
+	Host    string
+	Port    string
+	Keys    []string
+	Timeout time.Duration
+	Copy    bool
+	Replace bool
+}
+
+func (c *MigrateCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("MIGRATE")
+}
+
+func (c *MigrateCommand) ExecuteServer(srv Server) (interface{}, error) {
+	status, err := srv.Migrate(c.Host, c.Port, c.Keys, c.Timeout, c.Copy, c.Replace)
+	if err != nil {
+		return nil, err
+	}
+	return types.SimpleString(status), nil
+}
+
+// AskingCommand lets the next command of the connection access a slot that
+// is being imported, following an ASK redirection.
+type AskingCommand struct{}
+
+func (c *AskingCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("ASKING")
+}
+
+func (c *AskingCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.Asking(); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
@@ -0,0 +1,60 @@
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BitRange is the range BITCOUNT and BITPOS look in, counted in bytes or,
+// with Bit set, in bits. Negative indexes count back from the end of the
+// string.
+type BitRange struct {
+	Start  int64
+	End    int64
+	HasEnd bool // BITPOS treats the end of the string differently when given
+	Bit    bool
+}
+
+// BitFieldOp is a single GET, SET or INCRBY operation of BITFIELD on an
+// integer of Bits bits at bit Offset.
+type BitFieldOp struct {
+	Op       string // "GET", "SET" or "INCRBY"
+	Signed   bool
+	Bits     int
+	Offset   int64
+	Value    int64  // The value to SET or the increment
+	Overflow string // "WRAP", "SAT" or "FAIL", as set by the last OVERFLOW
+}
+
+// ParseBitFieldType parses an integer type of BITFIELD: i1 to i64 or u1 to
+// u63.
+func ParseBitFieldType(s string) (signed bool, bits int, err error) {
+	if len(s) > 1 {
+		signed = s[0] == 'i' || s[0] == 'I'
+		bits, err = strconv.Atoi(s[1:])
+	}
+	if len(s) < 2 || err != nil || (!signed && s[0] != 'u' && s[0] != 'U') ||
+		bits < 1 || (signed && bits > 64) || (!signed && bits > 63) {
+		return false, 0, fmt.Errorf("Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.")
+	}
+	return signed, bits, nil
+}
+
+// ParseBitFieldOffset parses the offset of a BITFIELD operation, which is
+// multiplied by the width of the type when prefixed with "#".
+func ParseBitFieldOffset(s string, bits int) (int64, error) {
+	multiply := strings.HasPrefix(s, "#")
+	offset, err := strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64)
+	if err == nil && multiply && offset <= MaxBitOffset {
+		offset *= int64(bits)
+	}
+	if err != nil || offset < 0 || offset > MaxBitOffset {
+		return 0, fmt.Errorf("bit offset is not an integer or out of range")
+	}
+	return offset, nil
+}
+
+// MaxBitOffset is the greatest bit offset SETBIT and BITFIELD accept, which
+// limits strings to 512 MB.
+const MaxBitOffset = 1<<32 - 1
@@ -0,0 +1,69 @@
+package options
+
+import (
+	"fmt"
+	"strings"
+)
+
+type GeoAddOptions struct {
+	*Options
+}
+
+func NewGeoAddOptions() *GeoAddOptions {
+	opts := &GeoAddOptions{
+		Options: NewOptions(),
+	}
+
+	opts.RegisterOption("NX", "Only add new elements, don't update already existing elements", []string{"XX"})
+	opts.RegisterOption("XX", "Only update elements that already exist, don't add new elements", []string{"NX"})
+	opts.RegisterOption("CH", "Modify the return value to return the number of changed elements instead of new elements", nil)
+
+	return opts
+}
+
+func (o *GeoAddOptions) IsNX() bool {
+	return o.IsSet("NX")
+}
+
+func (o *GeoAddOptions) IsXX() bool {
+	return o.IsSet("XX")
+}
+
+func (o *GeoAddOptions) IsCH() bool {
+	return o.IsSet("CH")
+}
+
+// GeoSearchOptions describes the area GEOSEARCH looks in and how it orders
+// and limits the members found. Distances are in meters.
+type GeoSearchOptions struct {
+	FromMember string // Center on this member unless FromLonLat is set
+	FromLonLat bool
+	Longitude  float64
+	Latitude   float64
+
+	ByBox  bool // Search a Width by Height box instead of a Radius
+	Radius float64
+	Width  float64
+	Height float64
+
+	Sort  string // "ASC", "DESC" or "" for no ordering
+	Count int    // 0 for no limit
+	Any   bool   // Stop as soon as Count members are found
+}
+
+// GeoUnitFactor returns the number of meters in a distance unit of GEODIST
+// and GEOSEARCH: m, km, ft or mi.
+func GeoUnitFactor(unit string) (float64, error) {
+	switch strings.ToLower(unit) {
+	case "m":
+		return 1, nil
+	case "km":
+		return 1000, nil
+	case "ft":
+		return 0.3048, nil
+	case "mi":
+		return 1609.34, nil
+	default:
+		return 0, fmt.Errorf("unsupported unit provided. please use M, KM, FT, MI")
+	}
+}
@@ -0,0 +1,110 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// HSetExOptions represents options for the HSETEX command
+type HSetExOptions struct {
+	*Options
+	ExpiryTime time.Time
+	ExpiryType string // "EX", "PX", "EXAT", "PXAT", "KEEPTTL" or "" to clear the TTL
+}
+
+func NewHSetExOptions() *HSetExOptions {
+	opts := &HSetExOptions{
+		Options: NewOptions(),
+	}
+
+	opts.RegisterOption("FNX", "Only set the fields if none of them exist", []string{"FXX"})
+	opts.RegisterOption("FXX", "Only set the fields if all of them exist", []string{"FNX"})
+
+	return opts
+}
+
+func (o *HSetExOptions) IsFNX() bool {
+	return o.IsSet("FNX")
+}
+
+func (o *HSetExOptions) IsFXX() bool {
+	return o.IsSet("FXX")
+}
+
+func (o *HSetExOptions) IsKEEPTTL() bool {
+	return o.ExpiryType == "KEEPTTL"
+}
+
+func (o *HSetExOptions) SetExpiry(expiryType string, value int64) error {
+	if expiryType == "KEEPTTL" {
+		o.ExpiryType = expiryType
+		return nil
+	}
+	at, err := expiryTime(expiryType, value)
+	if err != nil {
+		return err
+	}
+	o.ExpiryTime = at
+	o.ExpiryType = expiryType
+	return nil
+}
+
+// HGetExOptions represents options for the HGETEX command
+type HGetExOptions struct {
+	ExpiryTime time.Time
+	ExpiryType string // "EX", "PX", "EXAT", "PXAT", "PERSIST" or "" to leave the TTL alone
+}
+
+func NewHGetExOptions() *HGetExOptions {
+	return &HGetExOptions{}
+}
+
+func (o *HGetExOptions) IsPERSIST() bool {
+	return o.ExpiryType == "PERSIST"
+}
+
+func (o *HGetExOptions) SetExpiry(expiryType string, value int64) error {
+	if expiryType == "PERSIST" {
+		o.ExpiryType = expiryType
+		return nil
+	}
+	at, err := expiryTime(expiryType, value)
+	if err != nil {
+		return err
+	}
+	o.ExpiryTime = at
+	o.ExpiryType = expiryType
+	return nil
+}
+
+var errInvalidExpireTime = errors.New("invalid expire time")
+
+// expiryTime converts a relative or absolute expiry of the given type into
+// the time it falls on, at millisecond precision. Negative values and values
+// whose deadline does not fit in int64 milliseconds are rejected.
+func expiryTime(expiryType string, value int64) (time.Time, error) {
+	if value < 0 {
+		return time.Time{}, errInvalidExpireTime
+	}
+	ms := value
+	switch expiryType {
+	case "EX", "EXAT":
+		if value > math.MaxInt64/1000 {
+			return time.Time{}, errInvalidExpireTime
+		}
+		ms = value * 1000
+	case "PX", "PXAT":
+	default:
+		return time.Time{}, fmt.Errorf("invalid expiry type: %s", expiryType)
+	}
+	if expiryType == "EX" || expiryType == "PX" {
+		now := time.Now().UnixMilli()
+		if ms > math.MaxInt64-now {
+			return time.Time{}, errInvalidExpireTime
+		}
+		ms += now
+	}
+	return time.UnixMilli(ms), nil
+}
@@ -1,14 +1,11 @@
 package options
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
 type SetOptions struct {
 	*Options
 	ExpiryTime time.Time
-	ExpiryType string // "EX", "PX", "EXAT", "PXAT", "KEEPTTL"
+	ExpiryType string // "EX", "PX", "EXAT", "PXAT", "KEEPTTL" or, for GETEX, "PERSIST"
 }
 
 func NewSetOptions() *SetOptions {
@@ -39,24 +36,26 @@ func (o *SetOptions) IsKEEPTTL() bool {
 	return o.ExpiryType == "KEEPTTL"
 }
 
+func (o *SetOptions) IsPERSIST() bool {
+	return o.ExpiryType == "PERSIST"
+}
+
+// SetExpiry records an expiry for SET, SETEX, PSETEX or GETEX. Unlike the
+// hash field expiries, these commands need a strictly positive time.
 func (o *SetOptions) SetExpiry(expiryType string, value int64) error {
 	switch expiryType {
-	case "EX":
-		o.ExpiryTime = time.Now().Add(time.Duration(value) * time.Second)
-		o.ExpiryType = "EX"
-	case "PX":
-		o.ExpiryTime = time.Now().Add(time.Duration(value) * time.Millisecond)
-		o.ExpiryType = "PX"
-	case "EXAT":
-		o.ExpiryTime = time.Unix(value, 0)
-		o.ExpiryType = "EXAT"
-	case "PXAT":
-		o.ExpiryTime = time.Unix(0, value*int64(time.Millisecond))
-		o.ExpiryType = "PXAT"
-	case "KEEPTTL":
-		o.ExpiryType = "KEEPTTL"
-	default:
-		return fmt.Errorf("invalid expiry type: %s", expiryType)
+	case "KEEPTTL", "PERSIST":
+		o.ExpiryType = expiryType
+		return nil
+	}
+	if value == 0 {
+		return errInvalidExpireTime
+	}
+	at, err := expiryTime(expiryType, value)
+	if err != nil {
+		return err
 	}
+	o.ExpiryTime = at
+	o.ExpiryType = expiryType
 	return nil
 }
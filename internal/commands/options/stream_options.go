@@ -0,0 +1,55 @@
+package options
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// XTrimOptions represents the trimming arguments of XADD and XTRIM. Trimming
+// is always exact; "~" is accepted for compatibility and only allows LIMIT.
+type XTrimOptions struct {
+	Strategy string // "MAXLEN" or "MINID"
+	Approx   bool
+	MaxLen   int64
+	MinID    types.StreamID
+	Limit    int64 // Most entries to remove, 0 for no limit
+}
+
+// Args returns the trimming arguments in the form XADD and XTRIM accept.
+func (o *XTrimOptions) Args() []string {
+	op := "="
+	if o.Approx {
+		op = "~"
+	}
+	threshold := strconv.FormatInt(o.MaxLen, 10)
+	if o.Strategy == "MINID" {
+		threshold = o.MinID.String()
+	}
+	args := []string{o.Strategy, op, threshold}
+	if o.Limit > 0 {
+		args = append(args, "LIMIT", strconv.FormatInt(o.Limit, 10))
+	}
+	return args
+}
+
+// XAddOptions represents options for the XADD command
+type XAddOptions struct {
+	NoMkStream bool
+	Trim       *XTrimOptions
+}
+
+// XClaimOptions represents options for the XCLAIM command. IDLE is converted
+// to the absolute Time when the command is parsed.
+type XClaimOptions struct {
+	Time       time.Time // Delivery time to record, zero for now
+	RetryCount int64     // Delivery count to record, -1 to increment it
+	Force      bool
+	JustID     bool
+	LastID     *types.StreamID
+}
+
+func NewXClaimOptions() *XClaimOptions {
+	return &XClaimOptions{RetryCount: -1}
+}
@@ -1,6 +1,111 @@
 package options
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoreBound is one end of a score range such as the one of ZRANGE BYSCORE:
+// a float, which may be -inf or +inf, that is exclusive when written with a
+// "(" prefix.
+type ScoreBound struct {
+	Score     float64
+	Exclusive bool
+}
+
+// ParseScoreBound parses a score range bound like "1.5", "(1.5" or "+inf".
+func ParseScoreBound(arg string) (ScoreBound, error) {
+	var bound ScoreBound
+	if strings.HasPrefix(arg, "(") {
+		bound.Exclusive = true
+		arg = arg[1:]
+	}
+	score, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(score) {
+		return ScoreBound{}, fmt.Errorf("min or max is not a float")
+	}
+	bound.Score = score
+	return bound, nil
+}
+
+// AboveMin reports whether score is within a range that b is the minimum of.
+func (b ScoreBound) AboveMin(score float64) bool {
+	if b.Exclusive {
+		return score > b.Score
+	}
+	return score >= b.Score
+}
+
+// BelowMax reports whether score is within a range that b is the maximum of.
+func (b ScoreBound) BelowMax(score float64) bool {
+	if b.Exclusive {
+		return score < b.Score
+	}
+	return score <= b.Score
+}
+
+// LexBound is one end of a lexicographical range such as the one of ZRANGE
+// BYLEX: a member written with a "[" prefix when inclusive or "(" when
+// exclusive, or "-" or "+" for the lowest and highest possible member.
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Inf       int // -1 for "-", 1 for "+" and 0 otherwise
+}
+
+// ParseLexBound parses a lex range bound like "[a", "(a", "-" or "+".
+func ParseLexBound(arg string) (LexBound, error) {
+	switch {
+	case arg == "-":
+		return LexBound{Inf: -1}, nil
+	case arg == "+":
+		return LexBound{Inf: 1}, nil
+	case strings.HasPrefix(arg, "["):
+		return LexBound{Value: arg[1:]}, nil
+	case strings.HasPrefix(arg, "("):
+		return LexBound{Value: arg[1:], Exclusive: true}, nil
+	}
+	return LexBound{}, fmt.Errorf("min or max not valid string range item")
+}
+
+// AboveMin reports whether member is within a range that b is the minimum
+// of.
+func (b LexBound) AboveMin(member string) bool {
+	switch {
+	case b.Inf != 0:
+		return b.Inf < 0
+	case b.Exclusive:
+		return member > b.Value
+	}
+	return member >= b.Value
+}
+
+// BelowMax reports whether member is within a range that b is the maximum
+// of.
+func (b LexBound) BelowMax(member string) bool {
+	switch {
+	case b.Inf != 0:
+		return b.Inf > 0
+	case b.Exclusive:
+		return member < b.Value
+	}
+	return member <= b.Value
+}
+
+// String returns b as it is written in a command.
+func (b LexBound) String() string {
+	switch {
+	case b.Inf < 0:
+		return "-"
+	case b.Inf > 0:
+		return "+"
+	case b.Exclusive:
+		return "(" + b.Value
+	}
+	return "[" + b.Value
+}
 
 type ZRangeOptions struct {
 	*Options
@@ -8,8 +113,9 @@ type ZRangeOptions struct {
 	Rev       bool
 	Limit     struct {
 		Offset int
-		Count  int
+		Count  int // Negative for every member after Offset
 	}
+	HasLimit   bool
 	WithScores bool
 }
 
@@ -71,10 +177,8 @@ func (o *ZRangeOptions) SetLimit(offset, count int) error {
 	if offset < 0 {
 		return fmt.Errorf("offset must be non-negative")
 	}
-	if count < 0 {
-		return fmt.Errorf("count must be non-negative")
-	}
 	o.Limit.Offset = offset
 	o.Limit.Count = count
+	o.HasLimit = true
 	return nil
 }
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// ReplicaOfCommand makes the server a replica of Host:Port. An empty Host
+// turns a replica back into a primary, as in REPLICAOF NO ONE.
+type ReplicaOfCommand struct {
+	Host string
+	Port string
+}
+
+func (c *ReplicaOfCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("REPLICAOF")
+}
+
+func (c *ReplicaOfCommand) ExecuteServer(srv Server) (interface{}, error) {
+	status, err := srv.ReplicaOf(c.Host, c.Port)
+	if err != nil {
+		return nil, err
+	}
+	return types.SimpleString(status), nil
+}
+
+type RoleCommand struct{}
+
+func (c *RoleCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("ROLE")
+}
+
+func (c *RoleCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return srv.Role(), nil
+}
+
+type InfoCommand struct {
+	Section string
+}
+
+func (c *InfoCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("INFO")
+}
+
+func (c *InfoCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return srv.Info(c.Section), nil
+}
+
+// ReplConfCommand carries the configuration a replica sends to its primary
+// during the handshake.
+type ReplConfCommand struct {
+	Args []string
+}
+
+func (c *ReplConfCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("REPLCONF")
+}
+
+func (c *ReplConfCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.ReplConf(c.Args); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+// SyncCommand turns the calling connection into a replication link: the
+// primary sends a snapshot of its dataset followed by every write command.
+type SyncCommand struct{}
+
+func (c *SyncCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("SYNC")
+}
+
+func (c *SyncCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return nil, srv.Sync()
+}
+
+// PSyncCommand asks the primary to resume replication from Offset of the
+// history identified by ReplID, falling back to a full synchronization.
+type PSyncCommand struct {
+	ReplID string
+	Offset int64
+}
+
+func (c *PSyncCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("PSYNC")
+}
+
+func (c *PSyncCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return nil, srv.PSync(c.ReplID, c.Offset)
+}
+
+// WaitCommand blocks until NumReplicas replicas acknowledged the calling
+// client's last write or Timeout elapses. A zero Timeout blocks forever.
+type WaitCommand struct {
+	NumReplicas int
+	Timeout     time.Duration
+}
+
+func (c *WaitCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("WAIT")
+}
+
+func (c *WaitCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return srv.Wait(c.NumReplicas, c.Timeout)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type SaveCommand struct{}
+
+func (c *SaveCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("SAVE")
+}
+
+func (c *SaveCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.Save(); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+type BgSaveCommand struct{}
+
+func (c *BgSaveCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("BGSAVE")
+}
+
+func (c *BgSaveCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.BGSave(); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("Background saving started"), nil
+}
+
+type LastSaveCommand struct{}
+
+func (c *LastSaveCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("LASTSAVE")
+}
+
+func (c *LastSaveCommand) ExecuteServer(srv Server) (interface{}, error) {
+	return srv.LastSave().Unix(), nil
+}
+
+type BgRewriteAofCommand struct{}
+
+func (c *BgRewriteAofCommand) Execute(store store.Store) (interface{}, error) {
+	return nil, errNoServer("BGREWRITEAOF")
+}
+
+func (c *BgRewriteAofCommand) ExecuteServer(srv Server) (interface{}, error) {
+	if err := srv.BGRewriteAOF(); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("Background append only file rewriting started"), nil
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/cluster"
+)
+
+// Server is the view of the running server that administrative commands
+// operate on. It is implemented by server.Server.
+type Server interface {
+	Save() error
+	BGSave() error
+	LastSave() time.Time
+	BGRewriteAOF() error
+
+	ReplicaOf(host, port string) (string, error)
+	Role() []interface{}
+	Info(section string) string
+
+	// Cluster returns the cluster view, or an error if cluster mode is
+	// disabled.
+	Cluster() (*cluster.Cluster, error)
+	ClusterSetSlot(slot int, state, nodeID string) error
+	// ClusterKeysInSlot returns up to count keys in slot; a negative count
+	// returns all of them.
+	ClusterKeysInSlot(slot, count int) ([]string, error)
+
+	Dump(key string) (interface{}, error)
+	RestoreKey(key string, ttl int64, payload string, replace, absTTL bool) error
+	Migrate(host, port string, keys []string, timeout time.Duration, copy, replace bool) (string, error)
+
+	// ReplConf, Sync, PSync, Wait and Asking act on the calling connection.
+	ReplConf(args []string) error
+	Sync() error
+	PSync(replID string, offset int64) error
+	Wait(numReplicas int, timeout time.Duration) (int, error)
+	Asking() error
+}
+
+// ServerCommand is implemented by commands that act on the server itself
+// rather than on the keyspace. The handler calls ExecuteServer instead of
+// Execute for these commands.
+type ServerCommand interface {
+	Command
+	ExecuteServer(srv Server) (interface{}, error)
+}
+
+func errNoServer(name string) error {
+	return fmt.Errorf("%s is only available on a running server", name)
+}
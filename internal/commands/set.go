@@ -1,17 +1,20 @@
 package commands
 
 import (
+	"strconv"
+
 	"github.com/hardikphalet/go-redis/internal/commands/options"
 	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
 )
 
 type SetCommand struct {
 	Key     string
-	Value   string
+	Value   []byte
 	Options *options.SetOptions
 }
 
-func NewSetCommand(key, value string, opts *options.SetOptions) *SetCommand {
+func NewSetCommand(key string, value []byte, opts *options.SetOptions) *SetCommand {
 	return &SetCommand{
 		Key:     key,
 		Value:   value,
@@ -19,6 +22,32 @@ func NewSetCommand(key, value string, opts *options.SetOptions) *SetCommand {
 	}
 }
 
+// Execute replies OK, or with GET the old value.
 func (c *SetCommand) Execute(store store.Store) (interface{}, error) {
-	return store.Set(c.Key, c.Value, c.Options)
+	old, err := store.Set(c.Key, c.Value, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if c.Options != nil && c.Options.IsGET() {
+		return old, nil
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *SetCommand) Propagate() []string {
+	args := []string{"SET", c.Key, string(c.Value)}
+	if c.Options == nil {
+		return args
+	}
+
+	if c.Options.IsKEEPTTL() {
+		args = append(args, "KEEPTTL")
+	} else if c.Options.ExpiryType != "" {
+		args = append(args, "PXAT", strconv.FormatInt(c.Options.ExpiryTime.UnixMilli(), 10))
+	}
+	return args
+}
+
+func (c *SetCommand) KeyArgs() []string {
+	return []string{c.Key}
 }
@@ -0,0 +1,277 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+)
+
+type SAddCommand struct {
+	Key     string
+	Members []string
+}
+
+func (c *SAddCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SAdd(c.Key, c.Members)
+}
+
+func (c *SAddCommand) Propagate() []string {
+	return append([]string{"SADD", c.Key}, c.Members...)
+}
+
+func (c *SAddCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SRemCommand struct {
+	Key     string
+	Members []string
+}
+
+func (c *SRemCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SRem(c.Key, c.Members)
+}
+
+func (c *SRemCommand) Propagate() []string {
+	return append([]string{"SREM", c.Key}, c.Members...)
+}
+
+func (c *SRemCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SIsMemberCommand struct {
+	Key    string
+	Member string
+}
+
+func (c *SIsMemberCommand) Execute(store store.Store) (interface{}, error) {
+	found, err := store.SIsMember(c.Key, c.Member)
+	return boolReply(found), err
+}
+
+func (c *SIsMemberCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SMIsMemberCommand struct {
+	Key     string
+	Members []string
+}
+
+func (c *SMIsMemberCommand) Execute(store store.Store) (interface{}, error) {
+	found, err := store.SMIsMember(c.Key, c.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, len(found))
+	for i, f := range found {
+		result[i] = boolReply(f)
+	}
+	return result, nil
+}
+
+func (c *SMIsMemberCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SMembersCommand struct {
+	Key string
+}
+
+func (c *SMembersCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SMembers(c.Key)
+}
+
+func (c *SMembersCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SCardCommand struct {
+	Key string
+}
+
+func (c *SCardCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SCard(c.Key)
+}
+
+func (c *SCardCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// SPopCommand removes random members from a set. It remembers the members
+// it removed and propagates them as an SREM, so that replicas and the AOF
+// remove exactly the same ones.
+type SPopCommand struct {
+	Key      string
+	Count    int
+	HasCount bool // Reply with an array even when popping one member
+
+	popped []string
+}
+
+func (c *SPopCommand) Execute(store store.Store) (interface{}, error) {
+	count := 1
+	if c.HasCount {
+		count = c.Count
+	}
+
+	popped, err := store.SPop(c.Key, count)
+	if err != nil {
+		return nil, err
+	}
+	c.popped = popped
+
+	if c.HasCount {
+		if popped == nil {
+			return []string{}, nil
+		}
+		return popped, nil
+	}
+	if len(popped) == 0 {
+		return nil, nil
+	}
+	return popped[0], nil
+}
+
+func (c *SPopCommand) Propagate() []string {
+	if len(c.popped) == 0 {
+		return nil
+	}
+	return append([]string{"SREM", c.Key}, c.popped...)
+}
+
+func (c *SPopCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SRandMemberCommand struct {
+	Key      string
+	Count    int
+	HasCount bool // Reply with an array even for a single member
+}
+
+func (c *SRandMemberCommand) Execute(store store.Store) (interface{}, error) {
+	count := 1
+	if c.HasCount {
+		count = c.Count
+	}
+
+	members, err := store.SRandMember(c.Key, count)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.HasCount {
+		return members, nil
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members[0], nil
+}
+
+func (c *SRandMemberCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SMoveCommand struct {
+	Source      string
+	Destination string
+	Member      string
+}
+
+func (c *SMoveCommand) Execute(store store.Store) (interface{}, error) {
+	moved, err := store.SMove(c.Source, c.Destination, c.Member)
+	return boolReply(moved), err
+}
+
+func (c *SMoveCommand) Propagate() []string {
+	return []string{"SMOVE", c.Source, c.Destination, c.Member}
+}
+
+func (c *SMoveCommand) KeyArgs() []string {
+	return []string{c.Source, c.Destination}
+}
+
+// SetOpCommand computes the intersection, union or difference of sets, as
+// used by SINTER, SUNION and SDIFF.
+type SetOpCommand struct {
+	Op   string // "SINTER", "SUNION" or "SDIFF"
+	Keys []string
+}
+
+func (c *SetOpCommand) Execute(store store.Store) (interface{}, error) {
+	switch c.Op {
+	case "SINTER":
+		return store.SInter(c.Keys)
+	case "SUNION":
+		return store.SUnion(c.Keys)
+	default:
+		return store.SDiff(c.Keys)
+	}
+}
+
+func (c *SetOpCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+// SetOpStoreCommand stores the intersection, union or difference of sets, as
+// used by SINTERSTORE, SUNIONSTORE and SDIFFSTORE.
+type SetOpStoreCommand struct {
+	Op          string // "SINTER", "SUNION" or "SDIFF"
+	Destination string
+	Keys        []string
+}
+
+func (c *SetOpStoreCommand) Execute(store store.Store) (interface{}, error) {
+	switch c.Op {
+	case "SINTER":
+		return store.SInterStore(c.Destination, c.Keys)
+	case "SUNION":
+		return store.SUnionStore(c.Destination, c.Keys)
+	default:
+		return store.SDiffStore(c.Destination, c.Keys)
+	}
+}
+
+func (c *SetOpStoreCommand) Propagate() []string {
+	return append([]string{c.Op + "STORE", c.Destination}, c.Keys...)
+}
+
+func (c *SetOpStoreCommand) KeyArgs() []string {
+	return append([]string{c.Destination}, c.Keys...)
+}
+
+type SInterCardCommand struct {
+	Keys  []string
+	Limit int // 0 for no limit
+}
+
+func (c *SInterCardCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SInterCard(c.Keys, c.Limit)
+}
+
+func (c *SInterCardCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+type SScanCommand struct {
+	Key     string
+	Cursor  uint64
+	Pattern string
+	Count   int
+}
+
+func (c *SScanCommand) Execute(store store.Store) (interface{}, error) {
+	next, members, err := store.SScan(c.Key, c.Cursor, c.Pattern, c.Count)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{strconv.FormatUint(next, 10), members}, nil
+}
+
+func (c *SScanCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// XAddCommand adds an entry to a stream. It remembers the ID the entry got,
+// so that an auto-generated ID is propagated explicitly.
+type XAddCommand struct {
+	Key     string
+	ID      string // "*", "ms-*" or an explicit ID
+	Fields  []string
+	Options *options.XAddOptions
+
+	added string
+}
+
+func (c *XAddCommand) Execute(store store.Store) (interface{}, error) {
+	id, err := store.XAdd(c.Key, c.ID, c.Fields, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if added, ok := id.(string); ok {
+		c.added = added
+	}
+	return id, nil
+}
+
+func (c *XAddCommand) Propagate() []string {
+	args := []string{"XADD", c.Key}
+	if c.Options != nil {
+		if c.Options.NoMkStream {
+			args = append(args, "NOMKSTREAM")
+		}
+		if c.Options.Trim != nil {
+			args = append(args, c.Options.Trim.Args()...)
+		}
+	}
+	id := c.ID
+	if c.added != "" {
+		id = c.added
+	}
+	args = append(args, id)
+	return append(args, c.Fields...)
+}
+
+func (c *XAddCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XRangeCommand returns the entries of a stream between two IDs, as used by
+// XRANGE and, with Rev set, XREVRANGE.
+type XRangeCommand struct {
+	Key   string
+	Start types.StreamID
+	End   types.StreamID
+	Count int // -1 for no limit
+	Rev   bool
+}
+
+func (c *XRangeCommand) Execute(store store.Store) (interface{}, error) {
+	if c.Count == 0 {
+		return []interface{}{}, nil
+	}
+	entries, err := store.XRange(c.Key, c.Start, c.End, c.Count, c.Rev)
+	if err != nil {
+		return nil, err
+	}
+	return streamEntriesReply(entries), nil
+}
+
+func (c *XRangeCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type XLenCommand struct {
+	Key string
+}
+
+func (c *XLenCommand) Execute(store store.Store) (interface{}, error) {
+	return store.XLen(c.Key)
+}
+
+func (c *XLenCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type XDelCommand struct {
+	Key string
+	IDs []types.StreamID
+}
+
+func (c *XDelCommand) Execute(store store.Store) (interface{}, error) {
+	return store.XDel(c.Key, c.IDs)
+}
+
+func (c *XDelCommand) Propagate() []string {
+	return append([]string{"XDEL", c.Key}, streamIDArgs(c.IDs)...)
+}
+
+func (c *XDelCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type XTrimCommand struct {
+	Key     string
+	Options *options.XTrimOptions
+}
+
+func (c *XTrimCommand) Execute(store store.Store) (interface{}, error) {
+	return store.XTrim(c.Key, c.Options)
+}
+
+func (c *XTrimCommand) Propagate() []string {
+	return append([]string{"XTRIM", c.Key}, c.Options.Args()...)
+}
+
+func (c *XTrimCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XReadCommand reads entries newer than the given IDs from streams. "$"
+// stands for the last ID of a stream when the command is first run, so that
+// a blocked XREAD returns only entries added while it waits.
+type XReadCommand struct {
+	Keys     []string
+	IDs      []string
+	Count    int // 0 for no limit
+	Timeout  time.Duration
+	HasBlock bool
+
+	after []types.StreamID
+}
+
+func (c *XReadCommand) Execute(store store.Store) (interface{}, error) {
+	if c.after == nil {
+		after := make([]types.StreamID, len(c.IDs))
+		for i, id := range c.IDs {
+			var err error
+			if id == "$" {
+				after[i], err = store.XLastID(c.Keys[i])
+			} else {
+				after[i], err = types.ParseStreamID(id, 0)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		c.after = after
+	}
+
+	results, err := store.XRead(c.Keys, c.after, c.Count)
+	if err != nil {
+		return nil, err
+	}
+	return streamsReply(c.Keys, results), nil
+}
+
+func (c *XReadCommand) Block() (time.Duration, bool) {
+	return c.Timeout, c.HasBlock
+}
+
+func (c *XReadCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+// XSetIDCommand sets the last ID of a stream and, optionally, its count of
+// entries ever added and its greatest deleted ID.
+type XSetIDCommand struct {
+	Key          string
+	LastID       types.StreamID
+	EntriesAdded int64 // -1 to leave it unchanged
+	MaxDeletedID *types.StreamID
+}
+
+func (c *XSetIDCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.XSetID(c.Key, c.LastID, c.EntriesAdded, c.MaxDeletedID); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *XSetIDCommand) Propagate() []string {
+	args := []string{"XSETID", c.Key, c.LastID.String()}
+	if c.EntriesAdded >= 0 {
+		args = append(args, "ENTRIESADDED", strconv.FormatInt(c.EntriesAdded, 10))
+	}
+	if c.MaxDeletedID != nil {
+		args = append(args, "MAXDELETEDID", c.MaxDeletedID.String())
+	}
+	return args
+}
+
+func (c *XSetIDCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// streamEntriesReply converts entries to an array of [id, [field, value,
+// ...]] pairs. Entries without fields, which XREADGROUP returns for pending
+// entries deleted from the stream, get a null array instead.
+func streamEntriesReply(entries []types.StreamEntry) []interface{} {
+	reply := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		reply[i] = []interface{}{entry.ID.String(), entry.Fields}
+	}
+	return reply
+}
+
+// streamsReply converts the results of XREAD and XREADGROUP to an array of
+// [key, entries] pairs for the keys with results, or a null array if there
+// are none.
+func streamsReply(keys []string, results [][]types.StreamEntry) []interface{} {
+	var reply []interface{}
+	for i, entries := range results {
+		if entries != nil {
+			reply = append(reply, []interface{}{keys[i], streamEntriesReply(entries)})
+		}
+	}
+	return reply
+}
+
+// streamIDArgs formats ids as command arguments.
+func streamIDArgs(ids []types.StreamID) []string {
+	args := make([]string, len(ids))
+	for i, id := range ids {
+		args[i] = id.String()
+	}
+	return args
+}
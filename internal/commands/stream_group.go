@@ -0,0 +1,506 @@
+package commands
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type XGroupCreateCommand struct {
+	Key         string
+	Group       string
+	ID          string // An ID or "$"
+	MkStream    bool
+	EntriesRead int64 // -1 if not given
+}
+
+func (c *XGroupCreateCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.XGroupCreate(c.Key, c.Group, c.ID, c.MkStream, c.EntriesRead); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *XGroupCreateCommand) Propagate() []string {
+	args := []string{"XGROUP", "CREATE", c.Key, c.Group, c.ID}
+	if c.MkStream {
+		args = append(args, "MKSTREAM")
+	}
+	if c.EntriesRead >= 0 {
+		args = append(args, "ENTRIESREAD", strconv.FormatInt(c.EntriesRead, 10))
+	}
+	return args
+}
+
+func (c *XGroupCreateCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type XGroupSetIDCommand struct {
+	Key         string
+	Group       string
+	ID          string // An ID or "$"
+	EntriesRead int64  // -1 if not given
+}
+
+func (c *XGroupSetIDCommand) Execute(store store.Store) (interface{}, error) {
+	if err := store.XGroupSetID(c.Key, c.Group, c.ID, c.EntriesRead); err != nil {
+		return nil, err
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *XGroupSetIDCommand) Propagate() []string {
+	args := []string{"XGROUP", "SETID", c.Key, c.Group, c.ID}
+	if c.EntriesRead >= 0 {
+		args = append(args, "ENTRIESREAD", strconv.FormatInt(c.EntriesRead, 10))
+	}
+	return args
+}
+
+func (c *XGroupSetIDCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type XGroupDestroyCommand struct {
+	Key   string
+	Group string
+}
+
+func (c *XGroupDestroyCommand) Execute(store store.Store) (interface{}, error) {
+	destroyed, err := store.XGroupDestroy(c.Key, c.Group)
+	return boolReply(destroyed), err
+}
+
+func (c *XGroupDestroyCommand) Propagate() []string {
+	return []string{"XGROUP", "DESTROY", c.Key, c.Group}
+}
+
+func (c *XGroupDestroyCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XGroupConsumerCommand adds or removes a consumer of a group, as used by
+// XGROUP CREATECONSUMER and DELCONSUMER.
+type XGroupConsumerCommand struct {
+	Key      string
+	Group    string
+	Consumer string
+	Delete   bool
+}
+
+func (c *XGroupConsumerCommand) Execute(store store.Store) (interface{}, error) {
+	if c.Delete {
+		return store.XGroupDelConsumer(c.Key, c.Group, c.Consumer)
+	}
+	created, err := store.XGroupCreateConsumer(c.Key, c.Group, c.Consumer)
+	return boolReply(created), err
+}
+
+func (c *XGroupConsumerCommand) Propagate() []string {
+	subcommand := "CREATECONSUMER"
+	if c.Delete {
+		subcommand = "DELCONSUMER"
+	}
+	return []string{"XGROUP", subcommand, c.Key, c.Group, c.Consumer}
+}
+
+func (c *XGroupConsumerCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XReadGroupCommand reads streams on behalf of a consumer of a group. An ID
+// of ">" asks for entries never delivered to the group; any other ID asks
+// for the consumer's pending entries after it. It is replayed as Redis does,
+// keeping the delivery time: for every stream it delivered new entries from,
+// an XCLAIM of those entries, or an XGROUP CREATECONSUMER with NOACK, and an
+// XGROUP SETID of the new position; for every stream it read pending entries
+// from, an XCLAIM that counts their redelivery.
+type XReadGroupCommand struct {
+	Group    string
+	Consumer string
+	Keys     []string
+	IDs      []string
+	Count    int // 0 for no limit
+	NoAck    bool
+	Timeout  time.Duration
+	HasBlock bool
+
+	now   time.Time
+	reads []groupRead
+}
+
+// groupRead records what XReadGroupCommand delivered from one stream.
+type groupRead struct {
+	key         string
+	ids         []types.StreamID
+	entriesRead int64
+	history     bool // The entries were pending ones read again
+}
+
+func (c *XReadGroupCommand) Execute(store store.Store) (interface{}, error) {
+	after := make([]*types.StreamID, len(c.IDs))
+	for i, id := range c.IDs {
+		if id == ">" {
+			continue
+		}
+		parsed, err := types.ParseStreamID(id, 0)
+		if err != nil {
+			return nil, err
+		}
+		after[i] = &parsed
+	}
+
+	c.now = time.Now()
+	results, err := store.XReadGroup(c.Group, c.Consumer, c.Keys, after, c.Count, c.NoAck, c.now)
+	if err != nil {
+		return nil, err
+	}
+	c.reads = nil
+	for i, entries := range results {
+		if after[i] != nil {
+			// Entries deleted from the stream come back without fields
+			// and are not redelivered.
+			var ids []types.StreamID
+			for _, entry := range entries {
+				if entry.Fields != nil {
+					ids = append(ids, entry.ID)
+				}
+			}
+			if len(ids) > 0 {
+				c.reads = append(c.reads, groupRead{key: c.Keys[i], ids: ids, history: true})
+			}
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		read := groupRead{key: c.Keys[i], ids: entryIDs(entries), entriesRead: -1}
+		groups, err := store.XInfoGroups(c.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			if g.Name == c.Group {
+				read.entriesRead = g.EntriesRead
+			}
+		}
+		c.reads = append(c.reads, read)
+	}
+	return streamsReply(c.Keys, results), nil
+}
+
+func (c *XReadGroupCommand) Block() (time.Duration, bool) {
+	return c.Timeout, c.HasBlock
+}
+
+func (c *XReadGroupCommand) PropagateAll() [][]string {
+	var cmds [][]string
+	for _, read := range c.reads {
+		if read.history {
+			args := append([]string{"XCLAIM", read.key, c.Group, c.Consumer, "0"}, streamIDArgs(read.ids)...)
+			cmds = append(cmds, append(args, "TIME", strconv.FormatInt(c.now.UnixMilli(), 10)))
+			continue
+		}
+		if c.NoAck {
+			cmds = append(cmds, []string{"XGROUP", "CREATECONSUMER", read.key, c.Group, c.Consumer})
+		} else {
+			args := append([]string{"XCLAIM", read.key, c.Group, c.Consumer, "0"}, streamIDArgs(read.ids)...)
+			args = append(args, "TIME", strconv.FormatInt(c.now.UnixMilli(), 10), "RETRYCOUNT", "1", "FORCE", "JUSTID")
+			cmds = append(cmds, args)
+		}
+		last := read.ids[len(read.ids)-1].String()
+		cmds = append(cmds, []string{"XGROUP", "SETID", read.key, c.Group, last, "ENTRIESREAD", strconv.FormatInt(read.entriesRead, 10)})
+	}
+	return cmds
+}
+
+func (c *XReadGroupCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+type XAckCommand struct {
+	Key   string
+	Group string
+	IDs   []types.StreamID
+}
+
+func (c *XAckCommand) Execute(store store.Store) (interface{}, error) {
+	return store.XAck(c.Key, c.Group, c.IDs)
+}
+
+func (c *XAckCommand) Propagate() []string {
+	return append([]string{"XACK", c.Key, c.Group}, streamIDArgs(c.IDs)...)
+}
+
+func (c *XAckCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XPendingCommand inspects the pending entries of a consumer group. Without
+// Extended it replies with a summary; with it, it lists the entries between
+// Start and End.
+type XPendingCommand struct {
+	Key      string
+	Group    string
+	Extended bool
+	MinIdle  time.Duration
+	Start    types.StreamID
+	End      types.StreamID
+	Count    int
+	Consumer string
+}
+
+func (c *XPendingCommand) Execute(store store.Store) (interface{}, error) {
+	if !c.Extended {
+		pending, err := store.XPending(c.Key, c.Group, types.StreamID{}, types.MaxStreamID, -1, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		return pendingSummaryReply(pending), nil
+	}
+
+	pending, err := store.XPending(c.Key, c.Group, c.Start, c.End, c.Count, c.Consumer, c.MinIdle)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	reply := make([]interface{}, len(pending))
+	for i, p := range pending {
+		reply[i] = []interface{}{p.ID.String(), p.Consumer, max(now.Sub(p.DeliveryTime).Milliseconds(), 0), p.DeliveryCount}
+	}
+	return reply, nil
+}
+
+func (c *XPendingCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// pendingSummaryReply returns the number of pending entries, the smallest
+// and greatest pending ID and the number of entries pending per consumer.
+func pendingSummaryReply(pending []types.StreamPending) []interface{} {
+	if len(pending) == 0 {
+		return []interface{}{0, nil, nil, []interface{}(nil)}
+	}
+
+	counts := make(map[string]int)
+	var consumers []string
+	for _, p := range pending {
+		if counts[p.Consumer] == 0 {
+			consumers = append(consumers, p.Consumer)
+		}
+		counts[p.Consumer]++
+	}
+	sort.Strings(consumers)
+
+	perConsumer := make([]interface{}, len(consumers))
+	for i, name := range consumers {
+		perConsumer[i] = []interface{}{name, strconv.Itoa(counts[name])}
+	}
+	return []interface{}{len(pending), pending[0].ID.String(), pending[len(pending)-1].ID.String(), perConsumer}
+}
+
+// XClaimCommand changes the owner of pending entries. It is propagated as
+// an XCLAIM of exactly the entries it claimed or dropped, with a zero
+// minimum idle time and the delivery time it recorded.
+type XClaimCommand struct {
+	Key      string
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	IDs      []types.StreamID
+	Options  *options.XClaimOptions
+
+	claimed []types.StreamID
+}
+
+func (c *XClaimCommand) Execute(store store.Store) (interface{}, error) {
+	if c.Options.Time.IsZero() {
+		c.Options.Time = time.Now()
+	}
+	entries, deleted, err := store.XClaim(c.Key, c.Group, c.Consumer, c.MinIdle, c.IDs, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	c.claimed = append(entryIDs(entries), deleted...)
+
+	if c.Options.JustID {
+		return streamIDArgs(entryIDs(entries)), nil
+	}
+	return streamEntriesReply(entries), nil
+}
+
+func (c *XClaimCommand) Propagate() []string {
+	if len(c.claimed) == 0 {
+		return nil
+	}
+	args := []string{"XCLAIM", c.Key, c.Group, c.Consumer, "0"}
+	args = append(args, streamIDArgs(c.claimed)...)
+	args = append(args, "TIME", strconv.FormatInt(c.Options.Time.UnixMilli(), 10))
+	if c.Options.RetryCount >= 0 {
+		args = append(args, "RETRYCOUNT", strconv.FormatInt(c.Options.RetryCount, 10))
+	}
+	if c.Options.Force {
+		args = append(args, "FORCE")
+	}
+	if c.Options.JustID {
+		args = append(args, "JUSTID")
+	}
+	if c.Options.LastID != nil {
+		args = append(args, "LASTID", c.Options.LastID.String())
+	}
+	return args
+}
+
+func (c *XClaimCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XAutoClaimCommand claims the pending entries of a group that have been
+// idle for too long, scanning from Start. Like XClaimCommand it is
+// propagated as an XCLAIM of the entries it claimed or dropped.
+type XAutoClaimCommand struct {
+	Key      string
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	Start    types.StreamID
+	Count    int
+	JustID   bool
+
+	claimed []types.StreamID
+	now     time.Time
+}
+
+func (c *XAutoClaimCommand) Execute(store store.Store) (interface{}, error) {
+	c.now = time.Now()
+	next, entries, deleted, err := store.XAutoClaim(c.Key, c.Group, c.Consumer, c.MinIdle, c.Start, c.Count, c.JustID, c.now)
+	if err != nil {
+		return nil, err
+	}
+	c.claimed = append(entryIDs(entries), deleted...)
+
+	var claimed interface{} = streamEntriesReply(entries)
+	if c.JustID {
+		claimed = streamIDArgs(entryIDs(entries))
+	}
+	return []interface{}{next.String(), claimed, streamIDArgs(deleted)}, nil
+}
+
+func (c *XAutoClaimCommand) Propagate() []string {
+	if len(c.claimed) == 0 {
+		return nil
+	}
+	args := []string{"XCLAIM", c.Key, c.Group, c.Consumer, "0"}
+	args = append(args, streamIDArgs(c.claimed)...)
+	args = append(args, "TIME", strconv.FormatInt(c.now.UnixMilli(), 10))
+	if c.JustID {
+		args = append(args, "JUSTID")
+	}
+	return args
+}
+
+func (c *XAutoClaimCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// XInfoCommand describes a stream, its consumer groups or the consumers of
+// a group, as used by XINFO STREAM, GROUPS and CONSUMERS.
+type XInfoCommand struct {
+	Subcommand string
+	Key        string
+	Group      string
+}
+
+func (c *XInfoCommand) Execute(store store.Store) (interface{}, error) {
+	switch c.Subcommand {
+	case "STREAM":
+		info, err := store.XInfoStream(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{
+			"length", info.Length,
+			"last-generated-id", info.LastID.String(),
+			"max-deleted-entry-id", info.MaxDeletedID.String(),
+			"entries-added", info.EntriesAdded,
+			"recorded-first-entry-id", info.FirstID.String(),
+			"groups", info.Groups,
+			"first-entry", streamEntryReply(info.First),
+			"last-entry", streamEntryReply(info.Last),
+		}, nil
+
+	case "GROUPS":
+		infos, err := store.XInfoGroups(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		reply := make([]interface{}, len(infos))
+		for i, info := range infos {
+			reply[i] = []interface{}{
+				"name", info.Name,
+				"consumers", info.Consumers,
+				"pending", info.Pending,
+				"last-delivered-id", info.LastID.String(),
+				"entries-read", unknownIfNegative(info.EntriesRead),
+				"lag", unknownIfNegative(info.Lag),
+			}
+		}
+		return reply, nil
+
+	default:
+		infos, err := store.XInfoConsumers(c.Key, c.Group)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		reply := make([]interface{}, len(infos))
+		for i, info := range infos {
+			inactive := int64(-1)
+			if !info.ActiveTime.IsZero() {
+				inactive = max(now.Sub(info.ActiveTime).Milliseconds(), 0)
+			}
+			reply[i] = []interface{}{
+				"name", info.Name,
+				"pending", info.Pending,
+				"idle", max(now.Sub(info.SeenTime).Milliseconds(), 0),
+				"inactive", inactive,
+			}
+		}
+		return reply, nil
+	}
+}
+
+func (c *XInfoCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// streamEntryReply converts a single entry to [id, [field, value, ...]], or
+// nil if there is no entry.
+func streamEntryReply(entry *types.StreamEntry) interface{} {
+	if entry == nil {
+		return nil
+	}
+	return []interface{}{entry.ID.String(), entry.Fields}
+}
+
+// unknownIfNegative returns n, or nil for the -1 that marks an unknown
+// count.
+func unknownIfNegative(n int64) interface{} {
+	if n < 0 {
+		return nil
+	}
+	return n
+}
+
+func entryIDs(entries []types.StreamEntry) []types.StreamID {
+	ids := make([]types.StreamID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+type MGetCommand struct {
+	Keys []string
+}
+
+func (c *MGetCommand) Execute(store store.Store) (interface{}, error) {
+	return store.MGet(c.Keys)
+}
+
+func (c *MGetCommand) KeyArgs() []string {
+	return c.Keys
+}
+
+// MSetCommand sets several keys at once, as used by MSET and, with NX, by
+// MSETNX and SETNX, which reply whether they set the keys rather than OK.
+type MSetCommand struct {
+	Pairs []types.KeyValue
+	NX    bool
+}
+
+func (c *MSetCommand) Execute(store store.Store) (interface{}, error) {
+	set, err := store.MSet(c.Pairs, c.NX)
+	if err != nil {
+		return nil, err
+	}
+	if c.NX {
+		return boolReply(set), nil
+	}
+	return types.SimpleString("OK"), nil
+}
+
+func (c *MSetCommand) Propagate() []string {
+	args := []string{"MSET"}
+	if c.NX {
+		args[0] = "MSETNX"
+	}
+	for _, p := range c.Pairs {
+		args = append(args, p.Key, string(p.Value))
+	}
+	return args
+}
+
+func (c *MSetCommand) KeyArgs() []string {
+	keys := make([]string, len(c.Pairs))
+	for i, p := range c.Pairs {
+		keys[i] = p.Key
+	}
+	return keys
+}
+
+type AppendCommand struct {
+	Key   string
+	Value []byte
+}
+
+func (c *AppendCommand) Execute(store store.Store) (interface{}, error) {
+	return store.Append(c.Key, c.Value)
+}
+
+func (c *AppendCommand) Propagate() []string {
+	return []string{"APPEND", c.Key, string(c.Value)}
+}
+
+func (c *AppendCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type StrLenCommand struct {
+	Key string
+}
+
+func (c *StrLenCommand) Execute(store store.Store) (interface{}, error) {
+	return store.StrLen(c.Key)
+}
+
+func (c *StrLenCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GetRangeCommand struct {
+	Key   string
+	Start int64
+	End   int64
+}
+
+func (c *GetRangeCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GetRange(c.Key, c.Start, c.End)
+}
+
+func (c *GetRangeCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type SetRangeCommand struct {
+	Key    string
+	Offset int64
+	Value  []byte
+}
+
+func (c *SetRangeCommand) Execute(store store.Store) (interface{}, error) {
+	return store.SetRange(c.Key, c.Offset, c.Value)
+}
+
+func (c *SetRangeCommand) Propagate() []string {
+	return []string{"SETRANGE", c.Key, strconv.FormatInt(c.Offset, 10), string(c.Value)}
+}
+
+func (c *SetRangeCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type GetDelCommand struct {
+	Key string
+}
+
+func (c *GetDelCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GetDel(c.Key)
+}
+
+func (c *GetDelCommand) Propagate() []string {
+	return []string{"GETDEL", c.Key}
+}
+
+func (c *GetDelCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// GetExCommand reads a string and optionally changes its expiry. Without
+// options it only reads, and nothing is propagated.
+type GetExCommand struct {
+	Key     string
+	Options *options.SetOptions
+}
+
+func (c *GetExCommand) Execute(store store.Store) (interface{}, error) {
+	return store.GetEx(c.Key, c.Options)
+}
+
+func (c *GetExCommand) Propagate() []string {
+	switch {
+	case c.Options == nil || c.Options.ExpiryType == "":
+		return nil
+	case c.Options.IsPERSIST():
+		return []string{"GETEX", c.Key, "PERSIST"}
+	default:
+		return []string{"GETEX", c.Key, "PXAT", strconv.FormatInt(c.Options.ExpiryTime.UnixMilli(), 10)}
+	}
+}
+
+func (c *GetExCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// LCSCommand replies with the longest common subsequence of two strings,
+// its length with Len, or the ranges that make it up with Idx.
+type LCSCommand struct {
+	Key1         string
+	Key2         string
+	Len          bool
+	Idx          bool
+	MinMatchLen  int
+	WithMatchLen bool
+}
+
+func (c *LCSCommand) Execute(store store.Store) (interface{}, error) {
+	if c.Len && c.Idx {
+		return nil, fmt.Errorf("If you want both the length and indexes, please just use IDX.")
+	}
+
+	lcs, matches, err := store.LCS(c.Key1, c.Key2)
+	if err != nil {
+		return nil, err
+	}
+	if c.Len {
+		return len(lcs), nil
+	}
+	if !c.Idx {
+		return lcs, nil
+	}
+
+	result := []interface{}{}
+	for _, m := range matches {
+		if m.Len() < c.MinMatchLen {
+			continue
+		}
+		match := []interface{}{
+			[]interface{}{m.AStart, m.AEnd},
+			[]interface{}{m.BStart, m.BEnd},
+		}
+		if c.WithMatchLen {
+			match = append(match, m.Len())
+		}
+		result = append(result, match)
+	}
+	return []interface{}{"matches", result, "len", len(lcs)}, nil
+}
+
+func (c *LCSCommand) KeyArgs() []string {
+	return []string{c.Key1, c.Key2}
+}
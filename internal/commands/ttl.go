@@ -13,3 +13,7 @@ func (c *TtlCommand) Execute(store store.Store) (interface{}, error) {
 	}
 	return ttl, nil
 }
+
+func (c *TtlCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"strconv"
+
 	"github.com/hardikphalet/go-redis/internal/commands/options"
 	"github.com/hardikphalet/go-redis/internal/store"
 	"github.com/hardikphalet/go-redis/internal/types"
@@ -15,3 +17,18 @@ type ZAddCommand struct {
 func (c *ZAddCommand) Execute(store store.Store) (interface{}, error) {
 	return store.ZAdd(c.Key, c.Members, c.Options)
 }
+
+func (c *ZAddCommand) Propagate() []string {
+	args := []string{"ZADD", c.Key}
+	if c.Options != nil {
+		args = append(args, c.Options.GetActive()...)
+	}
+	for _, m := range c.Members {
+		args = append(args, strconv.FormatFloat(m.Score, 'g', -1, 64), m.Member)
+	}
+	return args
+}
+
+func (c *ZAddCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
@@ -5,13 +5,102 @@ import (
 	"github.com/hardikphalet/go-redis/internal/store"
 )
 
+// ZRangeCommand serves ZRANGE and its BYSCORE and BYLEX forms. For
+// ranges by score or lex, Start and Stop are the minimum and maximum even
+// with REV, which takes them the other way round.
 type ZRangeCommand struct {
 	Key     string
-	Start   interface{} // Can be int for index-based range or string for score/lex range
-	Stop    interface{} // Can be int for index-based range or string for score/lex range
+	Start   interface{} // int for an index range, options.ScoreBound for BYSCORE or options.LexBound for BYLEX
+	Stop    interface{} // int for an index range, options.ScoreBound for BYSCORE or options.LexBound for BYLEX
 	Options *options.ZRangeOptions
 }
 
 func (c *ZRangeCommand) Execute(store store.Store) (interface{}, error) {
 	return store.ZRange(c.Key, c.Start, c.Stop, c.Options)
 }
+
+func (c *ZRangeCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type ZLexCountCommand struct {
+	Key string
+	Min options.LexBound
+	Max options.LexBound
+}
+
+func (c *ZLexCountCommand) Execute(store store.Store) (interface{}, error) {
+	return store.ZLexCount(c.Key, c.Min, c.Max)
+}
+
+func (c *ZLexCountCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// ZRemRangeByLexCommand removes the members between Min and Max. It is
+// propagated only if it removed any.
+type ZRemRangeByLexCommand struct {
+	Key string
+	Min options.LexBound
+	Max options.LexBound
+
+	removed int
+}
+
+func (c *ZRemRangeByLexCommand) Execute(store store.Store) (interface{}, error) {
+	removed, err := store.ZRemRangeByLex(c.Key, c.Min, c.Max)
+	if err != nil {
+		return nil, err
+	}
+	c.removed = removed
+	return removed, nil
+}
+
+func (c *ZRemRangeByLexCommand) Propagate() []string {
+	if c.removed == 0 {
+		return nil
+	}
+	return []string{"ZREMRANGEBYLEX", c.Key, c.Min.String(), c.Max.String()}
+}
+
+func (c *ZRemRangeByLexCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+// ZRankCommand serves ZRANK and ZREVRANK, replying with the rank of the
+// member, or nil if it is not in the set, and with WITHSCORE its score too.
+type ZRankCommand struct {
+	Key       string
+	Member    string
+	Rev       bool
+	WithScore bool
+}
+
+func (c *ZRankCommand) Execute(store store.Store) (interface{}, error) {
+	rank, score, ok, err := store.ZRank(c.Key, c.Member, c.Rev)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if c.WithScore {
+		return []interface{}{rank, score}, nil
+	}
+	return rank, nil
+}
+
+func (c *ZRankCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
+
+type ZCountCommand struct {
+	Key string
+	Min options.ScoreBound
+	Max options.ScoreBound
+}
+
+func (c *ZCountCommand) Execute(store store.Store) (interface{}, error) {
+	return store.ZCount(c.Key, c.Min, c.Max)
+}
+
+func (c *ZCountCommand) KeyArgs() []string {
+	return []string{c.Key}
+}
@@ -0,0 +1,436 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/resp"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// FsyncPolicy controls how often the append-only file is flushed to disk.
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // fsync after every write
+	FsyncEverySec FsyncPolicy = "everysec" // fsync once per second
+	FsyncNo       FsyncPolicy = "no"       // leave flushing to the OS
+)
+
+// ParseFsyncPolicy validates an appendfsync setting.
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch p := FsyncPolicy(s); p {
+	case FsyncAlways, FsyncEverySec, FsyncNo:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid appendfsync policy: %s", s)
+	}
+}
+
+// ErrRewriteInProgress is returned when a rewrite is requested while another
+// one is still running.
+var ErrRewriteInProgress = errors.New("Background append only file rewriting already in progress")
+
+// AOF is an append-only log of write commands encoded as RESP arrays.
+type AOF struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	policy FsyncPolicy
+	size   int64
+	dirty  bool
+	quit   chan struct{}
+	done   chan struct{}
+
+	baseSize   int64        // Size after the last rewrite, used for growth triggers
+	rewriting  bool         // A rewrite is in progress
+	rewriteBuf bytes.Buffer // Commands appended since the rewrite started
+}
+
+// OpenAOF opens the append-only file at path for appending, creating it if
+// it does not exist.
+func OpenAOF(path string, policy FsyncPolicy) (*AOF, error) {
+	if _, err := ParseFsyncPolicy(string(policy)); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open AOF: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat AOF: %w", err)
+	}
+
+	a := &AOF{
+		path:     path,
+		file:     f,
+		policy:   policy,
+		size:     info.Size(),
+		baseSize: info.Size(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if policy == FsyncEverySec {
+		go a.syncLoop()
+	} else {
+		close(a.done)
+	}
+
+	return a, nil
+}
+
+// Append writes a single command to the log, syncing it to disk if the
+// policy is FsyncAlways.
+func (a *AOF) Append(args []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	encoded := resp.EncodeCommand(args)
+	if a.rewriting {
+		a.rewriteBuf.Write(encoded)
+	}
+
+	n, err := a.file.Write(encoded)
+	a.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	switch a.policy {
+	case FsyncAlways:
+		return a.file.Sync()
+	case FsyncEverySec:
+		a.dirty = true
+	}
+	return nil
+}
+
+// Size returns the current size of the log in bytes.
+func (a *AOF) Size() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.size
+}
+
+// ShouldRewrite reports whether the log has grown by at least percentage
+// percent since the last rewrite and is at least minSize bytes. A percentage
+// of zero disables automatic rewrites.
+func (a *AOF) ShouldRewrite(percentage int, minSize int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if percentage <= 0 || a.rewriting || a.size < minSize {
+		return false
+	}
+
+	base := a.baseSize
+	if base == 0 {
+		base = 1
+	}
+	growth := (a.size - base) * 100 / base
+	return growth >= int64(percentage)
+}
+
+// BeginRewrite starts buffering appended commands so that they can be carried
+// over into the rewritten log. The caller must capture the dataset passed to
+// Rewrite at the same point in the command stream.
+func (a *AOF) BeginRewrite() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.rewriting {
+		return ErrRewriteInProgress
+	}
+	a.rewriting = true
+	a.rewriteBuf.Reset()
+	return nil
+}
+
+// Rewrite writes the minimal set of commands recreating entries to a new
+// log, appends the commands buffered since BeginRewrite and atomically
+// replaces the current log with it.
+func (a *AOF) Rewrite(entries []store.Entry) error {
+	tmp, err := a.writeRewrite(entries)
+	if err != nil {
+		a.abortRewrite()
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	err = a.switchTo(tmp)
+	a.rewriting = false
+	a.rewriteBuf.Reset()
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// writeRewrite writes entries to a temporary file next to the log.
+func (a *AOF) writeRewrite(entries []store.Entry) (*os.File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(a.path), "temp-rewriteaof-*.aof")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary AOF: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		for _, args := range EntryCommands(entry) {
+			if _, err := w.Write(resp.EncodeCommand(args)); err != nil {
+				tmp.Close()
+				os.Remove(tmp.Name())
+				return nil, fmt.Errorf("failed to write temporary AOF: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to write temporary AOF: %w", err)
+	}
+
+	return tmp, nil
+}
+
+// switchTo appends the rewrite buffer to tmp and renames it over the log.
+// It must be called with a.mu held.
+func (a *AOF) switchTo(tmp *os.File) error {
+	if _, err := tmp.Write(a.rewriteBuf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write rewrite buffer: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync rewritten AOF: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat rewritten AOF: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), a.path); err != nil {
+		return fmt.Errorf("failed to rename rewritten AOF: %w", err)
+	}
+
+	a.file.Close()
+	a.file = tmp
+	a.size = info.Size()
+	a.baseSize = info.Size()
+	a.dirty = false
+	return nil
+}
+
+func (a *AOF) abortRewrite() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rewriting = false
+	a.rewriteBuf.Reset()
+}
+
+// Close stops the background syncer, flushes the log and closes the file.
+func (a *AOF) Close() error {
+	close(a.quit)
+	<-a.done
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.file.Sync(); err != nil {
+		a.file.Close()
+		return err
+	}
+	return a.file.Close()
+}
+
+func (a *AOF) syncLoop() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.quit:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if a.dirty {
+				if err := a.file.Sync(); err != nil {
+					log.Printf("AOF fsync failed: %v", err)
+				}
+				a.dirty = false
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// LoadAOF replays the log at path against st and returns the number of
+// commands applied. A missing file yields no commands. If the last command
+// was only partially written, the file is truncated to the last complete
+// command and loading succeeds.
+func LoadAOF(path string, st store.Store) (int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	counter := &countingReader{r: f}
+	reader := bufio.NewReader(counter)
+	parser := resp.NewParser(reader)
+
+	var valid int64
+	applied := 0
+	for {
+		cmd, err := parser.Parse()
+		consumed := counter.n - int64(reader.Buffered())
+		if err != nil {
+			if err == io.EOF && consumed == valid {
+				break
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				log.Printf("AOF ends with a truncated command, discarding the last %d bytes", consumed-valid)
+				if err := f.Truncate(valid); err != nil {
+					return applied, fmt.Errorf("failed to truncate AOF: %w", err)
+				}
+				break
+			}
+			return applied, fmt.Errorf("corrupt AOF at offset %d: %w", valid, err)
+		}
+		valid = consumed
+
+		if _, err := cmd.Execute(st); err != nil {
+			log.Printf("AOF command at offset %d failed during replay: %v", valid, err)
+		}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// EntryCommands returns the write commands that recreate entry.
+func EntryCommands(entry store.Entry) [][]string {
+	var cmds [][]string
+	switch v := entry.Value.(type) {
+	case string:
+		args := []string{"SET", entry.Key, v}
+		if !entry.Expiry.IsZero() {
+			args = append(args, "PXAT", strconv.FormatInt(entry.Expiry.UnixMilli(), 10))
+		}
+		return [][]string{args}
+	case []string:
+		args := make([]string, 0, 2+len(v))
+		args = append(args, "RPUSH", entry.Key)
+		args = append(args, v...)
+		cmds = append(cmds, args)
+	case map[string]struct{}:
+		args := make([]string, 0, 2+len(v))
+		args = append(args, "SADD", entry.Key)
+		for member := range v {
+			args = append(args, member)
+		}
+		cmds = append(cmds, args)
+	case []types.ScoreMember:
+		args := make([]string, 0, 2+2*len(v))
+		args = append(args, "ZADD", entry.Key)
+		for _, m := range v {
+			args = append(args, strconv.FormatFloat(m.Score, 'g', -1, 64), m.Member)
+		}
+		cmds = append(cmds, args)
+	case []types.FieldValue:
+		args := make([]string, 0, 2+2*len(v))
+		args = append(args, "HSET", entry.Key)
+		for _, p := range v {
+			args = append(args, p.Field, p.Value)
+		}
+		cmds = append(cmds, args)
+		for _, p := range v {
+			if !p.Expiry.IsZero() {
+				cmds = append(cmds, []string{"HPEXPIREAT", entry.Key, strconv.FormatInt(p.Expiry.UnixMilli(), 10), "FIELDS", "1", p.Field})
+			}
+		}
+	case types.StreamData:
+		cmds = streamCommands(entry.Key, v)
+	}
+
+	if len(cmds) > 0 && !entry.Expiry.IsZero() {
+		cmds = append(cmds, []string{"PEXPIREAT", entry.Key, strconv.FormatInt(entry.Expiry.UnixMilli(), 10)})
+	}
+	return cmds
+}
+
+// streamCommands returns the commands that recreate a stream: an XADD per
+// entry, or one trimmed away at once if the stream is empty, since XADD is
+// the only way to create it; XSETID for the IDs and counters; and for each
+// consumer group, XGROUP CREATE, CREATECONSUMER per consumer and an XCLAIM
+// per pending entry that restores its owner, delivery time and count.
+// Pending entries deleted from the stream cannot be claimed and are lost.
+func streamCommands(key string, st types.StreamData) [][]string {
+	var cmds [][]string
+	for _, entry := range st.Entries {
+		args := make([]string, 0, 3+len(entry.Fields))
+		args = append(args, "XADD", key, entry.ID.String())
+		cmds = append(cmds, append(args, entry.Fields...))
+	}
+	if len(st.Entries) == 0 {
+		id := st.LastID
+		if id.IsZero() {
+			id = types.StreamID{Seq: 1}
+		}
+		cmds = append(cmds, []string{"XADD", key, "MAXLEN", "0", id.String(), "x", "y"})
+	}
+	cmds = append(cmds, []string{"XSETID", key, st.LastID.String(),
+		"ENTRIESADDED", strconv.FormatInt(st.EntriesAdded, 10),
+		"MAXDELETEDID", st.MaxDeletedID.String()})
+
+	for _, g := range st.Groups {
+		args := []string{"XGROUP", "CREATE", key, g.Name, g.LastID.String()}
+		if g.EntriesRead >= 0 {
+			args = append(args, "ENTRIESREAD", strconv.FormatInt(g.EntriesRead, 10))
+		}
+		cmds = append(cmds, args)
+		for _, c := range g.Consumers {
+			cmds = append(cmds, []string{"XGROUP", "CREATECONSUMER", key, g.Name, c.Name})
+		}
+		for _, p := range g.Pending {
+			cmds = append(cmds, []string{"XCLAIM", key, g.Name, p.Consumer, "0", p.ID.String(),
+				"TIME", strconv.FormatInt(p.DeliveryTime.UnixMilli(), 10),
+				"RETRYCOUNT", strconv.FormatInt(p.DeliveryCount, 10), "FORCE", "JUSTID"})
+		}
+	}
+	return cmds
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,250 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestParseFsyncPolicy(t *testing.T) {
+	for _, valid := range []string{"always", "everysec", "no"} {
+		if _, err := ParseFsyncPolicy(valid); err != nil {
+			t.Errorf("ParseFsyncPolicy(%q) error = %v", valid, err)
+		}
+	}
+	if _, err := ParseFsyncPolicy("sometimes"); err == nil {
+		t.Error("ParseFsyncPolicy(sometimes) expected error but got none")
+	}
+}
+
+func TestAOF_AppendAndLoad(t *testing.T) {
+	for _, policy := range []FsyncPolicy{FsyncAlways, FsyncEverySec, FsyncNo} {
+		t.Run(string(policy), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "appendonly.aof")
+
+			aof, err := OpenAOF(path, policy)
+			if err != nil {
+				t.Fatalf("OpenAOF() error = %v", err)
+			}
+			aof.Append([]string{"SET", "a", "1"})
+			aof.Append([]string{"SET", "b", "two words"})
+			aof.Append([]string{"ZADD", "z", "1.5", "m"})
+			aof.Append([]string{"DEL", "a"})
+			if aof.Size() == 0 {
+				t.Error("Size() = 0 after appends")
+			}
+			if err := aof.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			s := store.NewMemoryStore()
+			n, err := LoadAOF(path, s)
+			if err != nil {
+				t.Fatalf("LoadAOF() error = %v", err)
+			}
+			if n != 4 {
+				t.Errorf("LoadAOF() applied %d commands, want 4", n)
+			}
+
+			if v, _ := s.Get("a"); v != nil {
+				t.Errorf("a = %v, want nil", v)
+			}
+			if v, _ := s.Get("b"); v != "two words" {
+				t.Errorf("b = %v, want %q", v, "two words")
+			}
+			if members, _ := s.ZRange("z", 0, -1, nil); len(members) != 1 || members[0] != "m" {
+				t.Errorf("z = %v, want [m]", members)
+			}
+		})
+	}
+}
+
+func TestLoadAOF_TruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	complete := "*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n"
+	partial := "*3\r\n$3\r\nSET\r\n$1\r\nb\r\n$5\r\nhel"
+	if err := os.WriteFile(path, []byte(complete+partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := store.NewMemoryStore()
+	n, err := LoadAOF(path, s)
+	if err != nil {
+		t.Fatalf("LoadAOF() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("LoadAOF() applied %d commands, want 1", n)
+	}
+	if v, _ := s.Get("b"); v != nil {
+		t.Errorf("b = %v, want nil", v)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != complete {
+		t.Errorf("AOF after load = %q, want %q", data, complete)
+	}
+}
+
+func TestLoadAOF_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	if err := os.WriteFile(path, []byte("*1\r\n$7\r\nUNKNOWN\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadAOF(path, store.NewMemoryStore()); err == nil {
+		t.Error("LoadAOF() expected error but got none")
+	}
+}
+
+func TestEntryCommands(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.Set("str", "value", nil)
+	s.ZAdd("zset", []types.ScoreMember{{Score: 2, Member: "b"}, {Score: 1, Member: "a"}}, nil)
+	s.Expire("zset", time.Hour, nil)
+	s.RPush("list", []string{"a", "b", "c"}, false)
+	s.SAdd("set", []string{"1", "x"})
+	s.HSet("hash", []types.FieldValue{{Field: "f", Value: "v"}, {Field: "g", Value: "w"}})
+	s.HExpire("hash", time.Now().Add(time.Hour), []string{"g"}, nil)
+	s.XGroupCreate("stream", "g", "0", true, -1)
+	s.XAdd("stream", "1-1", []string{"f", "v"}, nil)
+	s.XAdd("stream", "2-1", []string{"f", "w"}, nil)
+	s.XReadGroup("g", "alice", []string{"stream"}, []*types.StreamID{nil}, 1, false, time.Now())
+	s.XDel("stream", []types.StreamID{{Ms: 2, Seq: 1}})
+	s.XGroupCreate("empty stream", "g", "$", true, -1)
+
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatalf("OpenAOF() error = %v", err)
+	}
+	for _, entry := range s.Snapshot() {
+		for _, args := range EntryCommands(entry) {
+			aof.Append(args)
+		}
+	}
+	aof.Close()
+
+	replayed := store.NewMemoryStore()
+	if _, err := LoadAOF(path, replayed); err != nil {
+		t.Fatalf("LoadAOF() error = %v", err)
+	}
+
+	if v, _ := replayed.Get("str"); v != "value" {
+		t.Errorf("str = %v, want value", v)
+	}
+	if members, _ := replayed.ZRange("zset", 0, -1, nil); len(members) != 2 || members[0] != "a" {
+		t.Errorf("zset = %v, want [a b]", members)
+	}
+	if items, _ := replayed.LRange("list", 0, -1); len(items) != 3 || items[0] != "a" || items[2] != "c" {
+		t.Errorf("list = %v, want [a b c]", items)
+	}
+	if found, _ := replayed.SMIsMember("set", []string{"1", "x"}); !found[0] || !found[1] {
+		t.Errorf("set membership = %v, want [true true]", found)
+	}
+	if v, _ := replayed.HGet("hash", "f"); v != "v" {
+		t.Errorf("hash f = %v, want v", v)
+	}
+	if times, _ := replayed.HExpireTime("hash", []string{"f", "g"}); times[0] != -1 || times[1] <= 0 {
+		t.Errorf("hash field expiry times = %v, want [-1 positive]", times)
+	}
+	if info, _ := replayed.XInfoStream("stream"); info.Length != 1 || info.EntriesAdded != 2 || info.MaxDeletedID != (types.StreamID{Ms: 2, Seq: 1}) {
+		t.Errorf("stream info = %+v", info)
+	}
+	if pending, _ := replayed.XPending("stream", "g", types.StreamID{}, types.MaxStreamID, -1, "", 0); len(pending) != 1 || pending[0].Consumer != "alice" || pending[0].DeliveryCount != 1 {
+		t.Errorf("stream pending entries = %+v", pending)
+	}
+	if info, _ := replayed.XInfoStream("empty stream"); info.Length != 0 || info.LastID != (types.StreamID{}) || info.Groups != 1 {
+		t.Errorf("empty stream info = %+v", info)
+	}
+	if ttl, _ := replayed.TTL("zset"); ttl <= 0 {
+		t.Errorf("TTL(zset) = %d, want positive", ttl)
+	}
+}
+
+func TestAOF_Rewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatalf("OpenAOF() error = %v", err)
+	}
+	defer aof.Close()
+
+	s := store.NewMemoryStore()
+	apply := func(args ...string) {
+		s.Set(args[1], args[2], nil)
+		aof.Append(args)
+	}
+	for i := 0; i < 100; i++ {
+		apply("SET", "counter", strconv.Itoa(i))
+	}
+	before := aof.Size()
+
+	if err := aof.BeginRewrite(); err != nil {
+		t.Fatalf("BeginRewrite() error = %v", err)
+	}
+	if err := aof.BeginRewrite(); err != ErrRewriteInProgress {
+		t.Errorf("second BeginRewrite() error = %v, want %v", err, ErrRewriteInProgress)
+	}
+	entries := s.Snapshot()
+
+	// Writes arriving while the rewrite runs must survive the switch-over.
+	apply("SET", "late", "arrival")
+
+	if err := aof.Rewrite(entries); err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if aof.Size() >= before {
+		t.Errorf("Size() after rewrite = %d, want less than %d", aof.Size(), before)
+	}
+
+	// Appends after the switch go to the new file.
+	apply("SET", "after", "rewrite")
+
+	replayed := store.NewMemoryStore()
+	if _, err := LoadAOF(path, replayed); err != nil {
+		t.Fatalf("LoadAOF() error = %v", err)
+	}
+	for key, want := range map[string]string{"counter": "99", "late": "arrival", "after": "rewrite"} {
+		if v, _ := replayed.Get(key); v != want {
+			t.Errorf("%s = %v, want %s", key, v, want)
+		}
+	}
+}
+
+func TestAOF_ShouldRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	aof, err := OpenAOF(path, FsyncNo)
+	if err != nil {
+		t.Fatalf("OpenAOF() error = %v", err)
+	}
+	defer aof.Close()
+
+	aof.Append([]string{"SET", "k", "v"})
+	if aof.ShouldRewrite(100, 1<<20) {
+		t.Error("ShouldRewrite() = true below the minimum size")
+	}
+	if !aof.ShouldRewrite(100, 1) {
+		t.Error("ShouldRewrite() = false for a log grown from empty")
+	}
+	if aof.ShouldRewrite(0, 1) {
+		t.Error("ShouldRewrite() = true with automatic rewrites disabled")
+	}
+
+	if err := aof.BeginRewrite(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aof.Rewrite([]store.Entry{
+		{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	aof.Append([]string{"SET", "k", "v"})
+	if aof.ShouldRewrite(100, 1) {
+		t.Error("ShouldRewrite() = true right after a rewrite")
+	}
+}
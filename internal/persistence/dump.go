@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc64"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+)
+
+// ErrInvalidDump is returned for DUMP payloads that are malformed or fail
+// their checksum.
+var ErrInvalidDump = errors.New("DUMP payload version or checksum are wrong")
+
+// DumpPayload serializes the value of entry as returned by DUMP:
+//
+//	<value type> <value> <4-digit version> <crc64 of everything before it>
+//
+// The value uses the snapshot encoding. The key and expiry are not part of
+// the payload; RESTORE supplies them.
+func DumpPayload(entry store.Entry) ([]byte, error) {
+	valueType, ok := rdbValueType(entry.Value)
+	if !ok {
+		return nil, fmt.Errorf("unsupported value type %T for key %s", entry.Value, entry.Key)
+	}
+
+	var buf bytes.Buffer
+	enc := &rdbEncoder{w: &buf}
+	enc.writeByte(valueType)
+	enc.writeValue(entry.Value)
+	enc.writeRaw([]byte(rdbVersion))
+	if enc.err != nil {
+		return nil, enc.err
+	}
+
+	var sum [8]byte
+	binary.LittleEndian.PutUint64(sum[:], crc64.Checksum(buf.Bytes(), crcTable))
+	buf.Write(sum[:])
+	return buf.Bytes(), nil
+}
+
+// ParseDumpPayload decodes a payload produced by DumpPayload into an entry
+// for key without an expiry.
+func ParseDumpPayload(key string, payload []byte) (store.Entry, error) {
+	trailer := len(rdbVersion) + 8
+	if len(payload) < 1+trailer {
+		return store.Entry{}, ErrInvalidDump
+	}
+
+	body := payload[:len(payload)-8]
+	if binary.LittleEndian.Uint64(payload[len(body):]) != crc64.Checksum(body, crcTable) {
+		return store.Entry{}, ErrInvalidDump
+	}
+	if string(body[len(body)-len(rdbVersion):]) != rdbVersion {
+		return store.Entry{}, ErrInvalidDump
+	}
+
+	value := body[:len(body)-len(rdbVersion)]
+	r := bytes.NewReader(value[1:])
+	dec := &rdbDecoder{r: bufio.NewReader(r), crc: crc64.New(crcTable)}
+	v, err := dec.readValue(value[0])
+	if err != nil {
+		return store.Entry{}, ErrInvalidDump
+	}
+	if dec.r.Buffered() != 0 || r.Len() != 0 {
+		return store.Entry{}, ErrInvalidDump
+	}
+	return store.Entry{Key: key, Value: v}, nil
+}
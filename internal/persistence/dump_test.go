@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestDumpPayload_Roundtrip(t *testing.T) {
+	entries := []store.Entry{
+		{Key: "str", Value: "hello"},
+		{Key: "empty", Value: ""},
+		{Key: "zset", Value: []types.ScoreMember{{Score: 1.5, Member: "a"}, {Score: -2, Member: "b"}}},
+	}
+
+	for _, entry := range entries {
+		t.Run(entry.Key, func(t *testing.T) {
+			payload, err := DumpPayload(entry)
+			if err != nil {
+				t.Fatalf("DumpPayload() error = %v", err)
+			}
+			got, err := ParseDumpPayload(entry.Key, payload)
+			if err != nil {
+				t.Fatalf("ParseDumpPayload() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, entry) {
+				t.Errorf("ParseDumpPayload() = %+v, want %+v", got, entry)
+			}
+		})
+	}
+}
+
+func TestParseDumpPayload_Invalid(t *testing.T) {
+	payload, err := DumpPayload(store.Entry{Key: "k", Value: "value"})
+	if err != nil {
+		t.Fatalf("DumpPayload() error = %v", err)
+	}
+
+	corrupt := append([]byte(nil), payload...)
+	corrupt[2] ^= 0xFF
+
+	for name, p := range map[string][]byte{
+		"empty":     nil,
+		"corrupt":   corrupt,
+		"truncated": payload[:len(payload)-1],
+	} {
+		if _, err := ParseDumpPayload("k", p); err != ErrInvalidDump {
+			t.Errorf("ParseDumpPayload(%s) error = %v, want %v", name, err, ErrInvalidDump)
+		}
+	}
+}
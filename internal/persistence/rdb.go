@@ -0,0 +1,638 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// Snapshot file layout:
+//
+//	"CANDYKV" <4-digit version>
+//	{ [opExpireMs <int64 unix ms>] <value type> <key> <value> }
+//	opEOF <crc64 of everything before it>
+//
+// Strings are encoded as a uvarint length followed by the raw bytes, lists
+// and sets as a uvarint element count followed by the elements as strings,
+// hashes as a uvarint field count followed by field/value string pairs, and
+// sorted sets as a uvarint member count followed by member/score pairs with
+// the score stored as the little-endian IEEE 754 bits. Hashes with field TTLs use a
+// separate type whose pairs are each followed by the field's expiry as a
+// uvarint Unix time in milliseconds, 0 for none. Streams are encoded by
+// writeStream.
+const (
+	rdbMagic   = "CANDYKV"
+	rdbVersion = "0001"
+
+	opExpireMs = 0xFC
+	opEOF      = 0xFF
+
+	typeString    = 0
+	typeList      = 1
+	typeSet       = 2
+	typeSortedSet = 3
+	typeHash      = 4
+	typeHashTTL   = 5
+	typeStream    = 6
+)
+
+var (
+	ErrInvalidRDB     = errors.New("invalid snapshot file")
+	ErrChecksumFailed = errors.New("snapshot checksum mismatch")
+
+	crcTable = crc64.MakeTable(crc64.ECMA)
+)
+
+// WriteRDB serializes entries to w in the snapshot format.
+func WriteRDB(w io.Writer, entries []store.Entry) error {
+	bw := bufio.NewWriter(w)
+	crc := crc64.New(crcTable)
+	enc := &rdbEncoder{w: io.MultiWriter(bw, crc)}
+
+	enc.writeRaw([]byte(rdbMagic + rdbVersion))
+	for _, entry := range entries {
+		enc.writeEntry(entry)
+	}
+	enc.writeByte(opEOF)
+	if enc.err != nil {
+		return enc.err
+	}
+
+	var sum [8]byte
+	binary.LittleEndian.PutUint64(sum[:], crc.Sum64())
+	if _, err := bw.Write(sum[:]); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadRDB parses a snapshot produced by WriteRDB.
+func ReadRDB(r io.Reader) ([]store.Entry, error) {
+	crc := crc64.New(crcTable)
+	dec := &rdbDecoder{r: bufio.NewReader(r), crc: crc}
+
+	header := make([]byte, len(rdbMagic)+len(rdbVersion))
+	if err := dec.readFull(header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(rdbMagic)]) != rdbMagic {
+		return nil, ErrInvalidRDB
+	}
+	if string(header[len(rdbMagic):]) != rdbVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %s", header[len(rdbMagic):])
+	}
+
+	var entries []store.Entry
+	for {
+		op, err := dec.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if op == opEOF {
+			break
+		}
+
+		var entry store.Entry
+		if op == opExpireMs {
+			ms, err := dec.readUint64()
+			if err != nil {
+				return nil, err
+			}
+			entry.Expiry = time.UnixMilli(int64(ms))
+			if op, err = dec.readByte(); err != nil {
+				return nil, err
+			}
+		}
+
+		if entry.Key, err = dec.readString(); err != nil {
+			return nil, err
+		}
+		if entry.Value, err = dec.readValue(op); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	expected := crc.Sum64()
+	var sum [8]byte
+	if _, err := io.ReadFull(dec.r, sum[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if binary.LittleEndian.Uint64(sum[:]) != expected {
+		return nil, ErrChecksumFailed
+	}
+
+	return entries, nil
+}
+
+// SaveRDB atomically replaces the snapshot at path with entries. The data is
+// written to a temporary file in the same directory and renamed into place,
+// so a crash mid-save never leaves a truncated snapshot behind.
+func SaveRDB(path string, entries []store.Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "temp-*.rdb")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteRDB(tmp, entries); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadRDB reads the snapshot at path. A missing file is not an error and
+// yields no entries.
+func LoadRDB(path string) ([]store.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadRDB(f)
+}
+
+type rdbEncoder struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *rdbEncoder) writeRaw(p []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(p)
+}
+
+func (e *rdbEncoder) writeByte(b byte) {
+	e.writeRaw([]byte{b})
+}
+
+func (e *rdbEncoder) writeUvarint(n uint64) {
+	l := binary.PutUvarint(e.buf[:], n)
+	e.writeRaw(e.buf[:l])
+}
+
+func (e *rdbEncoder) writeUint64(n uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], n)
+	e.writeRaw(e.buf[:8])
+}
+
+func (e *rdbEncoder) writeString(s string) {
+	e.writeUvarint(uint64(len(s)))
+	e.writeRaw([]byte(s))
+}
+
+func (e *rdbEncoder) writeEntry(entry store.Entry) {
+	if !entry.Expiry.IsZero() {
+		e.writeByte(opExpireMs)
+		e.writeUint64(uint64(entry.Expiry.UnixMilli()))
+	}
+
+	valueType, ok := rdbValueType(entry.Value)
+	if !ok {
+		if e.err == nil {
+			e.err = fmt.Errorf("unsupported value type %T for key %s", entry.Value, entry.Key)
+		}
+		return
+	}
+	e.writeByte(valueType)
+	e.writeString(entry.Key)
+	e.writeValue(entry.Value)
+}
+
+func rdbValueType(value interface{}) (byte, bool) {
+	switch v := value.(type) {
+	case string:
+		return typeString, true
+	case []string:
+		return typeList, true
+	case map[string]struct{}:
+		return typeSet, true
+	case []types.ScoreMember:
+		return typeSortedSet, true
+	case []types.FieldValue:
+		for _, p := range v {
+			if !p.Expiry.IsZero() {
+				return typeHashTTL, true
+			}
+		}
+		return typeHash, true
+	case types.StreamData:
+		return typeStream, true
+	default:
+		return 0, false
+	}
+}
+
+func (e *rdbEncoder) writeValue(value interface{}) {
+	switch v := value.(type) {
+	case string:
+		e.writeString(v)
+	case []string:
+		e.writeUvarint(uint64(len(v)))
+		for _, item := range v {
+			e.writeString(item)
+		}
+	case map[string]struct{}:
+		e.writeUvarint(uint64(len(v)))
+		for member := range v {
+			e.writeString(member)
+		}
+	case []types.ScoreMember:
+		e.writeUvarint(uint64(len(v)))
+		for _, m := range v {
+			e.writeString(m.Member)
+			e.writeUint64(math.Float64bits(m.Score))
+		}
+	case []types.FieldValue:
+		valueType, _ := rdbValueType(v)
+		e.writeUvarint(uint64(len(v)))
+		for _, p := range v {
+			e.writeString(p.Field)
+			e.writeString(p.Value)
+			if valueType == typeHashTTL {
+				var ms uint64
+				if !p.Expiry.IsZero() {
+					ms = uint64(p.Expiry.UnixMilli())
+				}
+				e.writeUvarint(ms)
+			}
+		}
+	case types.StreamData:
+		e.writeStream(v)
+	}
+}
+
+// writeStream encodes a stream as its entry count followed by the entries,
+// each an ID and a uvarint-counted list of field/value strings; then the
+// last ID, the count of entries ever added and the greatest deleted ID; then
+// the consumer groups. IDs are two uvarints and times are uvarint Unix
+// milliseconds, 0 for none. Entries-read counts are stored plus one, so that
+// an unknown count of -1 fits in a uvarint.
+func (e *rdbEncoder) writeStream(st types.StreamData) {
+	e.writeUvarint(uint64(len(st.Entries)))
+	for _, entry := range st.Entries {
+		e.writeStreamID(entry.ID)
+		e.writeUvarint(uint64(len(entry.Fields)))
+		for _, f := range entry.Fields {
+			e.writeString(f)
+		}
+	}
+	e.writeStreamID(st.LastID)
+	e.writeUvarint(uint64(st.EntriesAdded))
+	e.writeStreamID(st.MaxDeletedID)
+
+	e.writeUvarint(uint64(len(st.Groups)))
+	for _, g := range st.Groups {
+		e.writeString(g.Name)
+		e.writeStreamID(g.LastID)
+		e.writeUvarint(uint64(g.EntriesRead + 1))
+		e.writeUvarint(uint64(len(g.Consumers)))
+		for _, c := range g.Consumers {
+			e.writeString(c.Name)
+			e.writeTime(c.SeenTime)
+			e.writeTime(c.ActiveTime)
+		}
+		e.writeUvarint(uint64(len(g.Pending)))
+		for _, p := range g.Pending {
+			e.writeStreamID(p.ID)
+			e.writeString(p.Consumer)
+			e.writeTime(p.DeliveryTime)
+			e.writeUvarint(uint64(p.DeliveryCount))
+		}
+	}
+}
+
+func (e *rdbEncoder) writeStreamID(id types.StreamID) {
+	e.writeUvarint(id.Ms)
+	e.writeUvarint(id.Seq)
+}
+
+func (e *rdbEncoder) writeTime(t time.Time) {
+	var ms uint64
+	if !t.IsZero() {
+		ms = uint64(t.UnixMilli())
+	}
+	e.writeUvarint(ms)
+}
+
+type rdbDecoder struct {
+	r   *bufio.Reader
+	crc hash.Hash64
+}
+
+func (d *rdbDecoder) readFull(p []byte) error {
+	if _, err := io.ReadFull(d.r, p); err != nil {
+		return unexpectedEOF(err)
+	}
+	d.crc.Write(p)
+	return nil
+}
+
+func (d *rdbDecoder) readByte() (byte, error) {
+	var b [1]byte
+	if err := d.readFull(b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *rdbDecoder) readUvarint() (uint64, error) {
+	var n uint64
+	var shift uint
+	for i := 0; i < binary.MaxVarintLen64; i++ {
+		b, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if b < 0x80 {
+			return n | uint64(b)<<shift, nil
+		}
+		n |= uint64(b&0x7f) << shift
+		shift += 7
+	}
+	return 0, ErrInvalidRDB
+}
+
+func (d *rdbDecoder) readUint64() (uint64, error) {
+	var b [8]byte
+	if err := d.readFull(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func (d *rdbDecoder) readString() (string, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > math.MaxInt32 {
+		return "", ErrInvalidRDB
+	}
+	b := make([]byte, n)
+	if err := d.readFull(b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *rdbDecoder) readValue(valueType byte) (interface{}, error) {
+	switch valueType {
+	case typeString:
+		return d.readString()
+	case typeList:
+		n, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]string, 0, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			item, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case typeSet:
+		n, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		members := make(map[string]struct{}, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			member, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			members[member] = struct{}{}
+		}
+		return members, nil
+	case typeSortedSet:
+		n, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		members := make([]types.ScoreMember, 0, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			member, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			bits, err := d.readUint64()
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, types.ScoreMember{Score: math.Float64frombits(bits), Member: member})
+		}
+		return members, nil
+	case typeHash, typeHashTTL:
+		n, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		pairs := make([]types.FieldValue, 0, min(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			field, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			pair := types.FieldValue{Field: field, Value: value}
+			if valueType == typeHashTTL {
+				ms, err := d.readUvarint()
+				if err != nil {
+					return nil, err
+				}
+				if ms != 0 {
+					pair.Expiry = time.UnixMilli(int64(ms))
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+		return pairs, nil
+	case typeStream:
+		return d.readStream()
+	default:
+		return nil, fmt.Errorf("unknown value type in snapshot: %d", valueType)
+	}
+}
+
+// readStream decodes a stream written by writeStream.
+func (d *rdbDecoder) readStream() (types.StreamData, error) {
+	var st types.StreamData
+	n, err := d.readUvarint()
+	if err != nil {
+		return st, err
+	}
+	st.Entries = make([]types.StreamEntry, 0, min(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		id, err := d.readStreamID()
+		if err != nil {
+			return st, err
+		}
+		fields, err := d.readStrings()
+		if err != nil {
+			return st, err
+		}
+		st.Entries = append(st.Entries, types.StreamEntry{ID: id, Fields: fields})
+	}
+	if st.LastID, err = d.readStreamID(); err != nil {
+		return st, err
+	}
+	added, err := d.readUvarint()
+	if err != nil {
+		return st, err
+	}
+	st.EntriesAdded = int64(added)
+	if st.MaxDeletedID, err = d.readStreamID(); err != nil {
+		return st, err
+	}
+
+	n, err = d.readUvarint()
+	if err != nil {
+		return st, err
+	}
+	for i := uint64(0); i < n; i++ {
+		g, err := d.readStreamGroup()
+		if err != nil {
+			return st, err
+		}
+		st.Groups = append(st.Groups, g)
+	}
+	return st, nil
+}
+
+func (d *rdbDecoder) readStreamGroup() (types.StreamGroup, error) {
+	var g types.StreamGroup
+	var err error
+	if g.Name, err = d.readString(); err != nil {
+		return g, err
+	}
+	if g.LastID, err = d.readStreamID(); err != nil {
+		return g, err
+	}
+	entriesRead, err := d.readUvarint()
+	if err != nil {
+		return g, err
+	}
+	g.EntriesRead = int64(entriesRead) - 1
+
+	n, err := d.readUvarint()
+	if err != nil {
+		return g, err
+	}
+	for i := uint64(0); i < n; i++ {
+		var c types.StreamConsumer
+		if c.Name, err = d.readString(); err != nil {
+			return g, err
+		}
+		if c.SeenTime, err = d.readTime(); err != nil {
+			return g, err
+		}
+		if c.ActiveTime, err = d.readTime(); err != nil {
+			return g, err
+		}
+		g.Consumers = append(g.Consumers, c)
+	}
+
+	n, err = d.readUvarint()
+	if err != nil {
+		return g, err
+	}
+	for i := uint64(0); i < n; i++ {
+		var p types.StreamPending
+		if p.ID, err = d.readStreamID(); err != nil {
+			return g, err
+		}
+		if p.Consumer, err = d.readString(); err != nil {
+			return g, err
+		}
+		if p.DeliveryTime, err = d.readTime(); err != nil {
+			return g, err
+		}
+		count, err := d.readUvarint()
+		if err != nil {
+			return g, err
+		}
+		p.DeliveryCount = int64(count)
+		g.Pending = append(g.Pending, p)
+	}
+	return g, nil
+}
+
+func (d *rdbDecoder) readStrings() ([]string, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]string, 0, min(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		item, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *rdbDecoder) readStreamID() (types.StreamID, error) {
+	ms, err := d.readUvarint()
+	if err != nil {
+		return types.StreamID{}, err
+	}
+	seq, err := d.readUvarint()
+	if err != nil {
+		return types.StreamID{}, err
+	}
+	return types.StreamID{Ms: ms, Seq: seq}, nil
+}
+
+func (d *rdbDecoder) readTime() (time.Time, error) {
+	ms, err := d.readUvarint()
+	if err != nil || ms == 0 {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
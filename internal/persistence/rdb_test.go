@@ -0,0 +1,171 @@
+package persistence
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestRDB_RoundTrip(t *testing.T) {
+	expiry := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	entries := []store.Entry{
+		{Key: "plain", Value: "value"},
+		{Key: "empty", Value: ""},
+		{Key: "binary", Value: "\x00\xff\r\n"},
+		{Key: "volatile", Value: "soon gone", Expiry: expiry},
+		{Key: "list", Value: []string{"a", "", "a"}},
+		{Key: "set", Value: map[string]struct{}{"a": {}, "": {}, "42": {}}},
+		{Key: "hash", Value: []types.FieldValue{{Field: "f", Value: "v"}, {Field: "", Value: ""}}},
+		{Key: "volatile hash", Value: []types.FieldValue{{Field: "f", Value: "v", Expiry: expiry}, {Field: "g", Value: "w"}}},
+		{Key: "zset", Value: []types.ScoreMember{
+			{Score: 1.5, Member: "one"},
+			{Score: -2, Member: "two"},
+		}},
+		{Key: "stream", Value: types.StreamData{
+			Entries:      []types.StreamEntry{{ID: types.StreamID{Ms: 5, Seq: 1}, Fields: []string{"f", "v", "", ""}}},
+			LastID:       types.StreamID{Ms: 7},
+			EntriesAdded: 3,
+			MaxDeletedID: types.StreamID{Ms: 7},
+			Groups: []types.StreamGroup{{
+				Name:        "g",
+				LastID:      types.StreamID{Ms: 5, Seq: 1},
+				EntriesRead: -1,
+				Consumers:   []types.StreamConsumer{{Name: "alice", SeenTime: expiry}},
+				Pending:     []types.StreamPending{{ID: types.StreamID{Ms: 5, Seq: 1}, Consumer: "alice", DeliveryTime: expiry, DeliveryCount: 2}},
+			}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRDB(&buf, entries); err != nil {
+		t.Fatalf("WriteRDB() error = %v", err)
+	}
+
+	got, err := ReadRDB(&buf)
+	if err != nil {
+		t.Fatalf("ReadRDB() error = %v", err)
+	}
+
+	if len(got) != len(entries) {
+		t.Fatalf("ReadRDB() returned %d entries, want %d", len(got), len(entries))
+	}
+
+	for i, want := range entries {
+		if got[i].Key != want.Key {
+			t.Errorf("entry %d key = %q, want %q", i, got[i].Key, want.Key)
+		}
+		if !got[i].Expiry.Equal(want.Expiry) {
+			t.Errorf("entry %d expiry = %v, want %v", i, got[i].Expiry, want.Expiry)
+		}
+		switch w := want.Value.(type) {
+		case string:
+			if got[i].Value != w {
+				t.Errorf("entry %d value = %q, want %q", i, got[i].Value, w)
+			}
+		case []string:
+			if items, ok := got[i].Value.([]string); !ok || !reflect.DeepEqual(items, w) {
+				t.Errorf("entry %d value = %v, want %v", i, got[i].Value, w)
+			}
+		case map[string]struct{}:
+			if members, ok := got[i].Value.(map[string]struct{}); !ok || !reflect.DeepEqual(members, w) {
+				t.Errorf("entry %d value = %v, want %v", i, got[i].Value, w)
+			}
+		case types.StreamData:
+			if st, ok := got[i].Value.(types.StreamData); !ok || !reflect.DeepEqual(st, w) {
+				t.Errorf("entry %d value = %+v, want %+v", i, got[i].Value, w)
+			}
+		case []types.FieldValue:
+			pairs, ok := got[i].Value.([]types.FieldValue)
+			if !ok || len(pairs) != len(w) {
+				t.Fatalf("entry %d value = %v, want %v", i, got[i].Value, w)
+			}
+			for j := range w {
+				if pairs[j].Field != w[j].Field || pairs[j].Value != w[j].Value || !pairs[j].Expiry.Equal(w[j].Expiry) {
+					t.Errorf("entry %d pair %d = %v, want %v", i, j, pairs[j], w[j])
+				}
+			}
+		case []types.ScoreMember:
+			members, ok := got[i].Value.([]types.ScoreMember)
+			if !ok || len(members) != len(w) {
+				t.Fatalf("entry %d value = %v, want %v", i, got[i].Value, w)
+			}
+			for j := range w {
+				if members[j] != w[j] {
+					t.Errorf("entry %d member %d = %v, want %v", i, j, members[j], w[j])
+				}
+			}
+		}
+	}
+}
+
+func TestRDB_Corruption(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRDB(&buf, []store.Entry{{Key: "key", Value: "value"}}); err != nil {
+		t.Fatalf("WriteRDB() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "bad magic", input: append([]byte("REDIS"), data[5:]...)},
+		{name: "flipped byte", input: func() []byte {
+			b := append([]byte(nil), data...)
+			b[len(b)-12] ^= 0xff
+			return b
+		}()},
+		{name: "truncated", input: data[:len(data)-4]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadRDB(bytes.NewReader(tt.input)); err == nil {
+				t.Error("ReadRDB() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadRDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.rdb")
+
+	entries, err := LoadRDB(path)
+	if err != nil || entries != nil {
+		t.Fatalf("LoadRDB() on missing file = %v, %v, want nil, nil", entries, err)
+	}
+
+	s := store.NewMemoryStore()
+	s.Set("a", "1", nil)
+	s.Set("b", "2", nil)
+	s.ZAdd("z", []types.ScoreMember{{Score: 3, Member: "m"}}, nil)
+
+	if err := SaveRDB(path, s.Snapshot()); err != nil {
+		t.Fatalf("SaveRDB() error = %v", err)
+	}
+
+	entries, err = LoadRDB(path)
+	if err != nil {
+		t.Fatalf("LoadRDB() error = %v", err)
+	}
+
+	restored := store.NewMemoryStore()
+	if err := restored.Restore(entries); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	keys, _ := restored.Keys("*")
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "z" {
+		t.Errorf("restored keys = %v, want [a b z]", keys)
+	}
+	if v, _ := restored.Get("b"); v != "2" {
+		t.Errorf("restored b = %v, want 2", v)
+	}
+}
@@ -0,0 +1,811 @@
+// Package raft implements the Raft consensus algorithm for replicating a
+// command log across a small group of nodes: leader election, log
+// replication and log compaction through state machine snapshots.
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// Role is the role a node currently plays in the group.
+type Role int
+
+const (
+	Follower Role = iota
+	Candidate
+	Leader
+)
+
+func (r Role) String() string {
+	switch r {
+	case Follower:
+		return "follower"
+	case Candidate:
+		return "candidate"
+	case Leader:
+		return "leader"
+	default:
+		return fmt.Sprintf("Role(%d)", int(r))
+	}
+}
+
+// Entry is a single log entry. A nil Command is the no-op a new leader
+// appends to commit the entries of earlier terms.
+type Entry struct {
+	Index   uint64
+	Term    uint64
+	Command []string
+}
+
+// StateMachine is the replicated state the log is applied to. Apply is
+// called for every committed command in log order, except those the leader
+// already applied through ProposeApplied. Snapshot and Restore serialize the
+// state for log compaction and for followers that fell behind; they are
+// never called concurrently with Apply.
+type StateMachine interface {
+	Apply(command []string) (interface{}, error)
+	Snapshot() ([]byte, error)
+	Restore(snapshot []byte) error
+}
+
+// CommitObserver is implemented by state machines that need to know when a
+// command the leader applied through ProposeApplied is committed, to hold
+// back effects that must not outlive a lost entry, such as writing it to
+// disk. Committed is called in log order, in place of Apply.
+type CommitObserver interface {
+	Committed(command []string)
+}
+
+var (
+	ErrNotLeader      = errors.New("not the Raft leader")
+	ErrLeadershipLost = errors.New("leadership lost before the command was committed")
+	ErrTimeout        = errors.New("timed out waiting for the command to be committed")
+	ErrStopped        = errors.New("Raft node stopped")
+)
+
+// NotLeaderError is returned by Propose on a node that is not the leader.
+// LeaderAddr is the client address of the current leader, empty if none is
+// known.
+type NotLeaderError struct {
+	LeaderID   string
+	LeaderAddr string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.LeaderID == "" {
+		return "not the Raft leader, no leader elected"
+	}
+	return fmt.Sprintf("not the Raft leader, leader is %s", e.LeaderID)
+}
+
+func (e *NotLeaderError) Is(target error) bool {
+	return target == ErrNotLeader
+}
+
+// Config holds the settings of a node.
+type Config struct {
+	ID         string
+	Addr       string            // Address the Raft RPC listener binds to
+	ClientAddr string            // Address clients reach this node at, passed on to followers
+	Peers      map[string]string // The other members of the group: ID to Raft address
+	Dir        string            // Directory for durable state; empty keeps it in memory
+
+	HeartbeatInterval time.Duration
+	ElectionTimeout   time.Duration // Minimum election timeout, randomized up to twice as much
+	SnapshotThreshold int           // Compact the log once it holds this many applied entries
+	ProposeTimeout    time.Duration // How long Propose waits for a commit
+	MaxBatch          int           // Maximum number of entries per AppendEntries
+}
+
+func (c *Config) setDefaults() {
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = 50 * time.Millisecond
+	}
+	if c.ElectionTimeout <= 0 {
+		c.ElectionTimeout = 300 * time.Millisecond
+	}
+	if c.SnapshotThreshold <= 0 {
+		c.SnapshotThreshold = 1024
+	}
+	if c.ProposeTimeout <= 0 {
+		c.ProposeTimeout = 5 * time.Second
+	}
+	if c.MaxBatch <= 0 {
+		c.MaxBatch = 512
+	}
+}
+
+// Status is a point-in-time view of a node.
+type Status struct {
+	ID            string
+	Role          Role
+	Term          uint64
+	LeaderID      string
+	LeaderAddr    string
+	CommitIndex   uint64
+	LastApplied   uint64
+	LastIndex     uint64
+	SnapshotIndex uint64
+}
+
+type proposal struct {
+	term uint64
+	done chan result
+}
+
+type result struct {
+	value interface{}
+	err   error
+}
+
+// Node is a member of a Raft group.
+type Node struct {
+	cfg     Config
+	fsm     StateMachine
+	storage *storage // Nil when state is kept in memory
+
+	mu        sync.Mutex
+	applyCond *sync.Cond
+
+	// Persistent state
+	term     uint64
+	votedFor string
+	log      []Entry // log[0] holds the index and term covered by the snapshot
+	snapshot []byte
+
+	// Volatile state
+	role             Role
+	leaderID         string
+	leaderAddr       string
+	commitIndex      uint64
+	lastApplied      uint64
+	electionDeadline time.Time
+	lastHeartbeat    time.Time
+	restorePending   bool // An installed snapshot awaits Restore by the applier
+	fsmBusy          bool // The applier is snapshotting or restoring the state machine
+	snapshotDue      bool // A snapshot waits for the entries applied ahead of the log
+	stopped          bool
+	pending          map[uint64]*proposal
+	applied          map[uint64]uint64 // Term of the entries ProposeApplied applied ahead of the log, by index
+
+	// Leader state
+	nextIndex   map[string]uint64
+	matchIndex  map[string]uint64
+	replicating map[string]bool
+
+	listener  net.Listener
+	rpcServer *rpc.Server
+	connsMu   sync.Mutex
+	conns     map[net.Conn]struct{}
+	clients   map[string]*rpc.Client
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewNode creates a node applying committed commands to fsm. If cfg.Dir holds
+// state from an earlier run, the log and snapshot are loaded from it and the
+// snapshot is restored into fsm.
+func NewNode(cfg Config, fsm StateMachine) (*Node, error) {
+	cfg.setDefaults()
+	if cfg.ID == "" {
+		return nil, errors.New("Raft node ID is required")
+	}
+
+	n := &Node{
+		cfg:         cfg,
+		fsm:         fsm,
+		log:         []Entry{{}},
+		pending:     make(map[uint64]*proposal),
+		applied:     make(map[uint64]uint64),
+		nextIndex:   make(map[string]uint64),
+		matchIndex:  make(map[string]uint64),
+		replicating: make(map[string]bool),
+		conns:       make(map[net.Conn]struct{}),
+		clients:     make(map[string]*rpc.Client),
+		quit:        make(chan struct{}),
+	}
+	n.applyCond = sync.NewCond(&n.mu)
+
+	if cfg.Dir != "" {
+		n.storage = newStorage(cfg.Dir, cfg.ID)
+		state, snap, err := n.storage.load()
+		if err != nil {
+			return nil, err
+		}
+		n.term, n.votedFor = state.Term, state.VotedFor
+		if len(state.Log) > 0 {
+			n.log = state.Log
+		}
+		if snap != nil {
+			if err := fsm.Restore(snap.Data); err != nil {
+				return nil, fmt.Errorf("failed to restore Raft snapshot: %w", err)
+			}
+			n.snapshot = snap.Data
+			n.compactTo(snap.Index, snap.Term)
+			n.commitIndex, n.lastApplied = snap.Index, snap.Index
+		}
+	}
+
+	return n, nil
+}
+
+// Start begins serving Raft RPCs and takes part in elections.
+func (n *Node) Start() error {
+	n.rpcServer = rpc.NewServer()
+	if err := n.rpcServer.RegisterName("Raft", &rpcService{n: n}); err != nil {
+		return err
+	}
+
+	l, err := net.Listen("tcp", n.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to start Raft listener: %w", err)
+	}
+	n.listener = l
+
+	n.mu.Lock()
+	n.resetElectionTimerLocked()
+	n.mu.Unlock()
+
+	n.wg.Add(3)
+	go n.acceptLoop()
+	go n.tickLoop()
+	go n.applyLoop()
+	return nil
+}
+
+// Stop shuts the node down. Pending proposals fail with ErrStopped.
+func (n *Node) Stop() {
+	n.mu.Lock()
+	if n.stopped {
+		n.mu.Unlock()
+		return
+	}
+	n.stopped = true
+	close(n.quit)
+	for index, p := range n.pending {
+		p.done <- result{err: ErrStopped}
+		delete(n.pending, index)
+	}
+	n.applyCond.Broadcast()
+	n.mu.Unlock()
+
+	if n.listener != nil {
+		n.listener.Close()
+	}
+	n.connsMu.Lock()
+	for conn := range n.conns {
+		conn.Close()
+	}
+	for id, client := range n.clients {
+		client.Close()
+		delete(n.clients, id)
+	}
+	n.connsMu.Unlock()
+
+	n.wg.Wait()
+}
+
+// Propose appends command to the log and waits until it is committed and
+// applied, returning the result of applying it. It fails with a
+// *NotLeaderError on nodes that are not the leader.
+func (n *Node) Propose(command []string) (interface{}, error) {
+	n.mu.Lock()
+	if n.stopped {
+		n.mu.Unlock()
+		return nil, ErrStopped
+	}
+	if n.role != Leader {
+		err := &NotLeaderError{LeaderID: n.leaderID, LeaderAddr: n.leaderAddr}
+		n.mu.Unlock()
+		return nil, err
+	}
+
+	return n.appendLocked([][]string{command})
+}
+
+// ProposeApplied is Propose for a state machine that executes commands on the
+// leader before they are committed. Once the state machine reflects every
+// earlier entry, apply is called with the node locked to change the state and
+// return the commands that make the same change on the other nodes, which
+// are then appended to the log. The leader does not apply those entries
+// again, so the commands must not depend on the time or the node they are
+// applied on. If apply returns an error or no commands nothing is appended.
+//
+// Until the entry is committed, readers on the leader may see a change that
+// is later lost. Should that happen, the leader rebuilds its state machine
+// from the snapshot and the committed log. A CommitObserver learns which
+// changes were kept.
+func (n *Node) ProposeApplied(apply func() ([][]string, error)) error {
+	n.mu.Lock()
+	for !n.stopped && n.role == Leader && (n.restorePending || n.fsmBusy || n.snapshotDue || !n.caughtUpLocked()) {
+		n.applyCond.Wait()
+	}
+	if n.stopped {
+		n.mu.Unlock()
+		return ErrStopped
+	}
+	if n.role != Leader {
+		err := &NotLeaderError{LeaderID: n.leaderID, LeaderAddr: n.leaderAddr}
+		n.mu.Unlock()
+		return err
+	}
+
+	commands, err := apply()
+	if err != nil || len(commands) == 0 {
+		n.mu.Unlock()
+		return err
+	}
+	for i := range commands {
+		n.applied[n.lastIndex()+1+uint64(i)] = n.term
+	}
+	_, err = n.appendLocked(commands)
+	return err
+}
+
+// caughtUpLocked reports whether the state machine reflects every entry in
+// the log, having applied it or had it applied ahead by ProposeApplied.
+func (n *Node) caughtUpLocked() bool {
+	base := n.log[0].Index
+	for index := n.lastApplied + 1; index <= n.lastIndex(); index++ {
+		if _, ok := n.applied[index]; !ok && n.log[index-base].Command != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// appendLocked appends an entry for each of commands to the log of the
+// leader, unlocks the node and waits until the last one is committed and
+// applied, returning the result of applying it.
+func (n *Node) appendLocked(commands [][]string) (interface{}, error) {
+	var entry Entry
+	for _, command := range commands {
+		entry = Entry{Index: n.lastIndex() + 1, Term: n.term, Command: command}
+		n.log = append(n.log, entry)
+	}
+	n.persistLocked()
+
+	p := &proposal{term: n.term, done: make(chan result, 1)}
+	n.pending[entry.Index] = p
+	n.broadcastLocked()
+	n.advanceCommitLocked()
+	n.mu.Unlock()
+
+	timer := time.NewTimer(n.cfg.ProposeTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-p.done:
+		return r.value, r.err
+	case <-timer.C:
+		n.mu.Lock()
+		if n.pending[entry.Index] == p {
+			delete(n.pending, entry.Index)
+		}
+		n.mu.Unlock()
+		return nil, ErrTimeout
+	}
+}
+
+// Status returns the current state of the node.
+func (n *Node) Status() Status {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return Status{
+		ID:            n.cfg.ID,
+		Role:          n.role,
+		Term:          n.term,
+		LeaderID:      n.leaderID,
+		LeaderAddr:    n.leaderAddr,
+		CommitIndex:   n.commitIndex,
+		LastApplied:   n.lastApplied,
+		LastIndex:     n.lastIndex(),
+		SnapshotIndex: n.log[0].Index,
+	}
+}
+
+func (n *Node) lastIndex() uint64 {
+	return n.log[len(n.log)-1].Index
+}
+
+func (n *Node) lastTerm() uint64 {
+	return n.log[len(n.log)-1].Term
+}
+
+// termAt returns the term of the entry at index, which must lie between the
+// snapshot index and the last index.
+func (n *Node) termAt(index uint64) uint64 {
+	return n.log[index-n.log[0].Index].Term
+}
+
+// compactTo discards the log up to index, which the snapshot covers, keeping
+// any later entries.
+func (n *Node) compactTo(index, term uint64) {
+	base := n.log[0].Index
+	log := []Entry{{Index: index, Term: term}}
+	if index >= base && index < n.lastIndex() && n.termAt(index) == term {
+		log = append(log, n.log[index-base+1:]...)
+	}
+	n.log = log
+}
+
+func (n *Node) resetElectionTimerLocked() {
+	timeout := n.cfg.ElectionTimeout + time.Duration(rand.Int63n(int64(n.cfg.ElectionTimeout)))
+	n.electionDeadline = time.Now().Add(timeout)
+}
+
+func (n *Node) persistLocked() {
+	if n.storage == nil {
+		return
+	}
+	state := persistentState{Term: n.term, VotedFor: n.votedFor, Log: n.log}
+	if err := n.storage.saveState(state); err != nil {
+		log.Printf("Failed to persist Raft state: %v", err)
+	}
+}
+
+func (n *Node) persistSnapshotLocked() {
+	if n.storage == nil {
+		return
+	}
+	snap := snapshotFile{Index: n.log[0].Index, Term: n.log[0].Term, Data: n.snapshot}
+	if err := n.storage.saveSnapshot(snap); err != nil {
+		log.Printf("Failed to persist Raft snapshot: %v", err)
+	}
+	n.persistLocked()
+}
+
+// becomeFollowerLocked steps down to follower, adopting term if it is newer.
+func (n *Node) becomeFollowerLocked(term uint64) {
+	if term > n.term {
+		n.term = term
+		n.votedFor = ""
+		n.persistLocked()
+	}
+	if n.role != Follower {
+		log.Printf("Raft node %s is now a follower in term %d", n.cfg.ID, n.term)
+	}
+	n.role = Follower
+	n.resetElectionTimerLocked()
+	n.applyCond.Broadcast()
+}
+
+// discardAppliedLocked is called when the log is truncated from index on. If
+// ProposeApplied applied any of the discarded entries ahead of the log, the
+// state machine is rebuilt from the snapshot and the committed log.
+func (n *Node) discardAppliedLocked(index uint64) {
+	for i := range n.applied {
+		if i >= index {
+			log.Printf("Raft node %s lost uncommitted entries it applied, rebuilding state", n.cfg.ID)
+			clear(n.applied)
+			n.restorePending = true
+			n.applyCond.Broadcast()
+			return
+		}
+	}
+}
+
+func (n *Node) quorum() int {
+	return (len(n.cfg.Peers)+1)/2 + 1
+}
+
+func (n *Node) tickLoop() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval / 5)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.quit:
+			return
+		case <-ticker.C:
+		}
+
+		n.mu.Lock()
+		switch {
+		case n.role == Leader:
+			if time.Since(n.lastHeartbeat) >= n.cfg.HeartbeatInterval {
+				n.broadcastLocked()
+			}
+		case time.Now().After(n.electionDeadline):
+			n.startElectionLocked()
+		}
+		n.mu.Unlock()
+	}
+}
+
+func (n *Node) startElectionLocked() {
+	n.role = Candidate
+	n.term++
+	n.votedFor = n.cfg.ID
+	n.leaderID, n.leaderAddr = "", ""
+	n.persistLocked()
+	n.resetElectionTimerLocked()
+
+	term := n.term
+	votes := 1
+	if votes >= n.quorum() {
+		n.becomeLeaderLocked()
+		return
+	}
+
+	args := &RequestVoteArgs{
+		Term:         term,
+		CandidateID:  n.cfg.ID,
+		LastLogIndex: n.lastIndex(),
+		LastLogTerm:  n.lastTerm(),
+	}
+	for id := range n.cfg.Peers {
+		n.wg.Add(1)
+		go func(id string) {
+			defer n.wg.Done()
+
+			var reply RequestVoteReply
+			if err := n.call(id, "RequestVote", args, &reply); err != nil {
+				return
+			}
+
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if reply.Term > n.term {
+				n.becomeFollowerLocked(reply.Term)
+				return
+			}
+			if n.role != Candidate || n.term != term || !reply.VoteGranted {
+				return
+			}
+			votes++
+			if votes >= n.quorum() {
+				n.becomeLeaderLocked()
+			}
+		}(id)
+	}
+}
+
+func (n *Node) becomeLeaderLocked() {
+	log.Printf("Raft node %s elected leader in term %d", n.cfg.ID, n.term)
+
+	n.role = Leader
+	n.leaderID, n.leaderAddr = n.cfg.ID, n.cfg.ClientAddr
+	for id := range n.cfg.Peers {
+		n.nextIndex[id] = n.lastIndex() + 1
+		n.matchIndex[id] = 0
+	}
+
+	n.log = append(n.log, Entry{Index: n.lastIndex() + 1, Term: n.term})
+	n.persistLocked()
+	n.broadcastLocked()
+	n.advanceCommitLocked()
+}
+
+// broadcastLocked starts replicating to every peer that is not already
+// being replicated to.
+func (n *Node) broadcastLocked() {
+	n.lastHeartbeat = time.Now()
+	for id := range n.cfg.Peers {
+		if n.replicating[id] || n.stopped {
+			continue
+		}
+		n.replicating[id] = true
+		n.wg.Add(1)
+		go n.replicate(id)
+	}
+}
+
+// replicate sends AppendEntries or InstallSnapshot to a peer until it has
+// caught up with the log or the node stops leading.
+func (n *Node) replicate(id string) {
+	defer n.wg.Done()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	defer func() { n.replicating[id] = false }()
+
+	for n.role == Leader && !n.stopped {
+		term := n.term
+		next := n.nextIndex[id]
+
+		if next <= n.log[0].Index {
+			args := &InstallSnapshotArgs{
+				Term:              term,
+				LeaderID:          n.cfg.ID,
+				LeaderAddr:        n.cfg.ClientAddr,
+				LastIncludedIndex: n.log[0].Index,
+				LastIncludedTerm:  n.log[0].Term,
+				Data:              n.snapshot,
+			}
+			var reply InstallSnapshotReply
+			n.mu.Unlock()
+			err := n.call(id, "InstallSnapshot", args, &reply)
+			n.mu.Lock()
+			if err != nil {
+				return
+			}
+			if reply.Term > n.term {
+				n.becomeFollowerLocked(reply.Term)
+				return
+			}
+			if n.role != Leader || n.term != term {
+				return
+			}
+			n.matchIndex[id] = max(n.matchIndex[id], args.LastIncludedIndex)
+			n.nextIndex[id] = n.matchIndex[id] + 1
+			continue
+		}
+
+		base := n.log[0].Index
+		end := min(n.lastIndex(), next+uint64(n.cfg.MaxBatch)-1)
+		entries := make([]Entry, end+1-next)
+		copy(entries, n.log[next-base:end-base+1])
+		args := &AppendEntriesArgs{
+			Term:         term,
+			LeaderID:     n.cfg.ID,
+			LeaderAddr:   n.cfg.ClientAddr,
+			PrevLogIndex: next - 1,
+			PrevLogTerm:  n.termAt(next - 1),
+			Entries:      entries,
+			LeaderCommit: n.commitIndex,
+		}
+
+		var reply AppendEntriesReply
+		n.mu.Unlock()
+		err := n.call(id, "AppendEntries", args, &reply)
+		n.mu.Lock()
+		if err != nil {
+			return
+		}
+		if reply.Term > n.term {
+			n.becomeFollowerLocked(reply.Term)
+			return
+		}
+		if n.role != Leader || n.term != term {
+			return
+		}
+
+		if !reply.Success {
+			n.nextIndex[id] = max(1, min(reply.ConflictIndex, next-1))
+			continue
+		}
+		n.matchIndex[id] = max(n.matchIndex[id], args.PrevLogIndex+uint64(len(entries)))
+		n.nextIndex[id] = n.matchIndex[id] + 1
+		n.advanceCommitLocked()
+
+		if n.nextIndex[id] > n.lastIndex() {
+			return
+		}
+	}
+}
+
+// advanceCommitLocked commits the highest entry of the current term stored
+// on a majority.
+func (n *Node) advanceCommitLocked() {
+	for index := n.lastIndex(); index > n.commitIndex && index > n.log[0].Index; index-- {
+		if n.termAt(index) != n.term {
+			return
+		}
+		count := 1
+		for _, match := range n.matchIndex {
+			if match >= index {
+				count++
+			}
+		}
+		if count >= n.quorum() {
+			n.commitIndex = index
+			n.applyCond.Broadcast()
+			return
+		}
+	}
+}
+
+// applyLoop applies committed entries and installed snapshots to the state
+// machine in log order, and compacts the log once it grows too long.
+func (n *Node) applyLoop() {
+	defer n.wg.Done()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for {
+		for !n.stopped && !n.restorePending && n.lastApplied >= n.commitIndex {
+			n.applyCond.Wait()
+		}
+		if n.stopped {
+			return
+		}
+
+		if n.restorePending {
+			n.restorePending, n.snapshotDue = false, false
+			clear(n.applied)
+			data, index := n.snapshot, n.log[0].Index
+			n.fsmBusy = true
+			n.mu.Unlock()
+			err := n.fsm.Restore(data)
+			n.mu.Lock()
+			n.fsmBusy = false
+			n.applyCond.Broadcast()
+			if err != nil {
+				log.Printf("Failed to restore Raft snapshot: %v", err)
+			}
+			n.lastApplied = index
+			for i, p := range n.pending {
+				if i <= index {
+					p.done <- result{err: ErrLeadershipLost}
+					delete(n.pending, i)
+				}
+			}
+			continue
+		}
+
+		index := n.lastApplied + 1
+		entry := n.log[index-n.log[0].Index]
+		var r result
+		if term, ok := n.applied[index]; ok && term == entry.Term {
+			delete(n.applied, index)
+			if observer, ok := n.fsm.(CommitObserver); ok {
+				n.mu.Unlock()
+				observer.Committed(entry.Command)
+				n.mu.Lock()
+			}
+		} else if entry.Command != nil {
+			n.mu.Unlock()
+			r.value, r.err = n.fsm.Apply(entry.Command)
+			n.mu.Lock()
+		}
+
+		if n.restorePending {
+			// A snapshot replaced the log while we were applying.
+			continue
+		}
+		n.lastApplied = index
+		n.applyCond.Broadcast()
+		if p, ok := n.pending[index]; ok {
+			delete(n.pending, index)
+			if p.term != entry.Term {
+				r = result{err: ErrLeadershipLost}
+			}
+			p.done <- r
+		}
+
+		if index-n.log[0].Index >= uint64(n.cfg.SnapshotThreshold) {
+			n.takeSnapshotLocked(index)
+		}
+	}
+}
+
+// takeSnapshotLocked snapshots the state machine, which reflects the log up
+// to index, and discards the log up to there.
+func (n *Node) takeSnapshotLocked(index uint64) {
+	if len(n.applied) > 0 {
+		// The state machine is ahead of index. Hold back ProposeApplied until
+		// the entries it applied are committed, then snapshot.
+		n.snapshotDue = true
+		return
+	}
+	n.snapshotDue = false
+	n.fsmBusy = true
+	n.mu.Unlock()
+	data, err := n.fsm.Snapshot()
+	n.mu.Lock()
+	n.fsmBusy = false
+	n.applyCond.Broadcast()
+	if err != nil {
+		log.Printf("Failed to snapshot Raft state machine: %v", err)
+		return
+	}
+	if n.restorePending || n.lastApplied != index || index <= n.log[0].Index {
+		return
+	}
+
+	n.compactTo(index, n.termAt(index))
+	n.snapshot = data
+	n.persistSnapshotLocked()
+}
@@ -0,0 +1,363 @@
+package raft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// kvMachine is a minimal state machine handling SET key value.
+type kvMachine struct {
+	mu        sync.Mutex
+	data      map[string]string
+	applied   int // Commands passed to Apply
+	committed int // Commands passed to Committed
+}
+
+func newKVMachine() *kvMachine {
+	return &kvMachine{data: make(map[string]string)}
+}
+
+func (m *kvMachine) Apply(command []string) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(command) != 3 || command[0] != "SET" {
+		return nil, fmt.Errorf("unknown command %v", command)
+	}
+	m.data[command[1]] = command[2]
+	m.applied++
+	return "OK", nil
+}
+
+func (m *kvMachine) Committed(command []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.committed++
+}
+
+func (m *kvMachine) Snapshot() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return json.Marshal(m.data)
+}
+
+func (m *kvMachine) Restore(snapshot []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string]string)
+	if len(snapshot) == 0 {
+		return nil
+	}
+	return json.Unmarshal(snapshot, &m.data)
+}
+
+func (m *kvMachine) get(key string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key]
+}
+
+func (m *kvMachine) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data)
+}
+
+type testGroup struct {
+	t     *testing.T
+	addrs map[string]string
+	dirs  map[string]string
+	nodes map[string]*Node
+	fsms  map[string]*kvMachine
+}
+
+// newTestGroup reserves loopback addresses for size nodes without starting
+// them.
+func newTestGroup(t *testing.T, size int, durable bool) *testGroup {
+	t.Helper()
+	g := &testGroup{
+		t:     t,
+		addrs: make(map[string]string),
+		dirs:  make(map[string]string),
+		nodes: make(map[string]*Node),
+		fsms:  make(map[string]*kvMachine),
+	}
+	for i := 1; i <= size; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to reserve address: %v", err)
+		}
+		id := fmt.Sprintf("n%d", i)
+		g.addrs[id] = l.Addr().String()
+		l.Close()
+		if durable {
+			g.dirs[id] = t.TempDir()
+		}
+	}
+	t.Cleanup(func() {
+		for _, n := range g.nodes {
+			n.Stop()
+		}
+	})
+	return g
+}
+
+func (g *testGroup) start(id string, snapshotThreshold int) {
+	g.t.Helper()
+	peers := make(map[string]string)
+	for peer, addr := range g.addrs {
+		if peer != id {
+			peers[peer] = addr
+		}
+	}
+	fsm := newKVMachine()
+	n, err := NewNode(Config{
+		ID:                id,
+		Addr:              g.addrs[id],
+		ClientAddr:        "client-" + id,
+		Peers:             peers,
+		Dir:               g.dirs[id],
+		HeartbeatInterval: 20 * time.Millisecond,
+		ElectionTimeout:   150 * time.Millisecond,
+		SnapshotThreshold: snapshotThreshold,
+	}, fsm)
+	if err != nil {
+		g.t.Fatalf("NewNode(%s) error = %v", id, err)
+	}
+	if err := n.Start(); err != nil {
+		g.t.Fatalf("Start(%s) error = %v", id, err)
+	}
+	g.nodes[id] = n
+	g.fsms[id] = fsm
+}
+
+func (g *testGroup) stop(id string) {
+	g.nodes[id].Stop()
+	delete(g.nodes, id)
+}
+
+// leader waits until exactly one running node leads and returns its ID.
+func (g *testGroup) leader() string {
+	g.t.Helper()
+	var id string
+	eventually(g.t, "a leader to be elected", func() bool {
+		id = ""
+		for nodeID, n := range g.nodes {
+			if n.Status().Role == Leader {
+				if id != "" {
+					return false
+				}
+				id = nodeID
+			}
+		}
+		return id != ""
+	})
+	return id
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestNode_ReplicatesToFollowers(t *testing.T) {
+	g := newTestGroup(t, 3, false)
+	for id := range g.addrs {
+		g.start(id, 0)
+	}
+	leader := g.leader()
+
+	for i := 0; i < 20; i++ {
+		reply, err := g.nodes[leader].Propose([]string{"SET", fmt.Sprintf("k%d", i), "v"})
+		if err != nil || reply != "OK" {
+			t.Fatalf("Propose() = %v, %v, want OK", reply, err)
+		}
+	}
+
+	for id, fsm := range g.fsms {
+		eventually(t, id+" to apply every entry", func() bool { return fsm.len() == 20 })
+	}
+
+	for id, n := range g.nodes {
+		if id == leader {
+			continue
+		}
+		_, err := n.Propose([]string{"SET", "x", "y"})
+		var notLeader *NotLeaderError
+		if !errors.As(err, &notLeader) || !errors.Is(err, ErrNotLeader) {
+			t.Fatalf("Propose() on follower error = %v, want NotLeaderError", err)
+		}
+		if notLeader.LeaderID != leader || notLeader.LeaderAddr != "client-"+leader {
+			t.Errorf("NotLeaderError = %+v, want leader %s", notLeader, leader)
+		}
+	}
+}
+
+func TestNode_ProposeApplied(t *testing.T) {
+	g := newTestGroup(t, 3, false)
+	for id := range g.addrs {
+		g.start(id, 5)
+	}
+	leader := g.leader()
+	fsm := g.fsms[leader]
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("k%d", i)
+		err := g.nodes[leader].ProposeApplied(func() ([][]string, error) {
+			fsm.mu.Lock()
+			defer fsm.mu.Unlock()
+			fsm.data[key] = "v"
+			return [][]string{{"SET", key, "v"}}, nil
+		})
+		if err != nil {
+			t.Fatalf("ProposeApplied() error = %v", err)
+		}
+	}
+
+	last := g.nodes[leader].Status().LastIndex
+	err := g.nodes[leader].ProposeApplied(func() ([][]string, error) { return nil, nil })
+	if err != nil || g.nodes[leader].Status().LastIndex != last {
+		t.Errorf("ProposeApplied() without commands = %v, appended an entry", err)
+	}
+
+	for id, fsm := range g.fsms {
+		eventually(t, id+" to apply every entry", func() bool { return fsm.len() == 20 })
+	}
+	eventually(t, "the leader to snapshot", func() bool { return g.nodes[leader].Status().SnapshotIndex > 0 })
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	if fsm.applied != 0 || fsm.committed != 20 {
+		t.Errorf("leader applied %d entries it had applied ahead of the log and saw %d committed, want 0 and 20", fsm.applied, fsm.committed)
+	}
+}
+
+func TestNode_LeaderFailover(t *testing.T) {
+	g := newTestGroup(t, 3, false)
+	for id := range g.addrs {
+		g.start(id, 0)
+	}
+	first := g.leader()
+	if _, err := g.nodes[first].Propose([]string{"SET", "a", "1"}); err != nil {
+		t.Fatalf("Propose() error = %v", err)
+	}
+
+	g.stop(first)
+	second := g.leader()
+	if second == first {
+		t.Fatalf("stopped leader %s still leads", first)
+	}
+	if got := g.fsms[second].get("a"); got != "1" {
+		t.Errorf("new leader lost committed entry, a = %q", got)
+	}
+	if _, err := g.nodes[second].Propose([]string{"SET", "b", "2"}); err != nil {
+		t.Fatalf("Propose() on new leader error = %v", err)
+	}
+
+	// The old leader rejoins as a follower and catches up.
+	g.start(first, 0)
+	eventually(t, "the old leader to catch up", func() bool { return g.fsms[first].get("b") == "2" })
+	if role := g.nodes[first].Status().Role; role == Leader && g.nodes[second].Status().Role == Leader {
+		t.Error("two leaders after the old leader rejoined")
+	}
+}
+
+func TestNode_NoQuorum(t *testing.T) {
+	g := newTestGroup(t, 3, false)
+	for id := range g.addrs {
+		g.start(id, 0)
+	}
+	leader := g.leader()
+	for id := range g.addrs {
+		if id != leader {
+			g.stop(id)
+		}
+	}
+
+	n := g.nodes[leader]
+	n.cfg.ProposeTimeout = 300 * time.Millisecond
+	_, err := n.Propose([]string{"SET", "a", "1"})
+	if err == nil {
+		t.Fatal("Propose() without a majority succeeded")
+	}
+	if got := g.fsms[leader].get("a"); got != "" {
+		t.Errorf("uncommitted entry applied, a = %q", got)
+	}
+}
+
+func TestNode_SnapshotCatchUp(t *testing.T) {
+	g := newTestGroup(t, 3, false)
+	g.start("n1", 10)
+	g.start("n2", 10)
+	leader := g.leader()
+
+	for i := 0; i < 50; i++ {
+		if _, err := g.nodes[leader].Propose([]string{"SET", fmt.Sprintf("k%d", i), "v"}); err != nil {
+			t.Fatalf("Propose() error = %v", err)
+		}
+	}
+	eventually(t, "the log to be compacted", func() bool {
+		return g.nodes[leader].Status().SnapshotIndex > 0
+	})
+
+	// n3 joins after its entries were compacted away and gets the snapshot.
+	g.start("n3", 10)
+	eventually(t, "n3 to catch up", func() bool { return g.fsms["n3"].len() == 50 })
+	if status := g.nodes["n3"].Status(); status.SnapshotIndex == 0 {
+		t.Errorf("n3 caught up without installing a snapshot: %+v", status)
+	}
+}
+
+func TestNode_Restart(t *testing.T) {
+	g := newTestGroup(t, 3, true)
+	for id := range g.addrs {
+		g.start(id, 5)
+	}
+	leader := g.leader()
+	for i := 0; i < 12; i++ {
+		if _, err := g.nodes[leader].Propose([]string{"SET", fmt.Sprintf("k%d", i), "v"}); err != nil {
+			t.Fatalf("Propose() error = %v", err)
+		}
+	}
+	for id, fsm := range g.fsms {
+		eventually(t, id+" to apply every entry", func() bool { return fsm.len() == 12 })
+	}
+
+	term := g.nodes[leader].Status().Term
+	for id := range g.addrs {
+		g.stop(id)
+	}
+	for id := range g.addrs {
+		g.start(id, 5)
+	}
+
+	for id, fsm := range g.fsms {
+		eventually(t, id+" to recover its state", func() bool { return fsm.len() == 12 })
+	}
+	leader = g.leader()
+	if status := g.nodes[leader].Status(); status.Term <= term {
+		t.Errorf("term after restart = %d, want > %d", status.Term, term)
+	}
+	if reply, err := g.nodes[leader].Propose([]string{"SET", "after", "restart"}); err != nil || reply != "OK" {
+		t.Fatalf("Propose() after restart = %v, %v", reply, err)
+	}
+}
+
+func TestRole_String(t *testing.T) {
+	for role, want := range map[Role]string{Follower: "follower", Candidate: "candidate", Leader: "leader"} {
+		if got := role.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package raft
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"time"
+)
+
+var errRPCTimeout = errors.New("Raft RPC timed out")
+
+// RequestVoteArgs is sent by candidates to gather votes.
+type RequestVoteArgs struct {
+	Term         uint64
+	CandidateID  string
+	LastLogIndex uint64
+	LastLogTerm  uint64
+}
+
+type RequestVoteReply struct {
+	Term        uint64
+	VoteGranted bool
+}
+
+// AppendEntriesArgs is sent by the leader to replicate entries and as a
+// heartbeat. LeaderAddr is the leader's client address, so followers can
+// redirect clients to it.
+type AppendEntriesArgs struct {
+	Term         uint64
+	LeaderID     string
+	LeaderAddr   string
+	PrevLogIndex uint64
+	PrevLogTerm  uint64
+	Entries      []Entry
+	LeaderCommit uint64
+}
+
+// AppendEntriesReply reports whether the follower's log matched. On a
+// mismatch ConflictIndex is the first index the leader should retry from.
+type AppendEntriesReply struct {
+	Term          uint64
+	Success       bool
+	ConflictIndex uint64
+}
+
+// InstallSnapshotArgs is sent by the leader to followers whose next entry
+// has already been compacted away.
+type InstallSnapshotArgs struct {
+	Term              uint64
+	LeaderID          string
+	LeaderAddr        string
+	LastIncludedIndex uint64
+	LastIncludedTerm  uint64
+	Data              []byte
+}
+
+type InstallSnapshotReply struct {
+	Term uint64
+}
+
+// rpcService exposes the RPC handlers of a node to net/rpc.
+type rpcService struct {
+	n *Node
+}
+
+func (s *rpcService) RequestVote(args *RequestVoteArgs, reply *RequestVoteReply) error {
+	s.n.handleRequestVote(args, reply)
+	return nil
+}
+
+func (s *rpcService) AppendEntries(args *AppendEntriesArgs, reply *AppendEntriesReply) error {
+	s.n.handleAppendEntries(args, reply)
+	return nil
+}
+
+func (s *rpcService) InstallSnapshot(args *InstallSnapshotArgs, reply *InstallSnapshotReply) error {
+	s.n.handleInstallSnapshot(args, reply)
+	return nil
+}
+
+func (n *Node) handleRequestVote(args *RequestVoteArgs, reply *RequestVoteReply) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if args.Term > n.term {
+		n.becomeFollowerLocked(args.Term)
+	}
+	reply.Term = n.term
+	if args.Term < n.term {
+		return
+	}
+
+	upToDate := args.LastLogTerm > n.lastTerm() ||
+		(args.LastLogTerm == n.lastTerm() && args.LastLogIndex >= n.lastIndex())
+	if (n.votedFor == "" || n.votedFor == args.CandidateID) && upToDate {
+		n.votedFor = args.CandidateID
+		n.persistLocked()
+		n.resetElectionTimerLocked()
+		reply.VoteGranted = true
+	}
+}
+
+func (n *Node) handleAppendEntries(args *AppendEntriesArgs, reply *AppendEntriesReply) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	reply.Term = n.term
+	if args.Term < n.term {
+		return
+	}
+	if args.Term > n.term || n.role != Follower {
+		n.becomeFollowerLocked(args.Term)
+		reply.Term = n.term
+	}
+	n.leaderID, n.leaderAddr = args.LeaderID, args.LeaderAddr
+	n.resetElectionTimerLocked()
+
+	base := n.log[0].Index
+	if args.PrevLogIndex > n.lastIndex() {
+		reply.ConflictIndex = n.lastIndex() + 1
+		return
+	}
+	if args.PrevLogIndex < base {
+		// The snapshot already covers part of the entries; they are committed
+		// and match, so skip them.
+		skip := base - args.PrevLogIndex
+		if skip > uint64(len(args.Entries)) {
+			reply.Success = true
+			return
+		}
+		args.Entries = args.Entries[skip:]
+		args.PrevLogIndex, args.PrevLogTerm = base, n.log[0].Term
+	}
+	if term := n.termAt(args.PrevLogIndex); term != args.PrevLogTerm {
+		// Skip back over the whole conflicting term at once.
+		index := args.PrevLogIndex
+		for index > base+1 && n.termAt(index-1) == term {
+			index--
+		}
+		reply.ConflictIndex = index
+		return
+	}
+
+	changed := false
+	for i, entry := range args.Entries {
+		if entry.Index <= n.lastIndex() {
+			if n.termAt(entry.Index) == entry.Term {
+				continue
+			}
+			n.log = n.log[:entry.Index-base]
+			n.discardAppliedLocked(entry.Index)
+		}
+		n.log = append(n.log, args.Entries[i:]...)
+		changed = true
+		break
+	}
+	if changed {
+		n.persistLocked()
+	}
+
+	if args.LeaderCommit > n.commitIndex {
+		last := args.PrevLogIndex + uint64(len(args.Entries))
+		n.commitIndex = min(args.LeaderCommit, last)
+		n.applyCond.Broadcast()
+	}
+	reply.Success = true
+}
+
+func (n *Node) handleInstallSnapshot(args *InstallSnapshotArgs, reply *InstallSnapshotReply) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	reply.Term = n.term
+	if args.Term < n.term {
+		return
+	}
+	if args.Term > n.term || n.role != Follower {
+		n.becomeFollowerLocked(args.Term)
+		reply.Term = n.term
+	}
+	n.leaderID, n.leaderAddr = args.LeaderID, args.LeaderAddr
+	n.resetElectionTimerLocked()
+
+	if args.LastIncludedIndex <= n.commitIndex {
+		return
+	}
+
+	n.compactTo(args.LastIncludedIndex, args.LastIncludedTerm)
+	n.snapshot = args.Data
+	n.commitIndex = args.LastIncludedIndex
+	n.restorePending = true
+	n.persistSnapshotLocked()
+	n.applyCond.Broadcast()
+}
+
+func (n *Node) acceptLoop() {
+	defer n.wg.Done()
+
+	for {
+		conn, err := n.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		n.connsMu.Lock()
+		n.conns[conn] = struct{}{}
+		n.connsMu.Unlock()
+
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.rpcServer.ServeConn(conn)
+			n.connsMu.Lock()
+			delete(n.conns, conn)
+			n.connsMu.Unlock()
+		}()
+	}
+}
+
+// call invokes an RPC on a peer, giving up after an election timeout so a
+// dead peer cannot stall replication to the others.
+func (n *Node) call(id, method string, args, reply interface{}) error {
+	client, err := n.client(id)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(n.cfg.ElectionTimeout)
+	defer timer.Stop()
+
+	call := client.Go("Raft."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			n.dropClient(id, client)
+		}
+		return call.Error
+	case <-timer.C:
+		n.dropClient(id, client)
+		return errRPCTimeout
+	case <-n.quit:
+		return ErrStopped
+	}
+}
+
+// client returns the RPC connection to a peer, dialing it if needed.
+func (n *Node) client(id string) (*rpc.Client, error) {
+	n.connsMu.Lock()
+	client, ok := n.clients[id]
+	n.connsMu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	conn, err := net.DialTimeout("tcp", n.cfg.Peers[id], n.cfg.ElectionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client = rpc.NewClient(conn)
+
+	n.connsMu.Lock()
+	defer n.connsMu.Unlock()
+	select {
+	case <-n.quit:
+		client.Close()
+		return nil, ErrStopped
+	default:
+	}
+	if existing, ok := n.clients[id]; ok {
+		client.Close()
+		return existing, nil
+	}
+	n.clients[id] = client
+	return client, nil
+}
+
+func (n *Node) dropClient(id string, client *rpc.Client) {
+	n.connsMu.Lock()
+	defer n.connsMu.Unlock()
+
+	if n.clients[id] == client {
+		delete(n.clients, id)
+	}
+	client.Close()
+}
@@ -0,0 +1,100 @@
+package raft
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// persistentState is the state a node must not forget across restarts.
+type persistentState struct {
+	Term     uint64
+	VotedFor string
+	Log      []Entry
+}
+
+// snapshotFile is a state machine snapshot and the log position it covers.
+type snapshotFile struct {
+	Index uint64
+	Term  uint64
+	Data  []byte
+}
+
+// storage keeps the durable state of a node in two files, each replaced
+// atomically on every save.
+type storage struct {
+	statePath    string
+	snapshotPath string
+}
+
+func newStorage(dir, id string) *storage {
+	return &storage{
+		statePath:    filepath.Join(dir, fmt.Sprintf("raft-%s.state", id)),
+		snapshotPath: filepath.Join(dir, fmt.Sprintf("raft-%s.snapshot", id)),
+	}
+}
+
+// load reads the saved state and snapshot. Missing files yield the zero
+// state and a nil snapshot.
+func (s *storage) load() (persistentState, *snapshotFile, error) {
+	var state persistentState
+	if err := readGob(s.statePath, &state); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return state, nil, fmt.Errorf("failed to load Raft state: %w", err)
+	}
+
+	var snap snapshotFile
+	if err := readGob(s.snapshotPath, &snap); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil, nil
+		}
+		return state, nil, fmt.Errorf("failed to load Raft snapshot: %w", err)
+	}
+	return state, &snap, nil
+}
+
+func (s *storage) saveState(state persistentState) error {
+	return writeGob(s.statePath, state)
+}
+
+func (s *storage) saveSnapshot(snap snapshotFile) error {
+	return writeGob(s.snapshotPath, snap)
+}
+
+func readGob(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// writeGob encodes v to a temporary file, syncs it and renames it over path,
+// so a crash leaves either the old or the new contents.
+func writeGob(path string, v interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hardikphalet/go-redis/internal/cluster"
 	"github.com/hardikphalet/go-redis/internal/commands"
 	"github.com/hardikphalet/go-redis/internal/commands/options"
 	"github.com/hardikphalet/go-redis/internal/types"
@@ -16,6 +18,9 @@ import (
 
 var (
 	ErrInvalidSyntax = errors.New("invalid RESP syntax")
+	// ErrNullArgument is returned for a null bulk string ("$-1") in a
+	// command, which unlike an empty string is not a valid argument.
+	ErrNullArgument = errors.New("Protocol error: invalid bulk length")
 )
 
 type Parser struct {
@@ -49,8 +54,57 @@ func (p *Parser) Parse() (commands.Command, error) {
 	}
 }
 
+// ReadArgs reads a single RESP array of bulk strings without turning it into
+// a command, for callers that need the raw arguments.
+func (p *Parser) ReadArgs() ([][]byte, error) {
+	firstByte, err := p.reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if firstByte != '*' {
+		return nil, ErrInvalidSyntax
+	}
+	return p.readArrayElements()
+}
+
+// NewCommand builds the command for args, exactly as Parse does for a
+// command read from the wire.
+func NewCommand(args [][]byte) (commands.Command, error) {
+	return (&Parser{}).createCommand(args)
+}
+
+// StringArgs returns args as strings, the form in which commands are
+// propagated.
+func StringArgs(args [][]byte) []string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = string(arg)
+	}
+	return strs
+}
+
+// ByteArgs returns args as NewCommand takes them.
+func ByteArgs(args []string) [][]byte {
+	raw := make([][]byte, len(args))
+	for i, arg := range args {
+		raw[i] = []byte(arg)
+	}
+	return raw
+}
+
 // parseArray parses a RESP array
 func (p *Parser) parseArray() (commands.Command, error) {
+	elements, err := p.readArrayElements()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.createCommand(elements)
+}
+
+// readArrayElements reads the length and bulk string elements of an array
+// whose '*' prefix has already been consumed
+func (p *Parser) readArrayElements() ([][]byte, error) {
 	length, err := p.readInteger()
 	if err != nil {
 		return nil, err
@@ -60,16 +114,19 @@ func (p *Parser) parseArray() (commands.Command, error) {
 		return nil, fmt.Errorf("array length must be positive")
 	}
 
-	elements := make([]string, length)
+	elements := make([][]byte, length)
 	for i := 0; i < length; i++ {
 		element, err := p.readBulkString()
 		if err != nil {
 			return nil, err
 		}
+		if element == nil {
+			return nil, ErrNullArgument
+		}
 		elements[i] = element
 	}
 
-	return p.createCommand(elements)
+	return elements, nil
 }
 
 // readInteger reads a RESP integer
@@ -87,36 +144,40 @@ func (p *Parser) readInteger() (int, error) {
 	return n, nil
 }
 
-// readBulkString reads a RESP bulk string
-func (p *Parser) readBulkString() (string, error) {
+// readBulkString reads a RESP bulk string, returning nil for a null bulk
+// string and an empty, non-nil slice for an empty one
+func (p *Parser) readBulkString() ([]byte, error) {
 	b, err := p.reader.ReadByte()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if b != '$' {
-		return "", ErrInvalidSyntax
+		return nil, ErrInvalidSyntax
 	}
 
 	length, err := p.readInteger()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if length < 0 {
-		return "", nil // Null bulk string
+	if length == -1 {
+		return nil, nil // Null bulk string
+	}
+	if length < -1 {
+		return nil, ErrInvalidSyntax
 	}
 
 	data := make([]byte, length)
 	_, err = io.ReadFull(p.reader, data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := p.readCRLF(); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(data), nil
+	return data, nil
 }
 
 // readLine reads a line ending in CRLF
@@ -154,11 +215,387 @@ func (p *Parser) readCRLF() error {
 	return nil
 }
 
-// createCommand converts string array to a specific command
-func (p *Parser) createCommand(args []string) (commands.Command, error) {
+// parseFieldsArg parses the "FIELDS numfields field ..." block that ends
+// the hash field expiry commands, where each field takes width arguments.
+func parseFieldsArg(args []string, width int) ([]string, error) {
+	if len(args) < 2 || strings.ToUpper(args[0]) != "FIELDS" {
+		return nil, fmt.Errorf("mandatory argument FIELDS is missing or not at the right position")
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("number of fields must be a positive integer")
+	}
+	if len(args)-2 != n*width {
+		return nil, fmt.Errorf("the numfields parameter must match the number of arguments")
+	}
+	return args[2:], nil
+}
+
+// parseTrimArgs parses the "MAXLEN|MINID [=|~] threshold [LIMIT count]"
+// trimming arguments of XADD and XTRIM at the start of args. It returns the
+// options and the number of arguments consumed.
+func parseTrimArgs(args []string) (*options.XTrimOptions, int, error) {
+	opts := &options.XTrimOptions{Strategy: strings.ToUpper(args[0])}
+	i := 1
+	if i < len(args) && (args[i] == "=" || args[i] == "~") {
+		opts.Approx = args[i] == "~"
+		i++
+	}
+	if i >= len(args) {
+		return nil, 0, fmt.Errorf("syntax error")
+	}
+	if opts.Strategy == "MAXLEN" {
+		maxLen, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("value is not an integer or out of range")
+		}
+		if maxLen < 0 {
+			return nil, 0, fmt.Errorf("The MAXLEN argument must be >= 0.")
+		}
+		opts.MaxLen = maxLen
+	} else {
+		minID, err := types.ParseStreamID(args[i], 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts.MinID = minID
+	}
+	i++
+	if i+1 < len(args) && strings.ToUpper(args[i]) == "LIMIT" {
+		limit, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || limit < 0 {
+			return nil, 0, fmt.Errorf("The LIMIT argument must be >= 0.")
+		}
+		if !opts.Approx {
+			return nil, 0, fmt.Errorf("syntax error, LIMIT cannot be used without the special ~ option")
+		}
+		opts.Limit = limit
+		i += 2
+	}
+	return opts, i, nil
+}
+
+// parseRangeID parses an XRANGE or XPENDING interval bound: "-" and "+" are
+// the smallest and greatest IDs, a "(" prefix excludes the ID itself, and an
+// ID without a sequence number covers the whole millisecond.
+func parseRangeID(s string, isStart bool) (types.StreamID, error) {
+	switch s {
+	case "-":
+		return types.StreamID{}, nil
+	case "+":
+		return types.MaxStreamID, nil
+	}
+
+	missingSeq := uint64(math.MaxUint64)
+	if isStart {
+		missingSeq = 0
+	}
+	exclusive := strings.HasPrefix(s, "(")
+	id, err := types.ParseStreamID(strings.TrimPrefix(s, "("), missingSeq)
+	if err != nil || !exclusive {
+		return id, err
+	}
+
+	if isStart {
+		next, ok := id.Next()
+		if !ok {
+			return types.StreamID{}, fmt.Errorf("invalid start ID for the interval")
+		}
+		return next, nil
+	}
+	prev, ok := id.Prev()
+	if !ok {
+		return types.StreamID{}, fmt.Errorf("invalid end ID for the interval")
+	}
+	return prev, nil
+}
+
+// parseStreamsArg splits the arguments after the STREAMS keyword of XREAD
+// and XREADGROUP into keys and the IDs that go with them.
+func parseStreamsArg(cmd string, args []string) (keys, ids []string, err error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return nil, nil, fmt.Errorf("Unbalanced '%s' list of streams: for each stream key an ID must be specified.", strings.ToLower(cmd))
+	}
+	n := len(args) / 2
+	return args[:n], args[n:], nil
+}
+
+// parseGeoSearchArgs parses the arguments of GEOSEARCH after the key, or of
+// GEOSEARCHSTORE after the source key, where STOREDIST takes the place of
+// the WITH options. Distances are converted to meters.
+func parseGeoSearchArgs(args []string, isStore bool) (search *commands.GeoSearchCommand, storeDist bool, err error) {
+	opts := &options.GeoSearchOptions{}
+	search = &commands.GeoSearchCommand{Options: opts}
+	hasFrom, hasBy := false, false
+	parseFloats := func(vals []string) ([]float64, error) {
+		floats := make([]float64, len(vals))
+		for i, v := range vals {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value is not a valid float")
+			}
+			floats[i] = f
+		}
+		return floats, nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch {
+		case opt == "FROMMEMBER" && i+1 < len(args):
+			if hasFrom {
+				return nil, false, fmt.Errorf("exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+			}
+			opts.FromMember, hasFrom = args[i+1], true
+			i++
+		case opt == "FROMLONLAT" && i+2 < len(args):
+			if hasFrom {
+				return nil, false, fmt.Errorf("exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+			}
+			coords, err := parseFloats(args[i+1 : i+3])
+			if err != nil {
+				return nil, false, err
+			}
+			opts.FromLonLat, opts.Longitude, opts.Latitude, hasFrom = true, coords[0], coords[1], true
+			i += 2
+		case (opt == "BYRADIUS" && i+2 < len(args)) || (opt == "BYBOX" && i+3 < len(args)):
+			if hasBy {
+				return nil, false, fmt.Errorf("exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH")
+			}
+			n := 1
+			if opt == "BYBOX" {
+				n = 2
+			}
+			dims, err := parseFloats(args[i+1 : i+1+n])
+			if err != nil {
+				return nil, false, err
+			}
+			for _, d := range dims {
+				if d < 0 {
+					return nil, false, fmt.Errorf("radius cannot be negative")
+				}
+			}
+			unit, err := options.GeoUnitFactor(args[i+1+n])
+			if err != nil {
+				return nil, false, err
+			}
+			search.Unit = unit
+			if opt == "BYBOX" {
+				opts.ByBox, opts.Width, opts.Height = true, dims[0]*unit, dims[1]*unit
+			} else {
+				opts.Radius = dims[0] * unit
+			}
+			hasBy = true
+			i += n + 1
+		case opt == "ASC" || opt == "DESC":
+			opts.Sort = opt
+		case opt == "COUNT" && i+1 < len(args):
+			count, err := strconv.Atoi(args[i+1])
+			if err != nil || count <= 0 {
+				return nil, false, fmt.Errorf("COUNT must be > 0")
+			}
+			opts.Count = count
+			i++
+			if i+1 < len(args) && strings.ToUpper(args[i+1]) == "ANY" {
+				opts.Any = true
+				i++
+			}
+		case opt == "WITHCOORD" && !isStore:
+			search.WithCoord = true
+		case opt == "WITHDIST" && !isStore:
+			search.WithDist = true
+		case opt == "WITHHASH" && !isStore:
+			search.WithHash = true
+		case opt == "STOREDIST" && isStore:
+			storeDist = true
+		case opt == "ANY":
+			return nil, false, fmt.Errorf("the ANY argument requires COUNT argument")
+		default:
+			return nil, false, fmt.Errorf("syntax error")
+		}
+	}
+
+	if !hasFrom {
+		return nil, false, fmt.Errorf("exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+	}
+	if !hasBy {
+		return nil, false, fmt.Errorf("exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH")
+	}
+	return search, storeDist, nil
+}
+
+// parseBitRange parses the "[start [end [BYTE|BIT]]]" range of BITCOUNT and
+// BITPOS, returning nil when it is absent. BITCOUNT, with needEnd set,
+// takes both ends or neither.
+func parseBitRange(args []string, needEnd bool) (*options.BitRange, error) {
 	if len(args) == 0 {
+		return nil, nil
+	}
+	if len(args) > 3 || (needEnd && len(args) == 1) {
+		return nil, fmt.Errorf("syntax error")
+	}
+
+	r := &options.BitRange{}
+	var err error
+	if r.Start, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("value is not an integer or out of range")
+	}
+	if len(args) > 1 {
+		if r.End, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		r.HasEnd = true
+	}
+	if len(args) == 3 {
+		switch strings.ToUpper(args[2]) {
+		case "BYTE":
+		case "BIT":
+			r.Bit = true
+		default:
+			return nil, fmt.Errorf("syntax error")
+		}
+	}
+	return r, nil
+}
+
+// parseBitFieldOps parses the operations of BITFIELD after the key, setting
+// on each SET and INCRBY the overflow behavior of the OVERFLOW before it.
+// BITFIELD_RO, with readOnly set, only takes GET.
+func parseBitFieldOps(args []string, readOnly bool) ([]options.BitFieldOp, error) {
+	var ops []options.BitFieldOp
+	overflow := "WRAP"
+	for i := 0; i < len(args); {
+		name := strings.ToUpper(args[i])
+		n := 3
+		switch {
+		case name == "GET" && i+2 < len(args):
+		case (name == "SET" || name == "INCRBY") && i+3 < len(args):
+			n = 4
+		case name == "OVERFLOW" && i+1 < len(args):
+			n = 2
+		default:
+			return nil, fmt.Errorf("syntax error")
+		}
+		if readOnly && name != "GET" {
+			return nil, fmt.Errorf("BITFIELD_RO only supports the GET subcommand")
+		}
+
+		if name == "OVERFLOW" {
+			overflow = strings.ToUpper(args[i+1])
+			if overflow != "WRAP" && overflow != "SAT" && overflow != "FAIL" {
+				return nil, fmt.Errorf("Invalid OVERFLOW type specified")
+			}
+			i += n
+			continue
+		}
+
+		op := options.BitFieldOp{Op: name, Overflow: overflow}
+		var err error
+		if op.Signed, op.Bits, err = options.ParseBitFieldType(args[i+1]); err != nil {
+			return nil, err
+		}
+		if op.Offset, err = options.ParseBitFieldOffset(args[i+2], op.Bits); err != nil {
+			return nil, err
+		}
+		if n == 4 {
+			if op.Value, err = strconv.ParseInt(args[i+3], 10, 64); err != nil {
+				return nil, fmt.Errorf("value is not an integer or out of range")
+			}
+		}
+		ops = append(ops, op)
+		i += n
+	}
+	return ops, nil
+}
+
+// parseZRangeOptions parses the options that follow the range of ZRANGE or,
+// without BYSCORE, BYLEX and REV, of ZRANGEBYSCORE, ZRANGEBYLEX and their
+// REV forms.
+func parseZRangeOptions(args []string, opts *options.ZRangeOptions, zrange bool) error {
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch {
+		case zrange && (opt == "BYSCORE" || opt == "BYLEX"):
+			if err := opts.SetRangeType(opt); err != nil {
+				return fmt.Errorf("invalid range type: %s", err)
+			}
+		case zrange && opt == "REV":
+			opts.Rev = true
+		case opt == "WITHSCORES":
+			opts.WithScores = true
+		case opt == "LIMIT":
+			if i+2 >= len(args) {
+				return fmt.Errorf("LIMIT option requires offset and count")
+			}
+			offset, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return fmt.Errorf("invalid LIMIT offset")
+			}
+			count, err := strconv.Atoi(args[i+2])
+			if err != nil {
+				return fmt.Errorf("invalid LIMIT count")
+			}
+			if err := opts.SetLimit(offset, count); err != nil {
+				return fmt.Errorf("invalid LIMIT parameters: %s", err)
+			}
+			i += 2
+		default:
+			return fmt.Errorf("unknown option: %s", opt)
+		}
+	}
+	return nil
+}
+
+// newZRangeCommand parses the start and stop of a ZRANGE as indexes, score
+// bounds or lex bounds according to opts. Ranges by score or lex are given
+// maximum first with REV, so they are swapped into minimum and maximum.
+func newZRangeCommand(key, start, stop string, opts *options.ZRangeOptions) (commands.Command, error) {
+	cmd := &commands.ZRangeCommand{Key: key, Options: opts}
+	if opts.HasLimit && !opts.IsByScore() && !opts.IsByLex() {
+		return nil, fmt.Errorf("syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+	}
+	if opts.Rev && (opts.IsByScore() || opts.IsByLex()) {
+		start, stop = stop, start
+	}
+
+	var err error
+	switch {
+	case opts.IsByScore():
+		if cmd.Start, err = options.ParseScoreBound(start); err != nil {
+			return nil, err
+		}
+		if cmd.Stop, err = options.ParseScoreBound(stop); err != nil {
+			return nil, err
+		}
+	case opts.IsByLex():
+		if opts.WithScores {
+			return nil, fmt.Errorf("syntax error, WITHSCORES not supported in combination with BYLEX")
+		}
+		if cmd.Start, err = options.ParseLexBound(start); err != nil {
+			return nil, err
+		}
+		if cmd.Stop, err = options.ParseLexBound(stop); err != nil {
+			return nil, err
+		}
+	default:
+		if cmd.Start, err = strconv.Atoi(start); err != nil {
+			return nil, fmt.Errorf("invalid start index")
+		}
+		if cmd.Stop, err = strconv.Atoi(stop); err != nil {
+			return nil, fmt.Errorf("invalid stop index")
+		}
+	}
+	return cmd, nil
+}
+
+// createCommand converts the arguments of a command to a specific command.
+// Names and options are parsed from args as strings, while string values
+// are taken from raw as read, so they reach the store without a copy.
+func (p *Parser) createCommand(raw [][]byte) (commands.Command, error) {
+	if len(raw) == 0 {
 		return nil, fmt.Errorf("empty command")
 	}
+	args := StringArgs(raw)
 
 	cmd := strings.ToUpper(args[0])
 
@@ -198,7 +635,7 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 						return nil, fmt.Errorf("invalid value for option %s: %s", opt, err)
 					}
 					if err := opts.SetExpiry(opt, val); err != nil {
-						return nil, fmt.Errorf("invalid option: %s", err)
+						return nil, fmt.Errorf("invalid expire time in 'set' command")
 					}
 					i += 2
 				}
@@ -207,7 +644,7 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 			}
 		}
 
-		return commands.NewSetCommand(args[1], args[2], opts), nil
+		return commands.NewSetCommand(args[1], raw[2], opts), nil
 
 	case "GET":
 		if len(args) != 2 {
@@ -217,6 +654,187 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 			Key: args[1],
 		}, nil
 
+	case "INCR", "DECR":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s command requires exactly 1 argument", cmd)
+		}
+		delta := int64(1)
+		if cmd == "DECR" {
+			delta = -1
+		}
+		return &commands.IncrByCommand{Key: args[1], Delta: delta}, nil
+
+	case "INCRBY", "DECRBY":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("%s command requires exactly 2 arguments", cmd)
+		}
+		delta, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		if cmd == "DECRBY" {
+			if delta == math.MinInt64 {
+				return nil, fmt.Errorf("decrement would overflow")
+			}
+			delta = -delta
+		}
+		return &commands.IncrByCommand{Key: args[1], Delta: delta}, nil
+
+	case "INCRBYFLOAT":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("INCRBYFLOAT command requires exactly 2 arguments")
+		}
+		delta, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+			return nil, fmt.Errorf("value is not a valid float")
+		}
+		return &commands.IncrByFloatCommand{Key: args[1], Delta: delta}, nil
+
+	case "SETNX":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("SETNX command requires exactly 2 arguments")
+		}
+		return &commands.MSetCommand{Pairs: []types.KeyValue{{Key: args[1], Value: raw[2]}}, NX: true}, nil
+
+	case "SETEX", "PSETEX":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("%s command requires exactly 3 arguments", cmd)
+		}
+		ttl, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		opts := options.NewSetOptions()
+		unit := "EX"
+		if cmd == "PSETEX" {
+			unit = "PX"
+		}
+		if err := opts.SetExpiry(unit, ttl); err != nil {
+			return nil, fmt.Errorf("invalid expire time in '%s' command", strings.ToLower(cmd))
+		}
+		return commands.NewSetCommand(args[1], raw[3], opts), nil
+
+	case "MGET":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("MGET command requires at least 1 argument")
+		}
+		return &commands.MGetCommand{Keys: args[1:]}, nil
+
+	case "MSET", "MSETNX":
+		if len(args) < 3 || len(args)%2 != 1 {
+			return nil, fmt.Errorf("wrong number of arguments for '%s' command", strings.ToLower(cmd))
+		}
+		pairs := make([]types.KeyValue, 0, len(args)/2)
+		for i := 1; i < len(args); i += 2 {
+			pairs = append(pairs, types.KeyValue{Key: args[i], Value: raw[i+1]})
+		}
+		return &commands.MSetCommand{Pairs: pairs, NX: cmd == "MSETNX"}, nil
+
+	case "APPEND":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("APPEND command requires exactly 2 arguments")
+		}
+		return &commands.AppendCommand{Key: args[1], Value: raw[2]}, nil
+
+	case "STRLEN":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("STRLEN command requires exactly 1 argument")
+		}
+		return &commands.StrLenCommand{Key: args[1]}, nil
+
+	case "GETRANGE", "SUBSTR":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("%s command requires exactly 3 arguments", cmd)
+		}
+		start, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		end, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		return &commands.GetRangeCommand{Key: args[1], Start: start, End: end}, nil
+
+	case "SETRANGE":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("SETRANGE command requires exactly 3 arguments")
+		}
+		offset, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("offset is out of range")
+		}
+		return &commands.SetRangeCommand{Key: args[1], Offset: offset, Value: raw[3]}, nil
+
+	case "GETDEL":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("GETDEL command requires exactly 1 argument")
+		}
+		return &commands.GetDelCommand{Key: args[1]}, nil
+
+	case "GETEX":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("GETEX command requires at least 1 argument")
+		}
+		opts := options.NewSetOptions()
+		for i := 2; i < len(args); i++ {
+			opt := strings.ToUpper(args[i])
+			if opts.ExpiryType != "" {
+				return nil, fmt.Errorf("syntax error")
+			}
+			switch opt {
+			case "PERSIST":
+				opts.SetExpiry(opt, 0)
+			case "EX", "PX", "EXAT", "PXAT":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				val, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("value is not an integer or out of range")
+				}
+				if err := opts.SetExpiry(opt, val); err != nil {
+					return nil, fmt.Errorf("invalid expire time in 'getex' command")
+				}
+				i++
+			default:
+				return nil, fmt.Errorf("syntax error")
+			}
+		}
+		return &commands.GetExCommand{Key: args[1], Options: opts}, nil
+
+	case "LCS":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("LCS command requires at least 2 arguments")
+		}
+		lcs := &commands.LCSCommand{Key1: args[1], Key2: args[2]}
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "LEN":
+				lcs.Len = true
+			case "IDX":
+				lcs.Idx = true
+			case "WITHMATCHLEN":
+				lcs.WithMatchLen = true
+			case "MINMATCHLEN":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				n, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("value is not an integer or out of range")
+				}
+				lcs.MinMatchLen = int(max(n, 0))
+				i++
+			default:
+				return nil, fmt.Errorf("syntax error")
+			}
+		}
+		return lcs, nil
+
 	case "DEL":
 		if len(args) < 2 {
 			return nil, fmt.Errorf("DEL command requires at least 1 argument")
@@ -249,6 +867,35 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 			Options: opts,
 		}, nil
 
+	case "EXPIREAT", "PEXPIREAT":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("%s command requires at least 2 arguments", cmd)
+		}
+		ts, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp value")
+		}
+
+		at := time.Unix(ts, 0)
+		if cmd == "PEXPIREAT" {
+			at = time.UnixMilli(ts)
+		}
+
+		opts := options.NewExpireOptions()
+
+		for i := 3; i < len(args); i++ {
+			opt := strings.ToUpper(args[i])
+			if err := opts.Set(opt); err != nil {
+				return nil, fmt.Errorf("invalid option: %s", err)
+			}
+		}
+
+		return &commands.ExpireAtCommand{
+			Key:     args[1],
+			At:      at,
+			Options: opts,
+		}, nil
+
 	case "TTL":
 		if len(args) != 2 {
 			return nil, fmt.Errorf("TTL command requires exactly 1 argument")
@@ -312,76 +959,1383 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 		if len(args) < 4 {
 			return nil, fmt.Errorf("ZRANGE command requires at least 3 arguments")
 		}
+		opts := options.NewZRangeOptions()
+		if err := parseZRangeOptions(args[4:], opts, true); err != nil {
+			return nil, err
+		}
+		return newZRangeCommand(args[1], args[2], args[3], opts)
 
+	case "ZRANGEBYSCORE", "ZREVRANGEBYSCORE":
+		if len(args) < 4 {
+			return nil, fmt.Errorf("%s command requires at least 3 arguments", cmd)
+		}
 		opts := options.NewZRangeOptions()
+		opts.SetRangeType("BYSCORE")
+		opts.Rev = cmd == "ZREVRANGEBYSCORE"
+		if err := parseZRangeOptions(args[4:], opts, false); err != nil {
+			return nil, err
+		}
+		return newZRangeCommand(args[1], args[2], args[3], opts)
 
-		i := 4 // Start after key, start, stop
+	case "ZRANGEBYLEX", "ZREVRANGEBYLEX":
+		if len(args) < 4 {
+			return nil, fmt.Errorf("%s command requires at least 3 arguments", cmd)
+		}
+		opts := options.NewZRangeOptions()
+		opts.SetRangeType("BYLEX")
+		opts.Rev = cmd == "ZREVRANGEBYLEX"
+		if err := parseZRangeOptions(args[4:], opts, false); err != nil {
+			return nil, err
+		}
+		return newZRangeCommand(args[1], args[2], args[3], opts)
+
+	case "ZRANK", "ZREVRANK":
+		if len(args) != 3 && len(args) != 4 {
+			return nil, fmt.Errorf("%s command requires 2 or 3 arguments", cmd)
+		}
+		withScore := len(args) == 4
+		if withScore && strings.ToUpper(args[3]) != "WITHSCORE" {
+			return nil, fmt.Errorf("syntax error")
+		}
+		return &commands.ZRankCommand{Key: args[1], Member: args[2], Rev: cmd == "ZREVRANK", WithScore: withScore}, nil
+
+	case "ZCOUNT":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("ZCOUNT command requires exactly 3 arguments")
+		}
+		min, err := options.ParseScoreBound(args[2])
+		if err != nil {
+			return nil, err
+		}
+		max, err := options.ParseScoreBound(args[3])
+		if err != nil {
+			return nil, err
+		}
+		return &commands.ZCountCommand{Key: args[1], Min: min, Max: max}, nil
+
+	case "ZLEXCOUNT", "ZREMRANGEBYLEX":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("%s command requires exactly 3 arguments", cmd)
+		}
+		min, err := options.ParseLexBound(args[2])
+		if err != nil {
+			return nil, err
+		}
+		max, err := options.ParseLexBound(args[3])
+		if err != nil {
+			return nil, err
+		}
+		if cmd == "ZLEXCOUNT" {
+			return &commands.ZLexCountCommand{Key: args[1], Min: min, Max: max}, nil
+		}
+		return &commands.ZRemRangeByLexCommand{Key: args[1], Min: min, Max: max}, nil
+
+	case "LPUSH", "RPUSH", "LPUSHX", "RPUSHX":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("%s command requires at least 2 arguments", cmd)
+		}
+		return &commands.PushCommand{
+			Key:          args[1],
+			Values:       args[2:],
+			Left:         cmd[0] == 'L',
+			OnlyIfExists: strings.HasSuffix(cmd, "X"),
+		}, nil
+
+	case "LPOP", "RPOP":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("%s command requires 1 or 2 arguments", cmd)
+		}
+		pop := &commands.PopCommand{
+			Key:  args[1],
+			Left: cmd == "LPOP",
+		}
+		if len(args) == 3 {
+			count, err := strconv.Atoi(args[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("value is out of range, must be positive")
+			}
+			pop.Count = count
+			pop.HasCount = true
+		}
+		return pop, nil
+
+	case "LLEN":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("LLEN command requires exactly 1 argument")
+		}
+		return &commands.LLenCommand{
+			Key: args[1],
+		}, nil
+
+	case "LRANGE", "LTRIM":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("%s command requires exactly 3 arguments", cmd)
+		}
+		start, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start index")
+		}
+		stop, err := strconv.Atoi(args[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid stop index")
+		}
+		if cmd == "LTRIM" {
+			return &commands.LTrimCommand{Key: args[1], Start: start, Stop: stop}, nil
+		}
+		return &commands.LRangeCommand{Key: args[1], Start: start, Stop: stop}, nil
+
+	case "LINDEX":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("LINDEX command requires exactly 2 arguments")
+		}
+		index, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid index")
+		}
+		return &commands.LIndexCommand{
+			Key:   args[1],
+			Index: index,
+		}, nil
+
+	case "LSET":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("LSET command requires exactly 3 arguments")
+		}
+		index, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid index")
+		}
+		return &commands.LSetCommand{
+			Key:   args[1],
+			Index: index,
+			Value: args[3],
+		}, nil
+
+	case "LINSERT":
+		if len(args) != 5 {
+			return nil, fmt.Errorf("LINSERT command requires exactly 4 arguments")
+		}
+		where := strings.ToUpper(args[2])
+		if where != "BEFORE" && where != "AFTER" {
+			return nil, fmt.Errorf("syntax error")
+		}
+		return &commands.LInsertCommand{
+			Key:    args[1],
+			Before: where == "BEFORE",
+			Pivot:  args[3],
+			Value:  args[4],
+		}, nil
+
+	case "LREM":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("LREM command requires exactly 3 arguments")
+		}
+		count, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid count")
+		}
+		return &commands.LRemCommand{
+			Key:   args[1],
+			Count: count,
+			Value: args[3],
+		}, nil
+
+	case "LPOS":
+		if len(args) < 3 || len(args)%2 != 1 {
+			return nil, fmt.Errorf("LPOS command requires a key, an element and option-value pairs")
+		}
+		lpos := &commands.LPosCommand{
+			Key:     args[1],
+			Element: args[2],
+			Rank:    1,
+		}
+		for i := 3; i < len(args); i += 2 {
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value", strings.ToUpper(args[i]))
+			}
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "RANK":
+				if n == 0 {
+					return nil, fmt.Errorf("RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+				}
+				lpos.Rank = n
+			case "COUNT":
+				if n < 0 {
+					return nil, fmt.Errorf("COUNT can't be negative")
+				}
+				lpos.Count = n
+				lpos.HasCount = true
+			case "MAXLEN":
+				if n < 0 {
+					return nil, fmt.Errorf("MAXLEN can't be negative")
+				}
+				lpos.MaxLen = n
+			default:
+				return nil, fmt.Errorf("unknown option: %s", opt)
+			}
+		}
+		return lpos, nil
+
+	case "LMOVE":
+		if len(args) != 5 {
+			return nil, fmt.Errorf("LMOVE command requires exactly 4 arguments")
+		}
+		from, to := strings.ToUpper(args[3]), strings.ToUpper(args[4])
+		if (from != "LEFT" && from != "RIGHT") || (to != "LEFT" && to != "RIGHT") {
+			return nil, fmt.Errorf("syntax error")
+		}
+		return &commands.LMoveCommand{
+			Source:      args[1],
+			Destination: args[2],
+			FromLeft:    from == "LEFT",
+			ToLeft:      to == "LEFT",
+		}, nil
+
+	case "HSET":
+		if len(args) < 4 || len(args)%2 != 0 {
+			return nil, fmt.Errorf("HSET command requires at least one field-value pair")
+		}
+		pairs := make([]types.FieldValue, 0, (len(args)-2)/2)
+		for i := 2; i < len(args); i += 2 {
+			pairs = append(pairs, types.FieldValue{Field: args[i], Value: args[i+1]})
+		}
+		return &commands.HSetCommand{
+			Key:   args[1],
+			Pairs: pairs,
+		}, nil
+
+	case "HSETNX":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("HSETNX command requires exactly 3 arguments")
+		}
+		return &commands.HSetNXCommand{
+			Key:   args[1],
+			Field: args[2],
+			Value: args[3],
+		}, nil
+
+	case "HGET", "HEXISTS", "HSTRLEN":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("%s command requires exactly 2 arguments", cmd)
+		}
+		switch cmd {
+		case "HEXISTS":
+			return &commands.HExistsCommand{Key: args[1], Field: args[2]}, nil
+		case "HSTRLEN":
+			return &commands.HStrLenCommand{Key: args[1], Field: args[2]}, nil
+		}
+		return &commands.HGetCommand{Key: args[1], Field: args[2]}, nil
+
+	case "HMGET", "HDEL":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("%s command requires at least 2 arguments", cmd)
+		}
+		if cmd == "HDEL" {
+			return &commands.HDelCommand{Key: args[1], Fields: args[2:]}, nil
+		}
+		return &commands.HMGetCommand{Key: args[1], Fields: args[2:]}, nil
+
+	case "HLEN", "HKEYS", "HVALS", "HGETALL":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s command requires exactly 1 argument", cmd)
+		}
+		switch cmd {
+		case "HLEN":
+			return &commands.HLenCommand{Key: args[1]}, nil
+		case "HKEYS":
+			return &commands.HKeysCommand{Key: args[1]}, nil
+		case "HVALS":
+			return &commands.HValsCommand{Key: args[1]}, nil
+		}
+		return &commands.HGetAllCommand{Key: args[1]}, nil
+
+	case "HINCRBY":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("HINCRBY command requires exactly 3 arguments")
+		}
+		delta, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		return &commands.HIncrByCommand{
+			Key:   args[1],
+			Field: args[2],
+			Delta: delta,
+		}, nil
+
+	case "HINCRBYFLOAT":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("HINCRBYFLOAT command requires exactly 3 arguments")
+		}
+		delta, err := strconv.ParseFloat(args[3], 64)
+		if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+			return nil, fmt.Errorf("value is not a valid float")
+		}
+		return &commands.HIncrByFloatCommand{
+			Key:   args[1],
+			Field: args[2],
+			Delta: delta,
+		}, nil
+
+	case "HRANDFIELD":
+		if len(args) < 2 || len(args) > 4 {
+			return nil, fmt.Errorf("HRANDFIELD command requires 1 to 3 arguments")
+		}
+		randField := &commands.HRandFieldCommand{Key: args[1]}
+		if len(args) >= 3 {
+			count, err := strconv.Atoi(args[2])
+			if err != nil {
+				return nil, fmt.Errorf("value is not an integer or out of range")
+			}
+			randField.Count = count
+			randField.HasCount = true
+		}
+		if len(args) == 4 {
+			if strings.ToUpper(args[3]) != "WITHVALUES" {
+				return nil, fmt.Errorf("syntax error")
+			}
+			randField.WithValues = true
+		}
+		return randField, nil
+
+	case "HSCAN":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("HSCAN command requires at least 2 arguments")
+		}
+		cursor, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		hscan := &commands.HScanCommand{Key: args[1], Cursor: cursor}
+		for i := 3; i < len(args); i++ {
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "MATCH":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				hscan.Pattern = args[i+1]
+				i++
+			case "COUNT":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				count, err := strconv.Atoi(args[i+1])
+				if err != nil || count < 1 {
+					return nil, fmt.Errorf("value is not an integer or out of range")
+				}
+				hscan.Count = count
+				i++
+			case "NOVALUES":
+				hscan.NoValues = true
+			default:
+				return nil, fmt.Errorf("unknown option: %s", opt)
+			}
+		}
+		return hscan, nil
+
+	case "HEXPIRE", "HPEXPIRE", "HEXPIREAT", "HPEXPIREAT":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("%s command requires at least 5 arguments", cmd)
+		}
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid expire time in '%s' command", strings.ToLower(cmd))
+		}
+
+		var at time.Time
+		switch cmd {
+		case "HEXPIRE":
+			at = time.Now().Add(time.Duration(n) * time.Second)
+		case "HPEXPIRE":
+			at = time.Now().Add(time.Duration(n) * time.Millisecond)
+		case "HEXPIREAT":
+			at = time.Unix(n, 0)
+		case "HPEXPIREAT":
+			at = time.UnixMilli(n)
+		}
+
+		opts := options.NewExpireOptions()
+		i := 3
+		for ; i < len(args) && strings.ToUpper(args[i]) != "FIELDS"; i++ {
+			if err := opts.Set(strings.ToUpper(args[i])); err != nil {
+				return nil, fmt.Errorf("invalid option: %s", err)
+			}
+		}
+		fields, err := parseFieldsArg(args[i:], 1)
+		if err != nil {
+			return nil, err
+		}
+		return &commands.HExpireCommand{
+			Key:     args[1],
+			At:      at,
+			Fields:  fields,
+			Options: opts,
+		}, nil
+
+	case "HTTL", "HPTTL", "HPERSIST":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("%s command requires at least 4 arguments", cmd)
+		}
+		fields, err := parseFieldsArg(args[2:], 1)
+		if err != nil {
+			return nil, err
+		}
+		if cmd == "HPERSIST" {
+			return &commands.HPersistCommand{Key: args[1], Fields: fields}, nil
+		}
+		return &commands.HTTLCommand{
+			Key:    args[1],
+			Fields: fields,
+			Millis: cmd == "HPTTL",
+		}, nil
+
+	case "HGETEX":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("HGETEX command requires at least 4 arguments")
+		}
+		opts := options.NewHGetExOptions()
+		i := 2
+		for ; i < len(args) && strings.ToUpper(args[i]) != "FIELDS"; i++ {
+			if opts.ExpiryType != "" {
+				return nil, fmt.Errorf("syntax error")
+			}
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "PERSIST":
+				opts.SetExpiry(opt, 0)
+			case "EX", "PX", "EXAT", "PXAT":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				n, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid expire time in 'hgetex' command")
+				}
+				if err := opts.SetExpiry(opt, n); err != nil {
+					return nil, fmt.Errorf("invalid expire time in 'hgetex' command")
+				}
+				i++
+			default:
+				return nil, fmt.Errorf("unknown option: %s", opt)
+			}
+		}
+		fields, err := parseFieldsArg(args[i:], 1)
+		if err != nil {
+			return nil, err
+		}
+		return &commands.HGetExCommand{
+			Key:     args[1],
+			Fields:  fields,
+			Options: opts,
+		}, nil
+
+	case "HSETEX":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("HSETEX command requires at least 5 arguments")
+		}
+		opts := options.NewHSetExOptions()
+		i := 2
+		for ; i < len(args) && strings.ToUpper(args[i]) != "FIELDS"; i++ {
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "FNX", "FXX":
+				if err := opts.Set(opt); err != nil {
+					return nil, fmt.Errorf("invalid option: %s", err)
+				}
+			case "KEEPTTL", "EX", "PX", "EXAT", "PXAT":
+				if opts.ExpiryType != "" {
+					return nil, fmt.Errorf("syntax error")
+				}
+				if opt == "KEEPTTL" {
+					opts.SetExpiry(opt, 0)
+					continue
+				}
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				n, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid expire time in 'hsetex' command")
+				}
+				if err := opts.SetExpiry(opt, n); err != nil {
+					return nil, fmt.Errorf("invalid expire time in 'hsetex' command")
+				}
+				i++
+			default:
+				return nil, fmt.Errorf("unknown option: %s", opt)
+			}
+		}
+		flat, err := parseFieldsArg(args[i:], 2)
+		if err != nil {
+			return nil, err
+		}
+		pairs := make([]types.FieldValue, 0, len(flat)/2)
+		for j := 0; j < len(flat); j += 2 {
+			pairs = append(pairs, types.FieldValue{Field: flat[j], Value: flat[j+1]})
+		}
+		return &commands.HSetExCommand{
+			Key:     args[1],
+			Pairs:   pairs,
+			Options: opts,
+		}, nil
+
+	case "SADD", "SREM":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("%s command requires at least 2 arguments", cmd)
+		}
+		if cmd == "SADD" {
+			return &commands.SAddCommand{Key: args[1], Members: args[2:]}, nil
+		}
+		return &commands.SRemCommand{Key: args[1], Members: args[2:]}, nil
+
+	case "SISMEMBER":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("SISMEMBER command requires exactly 2 arguments")
+		}
+		return &commands.SIsMemberCommand{
+			Key:    args[1],
+			Member: args[2],
+		}, nil
+
+	case "SMISMEMBER":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("SMISMEMBER command requires at least 2 arguments")
+		}
+		return &commands.SMIsMemberCommand{
+			Key:     args[1],
+			Members: args[2:],
+		}, nil
+
+	case "SMEMBERS":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("SMEMBERS command requires exactly 1 argument")
+		}
+		return &commands.SMembersCommand{
+			Key: args[1],
+		}, nil
+
+	case "SCARD":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("SCARD command requires exactly 1 argument")
+		}
+		return &commands.SCardCommand{
+			Key: args[1],
+		}, nil
+
+	case "SPOP":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("SPOP command requires 1 or 2 arguments")
+		}
+		pop := &commands.SPopCommand{Key: args[1]}
+		if len(args) == 3 {
+			count, err := strconv.Atoi(args[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("value is out of range, must be positive")
+			}
+			pop.Count = count
+			pop.HasCount = true
+		}
+		return pop, nil
+
+	case "SRANDMEMBER":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("SRANDMEMBER command requires 1 or 2 arguments")
+		}
+		randMember := &commands.SRandMemberCommand{Key: args[1]}
+		if len(args) == 3 {
+			count, err := strconv.Atoi(args[2])
+			if err != nil {
+				return nil, fmt.Errorf("value is not an integer or out of range")
+			}
+			randMember.Count = count
+			randMember.HasCount = true
+		}
+		return randMember, nil
+
+	case "SMOVE":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("SMOVE command requires exactly 3 arguments")
+		}
+		return &commands.SMoveCommand{
+			Source:      args[1],
+			Destination: args[2],
+			Member:      args[3],
+		}, nil
+
+	case "SINTER", "SUNION", "SDIFF":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s command requires at least 1 argument", cmd)
+		}
+		return &commands.SetOpCommand{
+			Op:   cmd,
+			Keys: args[1:],
+		}, nil
+
+	case "SINTERSTORE", "SUNIONSTORE", "SDIFFSTORE":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("%s command requires at least 2 arguments", cmd)
+		}
+		return &commands.SetOpStoreCommand{
+			Op:          strings.TrimSuffix(cmd, "STORE"),
+			Destination: args[1],
+			Keys:        args[2:],
+		}, nil
+
+	case "SINTERCARD":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("SINTERCARD command requires at least 2 arguments")
+		}
+		numKeys, err := strconv.Atoi(args[1])
+		if err != nil || numKeys < 1 {
+			return nil, fmt.Errorf("numkeys should be greater than 0")
+		}
+		if numKeys > len(args)-2 {
+			return nil, fmt.Errorf("Number of keys can't be greater than number of args")
+		}
+		interCard := &commands.SInterCardCommand{Keys: args[2 : 2+numKeys]}
+		rest := args[2+numKeys:]
+		switch {
+		case len(rest) == 0:
+		case len(rest) == 2 && strings.ToUpper(rest[0]) == "LIMIT":
+			limit, err := strconv.Atoi(rest[1])
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("LIMIT can't be negative")
+			}
+			interCard.Limit = limit
+		default:
+			return nil, fmt.Errorf("syntax error")
+		}
+		return interCard, nil
+
+	case "SSCAN":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("SSCAN command requires at least 2 arguments")
+		}
+		cursor, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		sscan := &commands.SScanCommand{Key: args[1], Cursor: cursor}
+		for i := 3; i < len(args); i++ {
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "MATCH":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				sscan.Pattern = args[i+1]
+				i++
+			case "COUNT":
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("syntax error")
+				}
+				count, err := strconv.Atoi(args[i+1])
+				if err != nil || count < 1 {
+					return nil, fmt.Errorf("value is not an integer or out of range")
+				}
+				sscan.Count = count
+				i++
+			default:
+				return nil, fmt.Errorf("unknown option: %s", opt)
+			}
+		}
+		return sscan, nil
+
+	case "XADD":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("XADD command requires at least 2 arguments")
+		}
+		opts := &options.XAddOptions{}
+		i := 2
+	xaddOptions:
 		for i < len(args) {
+			switch strings.ToUpper(args[i]) {
+			case "NOMKSTREAM":
+				opts.NoMkStream = true
+				i++
+			case "MAXLEN", "MINID":
+				trim, n, err := parseTrimArgs(args[i:])
+				if err != nil {
+					return nil, err
+				}
+				opts.Trim = trim
+				i += n
+			default:
+				break xaddOptions
+			}
+		}
+		if i >= len(args) || (len(args)-i-1)%2 != 0 || len(args)-i-1 == 0 {
+			return nil, fmt.Errorf("wrong number of arguments for 'xadd' command")
+		}
+		return &commands.XAddCommand{Key: args[1], ID: args[i], Fields: args[i+1:], Options: opts}, nil
+
+	case "XRANGE", "XREVRANGE":
+		if len(args) != 4 && len(args) != 6 {
+			return nil, fmt.Errorf("%s command requires 3 or 5 arguments", cmd)
+		}
+		rev := cmd == "XREVRANGE"
+		first, last := args[2], args[3]
+		if rev {
+			first, last = last, first
+		}
+		start, err := parseRangeID(first, true)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseRangeID(last, false)
+		if err != nil {
+			return nil, err
+		}
+		xrange := &commands.XRangeCommand{Key: args[1], Start: start, End: end, Count: -1, Rev: rev}
+		if len(args) == 6 {
+			if strings.ToUpper(args[4]) != "COUNT" {
+				return nil, fmt.Errorf("syntax error")
+			}
+			count, err := strconv.Atoi(args[5])
+			if err != nil {
+				return nil, fmt.Errorf("value is not an integer or out of range")
+			}
+			xrange.Count = max(count, 0)
+		}
+		return xrange, nil
+
+	case "XLEN":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("XLEN command requires exactly 1 argument")
+		}
+		return &commands.XLenCommand{Key: args[1]}, nil
+
+	case "XDEL", "XACK":
+		minArgs := 3
+		if cmd == "XACK" {
+			minArgs = 4
+		}
+		if len(args) < minArgs {
+			return nil, fmt.Errorf("%s command requires at least %d arguments", cmd, minArgs-1)
+		}
+		ids := make([]types.StreamID, 0, len(args)-minArgs+1)
+		for _, arg := range args[minArgs-1:] {
+			id, err := types.ParseStreamID(arg, 0)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		if cmd == "XACK" {
+			return &commands.XAckCommand{Key: args[1], Group: args[2], IDs: ids}, nil
+		}
+		return &commands.XDelCommand{Key: args[1], IDs: ids}, nil
+
+	case "XTRIM":
+		if len(args) < 4 {
+			return nil, fmt.Errorf("XTRIM command requires at least 3 arguments")
+		}
+		if strategy := strings.ToUpper(args[2]); strategy != "MAXLEN" && strategy != "MINID" {
+			return nil, fmt.Errorf("syntax error")
+		}
+		trim, n, err := parseTrimArgs(args[2:])
+		if err != nil {
+			return nil, err
+		}
+		if 2+n != len(args) {
+			return nil, fmt.Errorf("syntax error")
+		}
+		return &commands.XTrimCommand{Key: args[1], Options: trim}, nil
+
+	case "XREAD", "XREADGROUP":
+		var group, consumer string
+		var count int
+		var timeout time.Duration
+		var hasBlock, noAck bool
+		i := 1
+		for ; i < len(args); i++ {
+			opt := strings.ToUpper(args[i])
+			if opt == "STREAMS" {
+				break
+			}
+			switch {
+			case opt == "COUNT" && i+1 < len(args):
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil {
+					return nil, fmt.Errorf("value is not an integer or out of range")
+				}
+				count = max(n, 0)
+				i++
+			case opt == "BLOCK" && i+1 < len(args):
+				ms, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("timeout is not an integer or out of range")
+				}
+				if ms < 0 {
+					return nil, fmt.Errorf("timeout is negative")
+				}
+				timeout = time.Duration(ms) * time.Millisecond
+				hasBlock = true
+				i++
+			case opt == "GROUP" && cmd == "XREADGROUP" && i+2 < len(args):
+				group, consumer = args[i+1], args[i+2]
+				i += 2
+			case opt == "NOACK" && cmd == "XREADGROUP":
+				noAck = true
+			default:
+				return nil, fmt.Errorf("syntax error")
+			}
+		}
+		if i == len(args) {
+			return nil, fmt.Errorf("syntax error")
+		}
+		keys, ids, err := parseStreamsArg(cmd, args[i+1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if cmd == "XREAD" {
+			for _, id := range ids {
+				if id == "$" {
+					continue
+				}
+				if _, err := types.ParseStreamID(id, 0); err != nil {
+					return nil, err
+				}
+			}
+			return &commands.XReadCommand{Keys: keys, IDs: ids, Count: count, Timeout: timeout, HasBlock: hasBlock}, nil
+		}
+
+		if group == "" {
+			return nil, fmt.Errorf("Missing GROUP option for XREADGROUP")
+		}
+		for _, id := range ids {
+			switch id {
+			case ">":
+			case "$":
+				return nil, fmt.Errorf("The $ ID is meaningless in the context of XREADGROUP: you want to read the history of this consumer by specifying a proper ID, or use the > ID to get new messages. The $ ID would just return an empty result set.")
+			default:
+				if _, err := types.ParseStreamID(id, 0); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return &commands.XReadGroupCommand{
+			Group:    group,
+			Consumer: consumer,
+			Keys:     keys,
+			IDs:      ids,
+			Count:    count,
+			NoAck:    noAck,
+			Timeout:  timeout,
+			HasBlock: hasBlock,
+		}, nil
+
+	case "XGROUP":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("XGROUP command requires a subcommand")
+		}
+		return createXGroupCommand(strings.ToUpper(args[1]), args[2:])
+
+	case "XPENDING":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("XPENDING command requires at least 2 arguments")
+		}
+		xpending := &commands.XPendingCommand{Key: args[1], Group: args[2]}
+		rest := args[3:]
+		if len(rest) == 0 {
+			return xpending, nil
+		}
+		if len(rest) >= 2 && strings.ToUpper(rest[0]) == "IDLE" {
+			ms, err := strconv.ParseInt(rest[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value is not an integer or out of range")
+			}
+			xpending.MinIdle = time.Duration(max(ms, 0)) * time.Millisecond
+			rest = rest[2:]
+		}
+		if len(rest) != 3 && len(rest) != 4 {
+			return nil, fmt.Errorf("syntax error")
+		}
+		start, err := parseRangeID(rest[0], true)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseRangeID(rest[1], false)
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.Atoi(rest[2])
+		if err != nil {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		xpending.Extended = true
+		xpending.Start, xpending.End, xpending.Count = start, end, max(count, 0)
+		if len(rest) == 4 {
+			xpending.Consumer = rest[3]
+		}
+		return xpending, nil
+
+	case "XCLAIM":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("XCLAIM command requires at least 5 arguments")
+		}
+		minIdle, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid min-idle-time argument for XCLAIM")
+		}
+		xclaim := &commands.XClaimCommand{
+			Key:      args[1],
+			Group:    args[2],
+			Consumer: args[3],
+			MinIdle:  time.Duration(max(minIdle, 0)) * time.Millisecond,
+			Options:  options.NewXClaimOptions(),
+		}
+		i := 5
+		for ; i < len(args); i++ {
+			id, err := types.ParseStreamID(args[i], 0)
+			if err != nil {
+				break
+			}
+			xclaim.IDs = append(xclaim.IDs, id)
+		}
+		if len(xclaim.IDs) == 0 {
+			return nil, types.ErrInvalidStreamID
+		}
+		for ; i < len(args); i++ {
 			opt := strings.ToUpper(args[i])
-			switch opt {
-			case "BYSCORE", "BYLEX":
-				if err := opts.SetRangeType(opt); err != nil {
-					return nil, fmt.Errorf("invalid range type: %s", err)
+			switch {
+			case opt == "FORCE":
+				xclaim.Options.Force = true
+			case opt == "JUSTID":
+				xclaim.Options.JustID = true
+			case (opt == "IDLE" || opt == "TIME" || opt == "RETRYCOUNT") && i+1 < len(args):
+				n, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid %s option argument for XCLAIM", opt)
+				}
+				switch opt {
+				case "IDLE":
+					xclaim.Options.Time = time.Now().Add(-time.Duration(n) * time.Millisecond)
+				case "TIME":
+					xclaim.Options.Time = time.UnixMilli(n)
+				default:
+					xclaim.Options.RetryCount = max(n, 0)
 				}
 				i++
-			case "REV":
-				opts.Rev = true
+			case opt == "LASTID" && i+1 < len(args):
+				lastID, err := types.ParseStreamID(args[i+1], 0)
+				if err != nil {
+					return nil, err
+				}
+				xclaim.Options.LastID = &lastID
 				i++
-			case "WITHSCORES":
-				opts.WithScores = true
+			default:
+				return nil, fmt.Errorf("Unrecognized XCLAIM option '%s'", args[i])
+			}
+		}
+		return xclaim, nil
+
+	case "XAUTOCLAIM":
+		if len(args) < 6 {
+			return nil, fmt.Errorf("XAUTOCLAIM command requires at least 5 arguments")
+		}
+		minIdle, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid min-idle-time argument for XAUTOCLAIM")
+		}
+		start, err := parseRangeID(args[5], true)
+		if err != nil {
+			return nil, err
+		}
+		xautoclaim := &commands.XAutoClaimCommand{
+			Key:      args[1],
+			Group:    args[2],
+			Consumer: args[3],
+			MinIdle:  time.Duration(max(minIdle, 0)) * time.Millisecond,
+			Start:    start,
+			Count:    100,
+		}
+		for i := 6; i < len(args); i++ {
+			switch opt := strings.ToUpper(args[i]); {
+			case opt == "JUSTID":
+				xautoclaim.JustID = true
+			case opt == "COUNT" && i+1 < len(args):
+				count, err := strconv.Atoi(args[i+1])
+				if err != nil || count < 1 {
+					return nil, fmt.Errorf("COUNT must be > 0")
+				}
+				xautoclaim.Count = count
 				i++
-			case "LIMIT":
-				if i+2 >= len(args) {
-					return nil, fmt.Errorf("LIMIT option requires offset and count")
+			default:
+				return nil, fmt.Errorf("syntax error")
+			}
+		}
+		return xautoclaim, nil
+
+	case "XINFO":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("XINFO command requires a subcommand and a key")
+		}
+		switch sub := strings.ToUpper(args[1]); sub {
+		case "STREAM":
+			if len(args) > 3 {
+				if strings.ToUpper(args[3]) == "FULL" {
+					return nil, fmt.Errorf("XINFO STREAM FULL is not supported")
 				}
-				offset, err := strconv.Atoi(args[i+1])
-				if err != nil {
-					return nil, fmt.Errorf("invalid LIMIT offset")
+				return nil, fmt.Errorf("syntax error")
+			}
+			return &commands.XInfoCommand{Subcommand: sub, Key: args[2]}, nil
+		case "GROUPS":
+			if len(args) != 3 {
+				return nil, fmt.Errorf("XINFO GROUPS requires exactly 1 argument")
+			}
+			return &commands.XInfoCommand{Subcommand: sub, Key: args[2]}, nil
+		case "CONSUMERS":
+			if len(args) != 4 {
+				return nil, fmt.Errorf("XINFO CONSUMERS requires exactly 2 arguments")
+			}
+			return &commands.XInfoCommand{Subcommand: sub, Key: args[2], Group: args[3]}, nil
+		default:
+			return nil, fmt.Errorf("unknown subcommand '%s'", args[1])
+		}
+
+	case "XSETID":
+		if len(args) < 3 || len(args)%2 != 1 {
+			return nil, fmt.Errorf("XSETID command requires a key, an ID and option-value pairs")
+		}
+		lastID, err := types.ParseStreamID(args[2], 0)
+		if err != nil {
+			return nil, err
+		}
+		xsetid := &commands.XSetIDCommand{Key: args[1], LastID: lastID, EntriesAdded: -1}
+		for i := 3; i < len(args); i += 2 {
+			switch opt := strings.ToUpper(args[i]); opt {
+			case "ENTRIESADDED":
+				n, err := strconv.ParseInt(args[i+1], 10, 64)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("entries_added must be positive")
 				}
-				count, err := strconv.Atoi(args[i+2])
+				xsetid.EntriesAdded = n
+			case "MAXDELETEDID":
+				id, err := types.ParseStreamID(args[i+1], 0)
 				if err != nil {
-					return nil, fmt.Errorf("invalid LIMIT count")
+					return nil, err
 				}
-				if err := opts.SetLimit(offset, count); err != nil {
-					return nil, fmt.Errorf("invalid LIMIT parameters: %s", err)
-				}
-				i += 3
+				xsetid.MaxDeletedID = &id
 			default:
 				return nil, fmt.Errorf("unknown option: %s", opt)
 			}
 		}
+		return xsetid, nil
 
-		var start, stop interface{}
-		var err error
-
-		if opts.IsByScore() {
-			start, err = strconv.ParseFloat(args[2], 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid score range start: %s", err)
+	case "GEOADD":
+		if len(args) < 5 {
+			return nil, fmt.Errorf("GEOADD command requires at least 4 arguments")
+		}
+		opts := options.NewGeoAddOptions()
+		i := 2
+		for ; i < len(args); i++ {
+			opt := strings.ToUpper(args[i])
+			if opt != "NX" && opt != "XX" && opt != "CH" {
+				break
 			}
-			stop, err = strconv.ParseFloat(args[3], 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid score range stop: %s", err)
+			if err := opts.Set(opt); err != nil {
+				return nil, fmt.Errorf("XX and NX options at the same time are not compatible")
 			}
-		} else if opts.IsByLex() {
-			start = args[2]
-			stop = args[3]
-		} else {
-			start, err = strconv.Atoi(args[2])
+		}
+		if i == len(args) || (len(args)-i)%3 != 0 {
+			return nil, fmt.Errorf("syntax error")
+		}
+		geoadd := &commands.GeoAddCommand{Key: args[1], Options: opts}
+		for ; i < len(args); i += 3 {
+			lon, err := strconv.ParseFloat(args[i], 64)
 			if err != nil {
-				return nil, fmt.Errorf("invalid start index")
+				return nil, fmt.Errorf("value is not a valid float")
 			}
-			stop, err = strconv.Atoi(args[3])
+			lat, err := strconv.ParseFloat(args[i+1], 64)
 			if err != nil {
-				return nil, fmt.Errorf("invalid stop index")
+				return nil, fmt.Errorf("value is not a valid float")
+			}
+			geoadd.Locations = append(geoadd.Locations, types.GeoLocation{Longitude: lon, Latitude: lat, Member: args[i+2]})
+		}
+		return geoadd, nil
+
+	case "GEOPOS", "GEOHASH":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s command requires at least 1 argument", cmd)
+		}
+		if cmd == "GEOHASH" {
+			return &commands.GeoHashCommand{Key: args[1], Members: args[2:]}, nil
+		}
+		return &commands.GeoPosCommand{Key: args[1], Members: args[2:]}, nil
+
+	case "GEODIST":
+		if len(args) != 4 && len(args) != 5 {
+			return nil, fmt.Errorf("GEODIST command requires 3 or 4 arguments")
+		}
+		unit := 1.0
+		if len(args) == 5 {
+			var err error
+			if unit, err = options.GeoUnitFactor(args[4]); err != nil {
+				return nil, err
+			}
+		}
+		return &commands.GeoDistCommand{Key: args[1], Member1: args[2], Member2: args[3], Unit: unit}, nil
+
+	case "GEOSEARCH":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("GEOSEARCH command requires at least 1 argument")
+		}
+		search, _, err := parseGeoSearchArgs(args[2:], false)
+		if err != nil {
+			return nil, err
+		}
+		search.Key = args[1]
+		return search, nil
+
+	case "GEOSEARCHSTORE":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("GEOSEARCHSTORE command requires at least 2 arguments")
+		}
+		search, storeDist, err := parseGeoSearchArgs(args[3:], true)
+		if err != nil {
+			return nil, err
+		}
+		return &commands.GeoSearchStoreCommand{Destination: args[1], Source: args[2], Options: search.Options, Unit: search.Unit, StoreDist: storeDist}, nil
+
+	case "PFADD":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("PFADD command requires at least 1 argument")
+		}
+		return &commands.PFAddCommand{Key: args[1], Elements: args[2:]}, nil
+
+	case "PFCOUNT":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("PFCOUNT command requires at least 1 argument")
+		}
+		return &commands.PFCountCommand{Keys: args[1:]}, nil
+
+	case "PFMERGE":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("PFMERGE command requires at least 1 argument")
+		}
+		return &commands.PFMergeCommand{Destination: args[1], Keys: args[2:]}, nil
+
+	case "SETBIT":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("SETBIT command requires exactly 3 arguments")
+		}
+		offset, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || offset < 0 || offset > options.MaxBitOffset {
+			return nil, fmt.Errorf("bit offset is not an integer or out of range")
+		}
+		if args[3] != "0" && args[3] != "1" {
+			return nil, fmt.Errorf("bit is not an integer or out of range")
+		}
+		return &commands.SetBitCommand{Key: args[1], Offset: offset, Value: int(args[3][0] - '0')}, nil
+
+	case "GETBIT":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("GETBIT command requires exactly 2 arguments")
+		}
+		offset, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || offset < 0 || offset > options.MaxBitOffset {
+			return nil, fmt.Errorf("bit offset is not an integer or out of range")
+		}
+		return &commands.GetBitCommand{Key: args[1], Offset: offset}, nil
+
+	case "BITCOUNT":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("BITCOUNT command requires at least 1 argument")
+		}
+		r, err := parseBitRange(args[2:], true)
+		if err != nil {
+			return nil, err
+		}
+		return &commands.BitCountCommand{Key: args[1], Range: r}, nil
+
+	case "BITPOS":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("BITPOS command requires at least 2 arguments")
+		}
+		if args[2] != "0" && args[2] != "1" {
+			return nil, fmt.Errorf("The bit argument must be 1 or 0.")
+		}
+		r, err := parseBitRange(args[3:], false)
+		if err != nil {
+			return nil, err
+		}
+		return &commands.BitPosCommand{Key: args[1], Bit: int(args[2][0] - '0'), Range: r}, nil
+
+	case "BITOP":
+		if len(args) < 4 {
+			return nil, fmt.Errorf("BITOP command requires at least 3 arguments")
+		}
+		op := strings.ToUpper(args[1])
+		switch op {
+		case "AND", "OR", "XOR":
+		case "NOT":
+			if len(args) != 4 {
+				return nil, fmt.Errorf("BITOP NOT must be called with a single source key.")
 			}
+		default:
+			return nil, fmt.Errorf("syntax error")
+		}
+		return &commands.BitOpCommand{Op: op, Destination: args[2], Keys: args[3:]}, nil
+
+	case "BITFIELD", "BITFIELD_RO":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s command requires at least 1 argument", cmd)
+		}
+		ops, err := parseBitFieldOps(args[2:], cmd == "BITFIELD_RO")
+		if err != nil {
+			return nil, err
+		}
+		if cmd == "BITFIELD_RO" {
+			return &commands.BitFieldRoCommand{Key: args[1], Ops: ops}, nil
+		}
+		return &commands.BitFieldCommand{Key: args[1], Ops: ops}, nil
+
+	case "SAVE":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("SAVE command takes no arguments")
+		}
+		return &commands.SaveCommand{}, nil
+
+	case "BGSAVE":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("BGSAVE command takes no arguments")
+		}
+		return &commands.BgSaveCommand{}, nil
+
+	case "LASTSAVE":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("LASTSAVE command takes no arguments")
+		}
+		return &commands.LastSaveCommand{}, nil
+
+	case "BGREWRITEAOF":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("BGREWRITEAOF command takes no arguments")
+		}
+		return &commands.BgRewriteAofCommand{}, nil
+
+	case "REPLICAOF", "SLAVEOF":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("%s command requires exactly 2 arguments", cmd)
+		}
+		if strings.EqualFold(args[1], "NO") && strings.EqualFold(args[2], "ONE") {
+			return &commands.ReplicaOfCommand{}, nil
+		}
+		if _, err := strconv.Atoi(args[2]); err != nil {
+			return nil, fmt.Errorf("invalid master port")
+		}
+		return &commands.ReplicaOfCommand{
+			Host: args[1],
+			Port: args[2],
+		}, nil
+
+	case "ROLE":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("ROLE command takes no arguments")
+		}
+		return &commands.RoleCommand{}, nil
+
+	case "INFO":
+		if len(args) > 2 {
+			return nil, fmt.Errorf("INFO command takes at most 1 argument")
+		}
+		info := &commands.InfoCommand{}
+		if len(args) == 2 {
+			info.Section = strings.ToLower(args[1])
+		}
+		return info, nil
+
+	case "REPLCONF":
+		if len(args) < 2 || len(args)%2 == 0 {
+			return nil, fmt.Errorf("REPLCONF command requires option-value pairs")
+		}
+		return &commands.ReplConfCommand{
+			Args: args[1:],
+		}, nil
+
+	case "SYNC":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("SYNC command takes no arguments")
+		}
+		return &commands.SyncCommand{}, nil
+
+	case "WAIT":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("WAIT command requires exactly 2 arguments")
+		}
+		numReplicas, err := strconv.Atoi(args[1])
+		if err != nil || numReplicas < 0 {
+			return nil, fmt.Errorf("value is not an integer or out of range")
+		}
+		timeout, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("timeout is not an integer or out of range")
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("timeout is negative")
 		}
+		return &commands.WaitCommand{
+			NumReplicas: numReplicas,
+			Timeout:     time.Duration(timeout) * time.Millisecond,
+		}, nil
+
+	case "DUMP":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("DUMP command requires exactly 1 argument")
+		}
+		return &commands.DumpCommand{Key: args[1]}, nil
 
-		return &commands.ZRangeCommand{
+	case "RESTORE":
+		if len(args) < 4 {
+			return nil, fmt.Errorf("RESTORE command requires at least 3 arguments")
+		}
+		ttl, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("Invalid TTL value, must be >= 0")
+		}
+		restore := &commands.RestoreCommand{
 			Key:     args[1],
-			Start:   start,
-			Stop:    stop,
-			Options: opts,
+			TTL:     ttl,
+			Payload: args[3],
+		}
+		for _, opt := range args[4:] {
+			switch strings.ToUpper(opt) {
+			case "REPLACE":
+				restore.Replace = true
+			case "ABSTTL":
+				restore.AbsTTL = true
+			default:
+				return nil, fmt.Errorf("syntax error")
+			}
+		}
+		return restore, nil
+
+	case "MIGRATE":
+		return createMigrateCommand(args[1:])
+
+	case "ASKING":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("ASKING command takes no arguments")
+		}
+		return &commands.AskingCommand{}, nil
+
+	case "CLUSTER":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("CLUSTER command requires a subcommand")
+		}
+		return createClusterCommand(strings.ToUpper(args[1]), args[2:])
+
+	case "PSYNC":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("PSYNC command requires exactly 2 arguments")
+		}
+		offset, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replication offset")
+		}
+		return &commands.PSyncCommand{
+			ReplID: args[1],
+			Offset: offset,
 		}, nil
 
 	case "COMMAND":
@@ -391,3 +2345,194 @@ func (p *Parser) createCommand(args []string) (commands.Command, error) {
 		return nil, fmt.Errorf("unknown command: %s", cmd)
 	}
 }
+
+// createClusterCommand builds the CLUSTER subcommand sub with arguments args.
+func createXGroupCommand(sub string, args []string) (commands.Command, error) {
+	// parseGroupID validates the ID of CREATE and SETID, which may also be
+	// "$", and the ENTRIESREAD option that follows it.
+	parseGroupID := func(rest []string) (entriesRead int64, mkStream bool, err error) {
+		if rest[0] != "$" {
+			if _, err := types.ParseStreamID(rest[0], 0); err != nil {
+				return 0, false, err
+			}
+		}
+		entriesRead = -1
+		for i := 1; i < len(rest); i++ {
+			opt := strings.ToUpper(rest[i])
+			switch {
+			case opt == "MKSTREAM" && sub == "CREATE":
+				mkStream = true
+			case opt == "ENTRIESREAD" && i+1 < len(rest):
+				n, err := strconv.ParseInt(rest[i+1], 10, 64)
+				if err != nil || n < -1 {
+					return 0, false, fmt.Errorf("value for ENTRIESREAD must be positive or -1")
+				}
+				entriesRead = n
+				i++
+			default:
+				return 0, false, fmt.Errorf("syntax error")
+			}
+		}
+		return entriesRead, mkStream, nil
+	}
+
+	switch sub {
+	case "CREATE", "SETID":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("XGROUP %s requires at least 3 arguments", sub)
+		}
+		entriesRead, mkStream, err := parseGroupID(args[2:])
+		if err != nil {
+			return nil, err
+		}
+		if sub == "SETID" {
+			return &commands.XGroupSetIDCommand{Key: args[0], Group: args[1], ID: args[2], EntriesRead: entriesRead}, nil
+		}
+		return &commands.XGroupCreateCommand{Key: args[0], Group: args[1], ID: args[2], MkStream: mkStream, EntriesRead: entriesRead}, nil
+	case "DESTROY":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("XGROUP DESTROY requires exactly 2 arguments")
+		}
+		return &commands.XGroupDestroyCommand{Key: args[0], Group: args[1]}, nil
+	case "CREATECONSUMER", "DELCONSUMER":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("XGROUP %s requires exactly 3 arguments", sub)
+		}
+		return &commands.XGroupConsumerCommand{Key: args[0], Group: args[1], Consumer: args[2], Delete: sub == "DELCONSUMER"}, nil
+	default:
+		return nil, fmt.Errorf("unknown subcommand '%s'", sub)
+	}
+}
+
+func createClusterCommand(sub string, args []string) (commands.Command, error) {
+	noArgs := func(cmd commands.Command) (commands.Command, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("CLUSTER %s takes no arguments", sub)
+		}
+		return cmd, nil
+	}
+
+	switch sub {
+	case "KEYSLOT":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("CLUSTER KEYSLOT requires exactly 1 argument")
+		}
+		return &commands.ClusterKeySlotCommand{Key: args[0]}, nil
+	case "SLOTS":
+		return noArgs(&commands.ClusterSlotsCommand{})
+	case "SHARDS":
+		return noArgs(&commands.ClusterShardsCommand{})
+	case "NODES":
+		return noArgs(&commands.ClusterNodesCommand{})
+	case "INFO":
+		return noArgs(&commands.ClusterInfoCommand{})
+	case "MYID":
+		return noArgs(&commands.ClusterMyIDCommand{})
+	case "MEET":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("CLUSTER MEET requires exactly 2 arguments")
+		}
+		port, err := strconv.Atoi(args[1])
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("Invalid node port specified: %s", args[1])
+		}
+		return &commands.ClusterMeetCommand{Host: args[0], Port: port}, nil
+	case "SETSLOT":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("CLUSTER SETSLOT requires a slot and an action")
+		}
+		slot, err := cluster.ParseSlot(args[0])
+		if err != nil {
+			return nil, err
+		}
+		setSlot := &commands.ClusterSetSlotCommand{Slot: slot, State: strings.ToUpper(args[1])}
+		switch setSlot.State {
+		case cluster.SlotImporting, cluster.SlotMigrating, cluster.SlotNode:
+			if len(args) != 3 {
+				return nil, fmt.Errorf("CLUSTER SETSLOT %s requires a node ID", setSlot.State)
+			}
+			setSlot.NodeID = args[2]
+		case cluster.SlotStable:
+			if len(args) != 2 {
+				return nil, fmt.Errorf("CLUSTER SETSLOT STABLE takes no node ID")
+			}
+		default:
+			return nil, fmt.Errorf("Invalid CLUSTER SETSLOT action or number of arguments")
+		}
+		return setSlot, nil
+	case "GETKEYSINSLOT":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("CLUSTER GETKEYSINSLOT requires exactly 2 arguments")
+		}
+		slot, err := cluster.ParseSlot(args[0])
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.Atoi(args[1])
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("Invalid number of keys")
+		}
+		return &commands.ClusterGetKeysInSlotCommand{Slot: slot, Count: count}, nil
+	case "COUNTKEYSINSLOT":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("CLUSTER COUNTKEYSINSLOT requires exactly 1 argument")
+		}
+		slot, err := cluster.ParseSlot(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &commands.ClusterCountKeysInSlotCommand{Slot: slot}, nil
+	default:
+		return nil, fmt.Errorf("unknown CLUSTER subcommand '%s'", sub)
+	}
+}
+
+// createMigrateCommand builds MIGRATE from
+//
+//	host port key|"" destination-db timeout [COPY] [REPLACE] [KEYS key ...]
+func createMigrateCommand(args []string) (commands.Command, error) {
+	if len(args) < 5 {
+		return nil, fmt.Errorf("MIGRATE command requires at least 5 arguments")
+	}
+	if _, err := strconv.Atoi(args[1]); err != nil {
+		return nil, fmt.Errorf("invalid target port")
+	}
+	if args[3] != "0" {
+		return nil, fmt.Errorf("only destination-db 0 is supported")
+	}
+	timeout, err := strconv.ParseInt(args[4], 10, 64)
+	if err != nil || timeout < 0 {
+		return nil, fmt.Errorf("timeout is not an integer or out of range")
+	}
+
+	migrate := &commands.MigrateCommand{
+		Host:    args[0],
+		Port:    args[1],
+		Timeout: time.Duration(timeout) * time.Millisecond,
+	}
+	if args[2] != "" {
+		migrate.Keys = []string{args[2]}
+	}
+
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "COPY":
+			migrate.Copy = true
+		case "REPLACE":
+			migrate.Replace = true
+		case "KEYS":
+			if args[2] != "" {
+				return nil, fmt.Errorf("When using MIGRATE KEYS option, the key argument must be set to the empty string")
+			}
+			migrate.Keys = args[i+1:]
+			i = len(args)
+		default:
+			return nil, fmt.Errorf("syntax error")
+		}
+	}
+
+	if len(migrate.Keys) == 0 {
+		return nil, fmt.Errorf("MIGRATE requires a key or the KEYS option")
+	}
+	return migrate, nil
+}
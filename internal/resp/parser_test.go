@@ -0,0 +1,72 @@
+package resp
+
+import (
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/commands"
+)
+
+func TestNewCommand_ZRangeLimit(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"ZRANGE", "z", "0", "-1", "LIMIT", "0", "1"}, "syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"},
+		{[]string{"ZRANGE", "z", "0", "-1", "REV", "LIMIT", "0", "-1"}, "syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"},
+		{[]string{"ZRANGE", "z", "0", "10", "BYSCORE", "LIMIT", "0", "-1"}, ""},
+		{[]string{"ZRANGEBYLEX", "z", "-", "+", "LIMIT", "2", "0"}, ""},
+		{[]string{"ZRANGE", "z", "0", "10"}, ""},
+	}
+
+	for _, tt := range tests {
+		cmd, err := NewCommand(ByteArgs(tt.args))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("NewCommand(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewCommand(%v) error = %v", tt.args, err)
+			continue
+		}
+		if _, ok := cmd.(*commands.ZRangeCommand); !ok {
+			t.Errorf("NewCommand(%v) = %T, want *commands.ZRangeCommand", tt.args, cmd)
+		}
+	}
+}
+
+func TestNewCommand_ExpireTime(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"SET", "k", "v", "EX", "0"}, "invalid expire time in 'set' command"},
+		{[]string{"SET", "k", "v", "EX", "9223372036854775807"}, "invalid expire time in 'set' command"},
+		{[]string{"SET", "k", "v", "PX", "9223372036854775807"}, "invalid expire time in 'set' command"},
+		{[]string{"SET", "k", "v", "PXAT", "9223372036854775807"}, ""},
+		{[]string{"SETEX", "k", "-1", "v"}, "invalid expire time in 'setex' command"},
+		{[]string{"PSETEX", "k", "9223372036854775807", "v"}, "invalid expire time in 'psetex' command"},
+		{[]string{"GETEX", "k", "EXAT", "9223372036854775807"}, "invalid expire time in 'getex' command"},
+		{[]string{"HGETEX", "h", "EX", "0", "FIELDS", "1", "f"}, ""},
+		{[]string{"HGETEX", "h", "EX", "-1", "FIELDS", "1", "f"}, "invalid expire time in 'hgetex' command"},
+		{[]string{"HGETEX", "h", "EX", "9223372036854775807", "FIELDS", "1", "f"}, "invalid expire time in 'hgetex' command"},
+		{[]string{"HSETEX", "h", "EXAT", "9223372036854775807", "FIELDS", "1", "f", "v"}, "invalid expire time in 'hsetex' command"},
+		{[]string{"HSETEX", "h", "PX", "9223372036854775807", "FIELDS", "1", "f", "v"}, "invalid expire time in 'hsetex' command"},
+		{[]string{"HSETEX", "h", "PXAT", "9223372036854775807", "FIELDS", "1", "f", "v"}, ""},
+		{[]string{"HSETEX", "h", "PX", "100", "FIELDS", "1", "f", "v"}, ""},
+	}
+
+	for _, tt := range tests {
+		_, err := NewCommand(ByteArgs(tt.args))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("NewCommand(%v) error = %v", tt.args, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("NewCommand(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package resp
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/hardikphalet/go-redis/internal/types"
 )
@@ -28,9 +31,16 @@ func (w *Writer) WriteString(s string) error {
 	return w.writer.Flush()
 }
 
-// WriteError writes a RESP Error ("-Error message\r\n")
+// WriteError writes a RESP Error ("-Error message\r\n"). Errors are prefixed
+// with ERR unless they are a *types.Error carrying their own code.
 func (w *Writer) WriteError(err error) error {
-	_, err2 := fmt.Fprintf(w.writer, "-ERR %s\r\n", err.Error())
+	prefix := "ERR "
+	var coded *types.Error
+	if errors.As(err, &coded) {
+		prefix = ""
+	}
+
+	_, err2 := fmt.Fprintf(w.writer, "-%s%s\r\n", prefix, err.Error())
 	if err2 != nil {
 		return err2
 	}
@@ -46,25 +56,49 @@ func (w *Writer) WriteInteger(i int64) error {
 	return w.writer.Flush()
 }
 
-// WriteBulkString writes a RESP Bulk String ("$5\r\nhello\r\n")
+// WriteBulkString writes a RESP Bulk String ("$5\r\nhello\r\n"). The
+// string is written byte for byte, so it may hold any binary data; an empty
+// string is encoded as "$0\r\n\r\n".
 func (w *Writer) WriteBulkString(s string) error {
-	if s == "" {
-		// Empty string is encoded as "$0\r\n\r\n"
-		_, err := fmt.Fprintf(w.writer, "$0\r\n\r\n")
-		if err != nil {
-			return err
-		}
-		return w.writer.Flush()
+	if err := w.writeBulkHeader(len(s)); err != nil {
+		return err
 	}
+	if _, err := w.writer.WriteString(s); err != nil {
+		return err
+	}
+	if _, err := w.writer.WriteString("\r\n"); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
 
-	// Write the length prefix
-	_, err := fmt.Fprintf(w.writer, "$%d\r\n%s\r\n", len(s), s)
-	if err != nil {
+// WriteBulkBytes writes b as a RESP Bulk String, or a RESP Null if b is nil
+func (w *Writer) WriteBulkBytes(b []byte) error {
+	if b == nil {
+		return w.WriteNull()
+	}
+	if err := w.writeBulkHeader(len(b)); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(b); err != nil {
+		return err
+	}
+	if _, err := w.writer.WriteString("\r\n"); err != nil {
 		return err
 	}
 	return w.writer.Flush()
 }
 
+// writeBulkHeader writes the "$<length>\r\n" prefix of a bulk string
+func (w *Writer) writeBulkHeader(length int) error {
+	var buf [24]byte
+	header := append(buf[:0], '$')
+	header = strconv.AppendInt(header, int64(length), 10)
+	header = append(header, '\r', '\n')
+	_, err := w.writer.Write(header)
+	return err
+}
+
 // WriteNull writes a RESP Null value ("$-1\r\n")
 func (w *Writer) WriteNull() error {
 	_, err := fmt.Fprintf(w.writer, "$-1\r\n")
@@ -144,12 +178,16 @@ func (w *Writer) WriteInterface(v interface{}) error {
 		return w.WriteString(string(val))
 	case string:
 		return w.WriteBulkString(val)
+	case []byte:
+		return w.WriteBulkBytes(val)
 	case []string:
 		return w.WriteArray(val)
 	case int:
 		return w.WriteInteger(int64(val))
 	case int64:
 		return w.WriteInteger(val)
+	case float64:
+		return w.WriteBulkString(formatFloat(val))
 	case error:
 		return w.WriteError(val)
 	case []interface{}:
@@ -157,6 +195,48 @@ func (w *Writer) WriteInterface(v interface{}) error {
 	case map[string]interface{}:
 		return w.WriteMap(val)
 	default:
-		return w.WriteBulkString(fmt.Sprintf("%v", v))
+		// Replies must be built from the types above; guessing at the
+		// encoding of anything else could corrupt binary values
+		return w.WriteError(fmt.Errorf("unsupported reply type %T", v))
 	}
 }
+
+// formatFloat formats a float reply such as a sorted set score the way Redis
+// does: integers that a float holds exactly in plain decimal, other values
+// in the shortest form that parses back to the same value, with an exponent
+// only for very large or small magnitudes, and infinities as "inf" and
+// "-inf".
+func formatFloat(v float64) string {
+	const maxExact = 1 << 53
+	abs := math.Abs(v)
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	case v == 0 && math.Signbit(v):
+		return "-0"
+	case v == math.Trunc(v) && abs < maxExact:
+		return strconv.FormatInt(int64(v), 10)
+	case abs >= 1e-4 && abs < 1e17:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// EncodeCommand returns args encoded as a RESP array of bulk strings, the
+// form in which clients send commands.
+func EncodeCommand(args []string) []byte {
+	buf := make([]byte, 0, 16*len(args)+16)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
@@ -0,0 +1,33 @@
+package resp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{1234567, "1234567"},
+		{3479099956230698, "3479099956230698"},
+		{-42, "-42"},
+		{math.Copysign(0, -1), "-0"},
+		{1.5, "1.5"},
+		{0.1, "0.1"},
+		{56.44134, "56.44134"},
+		{123456789.123, "123456789.123"},
+		{0.0001, "0.0001"},
+		{1e-7, "1e-07"},
+		{1e20, "1e+20"},
+		{math.Inf(1), "inf"},
+		{math.Inf(-1), "-inf"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFloat(tt.v); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
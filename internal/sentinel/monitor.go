@@ -0,0 +1,517 @@
+package sentinel
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+var (
+	replyPong = types.SimpleString("PONG")
+	replyOK   = types.SimpleString("OK")
+
+	errNoSuchPrimary = errors.New("No such master with that name")
+)
+
+// monitor is the state kept for one monitored primary and its replicas.
+// All fields are guarded by Sentinel.mu.
+type monitor struct {
+	name        string
+	quorum      int
+	primary     string // Address of the current primary
+	configEpoch uint64 // Epoch of the failover that produced the current primary
+	instances   map[string]*instance
+
+	// reconfigure holds instances that must be pointed at the primary after
+	// a failover, including the old primary once it comes back.
+	reconfigure map[string]bool
+
+	odown       bool
+	leader      string // Sentinel voted for in leaderEpoch
+	leaderEpoch uint64
+	nextAttempt time.Time // Earliest time this sentinel may start a failover
+}
+
+// instance is what a sentinel knows about a primary or replica.
+type instance struct {
+	addr       string
+	lastOK     time.Time // Last valid PING reply
+	role       string    // "master" or "slave" as reported by INFO
+	masterAddr string    // Primary a replica replicates from
+	offset     int64
+}
+
+func newMonitor(mc MonitorConfig) *monitor {
+	m := &monitor{
+		name:        mc.Name,
+		quorum:      mc.Quorum,
+		primary:     mc.Addr,
+		instances:   make(map[string]*instance),
+		reconfigure: make(map[string]bool),
+	}
+	m.instance(mc.Addr)
+	return m
+}
+
+// instance returns the state of addr, adding it if it is new. New instances
+// get a grace period before they can be considered down.
+func (m *monitor) instance(addr string) *instance {
+	inst, ok := m.instances[addr]
+	if !ok {
+		inst = &instance{addr: addr, lastOK: time.Now()}
+		m.instances[addr] = inst
+	}
+	return inst
+}
+
+func (s *Sentinel) down(inst *instance) bool {
+	return time.Since(inst.lastOK) > s.cfg.DownAfter
+}
+
+// setPrimaryLocked makes addr the primary of m as of epoch. The previous
+// primary and every other replica are reconfigured to follow it.
+func (s *Sentinel) setPrimaryLocked(m *monitor, addr string, epoch uint64) {
+	old := m.primary
+	m.primary = addr
+	m.configEpoch = epoch
+	m.odown = false
+	m.nextAttempt = time.Time{}
+	m.instance(addr)
+	delete(m.reconfigure, addr)
+	for other := range m.instances {
+		if other != addr {
+			m.reconfigure[other] = true
+		}
+	}
+	if epoch > s.currentEpoch {
+		s.currentEpoch = epoch
+	}
+	log.Printf("Sentinel %s: primary %s switched from %s to %s in epoch %d", s.id, m.name, old, addr, epoch)
+}
+
+func (s *Sentinel) monitorLoop(m *monitor) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.check(m)
+		}
+	}
+}
+
+// check probes every instance of m, reconfigures replicas that follow the
+// wrong primary, shares the configuration with the other sentinels and
+// starts a failover once enough of them agree that the primary is down.
+func (s *Sentinel) check(m *monitor) {
+	s.mu.Lock()
+	addrs := make([]string, 0, len(m.instances))
+	for addr := range m.instances {
+		addrs = append(addrs, addr)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			s.probe(m, addr)
+		}(addr)
+	}
+	wg.Wait()
+
+	s.reconfigureReplicas(m)
+	s.sendHello(m)
+
+	s.mu.Lock()
+	down := s.down(m.instances[m.primary])
+	if !down {
+		if m.odown {
+			log.Printf("Sentinel %s: primary %s is reachable again", s.id, m.name)
+		}
+		m.odown = false
+		s.mu.Unlock()
+		return
+	}
+	primary, quorum := m.primary, m.quorum
+	s.mu.Unlock()
+
+	votes := 1
+	for _, down := range s.askPeers(primary, "*") {
+		if down.down {
+			votes++
+		}
+	}
+
+	s.mu.Lock()
+	if m.primary != primary {
+		s.mu.Unlock()
+		return
+	}
+	if votes < quorum {
+		m.odown = false
+		s.mu.Unlock()
+		return
+	}
+	if !m.odown {
+		log.Printf("Sentinel %s: primary %s at %s is objectively down (%d/%d)", s.id, m.name, primary, votes, quorum)
+		m.odown = true
+		if m.nextAttempt.IsZero() {
+			// Spread the sentinels' attempts so one of them usually wins the
+			// election in the first round.
+			m.nextAttempt = time.Now().Add(s.jitter())
+		}
+	}
+	due := !time.Now().Before(m.nextAttempt)
+	s.mu.Unlock()
+
+	if due {
+		s.failover(m, primary)
+	}
+}
+
+func (s *Sentinel) jitter() time.Duration {
+	return time.Duration(rand.Int63n(int64(s.cfg.DownAfter)))
+}
+
+// probe pings addr and refreshes its replication state from INFO. Replicas
+// listed by the primary are added to the monitored instances.
+func (s *Sentinel) probe(m *monitor, addr string) {
+	if reply, err := s.call(addr, "PING"); err != nil || reply != "PONG" {
+		return
+	}
+	reply, err := s.call(addr, "INFO", "replication")
+	info, _ := reply.(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := m.instances[addr]
+	if !ok {
+		return
+	}
+	inst.lastOK = time.Now()
+	if err != nil {
+		return
+	}
+
+	fields := parseInfo(info)
+	inst.role = fields["role"]
+	inst.masterAddr = ""
+	if inst.role == "slave" {
+		inst.masterAddr = net.JoinHostPort(fields["master_host"], fields["master_port"])
+		inst.offset, _ = strconv.ParseInt(fields["slave_repl_offset"], 10, 64)
+		return
+	}
+	inst.offset, _ = strconv.ParseInt(fields["master_repl_offset"], 10, 64)
+
+	if addr != m.primary {
+		return
+	}
+	for key, value := range fields {
+		if !strings.HasPrefix(key, "slave") || strings.Contains(key, "_") {
+			continue
+		}
+		replica := parseInfoList(value)
+		if replica["ip"] != "" && replica["port"] != "" {
+			m.instance(net.JoinHostPort(replica["ip"], replica["port"]))
+		}
+	}
+}
+
+// parseInfo parses the "key:value" lines of an INFO reply.
+func parseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// parseInfoList parses an INFO value of the form "a=1,b=2".
+func parseInfoList(value string) map[string]string {
+	fields := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// reconfigureReplicas points instances awaiting reconfiguration at the
+// current primary, once both are reachable.
+func (s *Sentinel) reconfigureReplicas(m *monitor) {
+	s.mu.Lock()
+	primary := m.primary
+	var pending []string
+	if !s.down(m.instances[primary]) {
+		for addr := range m.reconfigure {
+			inst := m.instances[addr]
+			switch {
+			case inst.role == "slave" && inst.masterAddr == primary:
+				delete(m.reconfigure, addr)
+			case inst.role != "" && !s.down(inst):
+				pending = append(pending, addr)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	host, port, _ := net.SplitHostPort(primary)
+	for _, addr := range pending {
+		if _, err := s.call(addr, "REPLICAOF", host, port); err != nil {
+			log.Printf("Sentinel %s: failed to point %s at %s: %v", s.id, addr, primary, err)
+			continue
+		}
+		log.Printf("Sentinel %s: %s now replicates from %s", s.id, addr, primary)
+	}
+}
+
+// sendHello shares the current primary of m and its epoch with the other
+// sentinels, so that they learn about failovers they did not perform.
+func (s *Sentinel) sendHello(m *monitor) {
+	s.mu.Lock()
+	host, port, _ := net.SplitHostPort(m.primary)
+	epoch := strconv.FormatUint(m.configEpoch, 10)
+	s.mu.Unlock()
+
+	for _, peer := range s.cfg.Peers {
+		s.call(peer, "SENTINEL", "HELLO", m.name, host, port, epoch)
+	}
+}
+
+// hello adopts the configuration announced by another sentinel if it comes
+// from a newer epoch.
+func (s *Sentinel) hello(name, primary string, epoch uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.monitors[name]
+	if !ok || epoch <= m.configEpoch || primary == m.primary {
+		return
+	}
+	s.setPrimaryLocked(m, primary, epoch)
+}
+
+type peerVote struct {
+	down        bool
+	leader      string
+	leaderEpoch uint64
+}
+
+// askPeers asks every other sentinel whether primary is down. A runID other
+// than "*" also requests their vote to lead the failover in the current
+// epoch.
+func (s *Sentinel) askPeers(primary, runID string) []peerVote {
+	host, port, _ := net.SplitHostPort(primary)
+	s.mu.Lock()
+	epoch := strconv.FormatUint(s.currentEpoch, 10)
+	s.mu.Unlock()
+
+	votes := make([]peerVote, len(s.cfg.Peers))
+	var wg sync.WaitGroup
+	for i, peer := range s.cfg.Peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			reply, err := s.call(peer, "SENTINEL", "IS-PRIMARY-DOWN-BY-ADDR", host, port, epoch, runID)
+			if err != nil {
+				return
+			}
+			fields, ok := reply.([]interface{})
+			if !ok || len(fields) != 3 {
+				return
+			}
+			down, _ := fields[0].(int)
+			leader, _ := fields[1].(string)
+			leaderEpoch, _ := fields[2].(int)
+			votes[i] = peerVote{down: down == 1, leader: leader, leaderEpoch: uint64(leaderEpoch)}
+		}(i, peer)
+	}
+	wg.Wait()
+	return votes
+}
+
+// isPrimaryDown answers a peer asking whether the primary at addr is down.
+// If runID is not "*", the peer also asks for our vote in epoch: it is
+// granted to the first sentinel asking in each epoch.
+func (s *Sentinel) isPrimaryDown(addr string, epoch uint64, runID string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var m *monitor
+	for _, candidate := range s.monitors {
+		if candidate.primary == addr {
+			m = candidate
+			break
+		}
+	}
+	if m == nil {
+		return []interface{}{0, "*", 0}
+	}
+
+	down := 0
+	if s.down(m.instances[m.primary]) {
+		down = 1
+	}
+	if runID == "*" {
+		return []interface{}{down, "*", 0}
+	}
+
+	if epoch > s.currentEpoch {
+		s.currentEpoch = epoch
+	}
+	if m.leaderEpoch < epoch && s.currentEpoch <= epoch {
+		m.leader, m.leaderEpoch = runID, epoch
+		if runID != s.id {
+			// Give the sentinel we voted for time to complete the failover.
+			m.nextAttempt = time.Now().Add(s.cfg.FailoverTimeout + s.jitter())
+		}
+	}
+	return []interface{}{down, m.leader, int(m.leaderEpoch)}
+}
+
+// failover runs an election for the right to fail over primary and, if this
+// sentinel wins, promotes the best replica.
+func (s *Sentinel) failover(m *monitor, primary string) {
+	s.mu.Lock()
+	s.currentEpoch++
+	epoch := s.currentEpoch
+	m.leader, m.leaderEpoch = s.id, epoch
+	m.nextAttempt = time.Now().Add(s.cfg.FailoverTimeout + s.jitter())
+	s.mu.Unlock()
+
+	votes := 1
+	for _, vote := range s.askPeers(primary, s.id) {
+		if vote.leader == s.id && vote.leaderEpoch == epoch {
+			votes++
+		}
+	}
+	needed := max(m.quorum, (len(s.cfg.Peers)+1)/2+1)
+	if votes < needed {
+		log.Printf("Sentinel %s: lost the election for %s in epoch %d (%d/%d votes)", s.id, m.name, epoch, votes, needed)
+		return
+	}
+	log.Printf("Sentinel %s: elected to fail over %s in epoch %d", s.id, m.name, epoch)
+
+	s.mu.Lock()
+	if m.primary != primary {
+		s.mu.Unlock()
+		return
+	}
+	candidate := s.selectReplicaLocked(m)
+	s.mu.Unlock()
+	if candidate == "" {
+		log.Printf("Sentinel %s: no suitable replica to promote for %s", s.id, m.name)
+		return
+	}
+
+	if _, err := s.call(candidate, "REPLICAOF", "NO", "ONE"); err != nil {
+		log.Printf("Sentinel %s: failed to promote %s: %v", s.id, candidate, err)
+		return
+	}
+
+	s.mu.Lock()
+	if m.primary == primary {
+		s.setPrimaryLocked(m, candidate, epoch)
+	}
+	s.mu.Unlock()
+
+	s.reconfigureReplicas(m)
+	s.sendHello(m)
+}
+
+// selectReplicaLocked picks the reachable replica of the current primary
+// with the highest replication offset, breaking ties by address.
+func (s *Sentinel) selectReplicaLocked(m *monitor) string {
+	var candidates []*instance
+	for addr, inst := range m.instances {
+		if addr == m.primary || s.down(inst) || inst.role != "slave" || inst.masterAddr != m.primary {
+			continue
+		}
+		candidates = append(candidates, inst)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].offset != candidates[j].offset {
+			return candidates[i].offset > candidates[j].offset
+		}
+		return candidates[i].addr < candidates[j].addr
+	})
+	return candidates[0].addr
+}
+
+// primaryReplyLocked returns the SENTINEL PRIMARY reply for m.
+func (s *Sentinel) primaryReplyLocked(m *monitor) []interface{} {
+	host, port, _ := net.SplitHostPort(m.primary)
+	flags := []string{"master"}
+	if s.down(m.instances[m.primary]) {
+		flags = append(flags, "s_down")
+	}
+	if m.odown {
+		flags = append(flags, "o_down")
+	}
+	return []interface{}{
+		"name", m.name,
+		"ip", host,
+		"port", port,
+		"flags", strings.Join(flags, ","),
+		"num-slaves", strconv.Itoa(len(m.instances) - 1),
+		"quorum", strconv.Itoa(m.quorum),
+		"config-epoch", strconv.FormatUint(m.configEpoch, 10),
+	}
+}
+
+// replicasReplyLocked returns the SENTINEL REPLICAS reply for m.
+func (s *Sentinel) replicasReplyLocked(m *monitor) []interface{} {
+	addrs := make([]string, 0, len(m.instances))
+	for addr := range m.instances {
+		if addr != m.primary {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+
+	replies := make([]interface{}, 0, len(addrs))
+	for _, addr := range addrs {
+		inst := m.instances[addr]
+		host, port, _ := net.SplitHostPort(addr)
+		flags := "slave"
+		if s.down(inst) {
+			flags += ",s_down"
+		}
+		replies = append(replies, []interface{}{
+			"name", addr,
+			"ip", host,
+			"port", port,
+			"flags", flags,
+			"role-reported", inst.role,
+			"master-addr", inst.masterAddr,
+			"slave-repl-offset", strconv.FormatInt(inst.offset, 10),
+		})
+	}
+	return replies
+}
@@ -0,0 +1,385 @@
+// Package sentinel implements a failover monitor for primary/replica groups.
+// A set of sentinels watches each named primary, agrees on a quorum that it
+// is down, elects one of them to promote the best replica and tells clients
+// where the current primary is.
+package sentinel
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/resp"
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+// MonitorConfig describes a primary to monitor.
+type MonitorConfig struct {
+	Name   string
+	Addr   string
+	Quorum int // Sentinels that must agree the primary is down before a failover
+}
+
+// ParseMonitor parses a monitor in the "<name> <host> <port> <quorum>" form
+// of Redis's "sentinel monitor" directive.
+func ParseMonitor(spec string) (MonitorConfig, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 4 {
+		return MonitorConfig{}, fmt.Errorf("invalid monitor %q, want <name> <host> <port> <quorum>", spec)
+	}
+	if _, err := strconv.Atoi(fields[2]); err != nil {
+		return MonitorConfig{}, fmt.Errorf("invalid port in monitor %q", spec)
+	}
+	quorum, err := strconv.Atoi(fields[3])
+	if err != nil || quorum < 1 {
+		return MonitorConfig{}, fmt.Errorf("invalid quorum in monitor %q", spec)
+	}
+	return MonitorConfig{
+		Name:   fields[0],
+		Addr:   net.JoinHostPort(fields[1], fields[2]),
+		Quorum: quorum,
+	}, nil
+}
+
+// Config holds the settings of a sentinel.
+type Config struct {
+	Addr     string
+	ID       string   // Run ID used in leader elections; random if empty
+	Peers    []string // Addresses of the other sentinels
+	Monitors []MonitorConfig
+
+	PingInterval    time.Duration // How often instances and peers are contacted
+	DownAfter       time.Duration // Silence after which an instance is considered down
+	FailoverTimeout time.Duration // Minimum time between failover attempts for a primary
+}
+
+func (c *Config) setDefaults() {
+	if c.PingInterval <= 0 {
+		c.PingInterval = time.Second
+	}
+	if c.DownAfter <= 0 {
+		c.DownAfter = 5 * time.Second
+	}
+	if c.FailoverTimeout <= 0 {
+		c.FailoverTimeout = 30 * time.Second
+	}
+}
+
+// Sentinel monitors primaries and fails them over.
+type Sentinel struct {
+	cfg Config
+	id  string
+
+	mu           sync.Mutex
+	currentEpoch uint64
+	monitors     map[string]*monitor
+
+	connsMu sync.Mutex
+	conns   map[string]*instanceConn
+
+	listener  net.Listener
+	clientsMu sync.Mutex
+	clients   map[net.Conn]struct{}
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	stopOnce  sync.Once
+}
+
+// instanceConn serializes commands on the connection to one address.
+type instanceConn struct {
+	mu     sync.Mutex
+	client *client.Client
+}
+
+// New creates a sentinel monitoring the primaries in cfg.
+func New(cfg Config) (*Sentinel, error) {
+	cfg.setDefaults()
+
+	id := cfg.ID
+	if id == "" {
+		b := make([]byte, 20)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+
+	s := &Sentinel{
+		cfg:      cfg,
+		id:       id,
+		monitors: make(map[string]*monitor),
+		conns:    make(map[string]*instanceConn),
+		clients:  make(map[net.Conn]struct{}),
+		quit:     make(chan struct{}),
+	}
+	for _, mc := range cfg.Monitors {
+		if _, dup := s.monitors[mc.Name]; dup {
+			return nil, fmt.Errorf("primary %q monitored twice", mc.Name)
+		}
+		s.monitors[mc.Name] = newMonitor(mc)
+	}
+	return s, nil
+}
+
+// ID returns the run ID of the sentinel.
+func (s *Sentinel) ID() string {
+	return s.id
+}
+
+// Start begins serving clients and monitoring the primaries.
+func (s *Sentinel) Start() error {
+	l, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to start sentinel: %w", err)
+	}
+	s.listener = l
+	log.Printf("Sentinel %s listening on %s", s.id, s.cfg.Addr)
+
+	s.wg.Add(1)
+	go s.acceptConnections()
+	for _, m := range s.monitors {
+		s.wg.Add(1)
+		go s.monitorLoop(m)
+	}
+	return nil
+}
+
+// Stop stops monitoring and closes every connection.
+func (s *Sentinel) Stop() error {
+	var err error
+	s.stopOnce.Do(func() {
+		close(s.quit)
+		if s.listener != nil {
+			err = s.listener.Close()
+		}
+
+		s.clientsMu.Lock()
+		for conn := range s.clients {
+			conn.Close()
+		}
+		s.clientsMu.Unlock()
+
+		s.connsMu.Lock()
+		for addr, c := range s.conns {
+			c.mu.Lock()
+			if c.client != nil {
+				c.client.Close()
+				c.client = nil
+			}
+			c.mu.Unlock()
+			delete(s.conns, addr)
+		}
+		s.connsMu.Unlock()
+
+		s.wg.Wait()
+	})
+	return err
+}
+
+// timeout bounds each command sent to an instance or a peer.
+func (s *Sentinel) timeout() time.Duration {
+	return s.cfg.DownAfter / 2
+}
+
+// call sends a command to addr and returns its reply. Error replies are
+// returned as errors. Connections are kept open between calls and dropped
+// on failure.
+func (s *Sentinel) call(addr string, args ...string) (interface{}, error) {
+	s.connsMu.Lock()
+	select {
+	case <-s.quit:
+		s.connsMu.Unlock()
+		return nil, errors.New("sentinel stopped")
+	default:
+	}
+	c, ok := s.conns[addr]
+	if !ok {
+		c = &instanceConn{}
+		s.conns[addr] = c
+	}
+	s.connsMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		cl, err := client.DialTimeout(addr, s.timeout())
+		if err != nil {
+			return nil, err
+		}
+		c.client = cl
+	}
+
+	c.client.SetDeadline(time.Now().Add(s.timeout()))
+	reply, err := c.roundTrip(args)
+	if err != nil {
+		c.client.Close()
+		c.client = nil
+		return nil, err
+	}
+	if replyErr, ok := reply.(error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+func (c *instanceConn) roundTrip(args []string) (interface{}, error) {
+	if err := c.client.Send(args[0], args[1:]...); err != nil {
+		return nil, err
+	}
+	return c.client.Receive()
+}
+
+func (s *Sentinel) acceptConnections() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+				log.Printf("Error accepting connection: %v", err)
+				continue
+			}
+		}
+
+		s.clientsMu.Lock()
+		select {
+		case <-s.quit:
+			s.clientsMu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		s.clients[conn] = struct{}{}
+		s.clientsMu.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConnection(conn)
+	}
+}
+
+func (s *Sentinel) handleConnection(conn net.Conn) {
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, conn)
+		s.clientsMu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	writer := bufio.NewWriter(conn)
+	parser := resp.NewParser(bufio.NewReader(conn))
+	respWriter := resp.NewWriter(writer)
+
+	for {
+		args, err := parser.ReadArgs()
+		if err != nil {
+			return
+		}
+
+		reply, err := s.execute(resp.StringArgs(args))
+		if err != nil {
+			err = respWriter.WriteError(err)
+		} else {
+			err = respWriter.WriteInterface(reply)
+		}
+		if err != nil {
+			return
+		}
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// execute runs a command received from a client or a peer sentinel.
+func (s *Sentinel) execute(args []string) (interface{}, error) {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return replyPong, nil
+	case "SENTINEL":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel' command")
+		}
+		return s.executeSentinel(strings.ToUpper(args[1]), args[2:])
+	default:
+		return nil, fmt.Errorf("unknown command '%s'", args[0])
+	}
+}
+
+func (s *Sentinel) executeSentinel(sub string, args []string) (interface{}, error) {
+	switch sub {
+	case "MYID":
+		return s.id, nil
+
+	case "GET-PRIMARY-ADDR-BY-NAME", "GET-MASTER-ADDR-BY-NAME":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel|%s' command", strings.ToLower(sub))
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		m, ok := s.monitors[args[0]]
+		if !ok {
+			return nil, nil
+		}
+		host, port, _ := net.SplitHostPort(m.primary)
+		return []interface{}{host, port}, nil
+
+	case "PRIMARY", "MASTER":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel|%s' command", strings.ToLower(sub))
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		m, ok := s.monitors[args[0]]
+		if !ok {
+			return nil, errNoSuchPrimary
+		}
+		return s.primaryReplyLocked(m), nil
+
+	case "REPLICAS", "SLAVES":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel|%s' command", strings.ToLower(sub))
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		m, ok := s.monitors[args[0]]
+		if !ok {
+			return nil, errNoSuchPrimary
+		}
+		return s.replicasReplyLocked(m), nil
+
+	case "IS-PRIMARY-DOWN-BY-ADDR", "IS-MASTER-DOWN-BY-ADDR":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel|%s' command", strings.ToLower(sub))
+		}
+		epoch, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch")
+		}
+		return s.isPrimaryDown(net.JoinHostPort(args[0], args[1]), epoch, args[3]), nil
+
+	case "HELLO":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("wrong number of arguments for 'sentinel|hello' command")
+		}
+		epoch, err := strconv.ParseUint(args[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch")
+		}
+		s.hello(args[0], net.JoinHostPort(args[1], args[2]), epoch)
+		return replyOK, nil
+
+	default:
+		return nil, fmt.Errorf("unknown sentinel subcommand '%s'", strings.ToLower(sub))
+	}
+}
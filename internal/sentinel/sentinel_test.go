@@ -0,0 +1,262 @@
+package sentinel
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/server"
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+func TestParseMonitor(t *testing.T) {
+	mc, err := ParseMonitor("mymaster 127.0.0.1 6379 2")
+	if err != nil {
+		t.Fatalf("ParseMonitor() error = %v", err)
+	}
+	if mc != (MonitorConfig{Name: "mymaster", Addr: "127.0.0.1:6379", Quorum: 2}) {
+		t.Errorf("ParseMonitor() = %+v", mc)
+	}
+
+	for _, spec := range []string{"", "mymaster 127.0.0.1 6379", "mymaster 127.0.0.1 port 2", "mymaster 127.0.0.1 6379 0"} {
+		if _, err := ParseMonitor(spec); err == nil {
+			t.Errorf("ParseMonitor(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Replication\r\nrole:master\r\nconnected_slaves:1\r\nslave0:ip=127.0.0.1,port=7001,state=online,offset=42\r\n"
+	fields := parseInfo(info)
+	if fields["role"] != "master" || fields["connected_slaves"] != "1" {
+		t.Errorf("parseInfo() = %v", fields)
+	}
+	replica := parseInfoList(fields["slave0"])
+	if replica["ip"] != "127.0.0.1" || replica["port"] != "7001" || replica["offset"] != "42" {
+		t.Errorf("parseInfoList() = %v", replica)
+	}
+}
+
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func startServer(t *testing.T, addr string) *server.Server {
+	t.Helper()
+	cfg := server.DefaultConfig(addr)
+	cfg.Dir = t.TempDir()
+	s := server.NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server on %s: %v", addr, err)
+	}
+	return s
+}
+
+// do sends one command to addr over a fresh connection.
+func do(t *testing.T, addr string, args ...string) interface{} {
+	t.Helper()
+	c, err := client.NewClient(addr)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", addr, err)
+	}
+	defer c.Close()
+	if err := c.Send(args[0], args[1:]...); err != nil {
+		t.Fatalf("Send(%v) error = %v", args, err)
+	}
+	reply, err := c.Receive()
+	if err != nil {
+		t.Fatalf("Receive(%v) error = %v", args, err)
+	}
+	return reply
+}
+
+func replicatesFrom(t *testing.T, addr, primary string) bool {
+	info, _ := do(t, addr, "INFO", "replication").(string)
+	fields := parseInfo(info)
+	return fields["role"] == "slave" &&
+		net.JoinHostPort(fields["master_host"], fields["master_port"]) == primary &&
+		fields["master_link_status"] == "up"
+}
+
+func primaryAddr(t *testing.T, sentinel string) string {
+	reply, ok := do(t, sentinel, "SENTINEL", "GET-PRIMARY-ADDR-BY-NAME", "mymaster").([]interface{})
+	if !ok || len(reply) != 2 {
+		return ""
+	}
+	return fmt.Sprintf("%v:%v", reply[0], reply[1])
+}
+
+func TestSentinel_Failover(t *testing.T) {
+	primary := "127.0.0.1:6430"
+	replicas := []string{"127.0.0.1:6431", "127.0.0.1:6432"}
+	sentinels := []string{"127.0.0.1:6433", "127.0.0.1:6434", "127.0.0.1:6435"}
+
+	servers := map[string]*server.Server{primary: startServer(t, primary)}
+	for _, addr := range replicas {
+		servers[addr] = startServer(t, addr)
+		if got := do(t, addr, "REPLICAOF", "127.0.0.1", "6430"); got != "OK" {
+			t.Fatalf("REPLICAOF = %v, want OK", got)
+		}
+	}
+	defer func() {
+		for _, s := range servers {
+			s.Stop()
+		}
+	}()
+
+	for i, addr := range sentinels {
+		var peers []string
+		for _, peer := range sentinels {
+			if peer != addr {
+				peers = append(peers, peer)
+			}
+		}
+		s, err := New(Config{
+			Addr:            addr,
+			ID:              fmt.Sprintf("sentinel-%d", i),
+			Peers:           peers,
+			Monitors:        []MonitorConfig{{Name: "mymaster", Addr: primary, Quorum: 2}},
+			PingInterval:    50 * time.Millisecond,
+			DownAfter:       300 * time.Millisecond,
+			FailoverTimeout: 2 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := s.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		defer s.Stop()
+	}
+
+	do(t, primary, "SET", "greeting", "hello")
+	for _, addr := range replicas {
+		addr := addr
+		eventually(t, addr+" to sync", func() bool { return do(t, addr, "GET", "greeting") == "hello" })
+	}
+	for _, addr := range sentinels {
+		addr := addr
+		eventually(t, addr+" to discover the replicas", func() bool {
+			reply, _ := do(t, addr, "SENTINEL", "REPLICAS", "mymaster").([]interface{})
+			return len(reply) == 2
+		})
+		if got := primaryAddr(t, addr); got != primary {
+			t.Fatalf("GET-PRIMARY-ADDR-BY-NAME on %s = %s, want %s", addr, got, primary)
+		}
+	}
+
+	servers[primary].Stop()
+	delete(servers, primary)
+
+	var promoted string
+	eventually(t, "the sentinels to agree on a new primary", func() bool {
+		promoted = primaryAddr(t, sentinels[0])
+		if promoted == primary || promoted == "" {
+			return false
+		}
+		for _, addr := range sentinels[1:] {
+			if primaryAddr(t, addr) != promoted {
+				return false
+			}
+		}
+		return true
+	})
+
+	if info, _ := do(t, promoted, "INFO", "replication").(string); parseInfo(info)["role"] != "master" {
+		t.Errorf("promoted replica %s reports %q", promoted, info)
+	}
+	if got := do(t, promoted, "GET", "greeting"); got != "hello" {
+		t.Errorf("GET on promoted replica = %v, want hello", got)
+	}
+	for _, addr := range replicas {
+		if addr == promoted {
+			continue
+		}
+		addr := addr
+		eventually(t, addr+" to follow the new primary", func() bool { return replicatesFrom(t, addr, promoted) })
+	}
+
+	// The old primary comes back empty and is turned into a replica.
+	servers[primary] = startServer(t, primary)
+	eventually(t, "the old primary to be demoted", func() bool { return replicatesFrom(t, primary, promoted) })
+	eventually(t, "the old primary to sync", func() bool { return do(t, primary, "GET", "greeting") == "hello" })
+
+	if reply, _ := do(t, sentinels[0], "SENTINEL", "PRIMARY", "mymaster").([]interface{}); !strings.Contains(fmt.Sprint(reply), "config-epoch") {
+		t.Errorf("SENTINEL PRIMARY = %v", reply)
+	}
+}
+
+func TestSentinelClient_FollowsFailover(t *testing.T) {
+	primaryAddr, replicaAddr, sentinelAddr := "127.0.0.1:6436", "127.0.0.1:6437", "127.0.0.1:6438"
+
+	primary := startServer(t, primaryAddr)
+	defer primary.Stop()
+	replica := startServer(t, replicaAddr)
+	defer replica.Stop()
+	if got := do(t, replicaAddr, "REPLICAOF", "127.0.0.1", "6436"); got != "OK" {
+		t.Fatalf("REPLICAOF = %v, want OK", got)
+	}
+
+	s, err := New(Config{
+		Addr:            sentinelAddr,
+		Monitors:        []MonitorConfig{{Name: "mymaster", Addr: primaryAddr, Quorum: 1}},
+		PingInterval:    50 * time.Millisecond,
+		DownAfter:       300 * time.Millisecond,
+		FailoverTimeout: 2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Stop()
+
+	if _, err := client.PrimaryAddr("unknown", sentinelAddr); err == nil {
+		t.Error("PrimaryAddr() for an unmonitored name succeeded")
+	}
+
+	c, err := client.NewSentinelClient("mymaster", sentinelAddr)
+	if err != nil {
+		t.Fatalf("NewSentinelClient() error = %v", err)
+	}
+	defer c.Close()
+	if c.Addr() != primaryAddr {
+		t.Fatalf("Addr() = %s, want %s", c.Addr(), primaryAddr)
+	}
+	if reply, err := c.Do("SET", "greeting", "hello"); err != nil {
+		t.Fatalf("SET error = %v", err)
+	} else if replyErr, ok := reply.(error); ok {
+		t.Fatalf("SET error reply = %v", replyErr)
+	}
+
+	eventually(t, "the replica to sync", func() bool { return do(t, replicaAddr, "GET", "greeting") == "hello" })
+	eventually(t, "the sentinel to discover the replica", func() bool {
+		reply, _ := do(t, sentinelAddr, "SENTINEL", "REPLICAS", "mymaster").([]interface{})
+		return len(reply) == 1
+	})
+	primary.Stop()
+
+	eventually(t, "the client to reach the promoted replica", func() bool {
+		reply, err := c.Do("GET", "greeting")
+		return err == nil && reply == "hello"
+	})
+	if c.Addr() != replicaAddr {
+		t.Errorf("Addr() after failover = %s, want %s", c.Addr(), replicaAddr)
+	}
+	if reply, err := c.Do("SET", "after", "failover"); err != nil {
+		t.Fatalf("SET after failover error = %v", err)
+	} else if replyErr, ok := reply.(error); ok {
+		t.Fatalf("SET after failover error reply = %v", replyErr)
+	}
+}
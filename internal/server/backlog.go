@@ -0,0 +1,61 @@
+package server
+
+// backlog is a fixed-size ring buffer holding the most recent part of the
+// replication stream, so that a replica which briefly lost its link can
+// resume from its offset instead of resynchronizing the whole dataset.
+type backlog struct {
+	buf     []byte
+	offset  int64 // Total bytes written to the stream, the replication offset
+	histlen int   // Number of valid bytes in buf
+}
+
+func newBacklog(size int, offset int64) *backlog {
+	return &backlog{
+		buf:    make([]byte, size),
+		offset: offset,
+	}
+}
+
+// write appends p to the stream, overwriting the oldest bytes once the
+// buffer is full.
+func (b *backlog) write(p []byte) {
+	size := len(b.buf)
+	if len(p) > size {
+		b.offset += int64(len(p) - size)
+		p = p[len(p)-size:]
+	}
+
+	b.histlen += len(p)
+	if b.histlen > size {
+		b.histlen = size
+	}
+
+	for len(p) > 0 {
+		n := copy(b.buf[int(b.offset%int64(size)):], p)
+		b.offset += int64(n)
+		p = p[n:]
+	}
+}
+
+// firstOffset returns the offset of the oldest byte still held.
+func (b *backlog) firstOffset() int64 {
+	return b.offset - int64(b.histlen)
+}
+
+// since returns the part of the stream following offset, or false if that
+// part is no longer held.
+func (b *backlog) since(offset int64) ([]byte, bool) {
+	if offset > b.offset || offset < b.firstOffset() {
+		return nil, false
+	}
+
+	out := make([]byte, b.offset-offset)
+	if len(out) == 0 {
+		return out, true
+	}
+
+	start := int(offset % int64(len(b.buf)))
+	n := copy(out, b.buf[start:])
+	copy(out[n:], b.buf)
+	return out, true
+}
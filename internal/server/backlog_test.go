@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBacklog(t *testing.T) {
+	b := newBacklog(8, 100)
+
+	b.write([]byte("abcde"))
+	if b.offset != 105 || b.firstOffset() != 100 {
+		t.Fatalf("offset = %d, first = %d, want 105, 100", b.offset, b.firstOffset())
+	}
+
+	got, ok := b.since(102)
+	if !ok || string(got) != "cde" {
+		t.Errorf("since(102) = %q, %v, want cde, true", got, ok)
+	}
+
+	// Wrap around the end of the buffer.
+	b.write([]byte("fghij"))
+	if b.firstOffset() != 102 {
+		t.Errorf("firstOffset() = %d, want 102", b.firstOffset())
+	}
+	if got, ok := b.since(102); !ok || string(got) != "cdefghij" {
+		t.Errorf("since(102) = %q, %v, want cdefghij, true", got, ok)
+	}
+	if _, ok := b.since(101); ok {
+		t.Error("since(101) succeeded for an offset no longer held")
+	}
+	if got, ok := b.since(110); !ok || len(got) != 0 {
+		t.Errorf("since(110) = %q, %v, want empty, true", got, ok)
+	}
+	if _, ok := b.since(111); ok {
+		t.Error("since(111) succeeded for an offset in the future")
+	}
+
+	// Writes larger than the buffer keep only the tail.
+	b.write(bytes.Repeat([]byte("x"), 20))
+	if b.offset != 130 || b.firstOffset() != 122 {
+		t.Errorf("offset = %d, first = %d, want 130, 122", b.offset, b.firstOffset())
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNullBulkArgument(t *testing.T) {
+	s, c := startServer(t, "localhost:6442")
+	defer s.Stop()
+	defer c.Close()
+
+	conn, err := net.Dial("tcp", "localhost:6442")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$-1\r\n"))
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || !strings.HasPrefix(reply, "-ERR Protocol error") {
+		t.Errorf("reply to a null argument = %q, %v, want a protocol error", reply, err)
+	}
+	if got := call(t, c, "GET", "k"); got != nil {
+		t.Errorf("GET after a null argument = %q, want nil", got)
+	}
+}
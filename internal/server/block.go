@@ -0,0 +1,71 @@
+package server
+
+import (
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands"
+)
+
+// streamNotifier is implemented by stores that can tell when entries are
+// added to streams, so that blocked XREAD and XREADGROUP calls wake up.
+type streamNotifier interface {
+	StreamAdded() <-chan struct{}
+}
+
+// blockTimeout reports whether command asked to block and for how long.
+func blockTimeout(command commands.Command) (time.Duration, bool) {
+	if cmd, ok := command.(commands.BlockingCommand); ok {
+		return cmd.Block()
+	}
+	return 0, false
+}
+
+// executeBlocking runs a blocking command until it replies with something
+// other than a null array, re-running it each time an entry is added to a
+// stream. It gives up with a null array once timeout passes, or waits
+// forever if timeout is 0.
+func (h *Handler) executeBlocking(command commands.Command, timeout time.Duration) (interface{}, error) {
+	notifier, ok := h.store.(streamNotifier)
+	if !ok {
+		return h.execute(command)
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var quit <-chan struct{}
+	if h.server != nil {
+		quit = h.server.quit
+	}
+
+	for {
+		// Take the channel before running the command, so that an entry
+		// added in between is not missed
+		added := notifier.StreamAdded()
+		response, err := h.execute(command)
+		if err != nil || !isNullArray(response) {
+			return response, err
+		}
+
+		select {
+		case <-added:
+		case <-expired:
+			return []interface{}(nil), nil
+		case <-quit:
+			return []interface{}(nil), nil
+		}
+	}
+}
+
+func isNullArray(response interface{}) bool {
+	switch r := response.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return r == nil
+	}
+	return false
+}
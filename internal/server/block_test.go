@@ -0,0 +1,73 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+func TestBlockingXRead(t *testing.T) {
+	s, c := startServer(t, "localhost:6440")
+	defer s.Stop()
+	defer c.Close()
+
+	start := time.Now()
+	if reply := call(t, c, "XREAD", "BLOCK", "50", "STREAMS", "st", "$"); reply != nil {
+		t.Errorf("XREAD BLOCK on a missing stream = %v, want nil", reply)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("XREAD BLOCK 50 returned after %v", waited)
+	}
+
+	writer, err := client.NewClient("localhost:6440")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer writer.Close()
+
+	call(t, c, "XGROUP", "CREATE", "st", "g", "$", "MKSTREAM")
+	type result struct {
+		reply interface{}
+		err   error
+	}
+	waitFor := func(cmd ...string) <-chan result {
+		done := make(chan result, 1)
+		if err := c.Send(cmd[0], cmd[1:]...); err != nil {
+			t.Fatalf("Send(%v) error = %v", cmd, err)
+		}
+		go func() {
+			reply, err := c.Receive()
+			done <- result{reply, err}
+		}()
+		return done
+	}
+
+	for _, cmd := range [][]string{
+		{"XREAD", "BLOCK", "0", "STREAMS", "st", "$"},
+		{"XREADGROUP", "GROUP", "g", "c", "BLOCK", "0", "STREAMS", "st", ">"},
+	} {
+		call(t, c, "XGROUP", "SETID", "st", "g", "$")
+		done := waitFor(cmd...)
+		select {
+		case r := <-done:
+			t.Fatalf("%s returned %v, %v before an entry was added", cmd[0], r.reply, r.err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		id := call(t, writer, "XADD", "st", "*", "f", "v")
+		select {
+		case r := <-done:
+			streams, ok := r.reply.([]interface{})
+			if r.err != nil || !ok || len(streams) != 1 {
+				t.Fatalf("%s = %v, %v, want one stream", cmd[0], r.reply, r.err)
+			}
+			entries := streams[0].([]interface{})[1].([]interface{})
+			if len(entries) != 1 || entries[0].([]interface{})[0] != id {
+				t.Errorf("%s entries = %v, want the entry %v", cmd[0], entries, id)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s did not wake up when an entry was added", cmd[0])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/hardikphalet/go-redis/internal/cluster"
+)
+
+var errClusterDisabled = errors.New("This instance has cluster support disabled")
+
+// newCluster builds the cluster view described by cfg.
+func newCluster(cfg Config) (*cluster.Cluster, error) {
+	layout, err := cluster.ParseLayout(cfg.ClusterLayout)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.New(announceAddr(cfg), layout)
+}
+
+// announceAddr returns the address this node is known by in the cluster. A
+// listen address without a host, such as ":7000", is announced on loopback.
+func announceAddr(cfg Config) string {
+	if cfg.ClusterAnnounceAddr != "" {
+		return cfg.ClusterAnnounceAddr
+	}
+	host, port, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return cfg.Addr
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// Cluster returns the cluster view, or an error if cluster mode is disabled.
+func (s *Server) Cluster() (*cluster.Cluster, error) {
+	if s.cluster == nil {
+		return nil, errClusterDisabled
+	}
+	return s.cluster, nil
+}
+
+func (s *Server) clusterInfo() string {
+	enabled := 0
+	if s.cluster != nil {
+		enabled = 1
+	}
+	return fmt.Sprintf("# Cluster\r\ncluster_enabled:%d\r\n", enabled)
+}
+
+// ClusterSetSlot applies CLUSTER SETSLOT. A slot cannot be handed to another
+// node while keys of it are still stored here.
+func (s *Server) ClusterSetSlot(slot int, state, nodeID string) error {
+	if s.cluster == nil {
+		return errClusterDisabled
+	}
+
+	if state == cluster.SlotNode && s.cluster.Owner(slot) == s.cluster.Myself() && nodeID != s.cluster.Myself().ID {
+		if keys, _ := s.ClusterKeysInSlot(slot, 1); len(keys) > 0 {
+			return fmt.Errorf("Can't assign hashslot %d to a different node while I still hold keys for this hash slot.", slot)
+		}
+	}
+	return s.cluster.SetSlot(slot, state, nodeID)
+}
+
+// ClusterKeysInSlot returns up to count keys of slot in lexicographic order;
+// a negative count returns all of them.
+func (s *Server) ClusterKeysInSlot(slot, count int) ([]string, error) {
+	if s.cluster == nil {
+		return nil, errClusterDisabled
+	}
+
+	all, err := s.store.Keys("*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(all)
+
+	keys := make([]string, 0)
+	for _, key := range all {
+		if count >= 0 && len(keys) == count {
+			break
+		}
+		if cluster.KeySlot(key) == slot {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+const testLayout = "127.0.0.1:6400=0-8191,127.0.0.1:6401=8192-16383"
+
+func startClusterNode(t *testing.T, addr string) (*Server, *client.Client) {
+	t.Helper()
+	cfg := DefaultConfig(addr)
+	cfg.Dir = t.TempDir()
+	cfg.ClusterEnabled = true
+	cfg.ClusterLayout = testLayout
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start cluster node on %s: %v", addr, err)
+	}
+	c, err := client.NewClient(addr)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("Failed to connect to %s: %v", addr, err)
+	}
+	return s, c
+}
+
+func TestCluster_Redirects(t *testing.T) {
+	first, fc := startClusterNode(t, "127.0.0.1:6400")
+	defer first.Stop()
+	defer fc.Close()
+
+	second, sc := startClusterNode(t, "127.0.0.1:6401")
+	defer second.Stop()
+	defer sc.Close()
+
+	// "{b}" hashes to slot 3300 and "foo" to slot 12182.
+	call(t, fc, "SET", "{b}key", "here")
+	if got := call(t, fc, "GET", "{b}key"); got != "here" {
+		t.Errorf("GET on owning node = %v, want here", got)
+	}
+
+	err, ok := call(t, fc, "SET", "foo", "bar").(error)
+	if !ok || err.Error() != "MOVED 12182 127.0.0.1:6401" {
+		t.Errorf("SET foo on first node = %v, want MOVED 12182 127.0.0.1:6401", err)
+	}
+	if _, ok := call(t, sc, "SET", "foo", "bar").(error); ok {
+		t.Error("SET foo on second node failed")
+	}
+
+	err, ok = call(t, fc, "DEL", "{b}key", "other").(error)
+	if !ok || !strings.HasPrefix(err.Error(), "CROSSSLOT") {
+		t.Errorf("DEL across slots = %v, want CROSSSLOT error", err)
+	}
+	if got := call(t, fc, "DEL", "{b}key", "{b}other"); got != 1 {
+		t.Errorf("DEL within one slot = %v, want 1", got)
+	}
+
+	if got := call(t, sc, "CLUSTER", "KEYSLOT", "foo"); got != 12182 {
+		t.Errorf("CLUSTER KEYSLOT foo = %v, want 12182", got)
+	}
+
+	slots := call(t, sc, "CLUSTER", "SLOTS").([]interface{})
+	if len(slots) != 2 {
+		t.Fatalf("CLUSTER SLOTS = %v, want two ranges", slots)
+	}
+	second0 := slots[1].([]interface{})
+	if second0[0] != 8192 || second0[1] != 16383 || second0[2].([]interface{})[1] != 6401 {
+		t.Errorf("CLUSTER SLOTS second range = %v", second0)
+	}
+
+	nodes := call(t, fc, "CLUSTER", "NODES").(string)
+	if !strings.Contains(nodes, "127.0.0.1:6400@16400 myself,master - 0 0 0 connected 0-8191") {
+		t.Errorf("CLUSTER NODES = %q", nodes)
+	}
+
+	shards := call(t, fc, "CLUSTER", "SHARDS").([]interface{})
+	if len(shards) != 2 {
+		t.Errorf("CLUSTER SHARDS = %v, want two shards", shards)
+	}
+
+	standalone, c := startServer(t, "127.0.0.1:6402")
+	defer standalone.Stop()
+	defer c.Close()
+	if _, ok := call(t, c, "CLUSTER", "SLOTS").(error); !ok {
+		t.Error("CLUSTER SLOTS without cluster mode succeeded, want error")
+	}
+}
+
+func TestCluster_SlotMigration(t *testing.T) {
+	const layout = "127.0.0.1:6403=0-16383"
+
+	start := func(addr string) (*Server, *client.Client) {
+		cfg := DefaultConfig(addr)
+		cfg.Dir = t.TempDir()
+		cfg.ClusterEnabled = true
+		cfg.ClusterLayout = layout
+		s := NewWithConfig(cfg)
+		if err := s.Start(); err != nil {
+			t.Fatalf("Failed to start cluster node on %s: %v", addr, err)
+		}
+		c, err := client.NewClient(addr)
+		if err != nil {
+			s.Stop()
+			t.Fatalf("Failed to connect to %s: %v", addr, err)
+		}
+		return s, c
+	}
+
+	source, sc := start("127.0.0.1:6403")
+	defer source.Stop()
+	defer sc.Close()
+	target, tc := start("127.0.0.1:6404")
+	defer target.Stop()
+	defer tc.Close()
+
+	call(t, sc, "CLUSTER", "MEET", "127.0.0.1", "6404")
+	sourceID := call(t, sc, "CLUSTER", "MYID").(string)
+	targetID := call(t, tc, "CLUSTER", "MYID").(string)
+
+	// "foo" and "{foo}zset" share slot 12182.
+	const slot = "12182"
+	call(t, sc, "SET", "foo", "bar")
+	call(t, sc, "ZADD", "{foo}zset", "1", "a", "2", "b")
+	call(t, sc, "SET", "other", "stays")
+
+	if got := call(t, sc, "CLUSTER", "COUNTKEYSINSLOT", slot); got != 2 {
+		t.Errorf("CLUSTER COUNTKEYSINSLOT = %v, want 2", got)
+	}
+	keys := call(t, sc, "CLUSTER", "GETKEYSINSLOT", slot, "1").([]interface{})
+	if len(keys) != 1 || keys[0] != "foo" {
+		t.Errorf("CLUSTER GETKEYSINSLOT %s 1 = %v, want [foo]", slot, keys)
+	}
+
+	for _, step := range []struct {
+		c    *client.Client
+		args []string
+	}{
+		{tc, []string{"CLUSTER", "SETSLOT", slot, "IMPORTING", sourceID}},
+		{sc, []string{"CLUSTER", "SETSLOT", slot, "MIGRATING", targetID}},
+	} {
+		if got := call(t, step.c, step.args...); got != "OK" {
+			t.Fatalf("%v = %v, want OK", step.args, got)
+		}
+	}
+
+	if got := call(t, sc, "MIGRATE", "127.0.0.1", "6404", "foo", "0", "1000"); got != "OK" {
+		t.Fatalf("MIGRATE foo = %v, want OK", got)
+	}
+
+	err, ok := call(t, sc, "GET", "foo").(error)
+	if !ok || err.Error() != "ASK "+slot+" 127.0.0.1:6404" {
+		t.Errorf("GET foo on source after MIGRATE = %v, want ASK redirection", err)
+	}
+	if got := call(t, sc, "ZRANGE", "{foo}zset", "0", "-1"); len(got.([]interface{})) != 2 {
+		t.Errorf("ZRANGE on source before its key moved = %v", got)
+	}
+	err, ok = call(t, tc, "GET", "foo").(error)
+	if !ok || !strings.HasPrefix(err.Error(), "MOVED") {
+		t.Errorf("GET foo on target without ASKING = %v, want MOVED", err)
+	}
+	call(t, tc, "ASKING")
+	if got := call(t, tc, "GET", "foo"); got != "bar" {
+		t.Errorf("GET foo on target after ASKING = %v, want bar", got)
+	}
+
+	if _, ok := call(t, sc, "CLUSTER", "SETSLOT", slot, "NODE", targetID).(error); !ok {
+		t.Error("CLUSTER SETSLOT NODE with keys left succeeded, want error")
+	}
+
+	if got := call(t, sc, "MIGRATE", "127.0.0.1", "6404", "", "0", "1000", "KEYS", "{foo}zset"); got != "OK" {
+		t.Fatalf("MIGRATE KEYS = %v, want OK", got)
+	}
+	if got := call(t, sc, "MIGRATE", "127.0.0.1", "6404", "foo", "0", "1000"); got != "NOKEY" {
+		t.Errorf("MIGRATE of a moved key = %v, want NOKEY", got)
+	}
+
+	for _, c := range []*client.Client{tc, sc} {
+		if got := call(t, c, "CLUSTER", "SETSLOT", slot, "NODE", targetID); got != "OK" {
+			t.Fatalf("CLUSTER SETSLOT NODE = %v, want OK", got)
+		}
+	}
+
+	err, ok = call(t, sc, "GET", "foo").(error)
+	if !ok || err.Error() != "MOVED "+slot+" 127.0.0.1:6404" {
+		t.Errorf("GET foo on source after the move = %v, want MOVED", err)
+	}
+	if got := call(t, tc, "GET", "foo"); got != "bar" {
+		t.Errorf("GET foo on target after the move = %v, want bar", got)
+	}
+	if got := call(t, tc, "ZRANGE", "{foo}zset", "0", "-1"); len(got.([]interface{})) != 2 {
+		t.Errorf("ZRANGE on target after the move = %v", got)
+	}
+	if got := call(t, sc, "GET", "other"); got != "stays" {
+		t.Errorf("GET of a key in another slot = %v, want stays", got)
+	}
+}
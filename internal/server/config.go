@@ -0,0 +1,42 @@
+package server
+
+// Config holds the settings a Server is built from.
+type Config struct {
+	Addr       string
+	Dir        string // Directory holding persistence files
+	DBFilename string // Snapshot file name inside Dir
+
+	AppendOnly     bool   // Log every write command to the append-only file
+	AppendFilename string // Append-only file name inside Dir
+	AppendFsync    string // "always", "everysec" or "no"
+
+	AutoAOFRewritePercentage int   // Rewrite once the AOF grows by this percentage; 0 disables
+	AutoAOFRewriteMinSize    int64 // Never rewrite automatically below this size in bytes
+
+	ReplBacklogSize int // Bytes of replication stream kept for partial resynchronization
+
+	ClusterEnabled      bool   // Shard the keyspace across nodes by hash slot
+	ClusterLayout       string // Slot ownership, see cluster.ParseLayout
+	ClusterAnnounceAddr string // Address other nodes and clients reach us at; defaults to Addr
+
+	RaftAddr  string // Address the Raft listener binds to; empty disables Raft mode
+	RaftID    string // Node ID within the Raft group; defaults to RaftAddr
+	RaftPeers string // Raft group members as "id=host:port,..."
+}
+
+// DefaultConfig returns the configuration used by New for the given address.
+func DefaultConfig(addr string) Config {
+	return Config{
+		Addr:       addr,
+		Dir:        ".",
+		DBFilename: "dump.rdb",
+
+		AppendFilename: "appendonly.aof",
+		AppendFsync:    "everysec",
+
+		AutoAOFRewritePercentage: 100,
+		AutoAOFRewriteMinSize:    64 << 20,
+
+		ReplBacklogSize: 1 << 20,
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import "time"
+
+const (
+	// activeExpireInterval is how often expired keys and hash fields that
+	// no client touches are removed in the background.
+	activeExpireInterval = 100 * time.Millisecond
+
+	// activeExpireBudget bounds how long each run may hold up clients.
+	activeExpireBudget = 25 * time.Millisecond
+)
+
+// activeExpirer is implemented by stores that can remove expired data
+// without waiting for it to be accessed.
+type activeExpirer interface {
+	ActiveExpire(budget time.Duration) int
+}
+
+// activeExpireLoop periodically removes expired data from the store until
+// the server stops.
+func (s *Server) activeExpireLoop() {
+	defer s.wg.Done()
+
+	expirer, ok := s.store.(activeExpirer)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(activeExpireInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			expirer.ActiveExpire(activeExpireBudget)
+		}
+	}
+}
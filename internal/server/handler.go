@@ -2,9 +2,14 @@ package server
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hardikphalet/go-redis/internal/commands"
 	"github.com/hardikphalet/go-redis/internal/resp"
 	"github.com/hardikphalet/go-redis/internal/store"
 )
@@ -16,6 +21,12 @@ type Handler struct {
 	store      store.Store
 	parser     *resp.Parser
 	respWriter *resp.Writer
+	server     *Server
+
+	listeningPort   string   // Port announced by a replica through REPLCONF
+	replica         *replica // Set once the connection becomes a replication link
+	lastWriteOffset int64    // Replication offset right after this client's last write
+	asking          bool     // ASKING was sent; applies to the next command only
 }
 
 func NewHandler(conn net.Conn, store store.Store) *Handler {
@@ -34,17 +45,34 @@ func NewHandler(conn net.Conn, store store.Store) *Handler {
 func (h *Handler) Handle() error {
 	for {
 		// Parse the incoming command using RESP protocol
-		command, err := h.parser.Parse()
+		args, err := h.parser.ReadArgs()
 		if err != nil {
 			if err.Error() == "EOF" {
 				// Client closed connection - this is normal
 				return nil
 			}
+			if errors.Is(err, resp.ErrInvalidSyntax) || errors.Is(err, resp.ErrNullArgument) {
+				// Tell the client why the connection is being closed
+				h.writeError(err)
+			}
+			return fmt.Errorf("error parsing command: %w", err)
+		}
+		command, err := resp.NewCommand(args)
+		if err != nil {
 			return fmt.Errorf("error parsing command: %w", err)
 		}
 
-		// Execute the command
-		response, err := command.Execute(h.store)
+		// Execute the command, waiting for data if it blocks
+		var response interface{}
+		if timeout, ok := blockTimeout(command); ok {
+			response, err = h.executeBlocking(command, timeout)
+		} else {
+			response, err = h.execute(command)
+		}
+		if h.replica != nil {
+			// Replication links only carry the write stream
+			continue
+		}
 		if err != nil {
 			if err := h.writeError(err); err != nil {
 				return fmt.Errorf("error writing error response: %w", err)
@@ -59,6 +87,43 @@ func (h *Handler) Handle() error {
 	}
 }
 
+// execute runs command against the store, or against the server for
+// administrative commands such as SAVE. Write commands go through the server
+// so that they are replicated and recorded in the AOF; in Raft mode the
+// leader executes them and proposes them to the Raft group. In cluster mode,
+// commands for keys served by another node are redirected.
+func (h *Handler) execute(command commands.Command) (interface{}, error) {
+	if h.server != nil && h.server.cluster != nil {
+		asking := h.asking
+		h.asking = false
+		if cmd, ok := command.(commands.KeyedCommand); ok {
+			if err := h.server.cluster.Route(cmd.KeyArgs(), asking, h.store.Exists); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if h.server != nil {
+		switch cmd := command.(type) {
+		case commands.ServerCommand:
+			return cmd.ExecuteServer(&session{Server: h.server, handler: h})
+		case commands.WriteCommand, commands.MultiWriteCommand:
+			if h.server.isReplica() {
+				return nil, errReadOnly
+			}
+			if h.server.raft != nil {
+				return h.server.raftWrite(cmd)
+			}
+			result, offset, err := h.server.executeWrite(cmd)
+			if err == nil {
+				h.lastWriteOffset = offset
+			}
+			return result, err
+		}
+	}
+	return command.Execute(h.store)
+}
+
 func (h *Handler) writeResponse(response interface{}) error {
 	if err := h.respWriter.WriteInterface(response); err != nil {
 		return err
@@ -72,3 +137,52 @@ func (h *Handler) writeError(err error) error {
 	}
 	return h.writer.Flush()
 }
+
+// session is the view of the server handed to server commands. It adds the
+// operations that act on the calling connection.
+type session struct {
+	*Server
+	handler *Handler
+}
+
+func (c *session) ReplConf(args []string) error {
+	for i := 0; i+1 < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "listening-port":
+			c.handler.listeningPort = args[i+1]
+		case "capa":
+			// No optional capabilities are supported yet
+		case "ack":
+			offset, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid replication offset")
+			}
+			if c.handler.replica != nil {
+				c.ackReplica(c.handler.replica, offset)
+			}
+		default:
+			return fmt.Errorf("Unrecognized REPLCONF option: %s", args[i])
+		}
+	}
+	return nil
+}
+
+func (c *session) Sync() error {
+	return c.Server.syncReplica(c.handler, false, "", 0)
+}
+
+func (c *session) PSync(replID string, offset int64) error {
+	return c.Server.syncReplica(c.handler, true, replID, offset)
+}
+
+func (c *session) Wait(numReplicas int, timeout time.Duration) (int, error) {
+	return c.Server.waitForReplicas(c.handler.lastWriteOffset, numReplicas, timeout)
+}
+
+func (c *session) Asking() error {
+	if c.cluster == nil {
+		return errClusterDisabled
+	}
+	c.handler.asking = true
+	return nil
+}
@@ -46,6 +46,21 @@ func TestHandler_Handle(t *testing.T) {
 			input:    "*2\r\n$4\r\nECHO\r\n$5\r\nhello\r\n",
 			expected: "$5\r\nhello\r\n",
 		},
+		{
+			name:     "scores as bulk strings",
+			input:    "*6\r\n$4\r\nZADD\r\n$1\r\nz\r\n$3\r\n1.5\r\n$1\r\na\r\n$4\r\n-inf\r\n$1\r\nb\r\n*5\r\n$6\r\nZRANGE\r\n$1\r\nz\r\n$1\r\n0\r\n$2\r\n-1\r\n$10\r\nWITHSCORES\r\n",
+			expected: ":2\r\n*4\r\n$1\r\nb\r\n$4\r\n-inf\r\n$1\r\na\r\n$3\r\n1.5\r\n",
+		},
+		{
+			name:     "integer scores without an exponent",
+			input:    "*6\r\n$4\r\nZADD\r\n$1\r\nz\r\n$7\r\n1234567\r\n$1\r\na\r\n$4\r\n0.25\r\n$1\r\nb\r\n*5\r\n$6\r\nZRANGE\r\n$1\r\nz\r\n$1\r\n0\r\n$2\r\n-1\r\n$10\r\nWITHSCORES\r\n",
+			expected: ":2\r\n*4\r\n$1\r\nb\r\n$4\r\n0.25\r\n$1\r\na\r\n$7\r\n1234567\r\n",
+		},
+		{
+			name:     "set replies",
+			input:    "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n*4\r\n$5\r\nSETEX\r\n$1\r\nk\r\n$2\r\n10\r\n$1\r\nv\r\n*4\r\n$6\r\nPSETEX\r\n$1\r\nk\r\n$5\r\n10000\r\n$1\r\nw\r\n*4\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nx\r\n$3\r\nGET\r\n",
+			expected: "+OK\r\n+OK\r\n+OK\r\n$1\r\nw\r\n",
+		},
 	}
 
 	for _, tt := range tests {
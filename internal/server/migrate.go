@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/persistence"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+const migrateDefaultTimeout = time.Second
+
+var errMigrateIO = &types.Error{Code: "IOERR", Message: "error or timeout connecting to the client"}
+
+// Dump returns the DUMP payload of key, or nil if the key does not exist.
+func (s *Server) Dump(key string) (interface{}, error) {
+	entry, ok := s.store.Dump(key)
+	if !ok {
+		return nil, nil
+	}
+	payload, err := persistence.DumpPayload(entry)
+	if err != nil {
+		return nil, err
+	}
+	return string(payload), nil
+}
+
+// RestoreKey creates key from a DUMP payload. ttl is in milliseconds, an
+// absolute Unix time if absTTL is set, and zero for no expiry. The key is
+// replicated as the commands that recreate it.
+func (s *Server) RestoreKey(key string, ttl int64, payload string, replace, absTTL bool) error {
+	if s.isReplica() {
+		return errReadOnly
+	}
+	if s.raft != nil {
+		return errRaftMode
+	}
+
+	entry, err := persistence.ParseDumpPayload(key, []byte(payload))
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		if absTTL {
+			entry.Expiry = time.UnixMilli(ttl)
+		} else {
+			entry.Expiry = time.Now().Add(time.Duration(ttl) * time.Millisecond)
+		}
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.store.RestoreEntry(entry, replace); err != nil {
+		return err
+	}
+	if replace {
+		if err := s.recordWrite([]string{"DEL", key}); err != nil {
+			return err
+		}
+	}
+	for _, args := range persistence.EntryCommands(entry) {
+		if err := s.recordWrite(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate restores keys on the server at host:port and, unless copy is set,
+// deletes them here once the target accepted all of them. Writes are blocked
+// while the keys are transferred, so no client sees a key on both nodes or
+// on neither. It returns "NOKEY" if none of the keys exist.
+func (s *Server) Migrate(host, port string, keys []string, timeout time.Duration, copy, replace bool) (string, error) {
+	if !copy && s.isReplica() {
+		return "", errReadOnly
+	}
+	if !copy && s.raft != nil {
+		return "", errRaftMode
+	}
+	if timeout <= 0 {
+		timeout = migrateDefaultTimeout
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var entries []store.Entry
+	for _, key := range keys {
+		if entry, ok := s.store.Dump(key); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return "NOKEY", nil
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), timeout)
+	if err != nil {
+		return "", errMigrateIO
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	link := &peerLink{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+	for _, entry := range entries {
+		if err := s.migrateEntry(link, entry, replace); err != nil {
+			return "", err
+		}
+	}
+
+	if copy {
+		return "OK", nil
+	}
+
+	moved := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if err := s.store.Del(entry.Key); err != nil {
+			return "", err
+		}
+		moved = append(moved, entry.Key)
+	}
+	if err := s.recordWrite(append([]string{"DEL"}, moved...)); err != nil {
+		return "", err
+	}
+	return "OK", nil
+}
+
+// migrateEntry sends entry to the target with RESTORE. In cluster mode the
+// command is preceded by ASKING, as the target is still importing the slot.
+func (s *Server) migrateEntry(link *peerLink, entry store.Entry, replace bool) error {
+	payload, err := persistence.DumpPayload(entry)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"RESTORE", entry.Key, "0", string(payload)}
+	if !entry.Expiry.IsZero() {
+		args[2] = strconv.FormatInt(entry.Expiry.UnixMilli(), 10)
+		args = append(args, "ABSTTL")
+	}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+
+	if s.cluster != nil {
+		if err := migrateCall(link, "ASKING"); err != nil {
+			return err
+		}
+	}
+	return migrateCall(link, args...)
+}
+
+// migrateCall sends a command to the MIGRATE target and checks its reply.
+func migrateCall(link *peerLink, args ...string) error {
+	if err := link.send(args...); err != nil {
+		return errMigrateIO
+	}
+	line, err := link.readLine()
+	if err != nil {
+		return errMigrateIO
+	}
+	if strings.HasPrefix(line, "-") {
+		return fmt.Errorf("Target instance replied with error: %s", line[1:])
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands"
+	"github.com/hardikphalet/go-redis/internal/persistence"
+)
+
+func (s *Server) snapshotPath() string {
+	return filepath.Join(s.config.Dir, s.config.DBFilename)
+}
+
+func (s *Server) aofPath() string {
+	return filepath.Join(s.config.Dir, s.config.AppendFilename)
+}
+
+// loader is implemented by stores that can suspend expiry while a log of
+// past writes is replayed into them.
+type loader interface {
+	StartLoading()
+	StopLoading() int
+}
+
+// load restores the store on startup. When append-only mode is enabled and
+// an AOF exists it takes precedence, since it is the more complete record.
+func (s *Server) load() error {
+	if s.config.AppendOnly {
+		if _, err := os.Stat(s.aofPath()); err == nil {
+			return s.loadAOF()
+		}
+	}
+	return s.loadSnapshot()
+}
+
+// loadAOF replays the AOF into the store. Expiry is suspended meanwhile, so
+// that a key whose logged deadline passed while the server was down is still
+// there for the commands logged after it; what expired is dropped at the end.
+func (s *Server) loadAOF() error {
+	if l, ok := s.store.(loader); ok {
+		l.StartLoading()
+		defer func() {
+			if n := l.StopLoading(); n > 0 {
+				log.Printf("Dropped %d keys and fields that expired before the AOF was loaded", n)
+			}
+		}()
+	}
+	n, err := persistence.LoadAOF(s.aofPath(), s.store)
+	if err != nil {
+		return err
+	}
+	log.Printf("Replayed %d commands from %s", n, s.aofPath())
+	return nil
+}
+
+// openAOF opens the append-only file for writing. A new file is seeded with
+// the current dataset so that data loaded from a snapshot is not lost the
+// next time the server starts from the AOF.
+func (s *Server) openAOF() error {
+	policy, err := persistence.ParseFsyncPolicy(s.config.AppendFsync)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(s.aofPath())
+	aof, err := persistence.OpenAOF(s.aofPath(), policy)
+	if err != nil {
+		return err
+	}
+
+	if os.IsNotExist(statErr) {
+		for _, entry := range s.store.Snapshot() {
+			for _, args := range persistence.EntryCommands(entry) {
+				if err := aof.Append(args); err != nil {
+					aof.Close()
+					return fmt.Errorf("failed to seed AOF: %w", err)
+				}
+			}
+		}
+	}
+
+	s.aof = aof
+	return nil
+}
+
+// executeWrite applies a write command to the store, forwards it to the
+// replicas and appends it to the AOF. Writes are serialized so the replicas
+// and the log see them in the order in which they were applied. It returns
+// the replication offset right after the write.
+func (s *Server) executeWrite(cmd commands.Command) (interface{}, int64, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	result, err := cmd.Execute(s.store)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.recordWrites(commands.Propagation(cmd)); err != nil {
+		return nil, 0, err
+	}
+	return result, s.replicationOffset(), nil
+}
+
+// recordWrite feeds an applied write into the replication stream and the
+// AOF. Nil args, which a write command propagates when it turned out to
+// change nothing, are skipped. It must be called with s.writeMu held.
+func (s *Server) recordWrite(args []string) error {
+	if args == nil {
+		return nil
+	}
+	s.propagate(args)
+
+	if s.aof != nil {
+		if err := s.aof.Append(args); err != nil {
+			return fmt.Errorf("failed to append to AOF: %w", err)
+		}
+		if s.aof.ShouldRewrite(s.config.AutoAOFRewritePercentage, s.config.AutoAOFRewriteMinSize) {
+			log.Printf("AOF grew to %d bytes, starting automatic rewrite", s.aof.Size())
+			if err := s.startAOFRewrite(); err != nil {
+				log.Printf("Automatic AOF rewrite failed to start: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordWrites records the commands of a write that takes several to
+// replay, in order. It must be called with s.writeMu held.
+func (s *Server) recordWrites(cmds [][]string) error {
+	for _, args := range cmds {
+		if err := s.recordWrite(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BGRewriteAOF compacts the AOF in the background into the minimal set of
+// commands that recreate the current dataset.
+func (s *Server) BGRewriteAOF() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.aof == nil {
+		return fmt.Errorf("append only file is not enabled")
+	}
+	return s.startAOFRewrite()
+}
+
+// startAOFRewrite captures the dataset and starts buffering new writes at
+// the same point in the command stream, then rewrites the log in the
+// background. It must be called with s.writeMu held.
+func (s *Server) startAOFRewrite() error {
+	if err := s.aof.BeginRewrite(); err != nil {
+		return err
+	}
+	entries := s.store.Snapshot()
+	aof := s.aof
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		if err := aof.Rewrite(entries); err != nil {
+			log.Printf("Background AOF rewrite failed: %v", err)
+			return
+		}
+		log.Printf("Background AOF rewrite of %d keys completed", len(entries))
+	}()
+
+	return nil
+}
+
+// loadSnapshot restores the store from the snapshot file, if there is one.
+func (s *Server) loadSnapshot() error {
+	entries, err := persistence.LoadRDB(s.snapshotPath())
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		return nil
+	}
+
+	if err := s.store.Restore(entries); err != nil {
+		return err
+	}
+	log.Printf("Loaded %d keys from %s", len(entries), s.snapshotPath())
+	return nil
+}
+
+// Save writes a snapshot of the store to disk, blocking until it is done.
+func (s *Server) Save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if s.bgSaving {
+		return fmt.Errorf("Background save already in progress")
+	}
+
+	if err := persistence.SaveRDB(s.snapshotPath(), s.store.Snapshot()); err != nil {
+		return err
+	}
+	s.lastSave = time.Now()
+	return nil
+}
+
+// BGSave captures a point-in-time copy of the store and writes it to disk in
+// a background goroutine, so clients are only blocked while the copy is taken.
+func (s *Server) BGSave() error {
+	s.saveMu.Lock()
+	if s.bgSaving {
+		s.saveMu.Unlock()
+		return fmt.Errorf("Background save already in progress")
+	}
+	s.bgSaving = true
+	s.saveMu.Unlock()
+
+	entries := s.store.Snapshot()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		err := persistence.SaveRDB(s.snapshotPath(), entries)
+
+		s.saveMu.Lock()
+		s.bgSaving = false
+		if err == nil {
+			s.lastSave = time.Now()
+		}
+		s.saveMu.Unlock()
+
+		if err != nil {
+			log.Printf("Background save failed: %v", err)
+		} else {
+			log.Printf("Background save of %d keys completed", len(entries))
+		}
+	}()
+
+	return nil
+}
+
+// LastSave returns the time of the last successful save.
+func (s *Server) LastSave() time.Time {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	return s.lastSave
+}
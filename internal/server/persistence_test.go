@@ -0,0 +1,265 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+func TestServer_SaveAndLoadOnStartup(t *testing.T) {
+	cfg := DefaultConfig("localhost:6390")
+	cfg.Dir = t.TempDir()
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	c, err := client.NewClient(cfg.Addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	send := func(args ...string) interface{} {
+		t.Helper()
+		if err := c.Send(args[0], args[1:]...); err != nil {
+			t.Fatalf("Send(%v) error = %v", args, err)
+		}
+		resp, err := c.Receive()
+		if err != nil {
+			t.Fatalf("Receive(%v) error = %v", args, err)
+		}
+		return resp
+	}
+
+	send("SET", "greeting", "hello")
+	send("SET", "temp", "x", "EX", "100")
+	send("ZADD", "board", "10", "alice", "20", "bob")
+
+	if got := send("SAVE"); got != "OK" {
+		t.Fatalf("SAVE = %v, want OK", got)
+	}
+	if got, ok := send("LASTSAVE").(int); !ok || int64(got) < time.Now().Add(-time.Minute).Unix() {
+		t.Errorf("LASTSAVE = %v, want a recent timestamp", got)
+	}
+
+	send("SET", "greeting", "changed")
+	if got := send("BGSAVE"); got != "Background saving started" {
+		t.Fatalf("BGSAVE = %v, want Background saving started", got)
+	}
+
+	c.Close()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	restarted := NewWithConfig(cfg)
+	if restarted.loadErr != nil {
+		t.Fatalf("loading snapshot failed: %v", restarted.loadErr)
+	}
+
+	if v, _ := restarted.store.Get("greeting"); v != "changed" {
+		t.Errorf("greeting after restart = %v, want changed", v)
+	}
+	if ttl, _ := restarted.store.TTL("temp"); ttl <= 0 {
+		t.Errorf("TTL(temp) after restart = %d, want positive", ttl)
+	}
+	members, err := restarted.store.ZRange("board", 0, -1, nil)
+	if err != nil || len(members) != 2 || members[0] != "alice" || members[1] != "bob" {
+		t.Errorf("ZRANGE board after restart = %v, %v, want [alice bob]", members, err)
+	}
+}
+
+func TestServer_CorruptSnapshotFailsStart(t *testing.T) {
+	cfg := DefaultConfig("localhost:6391")
+	cfg.Dir = t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(cfg.Dir, cfg.DBFilename), []byte("not a snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err == nil {
+		s.Stop()
+		t.Error("Start() with corrupt snapshot expected error but got none")
+	}
+}
+
+func TestServer_AppendOnlyReplayOnStartup(t *testing.T) {
+	cfg := DefaultConfig("localhost:6392")
+	cfg.Dir = t.TempDir()
+	cfg.AppendOnly = true
+	cfg.AppendFsync = "always"
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	c, err := client.NewClient(cfg.Addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"SET", "counter", "1"},
+		{"SET", "counter", "2"},
+		{"SET", "session", "abc", "EX", "100"},
+		{"SET", "gone", "x"},
+		{"DEL", "gone"},
+		{"ZADD", "board", "10", "alice"},
+		{"EXPIRE", "board", "100"},
+	} {
+		if err := c.Send(args[0], args[1:]...); err != nil {
+			t.Fatalf("Send(%v) error = %v", args, err)
+		}
+		if _, err := c.Receive(); err != nil {
+			t.Fatalf("Receive(%v) error = %v", args, err)
+		}
+	}
+
+	c.Close()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	restarted := NewWithConfig(cfg)
+	if restarted.loadErr != nil {
+		t.Fatalf("replaying AOF failed: %v", restarted.loadErr)
+	}
+
+	if v, _ := restarted.store.Get("counter"); v != "2" {
+		t.Errorf("counter after restart = %v, want 2", v)
+	}
+	if v, _ := restarted.store.Get("gone"); v != nil {
+		t.Errorf("gone after restart = %v, want nil", v)
+	}
+	if ttl, _ := restarted.store.TTL("session"); ttl <= 0 || ttl > 100 {
+		t.Errorf("TTL(session) after restart = %d, want 1..100", ttl)
+	}
+	if ttl, _ := restarted.store.TTL("board"); ttl <= 0 || ttl > 100 {
+		t.Errorf("TTL(board) after restart = %d, want 1..100", ttl)
+	}
+}
+
+func TestServer_AOFReplayWithExpiredTTLs(t *testing.T) {
+	cfg := DefaultConfig("localhost:6387")
+	cfg.Dir = t.TempDir()
+	cfg.AppendOnly = true
+	cfg.AppendFsync = "always"
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	c, err := client.NewClient(cfg.Addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// Each key or field is written again after its TTL was set, and the
+	// TTL passes before the AOF is replayed.
+	for _, args := range [][]string{
+		{"SET", "ctr", "5", "PX", "300"},
+		{"INCR", "ctr"},
+		{"RPUSH", "lq", "a", "b"},
+		{"PEXPIREAT", "lq", strconv.FormatInt(time.Now().Add(300*time.Millisecond).UnixMilli(), 10)},
+		{"LPOP", "lq"},
+		{"HSET", "h", "f", "1", "g", "1"},
+		{"HPEXPIRE", "h", "300", "FIELDS", "1", "f"},
+		{"HINCRBY", "h", "f", "1"},
+		{"SET", "kept", "v"},
+	} {
+		if err := c.Send(args[0], args[1:]...); err != nil {
+			t.Fatalf("Send(%v) error = %v", args, err)
+		}
+		if _, err := c.Receive(); err != nil {
+			t.Fatalf("Receive(%v) error = %v", args, err)
+		}
+	}
+
+	c.Close()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+
+	restarted := NewWithConfig(cfg)
+	if restarted.loadErr != nil {
+		t.Fatalf("replaying AOF failed: %v", restarted.loadErr)
+	}
+
+	for _, key := range []string{"ctr", "lq"} {
+		if restarted.store.Exists(key) {
+			t.Errorf("%s exists after restart although its TTL passed", key)
+		}
+	}
+	if v, _ := restarted.store.HGet("h", "f"); v != nil {
+		t.Errorf("HGET h f after restart = %v, want nil", v)
+	}
+	if v, _ := restarted.store.HGet("h", "g"); v != "1" {
+		t.Errorf("HGET h g after restart = %v, want 1", v)
+	}
+	if v, _ := restarted.store.Get("kept"); v != "v" {
+		t.Errorf("kept after restart = %v, want v", v)
+	}
+}
+
+func TestServer_AutomaticAOFRewrite(t *testing.T) {
+	cfg := DefaultConfig("localhost:6393")
+	cfg.Dir = t.TempDir()
+	cfg.AppendOnly = true
+	cfg.AutoAOFRewriteMinSize = 4096
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	c, err := client.NewClient(cfg.Addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	for i := 0; i < 500; i++ {
+		if err := c.Send("SET", "counter", strconv.Itoa(i)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if _, err := c.Receive(); err != nil {
+			t.Fatalf("Receive() error = %v", err)
+		}
+	}
+
+	if err := c.Send("BGREWRITEAOF"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := c.Receive(); err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+
+	c.Close()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(cfg.Dir, cfg.AppendFilename))
+	if err != nil {
+		t.Fatalf("Stat(AOF) error = %v", err)
+	}
+	if info.Size() >= cfg.AutoAOFRewriteMinSize*2 {
+		t.Errorf("AOF size = %d, want it compacted below %d", info.Size(), cfg.AutoAOFRewriteMinSize*2)
+	}
+
+	restarted := NewWithConfig(cfg)
+	if restarted.loadErr != nil {
+		t.Fatalf("replaying AOF failed: %v", restarted.loadErr)
+	}
+	if v, _ := restarted.store.Get("counter"); v != "499" {
+		t.Errorf("counter after restart = %v, want 499", v)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hardikphalet/go-redis/internal/commands"
+	"github.com/hardikphalet/go-redis/internal/persistence"
+	"github.com/hardikphalet/go-redis/internal/raft"
+	"github.com/hardikphalet/go-redis/internal/resp"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+var (
+	errNoRaftLeader = &types.Error{Code: "CLUSTERDOWN", Message: "No Raft leader elected, try again later"}
+	errRaftMode     = errors.New("This command is not allowed in Raft mode")
+)
+
+// newRaftNode builds the Raft node described by cfg, applying committed
+// commands to the server's store. Durable Raft state is kept in cfg.Dir.
+func newRaftNode(cfg Config, s *Server) (*raft.Node, error) {
+	if cfg.ClusterEnabled {
+		return nil, errors.New("Raft mode cannot be combined with cluster mode")
+	}
+	id := cfg.RaftID
+	if id == "" {
+		id = cfg.RaftAddr
+	}
+	peers, err := parseRaftPeers(cfg.RaftPeers, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return raft.NewNode(raft.Config{
+		ID:         id,
+		Addr:       cfg.RaftAddr,
+		ClientAddr: announceAddr(cfg),
+		Peers:      peers,
+		Dir:        cfg.Dir,
+	}, &raftFSM{s: s})
+}
+
+// parseRaftPeers parses the group members from "id=host:port,...". The
+// entry for self, if present, is skipped so every node can share the list.
+func parseRaftPeers(spec, self string) (map[string]string, error) {
+	peers := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, addr, ok := strings.Cut(part, "=")
+		if !ok || id == "" || addr == "" {
+			return nil, fmt.Errorf("invalid Raft peer %q, want id=host:port", part)
+		}
+		if _, dup := peers[id]; dup {
+			return nil, fmt.Errorf("Raft peer %q listed twice", id)
+		}
+		if id != self {
+			peers[id] = addr
+		}
+	}
+	return peers, nil
+}
+
+// raftWrite executes a write on the Raft leader and proposes the commands it
+// propagates, which replay the same change on every node: expiries are
+// absolute, stream IDs concrete and random picks explicit. It returns once a
+// majority has committed it. The commands reach the AOF and the replicas
+// only then, through raftFSM.Committed, so a write the leader loses is never
+// persisted or replicated. Followers redirect the client to the leader with
+// a NOTLEADER error.
+func (s *Server) raftWrite(cmd commands.Command) (interface{}, error) {
+	var result interface{}
+	err := s.raft.ProposeApplied(func() ([][]string, error) {
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+
+		var err error
+		if result, err = cmd.Execute(s.store); err != nil {
+			return nil, err
+		}
+		return commands.Propagation(cmd), nil
+	})
+	var notLeader *raft.NotLeaderError
+	if errors.As(err, &notLeader) {
+		if notLeader.LeaderAddr == "" {
+			return nil, errNoRaftLeader
+		}
+		return nil, &types.Error{Code: "NOTLEADER", Message: notLeader.LeaderAddr}
+	}
+	return result, err
+}
+
+func (s *Server) raftInfo() string {
+	var b strings.Builder
+	b.WriteString("# Raft\r\n")
+	if s.raft == nil {
+		b.WriteString("raft_enabled:0\r\n")
+		return b.String()
+	}
+
+	status := s.raft.Status()
+	b.WriteString("raft_enabled:1\r\n")
+	fmt.Fprintf(&b, "raft_id:%s\r\n", status.ID)
+	fmt.Fprintf(&b, "raft_role:%s\r\n", status.Role)
+	fmt.Fprintf(&b, "raft_term:%d\r\n", status.Term)
+	fmt.Fprintf(&b, "raft_leader_id:%s\r\n", status.LeaderID)
+	fmt.Fprintf(&b, "raft_leader_addr:%s\r\n", status.LeaderAddr)
+	fmt.Fprintf(&b, "raft_commit_index:%d\r\n", status.CommitIndex)
+	fmt.Fprintf(&b, "raft_last_applied:%d\r\n", status.LastApplied)
+	fmt.Fprintf(&b, "raft_last_index:%d\r\n", status.LastIndex)
+	fmt.Fprintf(&b, "raft_snapshot_index:%d\r\n", status.SnapshotIndex)
+	return b.String()
+}
+
+// raftFSM applies the commands committed by the Raft group to the store.
+// The leader executed them already; they are the propagated forms, so
+// applying them on any node at any time gives the same result. Applied
+// commands still go to the AOF and to any replicas of this node.
+type raftFSM struct {
+	s *Server
+}
+
+func (f *raftFSM) Apply(args []string) (interface{}, error) {
+	cmd, err := resp.NewCommand(resp.ByteArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	if !commands.IsWrite(cmd) {
+		return nil, fmt.Errorf("not a write command: %s", args[0])
+	}
+
+	f.s.writeMu.Lock()
+	defer f.s.writeMu.Unlock()
+
+	result, err := cmd.Execute(f.s.store)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.s.recordWrites(commands.Propagation(cmd)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Committed records a command the leader executed in raftWrite now that it
+// is committed.
+func (f *raftFSM) Committed(args []string) {
+	f.s.writeMu.Lock()
+	defer f.s.writeMu.Unlock()
+
+	if err := f.s.recordWrite(args); err != nil {
+		log.Printf("Failed to record committed Raft command: %v", err)
+	}
+}
+
+// Snapshot encodes the dataset in the RDB format.
+func (f *raftFSM) Snapshot() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := persistence.WriteRDB(&buf, f.s.store.Snapshot()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore replaces the dataset with a snapshot taken by Snapshot. Replicas
+// of this node hold the old dataset and have to resynchronize.
+// Empty data, from a group that never took a snapshot, restores an empty
+// dataset.
+func (f *raftFSM) Restore(data []byte) error {
+	var entries []store.Entry
+	if len(data) > 0 {
+		var err error
+		if entries, err = persistence.ReadRDB(bytes.NewReader(data)); err != nil {
+			return err
+		}
+	}
+
+	s := f.s
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.store.Restore(entries); err != nil {
+		return err
+	}
+
+	s.replMu.Lock()
+	for r := range s.replicas {
+		s.removeReplicaLocked(r)
+	}
+	s.replMu.Unlock()
+
+	if s.aof != nil {
+		if err := s.startAOFRewrite(); err != nil {
+			log.Printf("Failed to rewrite AOF after Raft snapshot restore: %v", err)
+		}
+	}
+	return nil
+}
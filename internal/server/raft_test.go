@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/raft"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+const testRaftPeers = "n1=127.0.0.1:6420,n2=127.0.0.1:6421,n3=127.0.0.1:6422"
+
+type raftTestNode struct {
+	srv    *Server
+	client *client.Client
+	addr   string
+}
+
+func startRaftNode(t *testing.T, i int) *raftTestNode {
+	t.Helper()
+	return startRaftNodeIn(t, i, t.TempDir(), false)
+}
+
+// startRaftNodeIn starts node i with its data in dir, so that it can be
+// restarted with the state it had.
+func startRaftNodeIn(t *testing.T, i int, dir string, appendOnly bool) *raftTestNode {
+	t.Helper()
+	addr := fmt.Sprintf("127.0.0.1:%d", 6410+i)
+	cfg := DefaultConfig(addr)
+	cfg.Dir = dir
+	cfg.AppendOnly = appendOnly
+	cfg.AppendFsync = "always"
+	cfg.RaftID = fmt.Sprintf("n%d", i+1)
+	cfg.RaftAddr = fmt.Sprintf("127.0.0.1:%d", 6420+i)
+	cfg.RaftPeers = testRaftPeers
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start Raft node on %s: %v", addr, err)
+	}
+	c, err := client.NewClient(addr)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("Failed to connect to %s: %v", addr, err)
+	}
+	return &raftTestNode{srv: s, client: c, addr: addr}
+}
+
+func (n *raftTestNode) stop() {
+	n.client.Close()
+	n.srv.Stop()
+}
+
+func raftLeader(t *testing.T, nodes []*raftTestNode) *raftTestNode {
+	t.Helper()
+	var leader *raftTestNode
+	eventually(t, "a Raft leader", func() bool {
+		leader = nil
+		for _, n := range nodes {
+			if n.srv.raft.Status().Role == raft.Leader {
+				leader = n
+			}
+		}
+		return leader != nil
+	})
+	return leader
+}
+
+func TestRaft_ReplicatedWrites(t *testing.T) {
+	nodes := make([]*raftTestNode, 3)
+	for i := range nodes {
+		nodes[i] = startRaftNode(t, i)
+	}
+	defer func() {
+		for _, n := range nodes {
+			if n != nil {
+				n.stop()
+			}
+		}
+	}()
+
+	leader := raftLeader(t, nodes)
+	if reply := call(t, leader.client, "SET", "greeting", "hello"); reply != nil {
+		if err, ok := reply.(error); ok {
+			t.Fatalf("SET on leader error = %v", err)
+		}
+	}
+	if got := call(t, leader.client, "ZADD", "board", "1", "alice"); got != 1 {
+		t.Fatalf("ZADD on leader = %v, want 1", got)
+	}
+
+	for _, n := range nodes {
+		n := n
+		eventually(t, n.addr+" to apply the writes", func() bool {
+			return call(t, n.client, "GET", "greeting") == "hello"
+		})
+		if n == leader {
+			continue
+		}
+
+		reply := call(t, n.client, "SET", "greeting", "bye")
+		err, ok := reply.(error)
+		if !ok || err.Error() != "NOTLEADER "+leader.addr {
+			t.Errorf("SET on follower %s = %v, want NOTLEADER %s", n.addr, reply, leader.addr)
+		}
+		if reply := call(t, n.client, "REPLICAOF", "127.0.0.1", "6379"); reply == "OK" {
+			t.Errorf("REPLICAOF on Raft node %s succeeded", n.addr)
+		}
+	}
+
+	// The remaining two nodes still form a majority after the leader stops.
+	var rest []*raftTestNode
+	for i, n := range nodes {
+		if n == leader {
+			n.stop()
+			nodes[i] = nil
+		} else {
+			rest = append(rest, n)
+		}
+	}
+	newLeader := raftLeader(t, rest)
+	if got := call(t, newLeader.client, "GET", "greeting"); got != "hello" {
+		t.Errorf("GET on new leader = %v, want hello", got)
+	}
+	if reply, ok := call(t, newLeader.client, "SET", "greeting", "again").(error); ok {
+		t.Fatalf("SET on new leader error = %v", reply)
+	}
+	for _, n := range rest {
+		n := n
+		eventually(t, n.addr+" to apply the write", func() bool {
+			return call(t, n.client, "GET", "greeting") == "again"
+		})
+	}
+}
+
+// raftState returns the dataset of a node in a comparable form: times in
+// milliseconds, as they are propagated, and without the times consumers were
+// last seen, which every node records itself.
+func raftState(n *raftTestNode) map[string]store.Entry {
+	millis := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return time.UnixMilli(t.UnixMilli())
+	}
+
+	state := make(map[string]store.Entry)
+	for _, entry := range n.srv.store.Snapshot() {
+		entry.Expiry = millis(entry.Expiry)
+		if stream, ok := entry.Value.(types.StreamData); ok {
+			for i := range stream.Groups {
+				stream.Groups[i].Consumers = nil
+				for j := range stream.Groups[i].Pending {
+					stream.Groups[i].Pending[j].DeliveryTime = millis(stream.Groups[i].Pending[j].DeliveryTime)
+				}
+			}
+			entry.Value = stream
+		}
+		state[entry.Key] = entry
+	}
+	return state
+}
+
+func TestRaft_DeterministicWrites(t *testing.T) {
+	nodes := make([]*raftTestNode, 3)
+	for i := range nodes {
+		nodes[i] = startRaftNode(t, i)
+		defer nodes[i].stop()
+	}
+
+	// Each of these depends on the clock of the node executing it.
+	leader := raftLeader(t, nodes)
+	writes := [][]string{
+		{"XADD", "events", "*", "type", "login"},
+		{"SET", "session", "abc", "EX", "100"},
+		{"EXPIRE", "events", "200"},
+		{"XGROUP", "CREATE", "events", "workers", "0"},
+		{"XREADGROUP", "GROUP", "workers", "w1", "STREAMS", "events", ">"},
+	}
+	for _, args := range writes {
+		// Let the clocks move on between the leader and the followers.
+		time.Sleep(5 * time.Millisecond)
+		if err, ok := call(t, leader.client, args...).(error); ok {
+			t.Fatalf("%v on leader error = %v", args, err)
+		}
+	}
+
+	want := raftState(leader)
+	if len(want) != 2 {
+		t.Fatalf("leader holds %d keys, want 2", len(want))
+	}
+	for _, n := range nodes {
+		n := n
+		eventually(t, n.addr+" to match the leader", func() bool {
+			return reflect.DeepEqual(raftState(n), want)
+		})
+	}
+}
+
+func TestRaft_LostWriteNotRecorded(t *testing.T) {
+	dirs := make([]string, 3)
+	nodes := make([]*raftTestNode, 3)
+	for i := range nodes {
+		dirs[i] = t.TempDir()
+		nodes[i] = startRaftNodeIn(t, i, dirs[i], true)
+	}
+	defer func() {
+		for _, n := range nodes {
+			if n != nil {
+				n.stop()
+			}
+		}
+	}()
+
+	leader := raftLeader(t, nodes)
+	replicaSrv, rc := startServer(t, "localhost:6443")
+	defer replicaSrv.Stop()
+	defer rc.Close()
+	host, port, _ := strings.Cut(leader.addr, ":")
+	if got := call(t, rc, "REPLICAOF", host, port); got != "OK" {
+		t.Fatalf("REPLICAOF = %v, want OK", got)
+	}
+	call(t, leader.client, "SET", "kept", "1")
+	eventually(t, "the replica to sync", func() bool {
+		return call(t, rc, "GET", "kept") == "1"
+	})
+
+	// Cut the leader off from the rest of the group. It executes the
+	// write, which can then never be committed.
+	var li int
+	for i, n := range nodes {
+		if n == leader {
+			li = i
+		} else {
+			n.stop()
+			nodes[i] = nil
+		}
+	}
+	if err := leader.client.Send("SET", "lost", "1"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	eventually(t, "the leader to execute the write", func() bool {
+		v, _ := leader.srv.store.Get("lost")
+		return v == "1"
+	})
+	time.Sleep(200 * time.Millisecond)
+
+	aofHas := func(n *raftTestNode, s string) bool {
+		data, err := os.ReadFile(n.srv.aofPath())
+		if err != nil {
+			t.Fatalf("reading the AOF failed: %v", err)
+		}
+		return bytes.Contains(data, []byte(s))
+	}
+	if !aofHas(leader, "kept") || aofHas(leader, "lost") {
+		t.Errorf("AOF before the write was lost holds kept = %v, lost = %v, want true, false", aofHas(leader, "kept"), aofHas(leader, "lost"))
+	}
+	if got := call(t, rc, "GET", "lost"); got != nil {
+		t.Errorf("replica holds the uncommitted write, GET lost = %v", got)
+	}
+
+	// The rest of the group elects a new leader, whose log replaces the
+	// entry of the old one when it rejoins.
+	leader.stop()
+	nodes[li] = nil
+	var rest []*raftTestNode
+	for i := range nodes {
+		if i != li {
+			nodes[i] = startRaftNodeIn(t, i, dirs[i], true)
+			rest = append(rest, nodes[i])
+		}
+	}
+	newLeader := raftLeader(t, rest)
+	if err, ok := call(t, newLeader.client, "SET", "other", "2").(error); ok {
+		t.Fatalf("SET on new leader error = %v", err)
+	}
+	nodes[li] = startRaftNodeIn(t, li, dirs[li], true)
+	old := nodes[li]
+	eventually(t, "the old leader to catch up", func() bool {
+		v, _ := old.srv.store.Get("other")
+		return v == "2"
+	})
+	if v, _ := old.srv.store.Get("lost"); v != nil {
+		t.Errorf("old leader kept the lost write, GET lost = %v", v)
+	}
+	if aofHas(old, "lost") {
+		t.Errorf("old leader's AOF holds the lost write")
+	}
+}
+
+func TestParseRaftPeers(t *testing.T) {
+	peers, err := parseRaftPeers(" n1=127.0.0.1:7100, n2=127.0.0.1:7101,n3=127.0.0.1:7102", "n2")
+	if err != nil {
+		t.Fatalf("parseRaftPeers() error = %v", err)
+	}
+	if len(peers) != 2 || peers["n1"] != "127.0.0.1:7100" || peers["n3"] != "127.0.0.1:7102" {
+		t.Errorf("parseRaftPeers() = %v", peers)
+	}
+
+	for _, spec := range []string{"n1", "=127.0.0.1:7100", "n1=", "n1=a:1,n1=b:2"} {
+		if _, err := parseRaftPeers(spec, "n9"); err == nil {
+			t.Errorf("parseRaftPeers(%q) succeeded, want error", spec)
+		}
+	}
+}
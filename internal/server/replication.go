@@ -0,0 +1,788 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands"
+	"github.com/hardikphalet/go-redis/internal/persistence"
+	"github.com/hardikphalet/go-redis/internal/resp"
+	"github.com/hardikphalet/go-redis/internal/store"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+const (
+	replicaQueueSize         = 1 << 14
+	replicationRetryInterval = time.Second
+	replicationTimeout       = 5 * time.Second
+	replicationAckInterval   = time.Second
+)
+
+// Replication link states reported by ROLE and INFO on a replica.
+const (
+	linkConnect    = "connect"
+	linkConnecting = "connecting"
+	linkSync       = "sync"
+	linkConnected  = "connected"
+)
+
+var (
+	errReadOnly      = &types.Error{Code: "READONLY", Message: "You can't write against a read only replica."}
+	errWaitOnReplica = errors.New("WAIT cannot be used with replica instances")
+)
+
+// replica is a connected replica being fed the primary's write stream.
+type replica struct {
+	addr      string
+	conn      net.Conn
+	writer    *bufio.Writer
+	queue     chan []byte
+	quit      chan struct{}
+	ackOffset int64 // Last offset acknowledged with REPLCONF ACK, guarded by replMu
+}
+
+func newReplica(h *Handler) *replica {
+	host := "?"
+	if addr := h.conn.RemoteAddr(); addr != nil {
+		host, _, _ = net.SplitHostPort(addr.String())
+	}
+	return &replica{
+		addr:   net.JoinHostPort(host, h.listeningPort),
+		conn:   h.conn,
+		writer: h.writer,
+		queue:  make(chan []byte, replicaQueueSize),
+		quit:   make(chan struct{}),
+	}
+}
+
+// run forwards queued commands to the replica until it is removed or the
+// connection fails.
+func (r *replica) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-r.quit:
+			return
+		case b := <-r.queue:
+			if _, err := r.writer.Write(b); err != nil {
+				r.conn.Close()
+				return
+			}
+			if len(r.queue) == 0 {
+				if err := r.writer.Flush(); err != nil {
+					r.conn.Close()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) isReplica() bool {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	return s.masterAddr != ""
+}
+
+// ReplicaOf starts replicating from host:port, or turns the server back into
+// a primary when host is empty. Raft group members cannot become replicas.
+func (s *Server) ReplicaOf(host, port string) (string, error) {
+	if host != "" && s.raft != nil {
+		return "", errRaftMode
+	}
+
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	if host == "" {
+		if s.masterAddr != "" {
+			s.stopReplicationLocked()
+			s.shiftReplicationIDLocked()
+			log.Printf("Replication from %s stopped, now acting as a primary", s.masterAddr)
+			s.masterAddr = ""
+		}
+		return "OK", nil
+	}
+
+	addr := net.JoinHostPort(host, port)
+	if addr == s.masterAddr {
+		return "OK Already connected to specified master", nil
+	}
+
+	s.stopReplicationLocked()
+	// Our replicas reconnect and resume from the new primary's stream.
+	for r := range s.replicas {
+		s.removeReplicaLocked(r)
+	}
+	s.masterAddr = addr
+	s.linkStatus = linkConnect
+	stop := make(chan struct{})
+	s.stopLink = stop
+
+	log.Printf("Replicating from %s", addr)
+	s.wg.Add(1)
+	go s.replicationLoop(addr, stop)
+
+	return "OK", nil
+}
+
+// stopReplicationLocked stops the current replication loop, if any. It must
+// be called with s.replMu held.
+func (s *Server) stopReplicationLocked() {
+	if s.stopLink != nil {
+		close(s.stopLink)
+		s.stopLink = nil
+	}
+	if s.masterConn != nil {
+		s.masterConn.Close()
+		s.masterConn = nil
+	}
+}
+
+// shiftReplicationIDLocked starts a new replication history after a replica
+// is promoted. The old ID is kept as the secondary ID so that replicas of
+// the previous primary can still resume with PSYNC up to the current offset.
+// It must be called with s.replMu held.
+func (s *Server) shiftReplicationIDLocked() {
+	s.replID2 = s.replID
+	s.secondReplOffset = s.backlog.offset + 1
+	s.replID = newReplID()
+}
+
+// newReplID returns a random 40 character replication ID.
+func newReplID() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// setLinkStatus updates the link state if stop still identifies the current
+// replication loop.
+func (s *Server) setLinkStatus(stop chan struct{}, status string) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	if s.stopLink == stop {
+		s.linkStatus = status
+	}
+}
+
+func (s *Server) replicationLoop(addr string, stop chan struct{}) {
+	defer s.wg.Done()
+
+	for {
+		err := s.syncWithMaster(addr, stop)
+
+		select {
+		case <-stop:
+			return
+		case <-s.quit:
+			return
+		default:
+		}
+
+		log.Printf("Replication link with %s lost: %v", addr, err)
+		s.setLinkStatus(stop, linkConnect)
+
+		select {
+		case <-stop:
+			return
+		case <-s.quit:
+			return
+		case <-time.After(replicationRetryInterval):
+		}
+	}
+}
+
+// syncWithMaster performs the handshake with the primary, loads its snapshot
+// and then applies its write stream until the link breaks.
+func (s *Server) syncWithMaster(addr string, stop chan struct{}) error {
+	conn, err := net.DialTimeout("tcp", addr, replicationTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s.replMu.Lock()
+	if s.stopLink != stop {
+		s.replMu.Unlock()
+		return errors.New("replication cancelled")
+	}
+	s.masterConn = conn
+	s.linkStatus = linkConnecting
+	s.replMu.Unlock()
+
+	link := &peerLink{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+
+	conn.SetDeadline(time.Now().Add(replicationTimeout))
+	if _, err := link.call("PING"); err != nil {
+		return err
+	}
+	if _, err := link.call("REPLCONF", "listening-port", s.listeningPort()); err != nil {
+		return err
+	}
+	if _, err := link.call("REPLCONF", "capa", "psync2"); err != nil {
+		return err
+	}
+
+	replID, offset := s.replicationCursor()
+	if err := link.send("PSYNC", replID, strconv.FormatInt(offset+1, 10)); err != nil {
+		return err
+	}
+	s.setLinkStatus(stop, linkSync)
+
+	line, err := link.readLine()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasPrefix(line, "+FULLRESYNC "):
+		fields := strings.Fields(line[1:])
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid FULLRESYNC reply: %q", line)
+		}
+		masterOffset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid FULLRESYNC offset: %q", line)
+		}
+
+		payload, err := link.readPayload()
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		entries, err := persistence.ReadRDB(bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to parse snapshot: %w", err)
+		}
+		if err := s.loadFromMaster(entries, fields[1], masterOffset); err != nil {
+			return err
+		}
+		log.Printf("Full resynchronization with %s completed, %d keys loaded", addr, len(entries))
+
+	case strings.HasPrefix(line, "+CONTINUE"):
+		s.continueFromMaster(strings.TrimSpace(line[len("+CONTINUE"):]))
+		log.Printf("Partial resynchronization with %s accepted from offset %d", addr, offset)
+
+	case strings.HasPrefix(line, "-"):
+		return fmt.Errorf("PSYNC rejected by primary: %s", line[1:])
+
+	default:
+		return fmt.Errorf("unexpected reply to PSYNC: %q", line)
+	}
+
+	conn.SetDeadline(time.Time{})
+	s.setLinkStatus(stop, linkConnected)
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.ackLoop(link, done)
+
+	return s.applyStream(link)
+}
+
+// ackLoop reports the replication offset to the primary until done is
+// closed, so that the primary can answer WAIT.
+func (s *Server) ackLoop(link *peerLink, done chan struct{}) {
+	ticker := time.NewTicker(replicationAckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := link.ack(s.replicationOffset()); err != nil {
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// replicationOffset returns the offset of the last byte of the replication
+// stream this server has seen.
+func (s *Server) replicationOffset() int64 {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	return s.backlog.offset
+}
+
+// replicationCursor returns the replication ID and offset a replica resumes
+// from.
+func (s *Server) replicationCursor() (string, int64) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	return s.replID, s.backlog.offset
+}
+
+// loadFromMaster replaces the dataset with the primary's snapshot and
+// adopts its replication ID and offset.
+func (s *Server) loadFromMaster(entries []store.Entry, replID string, offset int64) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.store.Restore(entries); err != nil {
+		return err
+	}
+
+	s.replMu.Lock()
+	s.replID = replID
+	s.replID2 = ""
+	s.secondReplOffset = -1
+	s.backlog = newBacklog(len(s.backlog.buf), offset)
+	// Our own replicas hold the old dataset and have to resynchronize.
+	for r := range s.replicas {
+		s.removeReplicaLocked(r)
+	}
+	s.replMu.Unlock()
+
+	if s.aof != nil {
+		if err := s.startAOFRewrite(); err != nil {
+			log.Printf("Failed to rewrite AOF after resynchronization: %v", err)
+		}
+	}
+	return nil
+}
+
+// continueFromMaster adopts the primary's replication ID after a partial
+// resynchronization. If the ID changed because the primary was promoted, the
+// old one is kept as the secondary ID for our own replicas.
+func (s *Server) continueFromMaster(replID string) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	if replID != "" && replID != s.replID {
+		s.replID2 = s.replID
+		s.secondReplOffset = s.backlog.offset + 1
+		s.replID = replID
+	}
+}
+
+// applyStream applies the replication stream sent by the primary. Every
+// command, including non-writes such as PING, is fed into our own stream so
+// that our offset matches the primary's byte for byte. REPLCONF GETACK is
+// answered with an immediate acknowledgement.
+func (s *Server) applyStream(link *peerLink) error {
+	parser := resp.NewParser(link.reader)
+	for {
+		args, err := parser.ReadArgs()
+		if err != nil {
+			return err
+		}
+		s.applyReplicated(args)
+
+		if len(args) >= 2 && bytes.EqualFold(args[0], []byte("REPLCONF")) && bytes.EqualFold(args[1], []byte("GETACK")) {
+			if err := link.ack(s.replicationOffset()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) applyReplicated(raw [][]byte) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	cmd, err := resp.NewCommand(raw)
+	if err != nil {
+		log.Printf("Replicated command rejected: %v", err)
+	}
+	args := resp.StringArgs(raw)
+
+	if !commands.IsWrite(cmd) {
+		s.propagate(args)
+		return
+	}
+
+	if _, err := cmd.Execute(s.store); err != nil {
+		log.Printf("Replicated command failed: %v", err)
+	}
+	if err := s.recordWrite(args); err != nil {
+		log.Printf("Failed to record replicated command: %v", err)
+	}
+}
+
+// listeningPort returns the port clients use to reach this server.
+func (s *Server) listeningPort() string {
+	addr := s.port
+	if s.listener != nil {
+		addr = s.listener.Addr().String()
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "0"
+	}
+	return port
+}
+
+// syncReplica turns the handler's connection into a replication link. For
+// PSYNC the replica resumes from the backlog when replID and offset allow,
+// otherwise it receives a full snapshot. The decision is made and the
+// replica registered under s.writeMu, so it receives exactly the writes that
+// follow the data it was sent.
+func (s *Server) syncReplica(h *Handler, psync bool, replID string, offset int64) error {
+	if h.replica != nil {
+		return fmt.Errorf("connection is already a replication link")
+	}
+
+	s.writeMu.Lock()
+	s.replMu.Lock()
+
+	var missing []byte
+	partial := false
+	if psync {
+		missing, partial = s.backlogSinceLocked(replID, offset)
+		if partial {
+			s.syncPartialOK++
+		} else if replID != "?" {
+			s.syncPartialErr++
+		}
+	}
+
+	var entries []store.Entry
+	if !partial {
+		entries = s.store.Snapshot()
+		s.syncFull++
+	}
+
+	r := newReplica(h)
+	s.replicas[r] = struct{}{}
+	currentID, currentOffset := s.replID, s.backlog.offset
+
+	s.replMu.Unlock()
+	s.writeMu.Unlock()
+
+	if partial {
+		fmt.Fprintf(h.writer, "+CONTINUE %s\r\n", currentID)
+		h.writer.Write(missing)
+		log.Printf("Replica %s resumed from offset %d", r.addr, offset)
+	} else {
+		var buf bytes.Buffer
+		if err := persistence.WriteRDB(&buf, entries); err != nil {
+			s.removeReplica(r)
+			return err
+		}
+		if psync {
+			fmt.Fprintf(h.writer, "+FULLRESYNC %s %d\r\n", currentID, currentOffset)
+		}
+		fmt.Fprintf(h.writer, "$%d\r\n", buf.Len())
+		h.writer.Write(buf.Bytes())
+		log.Printf("Replica %s synchronized with %d keys", r.addr, len(entries))
+	}
+
+	if err := h.writer.Flush(); err != nil {
+		s.removeReplica(r)
+		return err
+	}
+
+	h.replica = r
+	s.wg.Add(1)
+	go r.run(&s.wg)
+	return nil
+}
+
+// backlogSinceLocked returns the part of the stream a replica is missing if
+// it can resume from offset, the next byte it needs, under replID. It must
+// be called with s.replMu held.
+func (s *Server) backlogSinceLocked(replID string, offset int64) ([]byte, bool) {
+	if replID != s.replID && (replID != s.replID2 || offset > s.secondReplOffset) {
+		return nil, false
+	}
+	return s.backlog.since(offset - 1)
+}
+
+// propagate feeds a command into the replication stream: the backlog and
+// every replica. It must be called with s.writeMu held so the stream carries
+// writes in the order they were applied.
+func (s *Server) propagate(args []string) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	encoded := resp.EncodeCommand(args)
+	s.backlog.write(encoded)
+
+	for r := range s.replicas {
+		select {
+		case r.queue <- encoded:
+		default:
+			log.Printf("Replica %s is not keeping up, disconnecting it", r.addr)
+			s.removeReplicaLocked(r)
+		}
+	}
+}
+
+// ackReplica records the offset acknowledged by r and wakes up WAIT callers.
+func (s *Server) ackReplica(r *replica, offset int64) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	if offset > r.ackOffset {
+		r.ackOffset = offset
+	}
+	close(s.acked)
+	s.acked = make(chan struct{})
+}
+
+// waitForReplicas blocks until numReplicas replicas acknowledged offset or
+// timeout elapses, and returns the number of replicas that did. A zero
+// timeout blocks forever.
+func (s *Server) waitForReplicas(offset int64, numReplicas int, timeout time.Duration) (int, error) {
+	if s.isReplica() {
+		return 0, errWaitOnReplica
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	requested := false
+	for {
+		s.replMu.Lock()
+		acked := 0
+		for r := range s.replicas {
+			if r.ackOffset >= offset {
+				acked++
+			}
+		}
+		notify := s.acked
+		s.replMu.Unlock()
+
+		if acked >= numReplicas {
+			return acked, nil
+		}
+		if !requested {
+			// Ask the replicas to report their offsets right away instead
+			// of waiting for their next periodic acknowledgement.
+			s.writeMu.Lock()
+			s.propagate([]string{"REPLCONF", "GETACK", "*"})
+			s.writeMu.Unlock()
+			requested = true
+		}
+
+		select {
+		case <-notify:
+		case <-expired:
+			return acked, nil
+		case <-s.quit:
+			return acked, nil
+		}
+	}
+}
+
+func (s *Server) removeReplica(r *replica) {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	s.removeReplicaLocked(r)
+}
+
+func (s *Server) removeReplicaLocked(r *replica) {
+	if _, ok := s.replicas[r]; !ok {
+		return
+	}
+	delete(s.replicas, r)
+	close(r.quit)
+	r.conn.Close()
+}
+
+// dropReplicas disconnects every replica.
+func (s *Server) dropReplicas() {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+	for r := range s.replicas {
+		s.removeReplicaLocked(r)
+	}
+}
+
+// Role returns the ROLE reply for this server.
+func (s *Server) Role() []interface{} {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	if s.masterAddr != "" {
+		host, port, _ := net.SplitHostPort(s.masterAddr)
+		portNum, _ := strconv.Atoi(port)
+		return []interface{}{"slave", host, portNum, s.linkStatus, s.backlog.offset}
+	}
+
+	replicas := make([]interface{}, 0, len(s.replicas))
+	for r := range s.replicas {
+		host, port, _ := net.SplitHostPort(r.addr)
+		replicas = append(replicas, []interface{}{host, port, strconv.FormatInt(r.ackOffset, 10)})
+	}
+	return []interface{}{"master", s.backlog.offset, replicas}
+}
+
+// Info returns the INFO reply for section. The replication, stats, cluster
+// and raft sections are currently reported.
+func (s *Server) Info(section string) string {
+	switch section {
+	case "", "all", "default", "everything":
+		return s.replicationInfo() + "\r\n" + s.statsInfo() + "\r\n" + s.clusterInfo() + "\r\n" + s.raftInfo()
+	case "replication":
+		return s.replicationInfo()
+	case "stats":
+		return s.statsInfo()
+	case "cluster":
+		return s.clusterInfo()
+	case "raft":
+		return s.raftInfo()
+	default:
+		return ""
+	}
+}
+
+func (s *Server) replicationInfo() string {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# Replication\r\n")
+
+	if s.masterAddr != "" {
+		host, port, _ := net.SplitHostPort(s.masterAddr)
+		linkUp := "down"
+		if s.linkStatus == linkConnected {
+			linkUp = "up"
+		}
+		syncing := 0
+		if s.linkStatus == linkSync {
+			syncing = 1
+		}
+		fmt.Fprintf(&b, "role:slave\r\n")
+		fmt.Fprintf(&b, "master_host:%s\r\n", host)
+		fmt.Fprintf(&b, "master_port:%s\r\n", port)
+		fmt.Fprintf(&b, "master_link_status:%s\r\n", linkUp)
+		fmt.Fprintf(&b, "master_sync_in_progress:%d\r\n", syncing)
+		fmt.Fprintf(&b, "slave_repl_offset:%d\r\n", s.backlog.offset)
+	} else {
+		fmt.Fprintf(&b, "role:master\r\n")
+	}
+
+	fmt.Fprintf(&b, "connected_slaves:%d\r\n", len(s.replicas))
+	i := 0
+	for r := range s.replicas {
+		host, port, _ := net.SplitHostPort(r.addr)
+		fmt.Fprintf(&b, "slave%d:ip=%s,port=%s,state=online,offset=%d\r\n", i, host, port, r.ackOffset)
+		i++
+	}
+
+	replID2 := s.replID2
+	if replID2 == "" {
+		replID2 = strings.Repeat("0", 40)
+	}
+	fmt.Fprintf(&b, "master_replid:%s\r\n", s.replID)
+	fmt.Fprintf(&b, "master_replid2:%s\r\n", replID2)
+	fmt.Fprintf(&b, "master_repl_offset:%d\r\n", s.backlog.offset)
+	fmt.Fprintf(&b, "second_repl_offset:%d\r\n", s.secondReplOffset)
+	fmt.Fprintf(&b, "repl_backlog_active:1\r\n")
+	fmt.Fprintf(&b, "repl_backlog_size:%d\r\n", len(s.backlog.buf))
+	fmt.Fprintf(&b, "repl_backlog_first_byte_offset:%d\r\n", s.backlog.firstOffset()+1)
+	fmt.Fprintf(&b, "repl_backlog_histlen:%d\r\n", s.backlog.histlen)
+
+	return b.String()
+}
+
+func (s *Server) statsInfo() string {
+	s.replMu.Lock()
+	defer s.replMu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# Stats\r\n")
+	fmt.Fprintf(&b, "sync_full:%d\r\n", s.syncFull)
+	fmt.Fprintf(&b, "sync_partial_ok:%d\r\n", s.syncPartialOK)
+	fmt.Fprintf(&b, "sync_partial_err:%d\r\n", s.syncPartialErr)
+	return b.String()
+}
+
+// peerLink is a client connection to another server: a replica's link to
+// its primary, or the connection MIGRATE moves keys over.
+type peerLink struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	ackMu  sync.Mutex // Serializes acknowledgements once the stream is running
+}
+
+// ack sends REPLCONF ACK with offset to the primary.
+func (l *peerLink) ack(offset int64) error {
+	l.ackMu.Lock()
+	defer l.ackMu.Unlock()
+	return l.send("REPLCONF", "ACK", strconv.FormatInt(offset, 10))
+}
+
+func (l *peerLink) send(args ...string) error {
+	if _, err := l.writer.Write(resp.EncodeCommand(args)); err != nil {
+		return err
+	}
+	return l.writer.Flush()
+}
+
+// call sends a command and returns its simple string reply.
+func (l *peerLink) call(args ...string) (string, error) {
+	if err := l.send(args...); err != nil {
+		return "", err
+	}
+	line, err := l.readLine()
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(line, "-") {
+		return "", fmt.Errorf("%s rejected by primary: %s", args[0], line[1:])
+	}
+	if !strings.HasPrefix(line, "+") {
+		return "", fmt.Errorf("unexpected reply to %s: %q", args[0], line)
+	}
+	return line[1:], nil
+}
+
+func (l *peerLink) readLine() (string, error) {
+	line, err := l.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPayload reads a snapshot sent as "$<len>\r\n" followed by len bytes.
+// Unlike a bulk string, the payload is not terminated by CRLF.
+func (l *peerLink) readPayload() ([]byte, error) {
+	line, err := l.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(line, "-") {
+		return nil, fmt.Errorf("snapshot rejected by primary: %s", line[1:])
+	}
+	if !strings.HasPrefix(line, "$") {
+		return nil, fmt.Errorf("unexpected snapshot header: %q", line)
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid snapshot length: %q", line)
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(l.reader, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
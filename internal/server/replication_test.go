@@ -0,0 +1,194 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/pkg/client"
+)
+
+// call sends a command over c and returns the reply.
+func call(t *testing.T, c *client.Client, args ...string) interface{} {
+	t.Helper()
+	if err := c.Send(args[0], args[1:]...); err != nil {
+		t.Fatalf("Send(%v) error = %v", args, err)
+	}
+	reply, err := c.Receive()
+	if err != nil {
+		t.Fatalf("Receive(%v) error = %v", args, err)
+	}
+	return reply
+}
+
+// eventually polls cond until it holds or the timeout expires.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func startServer(t *testing.T, addr string) (*Server, *client.Client) {
+	t.Helper()
+	cfg := DefaultConfig(addr)
+	cfg.Dir = t.TempDir()
+
+	s := NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server on %s: %v", addr, err)
+	}
+	c, err := client.NewClient(addr)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("Failed to connect to %s: %v", addr, err)
+	}
+	return s, c
+}
+
+func TestReplication_FullSyncAndStream(t *testing.T) {
+	primary, pc := startServer(t, "localhost:6394")
+	defer primary.Stop()
+	defer pc.Close()
+
+	replicaSrv, rc := startServer(t, "localhost:6395")
+	defer replicaSrv.Stop()
+	defer rc.Close()
+
+	call(t, pc, "SET", "before", "sync")
+	call(t, pc, "ZADD", "board", "1", "alice")
+
+	if got := call(t, rc, "REPLICAOF", "localhost", "6394"); got != "OK" {
+		t.Fatalf("REPLICAOF = %v, want OK", got)
+	}
+
+	eventually(t, "full sync", func() bool {
+		return call(t, rc, "GET", "before") == "sync"
+	})
+
+	call(t, pc, "SET", "after", "stream")
+	call(t, pc, "DEL", "before")
+	eventually(t, "streamed writes", func() bool {
+		return call(t, rc, "GET", "after") == "stream" && call(t, rc, "GET", "before") == nil
+	})
+
+	if err, ok := call(t, rc, "SET", "direct", "write").(error); !ok || !strings.HasPrefix(err.Error(), "READONLY") {
+		t.Errorf("SET on replica = %v, want READONLY error", err)
+	}
+
+	role := call(t, rc, "ROLE").([]interface{})
+	if role[0] != "slave" || role[1] != "localhost" || role[2] != 6394 || role[3] != "connected" {
+		t.Errorf("ROLE on replica = %v", role)
+	}
+
+	role = call(t, pc, "ROLE").([]interface{})
+	if role[0] != "master" || len(role[2].([]interface{})) != 1 {
+		t.Errorf("ROLE on primary = %v, want one replica", role)
+	}
+	replicaInfo := role[2].([]interface{})[0].([]interface{})
+	if replicaInfo[1] != "6395" {
+		t.Errorf("replica listening port = %v, want 6395", replicaInfo[1])
+	}
+
+	info := call(t, rc, "INFO", "replication").(string)
+	if !strings.Contains(info, "role:slave") || !strings.Contains(info, "master_link_status:up") {
+		t.Errorf("INFO replication on replica = %q", info)
+	}
+	info = call(t, pc, "INFO", "replication").(string)
+	if !strings.Contains(info, "role:master") || !strings.Contains(info, "connected_slaves:1") {
+		t.Errorf("INFO replication on primary = %q", info)
+	}
+
+	if got := call(t, rc, "REPLICAOF", "NO", "ONE"); got != "OK" {
+		t.Fatalf("REPLICAOF NO ONE = %v, want OK", got)
+	}
+	call(t, rc, "SET", "direct", "write")
+	if got := call(t, rc, "GET", "direct"); got != "write" {
+		t.Errorf("GET direct after promotion = %v, want write", got)
+	}
+	eventually(t, "replica to detach", func() bool {
+		role := call(t, pc, "ROLE").([]interface{})
+		return len(role[2].([]interface{})) == 0
+	})
+}
+
+func TestReplication_PartialResync(t *testing.T) {
+	primary, pc := startServer(t, "localhost:6396")
+	defer primary.Stop()
+	defer pc.Close()
+
+	replicaSrv, rc := startServer(t, "localhost:6397")
+	defer replicaSrv.Stop()
+	defer rc.Close()
+
+	call(t, pc, "SET", "first", "1")
+	call(t, rc, "REPLICAOF", "localhost", "6396")
+	eventually(t, "full sync", func() bool {
+		return call(t, rc, "GET", "first") == "1"
+	})
+
+	// Break the link and write while the replica is disconnected.
+	replicaSrv.replMu.Lock()
+	replicaSrv.masterConn.Close()
+	replicaSrv.replMu.Unlock()
+	call(t, pc, "SET", "missed", "2")
+	call(t, pc, "DEL", "first")
+
+	eventually(t, "partial resync", func() bool {
+		return call(t, rc, "GET", "missed") == "2" && call(t, rc, "GET", "first") == nil
+	})
+
+	stats := call(t, pc, "INFO", "stats").(string)
+	if !strings.Contains(stats, "sync_full:1\r\n") || !strings.Contains(stats, "sync_partial_ok:1\r\n") {
+		t.Errorf("INFO stats on primary = %q, want one full and one partial sync", stats)
+	}
+
+	primaryRole := call(t, pc, "ROLE").([]interface{})
+	eventually(t, "offsets to match", func() bool {
+		return call(t, rc, "ROLE").([]interface{})[4] == primaryRole[1]
+	})
+}
+
+func TestReplication_Wait(t *testing.T) {
+	primary, pc := startServer(t, "localhost:6398")
+	defer primary.Stop()
+	defer pc.Close()
+
+	replicaSrv, rc := startServer(t, "localhost:6399")
+	defer replicaSrv.Stop()
+	defer rc.Close()
+
+	if got := call(t, pc, "WAIT", "0", "0"); got != 0 {
+		t.Errorf("WAIT 0 0 without replicas = %v, want 0", got)
+	}
+
+	call(t, rc, "REPLICAOF", "localhost", "6398")
+	eventually(t, "replica to connect", func() bool {
+		return len(call(t, pc, "ROLE").([]interface{})[2].([]interface{})) == 1
+	})
+
+	call(t, pc, "SET", "critical", "value")
+	if got := call(t, pc, "WAIT", "1", "0"); got != 1 {
+		t.Errorf("WAIT 1 0 = %v, want 1", got)
+	}
+	if got := call(t, rc, "GET", "critical"); got != "value" {
+		t.Errorf("GET on replica after WAIT = %v, want value", got)
+	}
+
+	start := time.Now()
+	if got := call(t, pc, "WAIT", "2", "100"); got != 1 {
+		t.Errorf("WAIT 2 100 = %v, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("WAIT 2 100 returned after %v, want it to block until the timeout", elapsed)
+	}
+
+	if _, ok := call(t, rc, "WAIT", "1", "10").(error); !ok {
+		t.Error("WAIT on a replica succeeded, want error")
+	}
+}
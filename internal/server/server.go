@@ -5,7 +5,11 @@ import (
 	"log"
 	"net"
 	"sync"
+	"time"
 
+	"github.com/hardikphalet/go-redis/internal/cluster"
+	"github.com/hardikphalet/go-redis/internal/persistence"
+	"github.com/hardikphalet/go-redis/internal/raft"
 	"github.com/hardikphalet/go-redis/internal/store"
 )
 
@@ -17,28 +21,118 @@ type Server struct {
 	quit     chan struct{}
 	mu       sync.Mutex
 	stopped  bool
+	conns    map[net.Conn]struct{} // Open client connections, guarded by mu
+	config   Config
+	loadErr  error
+
+	cluster    *cluster.Cluster // Nil unless cluster mode is enabled
+	clusterErr error
+
+	raft    *raft.Node // Nil unless Raft mode is enabled
+	raftErr error
+
+	saveMu   sync.Mutex
+	bgSaving bool
+	lastSave time.Time
+
+	writeMu sync.Mutex
+	aof     *persistence.AOF
+
+	replMu     sync.Mutex
+	replicas   map[*replica]struct{}
+	masterAddr string        // Primary being replicated, empty on a primary
+	linkStatus string        // State of the link to the primary
+	stopLink   chan struct{} // Closed to stop the current replication loop
+	masterConn net.Conn
+
+	replID           string // ID of the replication history we serve
+	replID2          string // Previous ID, valid up to secondReplOffset
+	secondReplOffset int64
+	backlog          *backlog
+	acked            chan struct{} // Closed and replaced on every REPLCONF ACK
+
+	syncFull       int
+	syncPartialOK  int
+	syncPartialErr int
 }
 
 func New(address string) *Server {
-	return &Server{
-		port:    address,
-		store:   store.NewMemoryStore(),
-		quit:    make(chan struct{}),
-		stopped: false,
+	return NewWithConfig(DefaultConfig(address))
+}
+
+// NewWithConfig builds a server from cfg and loads the AOF or snapshot file,
+// if one exists, into its store. In Raft mode the dataset is rebuilt from the
+// Raft snapshot and log instead.
+func NewWithConfig(cfg Config) *Server {
+	s := &Server{
+		port:     cfg.Addr,
+		store:    store.NewMemoryStore(),
+		quit:     make(chan struct{}),
+		stopped:  false,
+		config:   cfg,
+		lastSave: time.Now(),
+		replicas: make(map[*replica]struct{}),
+		conns:    make(map[net.Conn]struct{}),
+
+		replID:           newReplID(),
+		secondReplOffset: -1,
+		backlog:          newBacklog(cfg.ReplBacklogSize, 0),
+		acked:            make(chan struct{}),
+	}
+	if cfg.ClusterEnabled {
+		s.cluster, s.clusterErr = newCluster(cfg)
+	}
+	if cfg.RaftAddr != "" {
+		s.raft, s.raftErr = newRaftNode(cfg, s)
+		return s
 	}
+	s.loadErr = s.load()
+	return s
 }
 
 func (s *Server) Start() error {
+	if s.clusterErr != nil {
+		return fmt.Errorf("failed to configure cluster: %w", s.clusterErr)
+	}
+	if s.raftErr != nil {
+		return fmt.Errorf("failed to configure Raft: %w", s.raftErr)
+	}
+	if s.loadErr != nil {
+		return fmt.Errorf("failed to load data: %w", s.loadErr)
+	}
+
+	if s.config.AppendOnly {
+		if err := s.openAOF(); err != nil {
+			return fmt.Errorf("failed to open AOF: %w", err)
+		}
+	}
+
 	var err error
 	s.listener, err = net.Listen("tcp", s.port)
 	if err != nil {
+		if s.aof != nil {
+			s.aof.Close()
+			s.aof = nil
+		}
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
+	if s.raft != nil {
+		if err := s.raft.Start(); err != nil {
+			s.listener.Close()
+			if s.aof != nil {
+				s.aof.Close()
+				s.aof = nil
+			}
+			return err
+		}
+	}
+
 	log.Printf("Server listening on %s", s.port)
 
-	s.wg.Add(1)
+	s.wg.Add(2)
 	go s.acceptConnections()
+	go s.activeExpireLoop()
 
 	return nil
 }
@@ -51,15 +145,36 @@ func (s *Server) Stop() error {
 	}
 	s.stopped = true
 	close(s.quit)
+	// Idle clients would otherwise keep their handlers, and Stop, waiting.
+	for conn := range s.conns {
+		conn.Close()
+	}
 	s.mu.Unlock()
 
+	s.replMu.Lock()
+	s.stopReplicationLocked()
+	s.replMu.Unlock()
+	s.dropReplicas()
+
 	if s.listener != nil {
 		if err := s.listener.Close(); err != nil {
 			return fmt.Errorf("failed to close listener: %w", err)
 		}
 	}
 
+	// Stopping Raft releases clients still waiting for a commit.
+	if s.raft != nil {
+		s.raft.Stop()
+	}
+
 	s.wg.Wait()
+
+	if s.aof != nil {
+		if err := s.aof.Close(); err != nil {
+			return fmt.Errorf("failed to close AOF: %w", err)
+		}
+		s.aof = nil
+	}
 	return nil
 }
 
@@ -82,6 +197,15 @@ func (s *Server) acceptConnections() {
 				}
 			}
 
+			s.mu.Lock()
+			if s.stopped {
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conns[conn] = struct{}{}
+			s.mu.Unlock()
+
 			s.wg.Add(1)
 			go s.handleConnection(conn)
 		}
@@ -90,6 +214,9 @@ func (s *Server) acceptConnections() {
 
 func (s *Server) handleConnection(conn net.Conn) {
 	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
 		conn.Close()
 		s.wg.Done()
 	}()
@@ -98,7 +225,12 @@ func (s *Server) handleConnection(conn net.Conn) {
 	log.Printf("New client connection from %s", remoteAddr)
 
 	handler := NewHandler(conn, s.store)
-	if err := handler.Handle(); err != nil {
+	handler.server = s
+	err := handler.Handle()
+	if handler.replica != nil {
+		s.removeReplica(handler.replica)
+	}
+	if err != nil {
 		log.Printf("Error handling connection from %s: %v", remoteAddr, err)
 	} else {
 		log.Printf("Client %s disconnected", remoteAddr)
@@ -0,0 +1,364 @@
+package store
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+)
+
+// Bitmaps are plain strings addressed bit by bit, with bit 0 the most
+// significant bit of the first byte. Writing past the end grows the string
+// with zero bytes.
+
+// getBit returns bit offset of str, which is 0 past its end.
+func getBit[T string | []byte](str T, offset int64) uint64 {
+	i := offset >> 3
+	if i >= int64(len(str)) {
+		return 0
+	}
+	return uint64(str[i]>>(7-offset&7)) & 1
+}
+
+// setBit sets bit offset of str, which must be long enough to hold it.
+func setBit(str []byte, offset int64, bit uint64) {
+	mask := byte(1) << (7 - offset&7)
+	if bit != 0 {
+		str[offset>>3] |= mask
+	} else {
+		str[offset>>3] &^= mask
+	}
+}
+
+// growString pads str with zero bytes to at least size bytes, in place if
+// it has the capacity.
+func growString(str []byte, size int64) []byte {
+	if n := size - int64(len(str)); n > 0 {
+		str = append(str, make([]byte, n)...)
+	}
+	return str
+}
+
+// bitRange resolves r against a string of length bytes to an inclusive
+// range of bit offsets, reporting false when it is empty. A nil r covers
+// the whole string.
+func bitRange(r *options.BitRange, length int64) (int64, int64, bool) {
+	if r == nil {
+		return 0, length*8 - 1, length > 0
+	}
+
+	total := length
+	if r.Bit {
+		total *= 8
+	}
+	start, end := r.Start, r.End
+	if !r.HasEnd {
+		end = total - 1
+	}
+	if start < 0 {
+		start += total
+	}
+	if end < 0 {
+		end += total
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	end = min(end, total-1)
+	if start > end {
+		return 0, 0, false
+	}
+	if r.Bit {
+		return start, end, true
+	}
+	return start * 8, end*8 + 7, true
+}
+
+// bitMask returns the mask of the bits of byte i within the range of bit
+// offsets first..last.
+func bitMask(i, first, last int64) byte {
+	mask := byte(0xff)
+	if i == first>>3 {
+		mask &= 0xff >> (first & 7)
+	}
+	if i == last>>3 {
+		mask &= 0xff << (7 - last&7)
+	}
+	return mask
+}
+
+// SetBit sets the bit at offset of the string at key to value and returns
+// its previous value.
+func (s *MemoryStore) SetBit(key string, offset int64, value int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	buf := growString(str, offset>>3+1)
+	old := getBit(buf, offset)
+	setBit(buf, offset, uint64(value))
+	s.data[key] = buf
+	return int(old), nil
+}
+
+// GetBit returns the bit at offset of the string at key.
+func (s *MemoryStore) GetBit(key string, offset int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	return int(getBit(str, offset)), nil
+}
+
+// BitCount returns the number of set bits in the string at key, within r
+// if it is not nil.
+func (s *MemoryStore) BitCount(key string, r *options.BitRange) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	first, last, ok := bitRange(r, int64(len(str)))
+	if !ok {
+		return 0, nil
+	}
+
+	var count int64
+	for i := first >> 3; i <= last>>3; i++ {
+		count += int64(bits.OnesCount8(str[i] & bitMask(i, first, last)))
+	}
+	return count, nil
+}
+
+// BitPos returns the offset of the first bit set to bit in the string at
+// key, within r if it is not nil, or -1 if there is none. A missing key is
+// all zeros, and so is the string past its end unless r gives an end.
+func (s *MemoryStore) BitPos(key string, bit int, r *options.BitRange) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, ok, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		if bit == 1 {
+			return -1, nil
+		}
+		return 0, nil
+	}
+	first, last, ok := bitRange(r, int64(len(str)))
+	if !ok {
+		return -1, nil
+	}
+
+	for i := first >> 3; i <= last>>3; i++ {
+		b := str[i]
+		if bit == 0 {
+			b = ^b
+		}
+		if b &= bitMask(i, first, last); b != 0 {
+			return i*8 + int64(bits.LeadingZeros8(b)), nil
+		}
+	}
+	if bit == 0 && (r == nil || !r.HasEnd) {
+		return last + 1, nil
+	}
+	return -1, nil
+}
+
+// BitOp stores the bitwise AND, OR, XOR or NOT of the strings at keys at
+// destination and returns its length. Shorter strings and missing keys are
+// padded with zero bytes, and an empty result deletes destination.
+func (s *MemoryStore) BitOp(op, destination string, keys []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strs := make([][]byte, len(keys))
+	length := 0
+	for i, key := range keys {
+		str, _, err := s.getBytes(key)
+		if err != nil {
+			return 0, err
+		}
+		strs[i] = str
+		length = max(length, len(str))
+	}
+
+	result := make([]byte, length)
+	copy(result, strs[0])
+	for _, str := range strs[1:] {
+		for i := range result {
+			var b byte
+			if i < len(str) {
+				b = str[i]
+			}
+			switch op {
+			case "AND":
+				result[i] &= b
+			case "OR":
+				result[i] |= b
+			case "XOR":
+				result[i] ^= b
+			}
+		}
+	}
+	if op == "NOT" {
+		for i := range result {
+			result[i] = ^result[i]
+		}
+	}
+
+	delete(s.data, destination)
+	delete(s.expires, destination)
+	if length > 0 {
+		s.data[destination] = result
+	}
+	return length, nil
+}
+
+// BitField runs the operations of BITFIELD on the string at key in order.
+// The reply has the value read by each GET, the previous value for each SET
+// and the new value for each INCRBY, or nil for a SET or INCRBY that
+// overflowed with OVERFLOW FAIL. The string is created or grown to hold
+// every integer written, even if the writes fail.
+func (s *MemoryStore) BitField(key string, ops []options.BitFieldOp) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int64
+	write := false
+	for _, op := range ops {
+		if op.Op != "GET" {
+			write = true
+			size = max(size, (op.Offset+int64(op.Bits)-1)>>3+1)
+		}
+	}
+	buf := growString(str, size)
+
+	reply := make([]interface{}, len(ops))
+	for i, op := range ops {
+		old := getBitfield(buf, op.Offset, op.Bits)
+		if op.Signed {
+			old = signExtend(old, op.Bits)
+		}
+		if op.Op == "GET" {
+			reply[i] = int64(old)
+			continue
+		}
+
+		value, incr := op.Value, int64(0)
+		if op.Op == "INCRBY" {
+			value, incr = int64(old), op.Value
+		}
+		var result uint64
+		var overflow bool
+		if op.Signed {
+			result, overflow = signedBitfieldOverflow(value, incr, op.Bits, op.Overflow)
+		} else {
+			result, overflow = unsignedBitfieldOverflow(uint64(value), incr, op.Bits, op.Overflow)
+		}
+		if overflow && op.Overflow == "FAIL" {
+			reply[i] = nil
+			continue
+		}
+		if !overflow {
+			result = uint64(value + incr)
+		}
+
+		setBitfield(buf, op.Offset, op.Bits, result)
+		if op.Op == "SET" {
+			reply[i] = int64(old)
+		} else {
+			reply[i] = int64(result)
+		}
+	}
+
+	if write {
+		s.data[key] = buf
+	}
+	return reply, nil
+}
+
+// getBitfield reads an unsigned integer of width bits at offset.
+func getBitfield(str []byte, offset int64, width int) uint64 {
+	var value uint64
+	for j := 0; j < width; j++ {
+		value = value<<1 | getBit(str, offset+int64(j))
+	}
+	return value
+}
+
+// setBitfield writes the low width bits of value at offset.
+func setBitfield(str []byte, offset int64, width int, value uint64) {
+	for j := 0; j < width; j++ {
+		setBit(str, offset+int64(j), value>>(width-1-j)&1)
+	}
+}
+
+// signExtend interprets the low width bits of value as a two's complement
+// integer.
+func signExtend(value uint64, width int) uint64 {
+	if width < 64 && value&(1<<(width-1)) != 0 {
+		value |= math.MaxUint64 << width
+	}
+	return value
+}
+
+// unsignedBitfieldOverflow reports whether adding incr to value overflows
+// an unsigned integer of width bits and, if it does, the result wrapped or
+// saturated as overflowType asks.
+func unsignedBitfieldOverflow(value uint64, incr int64, width int, overflowType string) (uint64, bool) {
+	maxValue := uint64(math.MaxUint64) >> (64 - width)
+	maxIncr := int64(maxValue - value)
+	minIncr := -int64(value)
+
+	var limit uint64
+	switch {
+	case value > maxValue || (incr > 0 && incr > maxIncr):
+		limit = maxValue
+	case incr < 0 && incr < minIncr:
+		limit = 0
+	default:
+		return 0, false
+	}
+	if overflowType == "WRAP" {
+		limit = (value + uint64(incr)) & maxValue
+	}
+	return limit, true
+}
+
+// signedBitfieldOverflow is unsignedBitfieldOverflow for a signed integer
+// of width bits.
+func signedBitfieldOverflow(value, incr int64, width int, overflowType string) (uint64, bool) {
+	maxValue := int64(math.MaxInt64 >> (64 - width))
+	minValue := -maxValue - 1
+	maxIncr := maxValue - value
+	minIncr := minValue - value
+
+	var limit int64
+	switch {
+	case value > maxValue || (width != 64 && incr > maxIncr) || (value >= 0 && incr > 0 && incr > maxIncr):
+		limit = maxValue
+	case value < minValue || (width != 64 && incr < minIncr) || (value < 0 && incr < 0 && incr < minIncr):
+		limit = minValue
+	default:
+		return 0, false
+	}
+	if overflowType == "WRAP" {
+		return signExtend((uint64(value)+uint64(incr))&(math.MaxUint64>>(64-width)), width), true
+	}
+	return uint64(limit), true
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+)
+
+func TestMemoryStore_SetBit(t *testing.T) {
+	store := NewMemoryStore()
+
+	if old, err := store.SetBit("k", 7, 1); err != nil || old != 0 {
+		t.Fatalf("SetBit() = %d, %v, want 0", old, err)
+	}
+	if old, _ := store.SetBit("k", 7, 0); old != 1 {
+		t.Errorf("SetBit() of a set bit = %d, want 1", old)
+	}
+	store.SetBit("k", 7, 1)
+	for offset, want := range map[int64]int{0: 0, 7: 1, 100: 0} {
+		if got, _ := store.GetBit("k", offset); got != want {
+			t.Errorf("GetBit(%d) = %d, want %d", offset, got, want)
+		}
+	}
+
+	// Setting a bit past the end pads the string with zero bytes.
+	store.SetBit("k", 23, 1)
+	if got, _ := store.Get("k"); got != "\x01\x00\x01" {
+		t.Errorf("Get() after SetBit(23) = %q", got)
+	}
+
+	store.SAdd("set", []string{"a"})
+	if _, err := store.SetBit("set", 0, 1); err != ErrWrongType {
+		t.Errorf("SetBit() on a set error = %v, want %v", err, ErrWrongType)
+	}
+}
+
+func TestMemoryStore_BitCountAndPos(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("foobar", "foobar", nil)
+	store.Set("a", "\xff\xf0\x00", nil)
+	store.Set("b", "\x00\xff\xf0", nil)
+	store.Set("zeros", "\x00\x00\x00", nil)
+	store.Set("ones", "\xff", nil)
+
+	counts := []struct {
+		r    *options.BitRange
+		want int64
+	}{
+		{nil, 26},
+		{&options.BitRange{Start: 0, End: 0, HasEnd: true}, 4},
+		{&options.BitRange{Start: 1, End: 1, HasEnd: true}, 6},
+		{&options.BitRange{Start: 5, End: 30, HasEnd: true, Bit: true}, 17},
+		{&options.BitRange{Start: -2, End: -1, HasEnd: true}, 7},
+		{&options.BitRange{Start: 3, End: 1, HasEnd: true}, 0},
+	}
+	for _, tt := range counts {
+		if got, _ := store.BitCount("foobar", tt.r); got != tt.want {
+			t.Errorf("BitCount(%+v) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+
+	positions := []struct {
+		key  string
+		bit  int
+		r    *options.BitRange
+		want int64
+	}{
+		{"a", 0, nil, 12},
+		{"b", 1, &options.BitRange{Start: 0}, 8},
+		{"b", 1, &options.BitRange{Start: 2}, 16},
+		{"b", 1, &options.BitRange{Start: 2, End: -1, HasEnd: true}, 16},
+		{"b", 1, &options.BitRange{Start: 7, End: 15, HasEnd: true, Bit: true}, 8},
+		{"b", 1, &options.BitRange{Start: 7, End: -3, HasEnd: true, Bit: true}, 8},
+		{"zeros", 1, nil, -1},
+		// The string is followed by zeros unless the range ends explicitly.
+		{"foobar", 0, nil, 0},
+		{"ones", 0, nil, 8},
+		{"a", 0, &options.BitRange{Start: 0, End: 0, HasEnd: true}, -1},
+		{"missing", 0, nil, 0},
+		{"missing", 1, nil, -1},
+	}
+	for _, tt := range positions {
+		if got, _ := store.BitPos(tt.key, tt.bit, tt.r); got != tt.want {
+			t.Errorf("BitPos(%s, %d, %+v) = %d, want %d", tt.key, tt.bit, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStore_BitOp(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("key1", "foobar", nil)
+	store.Set("key2", "abcdef", nil)
+	store.Set("short", "\xff", nil)
+
+	tests := []struct {
+		op   string
+		keys []string
+		want string
+	}{
+		{"AND", []string{"key1", "key2"}, "`bc`ab"},
+		{"OR", []string{"key1", "key2"}, "goofev"},
+		{"XOR", []string{"key1", "short"}, "\x99oobar"},
+		{"AND", []string{"key1", "short"}, "f\x00\x00\x00\x00\x00"},
+		{"NOT", []string{"short"}, "\x00"},
+		{"OR", []string{"key1", "missing"}, "foobar"},
+	}
+	for _, tt := range tests {
+		n, err := store.BitOp(tt.op, "dest", tt.keys)
+		if err != nil || n != len(tt.want) {
+			t.Fatalf("BitOp(%s, %v) = %d, %v, want %d", tt.op, tt.keys, n, err, len(tt.want))
+		}
+		if got, _ := store.Get("dest"); got != tt.want {
+			t.Errorf("BitOp(%s, %v) stored %q, want %q", tt.op, tt.keys, got, tt.want)
+		}
+	}
+
+	if n, _ := store.BitOp("OR", "dest", []string{"missing"}); n != 0 || store.Exists("dest") {
+		t.Errorf("BitOp() of missing keys = %d, want 0 and dest deleted", n)
+	}
+}
+
+func TestMemoryStore_BitField(t *testing.T) {
+	store := NewMemoryStore()
+	op := func(name, typ string, offset, value int64, overflow string) options.BitFieldOp {
+		signed, bits, err := options.ParseBitFieldType(typ)
+		if err != nil {
+			t.Fatalf("ParseBitFieldType(%q) error = %v", typ, err)
+		}
+		return options.BitFieldOp{Op: name, Signed: signed, Bits: bits, Offset: offset, Value: value, Overflow: overflow}
+	}
+
+	got, _ := store.BitField("k", []options.BitFieldOp{op("INCRBY", "i5", 100, 1, "WRAP"), op("GET", "u4", 0, 0, "WRAP")})
+	if want := []interface{}{int64(1), int64(0)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BitField() = %v, want %v", got, want)
+	}
+
+	// Two unsigned 2-bit counters, one wrapping and one saturating.
+	wantWrap := []int64{1, 2, 3, 0}
+	wantSat := []int64{1, 2, 3, 3}
+	for i := range wantWrap {
+		got, _ := store.BitField("counters", []options.BitFieldOp{op("INCRBY", "u2", 100, 1, "WRAP"), op("INCRBY", "u2", 102, 1, "SAT")})
+		if want := []interface{}{wantWrap[i], wantSat[i]}; !reflect.DeepEqual(got, want) {
+			t.Errorf("BitField() round %d = %v, want %v", i, got, want)
+		}
+	}
+	if got, _ := store.BitField("counters", []options.BitFieldOp{op("INCRBY", "u2", 102, 1, "FAIL")}); got[0] != nil {
+		t.Errorf("BitField() overflowing with FAIL = %v, want nil", got)
+	}
+
+	tests := []struct {
+		ops  []options.BitFieldOp
+		want []interface{}
+	}{
+		{[]options.BitFieldOp{op("SET", "i8", 0, 200, "WRAP"), op("GET", "i8", 0, 0, "")}, []interface{}{int64(0), int64(-56)}},
+		{[]options.BitFieldOp{op("SET", "i8", 0, 200, "SAT"), op("GET", "i8", 0, 0, "")}, []interface{}{int64(-56), int64(127)}},
+		{[]options.BitFieldOp{op("SET", "i8", 0, 0, "SAT"), op("INCRBY", "i8", 0, -100, "SAT"), op("INCRBY", "i8", 0, -100, "SAT")}, []interface{}{int64(127), int64(-100), int64(-128)}},
+		{[]options.BitFieldOp{op("SET", "u8", 0, -1, "WRAP"), op("SET", "u8", 0, -1, "SAT"), op("GET", "u8", 0, 0, "")}, []interface{}{int64(0x80), int64(255), int64(255)}},
+		{[]options.BitFieldOp{op("SET", "i64", 8, math.MaxInt64, "WRAP"), op("INCRBY", "i64", 8, 1, "WRAP"), op("INCRBY", "i64", 8, -1, "FAIL")}, []interface{}{int64(0), int64(math.MinInt64), nil}},
+		{[]options.BitFieldOp{op("SET", "u63", 8, math.MaxInt64, "WRAP"), op("INCRBY", "u63", 8, 1, "SAT")}, []interface{}{int64(1 << 62), int64(math.MaxInt64)}},
+	}
+	for _, tt := range tests {
+		if got, _ := store.BitField("ints", tt.ops); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BitField(%+v) = %v, want %v", tt.ops, got, tt.want)
+		}
+	}
+
+	// GET alone does not create the key, while a failed write still does.
+	store.BitField("get", []options.BitFieldOp{op("GET", "u8", 0, 0, "")})
+	if store.Exists("get") {
+		t.Errorf("BitField() with GET alone created the key")
+	}
+	store.BitField("fail", []options.BitFieldOp{op("SET", "u8", 8, 256, "FAIL")})
+	if got, _ := store.Get("fail"); got != "\x00\x00" {
+		t.Errorf("BitField() with a failed SET stored %q, want two zero bytes", got)
+	}
+}
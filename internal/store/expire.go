@@ -0,0 +1,111 @@
+package store
+
+import "time"
+
+// activeExpireSample is how many volatile keys, and how many hashes with
+// volatile fields, a round of ActiveExpire checks.
+const activeExpireSample = 20
+
+// ActiveExpire removes expired keys and hash fields that no command has
+// touched since they expired, which lazy expiry alone would keep in memory
+// forever. Each round checks a random sample of volatile keys and hashes,
+// and rounds repeat while more than a quarter of a sample had expired and
+// the time budget lasts. It returns how many keys and fields were removed.
+func (s *MemoryStore) ActiveExpire(budget time.Duration) int {
+	deadline := time.Now().Add(budget)
+	removed := 0
+	for {
+		expired, sampled := s.activeExpireRound()
+		removed += expired
+		if sampled == 0 || expired*4 <= sampled || time.Now().After(deadline) {
+			return removed
+		}
+	}
+}
+
+// activeExpireRound runs one round of ActiveExpire and returns how many
+// keys and fields it removed and how many keys it checked.
+func (s *MemoryStore) activeExpireRound() (expired, sampled int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loading {
+		return 0, 0
+	}
+	now := time.Now()
+
+	// Map iteration starts at a random position, which makes the first
+	// entries a random sample.
+	checked := 0
+	for key, at := range s.expires {
+		if checked == activeExpireSample {
+			break
+		}
+		checked++
+		if now.After(at) {
+			delete(s.data, key)
+			delete(s.expires, key)
+			expired++
+		}
+	}
+	sampled += checked
+
+	checked = 0
+	for key := range s.volatileHashes {
+		if checked == activeExpireSample {
+			break
+		}
+		checked++
+		hash, ok := s.data[key].(*Hash)
+		if !ok {
+			// The key was deleted or overwritten.
+			delete(s.volatileHashes, key)
+			continue
+		}
+		expired += hash.removeExpired(now)
+		s.deleteIfEmptyHash(key, hash)
+	}
+	sampled += checked
+
+	return expired, sampled
+}
+
+// StartLoading suspends expiry while a log of past writes is replayed into
+// the store, as Redis does while loading. Keys and hash fields whose
+// deadline passes during the replay stay in place, and expiries in the
+// past are recorded rather than deleting the data, so the commands logged
+// after them find what they originally found.
+func (s *MemoryStore) StartLoading() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading = true
+}
+
+// StopLoading resumes expiry and removes in one pass the keys and hash
+// fields that expired while it was suspended. It returns how many there
+// were.
+func (s *MemoryStore) StopLoading() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loading = false
+	now := time.Now()
+	removed := 0
+	for key, at := range s.expires {
+		if now.After(at) {
+			delete(s.data, key)
+			delete(s.expires, key)
+			removed++
+		}
+	}
+	for key := range s.volatileHashes {
+		hash, ok := s.data[key].(*Hash)
+		if !ok {
+			delete(s.volatileHashes, key)
+			continue
+		}
+		removed += hash.removeExpired(now)
+		s.deleteIfEmptyHash(key, hash)
+	}
+	return removed
+}
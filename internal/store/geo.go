@@ -0,0 +1,429 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// Geo sets are sorted sets whose scores are 52-bit geohashes: the longitude
+// and latitude are each quantized to 26 bits and interleaved, longitude
+// first, so that members close to each other tend to have close scores. As
+// in Redis, latitudes are limited to the range of the Web Mercator
+// projection.
+const (
+	geoStepMax  = 26
+	geoLonMin   = -180.0
+	geoLonMax   = 180.0
+	geoLatMin   = -85.05112878
+	geoLatMax   = 85.05112878
+	earthRadius = 6372797.560856 // In meters
+	mercatorMax = 20037726.37    // Half the circumference of the earth in Web Mercator meters
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+var errGeoMemberNotFound = fmt.Errorf("could not decode requested zset member")
+
+func validLonLat(lon, lat float64) bool {
+	return lon >= geoLonMin && lon <= geoLonMax && lat >= geoLatMin && lat <= geoLatMax
+}
+
+// geoCell returns the column and row of the cell containing the point in a
+// grid of 2^step by 2^step cells spanning the given latitude range.
+func geoCell(lon, lat, latMin, latMax float64, step uint) (x, y uint64) {
+	cells := float64(uint64(1) << step)
+	x = uint64((lon - geoLonMin) / (geoLonMax - geoLonMin) * cells)
+	y = uint64((lat - latMin) / (latMax - latMin) * cells)
+	// The maximum longitude and latitude fall on the edge of the grid
+	max := uint64(1)<<step - 1
+	return min(x, max), min(y, max)
+}
+
+// interleave merges the bits of x and y, taking the higher bit of each pair
+// from x.
+func interleave(x, y uint64, step uint) uint64 {
+	var hash uint64
+	for i := int(step) - 1; i >= 0; i-- {
+		hash = hash<<2 | (x>>uint(i)&1)<<1 | y>>uint(i)&1
+	}
+	return hash
+}
+
+func deinterleave(hash uint64, step uint) (x, y uint64) {
+	for i := int(step) - 1; i >= 0; i-- {
+		pair := hash >> uint(2*i) & 3
+		x = x<<1 | pair>>1
+		y = y<<1 | pair&1
+	}
+	return x, y
+}
+
+// geohashEncode returns the 52-bit geohash of a point, used as its score.
+func geohashEncode(lon, lat float64) uint64 {
+	x, y := geoCell(lon, lat, geoLatMin, geoLatMax, geoStepMax)
+	return interleave(x, y, geoStepMax)
+}
+
+// geohashDecode returns the center of the cell a geohash stands for.
+func geohashDecode(hash uint64) (lon, lat float64) {
+	x, y := deinterleave(hash, geoStepMax)
+	cells := float64(uint64(1) << geoStepMax)
+	lon = geoLonMin + (float64(x)+0.5)*(geoLonMax-geoLonMin)/cells
+	lat = geoLatMin + (float64(y)+0.5)*(geoLatMax-geoLatMin)/cells
+	return lon, lat
+}
+
+// geohashString returns the standard 11-character geohash of the point a
+// score stands for. Standard geohashes span latitudes from -90 to 90, so
+// the point is encoded again rather than reusing the score.
+func geohashString(score float64) string {
+	lon, lat := geohashDecode(uint64(score))
+	x, y := geoCell(lon, lat, -90, 90, geoStepMax)
+	hash := interleave(x, y, geoStepMax)
+
+	// Like Redis, use the first 50 bits for ten characters and pad with a
+	// zero character
+	var b strings.Builder
+	for i := 1; i <= 10; i++ {
+		b.WriteByte(geohashAlphabet[hash>>uint(52-i*5)&0x1f])
+	}
+	b.WriteByte(geohashAlphabet[0])
+	return b.String()
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// geoDistance returns the distance in meters between two points using the
+// haversine formula, as Redis does.
+func geoDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := degToRad(lat1), degToRad(lat2)
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin(degToRad(lon2-lon1) / 2)
+	a := u*u + math.Cos(lat1r)*math.Cos(lat2r)*v*v
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// geoEstimateStep returns the geohash precision whose cells are about as
+// large as a search of the given radius, coarser near the poles where cells
+// shrink.
+func geoEstimateStep(radius, lat float64) uint {
+	if radius == 0 {
+		return geoStepMax
+	}
+	step := 1
+	for radius < mercatorMax {
+		radius *= 2
+		step++
+	}
+	step -= 2
+	if lat > 66 || lat < -66 {
+		step--
+		if lat > 80 || lat < -80 {
+			step--
+		}
+	}
+	return uint(max(1, min(step, geoStepMax)))
+}
+
+// geoSearchRanges returns the score ranges, each as [min, max), that
+// contain every member within the area opts describe around lon, lat: those
+// of the cell containing the center and its eight neighbors, at a precision
+// at which the nine cells cover the area.
+func geoSearchRanges(lon, lat float64, opts *options.GeoSearchOptions) [][2]uint64 {
+	halfWidth, halfHeight := opts.Radius, opts.Radius
+	if opts.ByBox {
+		halfWidth, halfHeight = opts.Width/2, opts.Height/2
+	}
+	latDelta := radToDeg(halfHeight / earthRadius)
+	lonDelta := radToDeg(halfWidth / earthRadius / math.Cos(degToRad(lat)))
+	minLat, maxLat := max(lat-latDelta, geoLatMin), min(lat+latDelta, geoLatMax)
+
+	radius := opts.Radius
+	if opts.ByBox {
+		radius = math.Hypot(halfWidth, halfHeight)
+	}
+	step := geoEstimateStep(radius, lat)
+	var x, y uint64
+	for ; ; step-- {
+		x, y = geoCell(lon, lat, geoLatMin, geoLatMax, step)
+		lonSize := (geoLonMax - geoLonMin) / float64(uint64(1)<<step)
+		latSize := (geoLatMax - geoLatMin) / float64(uint64(1)<<step)
+		covered := geoLonMin+(float64(x)-1)*lonSize <= lon-lonDelta &&
+			geoLonMin+(float64(x)+2)*lonSize >= lon+lonDelta &&
+			geoLatMin+(float64(y)-1)*latSize <= minLat &&
+			geoLatMin+(float64(y)+2)*latSize >= maxLat
+		if covered || step == 1 {
+			break
+		}
+	}
+
+	cells := uint64(1) << step
+	shift := uint(2 * (geoStepMax - step))
+	seen := make(map[uint64]bool)
+	var ranges [][2]uint64
+	for dy := -1; dy <= 1; dy++ {
+		cy := int64(y) + int64(dy)
+		if cy < 0 || cy >= int64(cells) {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			// Longitudes wrap around at 180 degrees
+			cx := (x + cells + uint64(dx)) % cells
+			hash := interleave(cx, uint64(cy), step)
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			ranges = append(ranges, [2]uint64{hash << shift, (hash + 1) << shift})
+		}
+	}
+	return ranges
+}
+
+// geoMatch returns the distance from the center to a point and whether the
+// point lies within the area opts describe. A box is measured along the
+// meridian and the point's parallel, as in Redis.
+func geoMatch(centerLon, centerLat, lon, lat float64, opts *options.GeoSearchOptions) (float64, bool) {
+	if !opts.ByBox {
+		d := geoDistance(centerLon, centerLat, lon, lat)
+		return d, d <= opts.Radius
+	}
+	if earthRadius*math.Abs(degToRad(lat)-degToRad(centerLat)) > opts.Height/2 {
+		return 0, false
+	}
+	if geoDistance(centerLon, lat, lon, lat) > opts.Width/2 {
+		return 0, false
+	}
+	return geoDistance(centerLon, centerLat, lon, lat), true
+}
+
+// GeoAdd adds members at the given locations to the geo set at key,
+// honouring NX and XX. It returns the number of members added, or with CH
+// the number added or moved.
+func (s *MemoryStore) GeoAdd(key string, locations []types.GeoLocation, opts *options.GeoAddOptions) (int, error) {
+	for _, loc := range locations {
+		if !validLonLat(loc.Longitude, loc.Latitude) {
+			return 0, fmt.Errorf("invalid longitude,latitude pair %f,%f", loc.Longitude, loc.Latitude)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil {
+		return 0, err
+	}
+	if set == nil {
+		if opts != nil && opts.IsXX() {
+			return 0, nil
+		}
+		set = newSortedSet()
+		s.data[key] = set
+	}
+
+	added, changed := 0, 0
+	for _, loc := range locations {
+		score := float64(geohashEncode(loc.Longitude, loc.Latitude))
+		oldScore, exists := set.dict[loc.Member]
+		if (opts != nil && opts.IsNX() && exists) || (opts != nil && opts.IsXX() && !exists) {
+			continue
+		}
+		if exists {
+			if oldScore == score {
+				continue
+			}
+		} else {
+			added++
+		}
+		changed++
+		set.Add(loc.Member, score)
+	}
+
+	if len(set.dict) == 0 {
+		delete(s.data, key)
+	}
+	if opts != nil && opts.IsCH() {
+		return changed, nil
+	}
+	return added, nil
+}
+
+// GeoPos returns the location of each member, or nil for members that do
+// not exist.
+func (s *MemoryStore) GeoPos(key string, members []string) ([]*types.GeoLocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]*types.GeoLocation, len(members))
+	if set == nil {
+		return locations, nil
+	}
+	for i, member := range members {
+		if score, ok := set.dict[member]; ok {
+			lon, lat := geohashDecode(uint64(score))
+			locations[i] = &types.GeoLocation{Longitude: lon, Latitude: lat, Member: member}
+		}
+	}
+	return locations, nil
+}
+
+// GeoDist returns the distance in meters between two members, and false if
+// either does not exist.
+func (s *MemoryStore) GeoDist(key, member1, member2 string) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return 0, false, err
+	}
+	score1, ok1 := set.dict[member1]
+	score2, ok2 := set.dict[member2]
+	if !ok1 || !ok2 {
+		return 0, false, nil
+	}
+	lon1, lat1 := geohashDecode(uint64(score1))
+	lon2, lat2 := geohashDecode(uint64(score2))
+	return geoDistance(lon1, lat1, lon2, lat2), true, nil
+}
+
+// GeoHash returns the standard geohash string of each member, or nil for
+// members that do not exist.
+func (s *MemoryStore) GeoHash(key string, members []string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]interface{}, len(members))
+	if set == nil {
+		return hashes, nil
+	}
+	for i, member := range members {
+		if score, ok := set.dict[member]; ok {
+			hashes[i] = geohashString(score)
+		}
+	}
+	return hashes, nil
+}
+
+// GeoSearch returns the members of the geo set at key within the area opts
+// describe.
+func (s *MemoryStore) GeoSearch(key string, opts *options.GeoSearchOptions) ([]types.GeoResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return []types.GeoResult{}, err
+	}
+	return geoSearch(set, opts)
+}
+
+// GeoSearchStore stores the members of the geo set at source within the
+// area opts describe in a sorted set at destination, replacing it, and
+// returns how many there were. The scores are the members' geohashes, or
+// with storeDist their distances from the center in units of unit meters.
+func (s *MemoryStore) GeoSearchStore(destination, source string, opts *options.GeoSearchOptions, storeDist bool, unit float64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(source)
+	if err != nil {
+		return 0, err
+	}
+	results := []types.GeoResult{}
+	if set != nil {
+		if results, err = geoSearch(set, opts); err != nil {
+			return 0, err
+		}
+	}
+
+	delete(s.data, destination)
+	delete(s.expires, destination)
+	if len(results) == 0 {
+		return 0, nil
+	}
+	stored := newSortedSet()
+	for _, r := range results {
+		score := float64(r.Hash)
+		if storeDist {
+			score = r.Distance / unit
+		}
+		stored.Add(r.Member, score)
+	}
+	s.data[destination] = stored
+	return len(results), nil
+}
+
+func geoSearch(set *SortedSet, opts *options.GeoSearchOptions) ([]types.GeoResult, error) {
+	lon, lat := opts.Longitude, opts.Latitude
+	if opts.FromLonLat && !validLonLat(lon, lat) {
+		return nil, fmt.Errorf("invalid longitude,latitude pair %f,%f", lon, lat)
+	}
+	if !opts.FromLonLat {
+		score, ok := set.dict[opts.FromMember]
+		if !ok {
+			return nil, errGeoMemberNotFound
+		}
+		lon, lat = geohashDecode(uint64(score))
+	}
+
+	results := []types.GeoResult{}
+	found := func() bool { return opts.Any && opts.Count > 0 && len(results) >= opts.Count }
+	for _, r := range geoSearchRanges(lon, lat, opts) {
+		set.sl.scanScores(float64(r[0]), float64(r[1]), func(node *skiplistNode) bool {
+			hash := uint64(node.score)
+			memberLon, memberLat := geohashDecode(hash)
+			if d, ok := geoMatch(lon, lat, memberLon, memberLat, opts); ok {
+				results = append(results, types.GeoResult{
+					Member:    node.member,
+					Distance:  d,
+					Hash:      hash,
+					Longitude: memberLon,
+					Latitude:  memberLat,
+				})
+			}
+			return !found()
+		})
+		if found() {
+			break
+		}
+	}
+
+	order := opts.Sort
+	if order == "" && opts.Count > 0 && !opts.Any {
+		// The nearest members are the ones worth keeping
+		order = "ASC"
+	}
+	switch order {
+	case "ASC":
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Distance < results[j].Distance })
+	case "DESC":
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Distance > results[j].Distance })
+	}
+	if opts.Count > 0 && len(results) > opts.Count {
+		results = results[:opts.Count]
+	}
+	return results, nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestGeohash_EncodeDecode(t *testing.T) {
+	// Scores and hashes as reported by Redis for the GEOADD example.
+	tests := []struct {
+		lon, lat float64
+		score    uint64
+		hash     string
+	}{
+		{lon: 13.361389, lat: 38.115556, score: 3479099956230698, hash: "sqc8b49rny0"},
+		{lon: 15.087269, lat: 37.502669, score: 3479447370796909, hash: "sqdtr74hyu0"},
+	}
+	for _, tt := range tests {
+		score := geohashEncode(tt.lon, tt.lat)
+		if score != tt.score {
+			t.Errorf("geohashEncode(%v, %v) = %d, want %d", tt.lon, tt.lat, score, tt.score)
+		}
+		lon, lat := geohashDecode(score)
+		if math.Abs(lon-tt.lon) > 1e-5 || math.Abs(lat-tt.lat) > 1e-5 {
+			t.Errorf("geohashDecode(%d) = %v, %v, want about %v, %v", score, lon, lat, tt.lon, tt.lat)
+		}
+		if got := geohashString(float64(score)); got != tt.hash {
+			t.Errorf("geohashString(%d) = %q, want %q", score, got, tt.hash)
+		}
+	}
+
+	if got := geoDistance(13.361389, 38.115556, 15.087269, 37.502669); math.Abs(got-166274.15) > 1 {
+		t.Errorf("geoDistance() = %v, want about 166274 meters", got)
+	}
+}
+
+func TestMemoryStore_GeoAdd(t *testing.T) {
+	store := NewMemoryStore()
+	locations := []types.GeoLocation{{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo"}, {Longitude: 15.087269, Latitude: 37.502669, Member: "Catania"}}
+	if n, err := store.GeoAdd("Sicily", locations, nil); err != nil || n != 2 {
+		t.Fatalf("GeoAdd() = %d, %v, want 2", n, err)
+	}
+	if _, err := store.GeoAdd("Sicily", []types.GeoLocation{{Longitude: 0, Latitude: 86, Member: "pole"}}, nil); err == nil {
+		t.Errorf("GeoAdd() with a latitude out of range succeeded")
+	}
+
+	// Moving a member keeps a single entry for it in the sorted set.
+	ch := options.NewGeoAddOptions()
+	ch.Set("CH")
+	if n, _ := store.GeoAdd("Sicily", []types.GeoLocation{{Longitude: 12, Latitude: 38, Member: "Catania"}}, ch); n != 1 {
+		t.Errorf("GeoAdd() with CH of a moved member = %d, want 1", n)
+	}
+	if got, _ := store.ZRange("Sicily", 0, -1, nil); !reflect.DeepEqual(got, []interface{}{"Catania", "Palermo"}) {
+		t.Errorf("ZRange() after moving a member = %v", got)
+	}
+
+	positions, _ := store.GeoPos("Sicily", []string{"Catania", "missing"})
+	if positions[0] == nil || math.Abs(positions[0].Longitude-12) > 1e-5 || positions[1] != nil {
+		t.Errorf("GeoPos() = %+v, %+v", positions[0], positions[1])
+	}
+	if _, ok, _ := store.GeoDist("Sicily", "Palermo", "missing"); ok {
+		t.Errorf("GeoDist() with a missing member reported a distance")
+	}
+}
+
+// TestMemoryStore_GeoSearch compares searches against checking the distance
+// to every member, around the antimeridian and the poles too.
+func TestMemoryStore_GeoSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	store := NewMemoryStore()
+	var points []types.GeoLocation
+	for i := 0; i < 2000; i++ {
+		points = append(points, types.GeoLocation{
+			Longitude: rng.Float64()*360 - 180,
+			Latitude:  rng.Float64()*170 - 85,
+			Member:    "m" + strconv.Itoa(i),
+		})
+	}
+	store.GeoAdd("points", points, nil)
+
+	centers := [][2]float64{{0, 0}, {179.9, 10}, {-179.9, -10}, {30, 84}, {-60, -84}}
+	for _, center := range centers {
+		for _, radius := range []float64{1e5, 1e6, 5e6} {
+			opts := &options.GeoSearchOptions{FromLonLat: true, Longitude: center[0], Latitude: center[1], Radius: radius}
+			results, err := store.GeoSearch("points", opts)
+			if err != nil {
+				t.Fatalf("GeoSearch() error = %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Member)
+			}
+
+			var want []string
+			for _, p := range points {
+				lon, lat := geohashDecode(geohashEncode(p.Longitude, p.Latitude))
+				if geoDistance(center[0], center[1], lon, lat) <= radius {
+					want = append(want, p.Member)
+				}
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GeoSearch() around %v within %vm found %d members, want %d", center, radius, len(got), len(want))
+			}
+		}
+	}
+
+	opts := &options.GeoSearchOptions{FromLonLat: true, Radius: 5e6, Count: 3}
+	results, _ := store.GeoSearch("points", opts)
+	if len(results) != 3 || results[0].Distance > results[1].Distance || results[1].Distance > results[2].Distance {
+		t.Errorf("GeoSearch() with COUNT 3 = %+v, want the three nearest members", results)
+	}
+}
+
+func TestMemoryStore_GeoSearchBox(t *testing.T) {
+	store := NewMemoryStore()
+	store.GeoAdd("Sicily", []types.GeoLocation{
+		{Longitude: 13.361389, Latitude: 38.115556, Member: "Palermo"},
+		{Longitude: 15.087269, Latitude: 37.502669, Member: "Catania"},
+		{Longitude: 12.758489, Latitude: 38.788135, Member: "edge1"},
+		{Longitude: 17.241510, Latitude: 38.788135, Member: "edge2"},
+	}, nil)
+
+	opts := &options.GeoSearchOptions{FromLonLat: true, Longitude: 15, Latitude: 37, ByBox: true, Width: 400e3, Height: 400e3, Sort: "ASC"}
+	results, _ := store.GeoSearch("Sicily", opts)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Member)
+	}
+	if want := []string{"Catania", "Palermo", "edge2", "edge1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GeoSearch() by box = %v, want %v", got, want)
+	}
+
+	opts.Width = 200e3
+	if n, _ := store.GeoSearchStore("near", "Sicily", opts, true, 1); n != 1 {
+		t.Errorf("GeoSearchStore() = %d, want 1", n)
+	}
+	if got, _ := store.ZRange("near", 0, -1, nil); !reflect.DeepEqual(got, []interface{}{"Catania"}) {
+		t.Errorf("stored members = %v", got)
+	}
+
+	// Distances are stored in the unit of the query.
+	radius := &options.GeoSearchOptions{FromLonLat: true, Longitude: 15, Latitude: 37, Radius: 200e3, Sort: "ASC"}
+	if n, _ := store.GeoSearchStore("near", "Sicily", radius, true, 1000); n != 2 {
+		t.Errorf("GeoSearchStore() in km = %d, want 2", n)
+	}
+	for member, want := range map[string]float64{"Catania": 56.4413, "Palermo": 190.4424} {
+		if got := store.data["near"].(*SortedSet).dict[member]; math.Abs(got-want) > 1e-4 {
+			t.Errorf("stored distance of %s = %v km, want %v", member, got, want)
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// Hash is a map of fields to string values stored at a single key. Fields
+// may expire independently of each other and of the key.
+type Hash struct {
+	fields  map[string]string
+	expires map[string]time.Time // Fields with a TTL and when they expire
+	index   *scanTable           // Built by the first HSCAN
+}
+
+func newHash() *Hash {
+	return &Hash{fields: make(map[string]string)}
+}
+
+// Len returns the number of fields in the hash.
+func (h *Hash) Len() int {
+	return len(h.fields)
+}
+
+// Get returns the value of field and whether it exists.
+func (h *Hash) Get(field string) (string, bool) {
+	value, ok := h.fields[field]
+	return value, ok
+}
+
+// Set sets field to value, discarding any TTL the field had, and reports
+// whether the field is new.
+func (h *Hash) Set(field, value string) bool {
+	_, exists := h.fields[field]
+	h.update(field, value)
+	delete(h.expires, field)
+	return !exists
+}
+
+// update changes the value of an existing or new field, keeping its TTL.
+func (h *Hash) update(field, value string) {
+	if _, exists := h.fields[field]; !exists && h.index != nil {
+		h.index.add(field)
+	}
+	h.fields[field] = value
+}
+
+// Delete removes field and reports whether it existed.
+func (h *Hash) Delete(field string) bool {
+	_, exists := h.fields[field]
+	if exists && h.index != nil {
+		h.index.remove(field)
+	}
+	delete(h.fields, field)
+	delete(h.expires, field)
+	return exists
+}
+
+// Expiry returns when field expires, or false if it has no TTL.
+func (h *Hash) Expiry(field string) (time.Time, bool) {
+	at, ok := h.expires[field]
+	return at, ok
+}
+
+// SetExpiry makes field expire at the given time.
+func (h *Hash) SetExpiry(field string, at time.Time) {
+	if h.expires == nil {
+		h.expires = make(map[string]time.Time)
+	}
+	h.expires[field] = at
+}
+
+// Persist removes the TTL of field and reports whether it had one.
+func (h *Hash) Persist(field string) bool {
+	_, ok := h.expires[field]
+	delete(h.expires, field)
+	return ok
+}
+
+// volatile reports whether any field has a TTL.
+func (h *Hash) volatile() bool {
+	return len(h.expires) > 0
+}
+
+// expireField deletes field if its TTL has passed by now and reports
+// whether it did.
+func (h *Hash) expireField(field string, now time.Time) bool {
+	at, ok := h.expires[field]
+	if !ok || !now.After(at) {
+		return false
+	}
+	h.Delete(field)
+	return true
+}
+
+// removeExpired deletes the fields that expired by now and returns how many
+// there were.
+func (h *Hash) removeExpired(now time.Time) int {
+	removed := 0
+	for field := range h.expires {
+		if h.expireField(field, now) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// Pairs returns a copy of every field with its value and expiry.
+func (h *Hash) Pairs() []types.FieldValue {
+	pairs := make([]types.FieldValue, 0, len(h.fields))
+	for field, value := range h.fields {
+		pairs = append(pairs, types.FieldValue{Field: field, Value: value, Expiry: h.expires[field]})
+	}
+	return pairs
+}
+
+// getHash returns the hash stored at key, or nil if the key does not exist.
+// An expired key is deleted on access, and so are those of fields that have
+// expired; other expired fields are left to ActiveExpire. It must be called
+// with s.mu held for writing.
+func (s *MemoryStore) getHash(key string, fields ...string) (*Hash, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	val, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	hash, ok := val.(*Hash)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	if hash.volatile() && len(fields) > 0 && !s.loading {
+		now := time.Now()
+		for _, field := range fields {
+			hash.expireField(field, now)
+		}
+		if s.deleteIfEmptyHash(key, hash) {
+			return nil, nil
+		}
+	}
+	return hash, nil
+}
+
+// getWholeHash is getHash for commands that read every field, such as HLEN
+// and HGETALL: it deletes all expired fields before returning the hash. It
+// must be called with s.mu held for writing.
+func (s *MemoryStore) getWholeHash(key string) (*Hash, error) {
+	hash, err := s.getHash(key)
+	if err != nil || hash == nil || !hash.volatile() || s.loading {
+		return hash, err
+	}
+	hash.removeExpired(time.Now())
+	if s.deleteIfEmptyHash(key, hash) {
+		return nil, nil
+	}
+	return hash, nil
+}
+
+// pairFields returns the field of each pair.
+func pairFields(pairs []types.FieldValue) []string {
+	fields := make([]string, len(pairs))
+	for i, p := range pairs {
+		fields[i] = p.Field
+	}
+	return fields
+}
+
+// deleteIfEmptyHash removes key once the hash stored there has no fields
+// left, and stops tracking its field TTLs once it has none. It reports
+// whether the key was removed. It must be called with s.mu held for writing.
+func (s *MemoryStore) deleteIfEmptyHash(key string, hash *Hash) bool {
+	if !hash.volatile() {
+		delete(s.volatileHashes, key)
+	}
+	if hash.Len() > 0 {
+		return false
+	}
+	delete(s.data, key)
+	delete(s.expires, key)
+	return true
+}
+
+// getOrCreateHash returns the hash stored at key, creating an empty one if
+// the key does not exist. It must be called with s.mu held for writing.
+func (s *MemoryStore) getOrCreateHash(key string, fields ...string) (*Hash, error) {
+	hash, err := s.getHash(key, fields...)
+	if err != nil || hash != nil {
+		return hash, err
+	}
+	hash = newHash()
+	s.data[key] = hash
+	return hash, nil
+}
+
+// HSet sets the given fields and returns how many of them are new.
+func (s *MemoryStore) HSet(key string, pairs []types.FieldValue) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getOrCreateHash(key, pairFields(pairs)...)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, p := range pairs {
+		if hash.Set(p.Field, p.Value) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// HSetNX sets field only if it does not exist yet and reports whether it
+// was set.
+func (s *MemoryStore) HSetNX(key, field, value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getOrCreateHash(key, field)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := hash.Get(field); exists {
+		return false, nil
+	}
+	hash.Set(field, value)
+	return true, nil
+}
+
+func (s *MemoryStore) HGet(key, field string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, field)
+	if err != nil || hash == nil {
+		return nil, err
+	}
+	if value, ok := hash.Get(field); ok {
+		return value, nil
+	}
+	return nil, nil
+}
+
+// HMGet returns the value of each field, or nil for fields that do not
+// exist.
+func (s *MemoryStore) HMGet(key string, fields []string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(fields))
+	if hash == nil {
+		return values, nil
+	}
+	for i, field := range fields {
+		if value, ok := hash.Get(field); ok {
+			values[i] = value
+		}
+	}
+	return values, nil
+}
+
+// HDel removes the given fields and returns how many existed. The key is
+// deleted along with its last field.
+func (s *MemoryStore) HDel(key string, fields []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil || hash == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, field := range fields {
+		if hash.Delete(field) {
+			removed++
+		}
+	}
+	s.deleteIfEmptyHash(key, hash)
+	return removed, nil
+}
+
+func (s *MemoryStore) HExists(key, field string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, field)
+	if err != nil || hash == nil {
+		return false, err
+	}
+	_, ok := hash.Get(field)
+	return ok, nil
+}
+
+func (s *MemoryStore) HLen(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getWholeHash(key)
+	if err != nil || hash == nil {
+		return 0, err
+	}
+	return hash.Len(), nil
+}
+
+func (s *MemoryStore) HKeys(key string) ([]string, error) {
+	pairs, err := s.HGetAll(key)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, len(pairs))
+	for i, p := range pairs {
+		fields[i] = p.Field
+	}
+	return fields, nil
+}
+
+func (s *MemoryStore) HVals(key string) ([]string, error) {
+	pairs, err := s.HGetAll(key)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(pairs))
+	for i, p := range pairs {
+		values[i] = p.Value
+	}
+	return values, nil
+}
+
+func (s *MemoryStore) HGetAll(key string) ([]types.FieldValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getWholeHash(key)
+	if err != nil || hash == nil {
+		return []types.FieldValue{}, err
+	}
+	return hash.Pairs(), nil
+}
+
+// HIncrBy adds delta to the integer stored in field, which is created as 0
+// if it does not exist, and returns the new value.
+func (s *MemoryStore) HIncrBy(key, field string, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getOrCreateHash(key, field)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	if value, ok := hash.Get(field); ok {
+		current, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("hash value is not an integer")
+		}
+	}
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, fmt.Errorf("increment or decrement would overflow")
+	}
+
+	current += delta
+	hash.update(field, strconv.FormatInt(current, 10))
+	return current, nil
+}
+
+// HIncrByFloat adds delta to the number stored in field, which is created
+// as 0 if it does not exist, and returns the new value as stored.
+func (s *MemoryStore) HIncrByFloat(key, field string, delta float64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getOrCreateHash(key, field)
+	if err != nil {
+		return "", err
+	}
+
+	var current float64
+	if value, ok := hash.Get(field); ok {
+		current, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return "", fmt.Errorf("hash value is not a float")
+		}
+	}
+
+	current += delta
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return "", fmt.Errorf("increment would produce NaN or Infinity")
+	}
+
+	value := strconv.FormatFloat(current, 'f', -1, 64)
+	hash.update(field, value)
+	return value, nil
+}
+
+// HStrLen returns the length of the value of field, or 0 if it does not
+// exist.
+func (s *MemoryStore) HStrLen(key, field string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, field)
+	if err != nil || hash == nil {
+		return 0, err
+	}
+	value, _ := hash.Get(field)
+	return len(value), nil
+}
+
+// HRandField returns random fields with their values. A positive count
+// returns up to count distinct fields; a negative one returns exactly -count
+// fields, possibly repeating some.
+func (s *MemoryStore) HRandField(key string, count int) ([]types.FieldValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getWholeHash(key)
+	if err != nil || hash == nil {
+		return []types.FieldValue{}, err
+	}
+
+	pairs := hash.Pairs()
+	if count < 0 {
+		picked := make([]types.FieldValue, -count)
+		for i := range picked {
+			picked[i] = pairs[rand.Intn(len(pairs))]
+		}
+		return picked, nil
+	}
+
+	rand.Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+	if count < len(pairs) {
+		pairs = pairs[:count]
+	}
+	return pairs, nil
+}
+
+// HScan visits about count fields starting at cursor and returns those
+// matching pattern along with the cursor to pass to the next call, which is
+// 0 when the iteration is complete. Expired fields are skipped, not removed.
+// See scanTable.
+func (s *MemoryStore) HScan(key string, cursor uint64, pattern string, count int) (uint64, []types.FieldValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key)
+	if err != nil || hash == nil {
+		return 0, []types.FieldValue{}, err
+	}
+
+	if hash.index == nil {
+		fields := make([]string, 0, hash.Len())
+		for field := range hash.fields {
+			fields = append(fields, field)
+		}
+		hash.index = newScanTable(fields)
+	}
+	matched, next := hash.index.scan(cursor, pattern, count)
+
+	now := time.Now()
+	pairs := make([]types.FieldValue, 0, len(matched))
+	for _, field := range matched {
+		if at, ok := hash.Expiry(field); ok && now.After(at) {
+			continue
+		}
+		value, _ := hash.Get(field)
+		pairs = append(pairs, types.FieldValue{Field: field, Value: value})
+	}
+	return next, pairs, nil
+}
@@ -0,0 +1,327 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestMemoryStore_HashCommands(t *testing.T) {
+	store := NewMemoryStore()
+
+	added, err := store.HSet("user", []types.FieldValue{{Field: "name", Value: "ada"}, {Field: "age", Value: "36"}})
+	if err != nil || added != 2 {
+		t.Fatalf("HSet() = %d, %v, want 2", added, err)
+	}
+	if added, _ := store.HSet("user", []types.FieldValue{{Field: "name", Value: "grace"}, {Field: "lang", Value: "cobol"}}); added != 1 {
+		t.Errorf("HSet() with one new field = %d, want 1", added)
+	}
+	if set, _ := store.HSetNX("user", "name", "x"); set {
+		t.Errorf("HSetNX() on an existing field succeeded")
+	}
+
+	if got, _ := store.HGet("user", "name"); got != "grace" {
+		t.Errorf("HGet(name) = %v, want grace", got)
+	}
+	if got, _ := store.HGet("user", "missing"); got != nil {
+		t.Errorf("HGet(missing) = %v, want nil", got)
+	}
+	if got, _ := store.HMGet("user", []string{"age", "missing", "lang"}); !reflect.DeepEqual(got, []interface{}{"36", nil, "cobol"}) {
+		t.Errorf("HMGet() = %v", got)
+	}
+	if ok, _ := store.HExists("user", "age"); !ok {
+		t.Errorf("HExists(age) = false")
+	}
+	if n, _ := store.HLen("user"); n != 3 {
+		t.Errorf("HLen() = %d, want 3", n)
+	}
+	if n, _ := store.HStrLen("user", "name"); n != 5 {
+		t.Errorf("HStrLen(name) = %d, want 5", n)
+	}
+
+	keys, _ := store.HKeys("user")
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"age", "lang", "name"}) {
+		t.Errorf("HKeys() = %v", keys)
+	}
+	if vals, _ := store.HVals("user"); len(vals) != 3 {
+		t.Errorf("HVals() = %v", vals)
+	}
+
+	if n, err := store.HIncrBy("user", "age", 4); err != nil || n != 40 {
+		t.Errorf("HIncrBy() = %d, %v, want 40", n, err)
+	}
+	if _, err := store.HIncrBy("user", "name", 1); err == nil {
+		t.Errorf("HIncrBy() on a non-integer succeeded")
+	}
+	if v, err := store.HIncrByFloat("user", "score", 10.5); err != nil || v != "10.5" {
+		t.Errorf("HIncrByFloat() = %q, %v, want 10.5", v, err)
+	}
+	if v, _ := store.HIncrByFloat("user", "score", -0.25); v != "10.25" {
+		t.Errorf("HIncrByFloat() = %q, want 10.25", v)
+	}
+
+	if n, _ := store.HDel("user", []string{"name", "missing"}); n != 1 {
+		t.Errorf("HDel() = %d, want 1", n)
+	}
+	store.HDel("user", []string{"age", "lang", "score"})
+	if store.Exists("user") {
+		t.Errorf("hash still exists after deleting every field")
+	}
+	if got, _ := store.HGetAll("user"); len(got) != 0 {
+		t.Errorf("HGetAll() on a missing key = %v", got)
+	}
+}
+
+func TestMemoryStore_HRandField(t *testing.T) {
+	store := NewMemoryStore()
+	store.HSet("h", []types.FieldValue{{Field: "a", Value: "1"}, {Field: "b", Value: "2"}, {Field: "c", Value: "3"}})
+
+	if got, _ := store.HRandField("h", 2); len(got) != 2 || got[0].Field == got[1].Field {
+		t.Errorf("HRandField(2) = %v, want 2 distinct fields", got)
+	}
+	if got, _ := store.HRandField("h", 10); len(got) != 3 {
+		t.Errorf("HRandField(10) = %v, want all 3 fields", got)
+	}
+	if got, _ := store.HRandField("h", -10); len(got) != 10 {
+		t.Errorf("HRandField(-10) returned %d fields, want 10", len(got))
+	}
+	if got, _ := store.HRandField("missing", 1); len(got) != 0 {
+		t.Errorf("HRandField() on a missing key = %v", got)
+	}
+}
+
+func TestMemoryStore_HScan(t *testing.T) {
+	store := NewMemoryStore()
+	var pairs []types.FieldValue
+	for i := 0; i < 100; i++ {
+		pairs = append(pairs, types.FieldValue{Field: "f" + strconv.Itoa(i), Value: strconv.Itoa(i)})
+	}
+	store.HSet("h", pairs)
+
+	seen := make(map[string]bool)
+	cursor := uint64(0)
+	for calls := 0; ; calls++ {
+		if calls > 100 {
+			t.Fatalf("HScan() did not finish")
+		}
+		next, got, err := store.HScan("h", cursor, "", 7)
+		if err != nil {
+			t.Fatalf("HScan() error = %v", err)
+		}
+		for _, p := range got {
+			if p.Value != p.Field[1:] {
+				t.Errorf("HScan() pair %v has the wrong value", p)
+			}
+			seen[p.Field] = true
+		}
+		// Fields removed or added during the iteration must not make it
+		// skip fields that stay.
+		if calls == 3 {
+			store.HDel("h", []string{"f1", "f2", "f3"})
+			store.HSet("h", []types.FieldValue{{Field: "new", Value: "ew"}})
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	for i := 4; i < 100; i++ {
+		if !seen["f"+strconv.Itoa(i)] {
+			t.Errorf("HScan() never returned f%d", i)
+		}
+	}
+
+	_, got, _ := store.HScan("h", 0, "f9*", 1000)
+	if len(got) != 11 {
+		t.Errorf("HScan() MATCH f9* returned %d fields, want 11", len(got))
+	}
+}
+
+func TestMemoryStore_HashWrongType(t *testing.T) {
+	store := NewMemoryStore()
+	store.RPush("list", []string{"a"}, false)
+
+	if _, err := store.HSet("list", []types.FieldValue{{Field: "f", Value: "v"}}); !errors.Is(err, ErrWrongType) {
+		t.Errorf("HSet() on a list error = %v, want %v", err, ErrWrongType)
+	}
+	if _, err := store.HGet("list", "f"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("HGet() on a list error = %v, want %v", err, ErrWrongType)
+	}
+}
+
+func TestMemoryStore_HExpire(t *testing.T) {
+	store := NewMemoryStore()
+	store.HSet("h", []types.FieldValue{{Field: "a", Value: "1"}, {Field: "b", Value: "2"}, {Field: "c", Value: "3"}})
+
+	opts := func(opt string) *options.ExpireOptions {
+		o := options.NewExpireOptions()
+		o.Set(opt)
+		return o
+	}
+	soon := time.Now().Add(time.Hour)
+	later := soon.Add(time.Hour)
+
+	if got, _ := store.HExpire("h", soon, []string{"a", "missing"}, nil); !reflect.DeepEqual(got, []int{1, -2}) {
+		t.Errorf("HExpire() = %v, want [1 -2]", got)
+	}
+	if got, _ := store.HExpire("h", later, []string{"a", "b"}, opts("NX")); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("HExpire(NX) = %v, want [0 1]", got)
+	}
+	if got, _ := store.HExpire("h", later, []string{"a", "c"}, opts("GT")); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("HExpire(GT) = %v, want [1 0]", got)
+	}
+	if got, _ := store.HExpire("h", soon, []string{"a", "c"}, opts("LT")); !reflect.DeepEqual(got, []int{1, 1}) {
+		t.Errorf("HExpire(LT) = %v, want [1 1]", got)
+	}
+	if got, _ := store.HExpire("h", later, []string{"c"}, opts("XX")); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("HExpire(XX) = %v, want [1]", got)
+	}
+
+	times, _ := store.HExpireTime("h", []string{"a", "c", "missing"})
+	if times[0] != soon.UnixMilli() || times[1] != later.UnixMilli() || times[2] != -2 {
+		t.Errorf("HExpireTime() = %v", times)
+	}
+	if got, _ := store.HPersist("h", []string{"a", "a", "missing"}); !reflect.DeepEqual(got, []int{1, -1, -2}) {
+		t.Errorf("HPersist() = %v, want [1 -1 -2]", got)
+	}
+
+	store.HSet("h", []types.FieldValue{{Field: "c", Value: "new"}})
+	if times, _ := store.HExpireTime("h", []string{"c"}); times[0] != -1 {
+		t.Errorf("HSet() kept the TTL of an overwritten field")
+	}
+	store.HIncrBy("h", "b", 1)
+	if times, _ := store.HExpireTime("h", []string{"b"}); times[0] == -1 {
+		t.Errorf("HIncrBy() dropped the TTL of the field")
+	}
+
+	if got, _ := store.HExpire("h", time.Now().Add(-time.Second), []string{"a", "b", "c"}, nil); !reflect.DeepEqual(got, []int{2, 2, 2}) {
+		t.Errorf("HExpire() in the past = %v, want [2 2 2]", got)
+	}
+	if store.Exists("h") {
+		t.Errorf("hash still exists after all its fields expired")
+	}
+}
+
+func TestMemoryStore_HashFieldExpiry(t *testing.T) {
+	store := NewMemoryStore()
+	store.HSet("lazy", []types.FieldValue{{Field: "token", Value: "t"}, {Field: "profile", Value: "p"}})
+	store.HSet("idle", []types.FieldValue{{Field: "token", Value: "t"}})
+	soon := time.Now().Add(20 * time.Millisecond)
+	store.HExpire("lazy", soon, []string{"token"}, nil)
+	store.HExpire("idle", soon, []string{"token"}, nil)
+
+	if entry, _ := store.Dump("lazy"); len(entry.Value.([]types.FieldValue)) != 2 {
+		t.Fatalf("Dump() before expiry = %v", entry.Value)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// Expired fields are invisible before anything removes them.
+	if entry, _ := store.Dump("lazy"); !reflect.DeepEqual(entry.Value, []types.FieldValue{{Field: "profile", Value: "p"}}) {
+		t.Errorf("Dump() after expiry = %v, want only profile", entry.Value)
+	}
+	if len(store.Snapshot()) != 1 {
+		t.Errorf("Snapshot() includes a hash whose fields all expired")
+	}
+
+	// Reading a field only expires the fields it reads.
+	if got, _ := store.HGet("lazy", "profile"); got != "p" {
+		t.Errorf("HGet() of a live field = %v, want p", got)
+	}
+	store.mu.RLock()
+	pending := store.data["lazy"].(*Hash).Len()
+	store.mu.RUnlock()
+	if pending != 2 {
+		t.Errorf("HGet() of another field removed the expired one")
+	}
+
+	if n, _ := store.HLen("lazy"); n != 1 {
+		t.Errorf("HLen() after expiry = %d, want 1", n)
+	}
+	if got, _ := store.HGet("lazy", "token"); got != nil {
+		t.Errorf("HGet() of an expired field = %v, want nil", got)
+	}
+
+	if removed := store.ActiveExpire(time.Second); removed != 1 {
+		t.Errorf("ActiveExpire() removed %d, want 1", removed)
+	}
+	store.mu.RLock()
+	_, idle := store.data["idle"]
+	tracked := len(store.volatileHashes)
+	store.mu.RUnlock()
+	if idle || tracked != 0 {
+		t.Errorf("after ActiveExpire() idle exists = %v, %d hashes tracked", idle, tracked)
+	}
+}
+
+func TestMemoryStore_ActiveExpireKeys(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		key := "k" + strconv.Itoa(i)
+		store.Set(key, "v", nil)
+		store.Expire(key, time.Millisecond, nil)
+	}
+	store.Set("stays", "v", nil)
+	time.Sleep(5 * time.Millisecond)
+
+	if removed := store.ActiveExpire(time.Second); removed != 100 {
+		t.Errorf("ActiveExpire() removed %d keys, want 100", removed)
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if len(store.data) != 1 || len(store.expires) != 0 {
+		t.Errorf("after ActiveExpire() %d keys and %d expiries remain", len(store.data), len(store.expires))
+	}
+}
+
+func TestMemoryStore_HGetExAndHSetEx(t *testing.T) {
+	store := NewMemoryStore()
+
+	ex := options.NewHSetExOptions()
+	ex.SetExpiry("EX", 100)
+	if ok, _ := store.HSetEx("h", []types.FieldValue{{Field: "a", Value: "1"}, {Field: "b", Value: "2"}}, ex); !ok {
+		t.Fatalf("HSetEx() = false")
+	}
+	if times, _ := store.HExpireTime("h", []string{"a", "b"}); times[0] <= 0 || times[1] <= 0 {
+		t.Errorf("HSetEx(EX) TTLs = %v", times)
+	}
+
+	fnx := options.NewHSetExOptions()
+	fnx.Set("FNX")
+	if ok, _ := store.HSetEx("h", []types.FieldValue{{Field: "a", Value: "x"}, {Field: "c", Value: "3"}}, fnx); ok {
+		t.Errorf("HSetEx(FNX) with an existing field succeeded")
+	}
+	fxx := options.NewHSetExOptions()
+	fxx.Set("FXX")
+	fxx.SetExpiry("KEEPTTL", 0)
+	if ok, _ := store.HSetEx("h", []types.FieldValue{{Field: "a", Value: "x"}}, fxx); !ok {
+		t.Errorf("HSetEx(FXX KEEPTTL) on an existing field failed")
+	}
+	if times, _ := store.HExpireTime("h", []string{"a"}); times[0] <= 0 {
+		t.Errorf("HSetEx(KEEPTTL) dropped the TTL")
+	}
+
+	persist := options.NewHGetExOptions()
+	persist.SetExpiry("PERSIST", 0)
+	if got, _ := store.HGetEx("h", []string{"a", "missing"}, persist); !reflect.DeepEqual(got, []interface{}{"x", nil}) {
+		t.Errorf("HGetEx(PERSIST) = %v", got)
+	}
+	if times, _ := store.HExpireTime("h", []string{"a", "b"}); times[0] != -1 || times[1] <= 0 {
+		t.Errorf("TTLs after HGetEx(PERSIST) = %v", times)
+	}
+
+	past := options.NewHGetExOptions()
+	past.SetExpiry("PXAT", 1)
+	if got, _ := store.HGetEx("h", []string{"a", "b"}, past); !reflect.DeepEqual(got, []interface{}{"x", "2"}) {
+		t.Errorf("HGetEx(PXAT in the past) = %v", got)
+	}
+	if store.Exists("h") {
+		t.Errorf("hash still exists after HGetEx() expired all its fields")
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// missingFields returns the reply of the field TTL commands for a key that
+// does not exist: -2 for every field.
+func missingFields(fields []string) []int {
+	codes := make([]int, len(fields))
+	for i := range codes {
+		codes[i] = -2
+	}
+	return codes
+}
+
+// expireField makes field expire at the given time, or deletes it right away
+// if that time is not in the future and the store is not loading. It returns
+// 1 if a TTL was set and 2 if the field was deleted. It must be called with
+// s.mu held for writing.
+func (s *MemoryStore) expireField(key string, hash *Hash, field string, at, now time.Time) int {
+	if !at.After(now) && !s.loading {
+		hash.Delete(field)
+		return 2
+	}
+	hash.SetExpiry(field, at)
+	s.volatileHashes[key] = struct{}{}
+	return 1
+}
+
+// HExpire makes each of fields expire at the given time, subject to the NX,
+// XX, GT and LT conditions of opts. A field without a TTL counts as never
+// expiring, so GT never applies to it and LT always does. For each field it
+// returns -2 if the field does not exist, 0 if the condition was not met, 1
+// if the TTL was set and 2 if the field was deleted because the time has
+// already passed.
+func (s *MemoryStore) HExpire(key string, at time.Time, fields []string, opts *options.ExpireOptions) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+	if hash == nil {
+		return missingFields(fields), nil
+	}
+
+	now := time.Now()
+	codes := make([]int, len(fields))
+	for i, field := range fields {
+		if _, ok := hash.Get(field); !ok {
+			codes[i] = -2
+			continue
+		}
+
+		current, volatile := hash.Expiry(field)
+		if opts != nil {
+			if (opts.IsNX() && volatile) ||
+				(opts.IsXX() && !volatile) ||
+				(opts.IsGT() && (!volatile || !at.After(current))) ||
+				(opts.IsLT() && volatile && !at.Before(current)) {
+				continue
+			}
+		}
+		codes[i] = s.expireField(key, hash, field, at, now)
+	}
+	s.deleteIfEmptyHash(key, hash)
+	return codes, nil
+}
+
+// HExpireTime returns for each field the Unix time in milliseconds at which
+// it expires, -1 if it has no TTL or -2 if it does not exist.
+func (s *MemoryStore) HExpireTime(key string, fields []string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]int64, len(fields))
+	for i, field := range fields {
+		if hash == nil {
+			times[i] = -2
+		} else if _, ok := hash.Get(field); !ok {
+			times[i] = -2
+		} else if at, ok := hash.Expiry(field); ok {
+			times[i] = at.UnixMilli()
+		} else {
+			times[i] = -1
+		}
+	}
+	return times, nil
+}
+
+// HPersist removes the TTL of each of fields. For each field it returns -2
+// if the field does not exist, -1 if it had no TTL and 1 if the TTL was
+// removed.
+func (s *MemoryStore) HPersist(key string, fields []string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+	if hash == nil {
+		return missingFields(fields), nil
+	}
+
+	codes := make([]int, len(fields))
+	for i, field := range fields {
+		if _, ok := hash.Get(field); !ok {
+			codes[i] = -2
+		} else if hash.Persist(field) {
+			codes[i] = 1
+		} else {
+			codes[i] = -1
+		}
+	}
+	s.deleteIfEmptyHash(key, hash)
+	return codes, nil
+}
+
+// HGetEx returns the value of each field, or nil for fields that do not
+// exist, and then sets or removes the TTL of the existing ones as opts
+// specify.
+func (s *MemoryStore) HGetEx(key string, fields []string, opts *options.HGetExOptions) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(fields))
+	if hash == nil {
+		return values, nil
+	}
+
+	now := time.Now()
+	for i, field := range fields {
+		value, ok := hash.Get(field)
+		if !ok {
+			continue
+		}
+		values[i] = value
+
+		switch {
+		case opts == nil || opts.ExpiryType == "":
+		case opts.IsPERSIST():
+			hash.Persist(field)
+		default:
+			s.expireField(key, hash, field, opts.ExpiryTime, now)
+		}
+	}
+	s.deleteIfEmptyHash(key, hash)
+	return values, nil
+}
+
+// HSetEx sets the given fields and their TTL as opts specify, and reports
+// whether they were set. With FNX nothing is set if any of the fields
+// exists, with FXX nothing is set unless all of them exist. Unless KEEPTTL
+// is given, the TTL of the fields is replaced or removed.
+func (s *MemoryStore) HSetEx(key string, pairs []types.FieldValue, opts *options.HSetExOptions) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, err := s.getHash(key, pairFields(pairs)...)
+	if err != nil {
+		return false, err
+	}
+
+	if opts != nil && (opts.IsFNX() || opts.IsFXX()) {
+		for _, p := range pairs {
+			exists := false
+			if hash != nil {
+				_, exists = hash.Get(p.Field)
+			}
+			if exists == opts.IsFNX() {
+				return false, nil
+			}
+		}
+	}
+
+	if hash == nil {
+		hash = newHash()
+		s.data[key] = hash
+	}
+
+	now := time.Now()
+	for _, p := range pairs {
+		switch {
+		case opts != nil && opts.IsKEEPTTL():
+			hash.update(p.Field, p.Value)
+		case opts != nil && opts.ExpiryType != "":
+			hash.Set(p.Field, p.Value)
+			s.expireField(key, hash, p.Field, opts.ExpiryTime, now)
+		default:
+			hash.Set(p.Field, p.Value)
+		}
+	}
+	s.deleteIfEmptyHash(key, hash)
+	return true, nil
+}
@@ -0,0 +1,390 @@
+package store
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// HyperLogLogs are stored as string values in the byte layout Redis uses, so
+// that GET and SET move them between servers. A 16-byte header holds the
+// magic "HYLL", the encoding and a cached cardinality, and is followed by
+// 16384 registers of 6 bits each, either packed (dense) or run-length
+// encoded (sparse).
+const (
+	hllP           = 14
+	hllQ           = 64 - hllP
+	hllRegisters   = 1 << hllP
+	hllBits        = 6
+	hllRegisterMax = 1<<hllBits - 1
+	hllHeaderSize  = 16
+	hllDenseSize   = hllHeaderSize + (hllRegisters*hllBits+7)/8
+	hllDense       = 0
+	hllSparse      = 1
+
+	// hllSparseMaxBytes is the size beyond which a sparse HyperLogLog is
+	// converted to the dense encoding, like hll-sparse-max-bytes.
+	hllSparseMaxBytes = 3000
+
+	// Sparse opcodes: ZERO is 00xxxxxx for a run of 1 to 64 empty registers,
+	// XZERO is 01xxxxxx xxxxxxxx for up to 16384, and VAL is 1vvvvvxx for a
+	// run of 1 to 4 registers set to 1 to 32.
+	hllSparseZeroMaxLen  = 64
+	hllSparseValMaxValue = 32
+	hllSparseValMaxLen   = 4
+
+	hllAlphaInf = 0.721347520444481703680 // 1 / (2 ln 2)
+	hllSeed     = 0xadc83b19
+)
+
+var (
+	errNotHyperLogLog = &types.Error{Code: "WRONGTYPE", Message: "Key is not a valid HyperLogLog string value."}
+	errHLLCorrupted   = &types.Error{Code: "INVALIDOBJ", Message: "Corrupted HLL object detected"}
+)
+
+// hyperLogLog holds the registers of a HyperLogLog decoded one per byte.
+type hyperLogLog struct {
+	registers [hllRegisters]uint8
+	dense     bool // Whether it was, and so must stay, densely encoded
+}
+
+// parseHyperLogLog decodes a HyperLogLog from its string value.
+func parseHyperLogLog[T string | []byte](value T) (*hyperLogLog, error) {
+	if len(value) < hllHeaderSize || string(value[:4]) != "HYLL" {
+		return nil, errNotHyperLogLog
+	}
+
+	h := &hyperLogLog{}
+	switch value[4] {
+	case hllDense:
+		if len(value) != hllDenseSize {
+			return nil, errNotHyperLogLog
+		}
+		h.dense = true
+		regs := value[hllHeaderSize:]
+		for i := range h.registers {
+			h.registers[i] = hllDenseGet(regs, i)
+		}
+	case hllSparse:
+		i := 0
+		ops := value[hllHeaderSize:]
+		for p := 0; p < len(ops); p++ {
+			op := ops[p]
+			var run int
+			var val uint8
+			switch {
+			case op&0xc0 == 0x00:
+				run = int(op&0x3f) + 1
+			case op&0xc0 == 0x40:
+				if p+1 == len(ops) {
+					return nil, errHLLCorrupted
+				}
+				p++
+				run = (int(op&0x3f)<<8 | int(ops[p])) + 1
+			default:
+				val = (op>>2)&0x1f + 1
+				run = int(op&0x3) + 1
+			}
+			if i+run > hllRegisters {
+				return nil, errHLLCorrupted
+			}
+			for end := i + run; i < end; i++ {
+				h.registers[i] = val
+			}
+		}
+		if i != hllRegisters {
+			return nil, errHLLCorrupted
+		}
+	default:
+		return nil, errNotHyperLogLog
+	}
+	return h, nil
+}
+
+// hllDenseGet returns register i of packed dense registers.
+func hllDenseGet[T string | []byte](regs T, i int) uint8 {
+	byteIdx := i * hllBits / 8
+	shift := uint(i * hllBits & 7)
+	v := regs[byteIdx] >> shift
+	if byteIdx+1 < len(regs) {
+		v |= regs[byteIdx+1] << (8 - shift)
+	}
+	return v & hllRegisterMax
+}
+
+// hllDenseSet sets register i of packed dense registers.
+func hllDenseSet(regs []byte, i int, val uint8) {
+	byteIdx := i * hllBits / 8
+	shift := uint(i * hllBits & 7)
+	regs[byteIdx] &^= hllRegisterMax << shift
+	regs[byteIdx] |= val << shift
+	if byteIdx+1 < len(regs) {
+		regs[byteIdx+1] &^= hllRegisterMax >> (8 - shift)
+		regs[byteIdx+1] |= val >> (8 - shift)
+	}
+}
+
+// Bytes encodes the HyperLogLog, sparsely while it fits within
+// hllSparseMaxBytes and no register exceeds what a VAL opcode can hold. The
+// cached cardinality is marked as invalid.
+func (h *hyperLogLog) Bytes() []byte {
+	if !h.dense {
+		if sparse, ok := h.encodeSparse(); ok {
+			return sparse
+		}
+		h.dense = true
+	}
+
+	buf := make([]byte, hllDenseSize)
+	copy(buf, "HYLL")
+	buf[4] = hllDense
+	buf[15] = 0x80
+	for i, val := range h.registers {
+		if val != 0 {
+			hllDenseSet(buf[hllHeaderSize:], i, val)
+		}
+	}
+	return buf
+}
+
+// encodeSparse returns the sparse encoding, or false if it cannot be used.
+func (h *hyperLogLog) encodeSparse() ([]byte, bool) {
+	buf := make([]byte, hllHeaderSize, 64)
+	copy(buf, "HYLL")
+	buf[4] = hllSparse
+	buf[15] = 0x80
+
+	for i := 0; i < hllRegisters; {
+		val := h.registers[i]
+		run := 1
+		for i+run < hllRegisters && h.registers[i+run] == val {
+			run++
+		}
+		i += run
+
+		switch {
+		case val == 0 && run <= hllSparseZeroMaxLen:
+			buf = append(buf, byte(run-1))
+		case val == 0:
+			buf = append(buf, 0x40|byte((run-1)>>8), byte(run-1))
+		case val > hllSparseValMaxValue:
+			return nil, false
+		default:
+			for ; run > 0; run -= hllSparseValMaxLen {
+				n := min(run, hllSparseValMaxLen)
+				buf = append(buf, 0x80|(val-1)<<2|byte(n-1))
+			}
+		}
+		if len(buf) > hllSparseMaxBytes {
+			return nil, false
+		}
+	}
+	return buf, true
+}
+
+// add adds element and reports whether a register changed.
+func (h *hyperLogLog) add(element string) bool {
+	index, count := hllPatLen(element)
+	if count <= h.registers[index] {
+		return false
+	}
+	h.registers[index] = count
+	return true
+}
+
+// merge sets each register to the greater of its value in h and in other.
+func (h *hyperLogLog) merge(other *hyperLogLog) {
+	for i, val := range other.registers {
+		h.registers[i] = max(h.registers[i], val)
+	}
+	h.dense = h.dense || other.dense
+}
+
+// count estimates the cardinality with the improved estimator of Otmar
+// Ertl's "New cardinality estimation algorithms for HyperLogLog sketches",
+// as Redis does.
+func (h *hyperLogLog) count() uint64 {
+	var histogram [hllRegisterMax + 1]int
+	for _, val := range h.registers {
+		histogram[val]++
+	}
+
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histogram[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	return uint64(math.Round(hllAlphaInf * m * m / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
+
+// hllPatLen returns the register an element maps to and the value it
+// proposes for it: the position of the first set bit in the rest of its
+// hash, counting from 1.
+func hllPatLen(element string) (int, uint8) {
+	hash := murmurHash64A(element, hllSeed)
+	index := int(hash & (hllRegisters - 1))
+	hash >>= hllP
+	hash |= 1 << hllQ
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+// murmurHash64A is the 64-bit MurmurHash2 by Austin Appleby, for the
+// little-endian layout Redis hashes in.
+func murmurHash64A(key string, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ uint64(len(key))*m
+	data := []byte(key)
+	for len(data) >= 8 {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+		data = data[8:]
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// getHyperLogLog returns the HyperLogLog stored at key, or nil if the key
+// does not exist.
+func (s *MemoryStore) getHyperLogLog(key string) (*hyperLogLog, error) {
+	value, ok, err := s.getBytes(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return parseHyperLogLog(value)
+}
+
+// PFAdd adds elements to the HyperLogLog at key, creating it if needed, and
+// reports whether its estimate may have changed.
+func (s *MemoryStore) PFAdd(key string, elements []string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, err := s.getHyperLogLog(key)
+	if err != nil {
+		return false, err
+	}
+	updated := h == nil
+	if h == nil {
+		h = &hyperLogLog{}
+	}
+	for _, element := range elements {
+		if h.add(element) {
+			updated = true
+		}
+	}
+	if updated {
+		s.data[key] = h.Bytes()
+	}
+	return updated, nil
+}
+
+// PFCount estimates the number of distinct elements added to the
+// HyperLogLogs at keys. The estimate for a single key is cached in its
+// header until it changes.
+func (s *MemoryStore) PFCount(keys []string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(keys) == 1 {
+		h, err := s.getHyperLogLog(keys[0])
+		if err != nil || h == nil {
+			return 0, err
+		}
+		value := s.data[keys[0]].([]byte)
+		if value[15]&0x80 == 0 {
+			return int64(binary.LittleEndian.Uint64(value[8:16])), nil
+		}
+		card := h.count()
+		binary.LittleEndian.PutUint64(value[8:16], card)
+		return int64(card), nil
+	}
+
+	union := &hyperLogLog{}
+	for _, key := range keys {
+		h, err := s.getHyperLogLog(key)
+		if err != nil {
+			return 0, err
+		}
+		if h != nil {
+			union.merge(h)
+		}
+	}
+	return int64(union.count()), nil
+}
+
+// PFMerge stores at destination the union of the HyperLogLogs at keys and at
+// destination itself.
+func (s *MemoryStore) PFMerge(destination string, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	union, err := s.getHyperLogLog(destination)
+	if err != nil {
+		return err
+	}
+	if union == nil {
+		union = &hyperLogLog{}
+	}
+	for _, key := range keys {
+		h, err := s.getHyperLogLog(key)
+		if err != nil {
+			return err
+		}
+		if h != nil {
+			union.merge(h)
+		}
+	}
+	s.data[destination] = union.Bytes()
+	return nil
+}
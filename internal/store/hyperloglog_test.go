@@ -0,0 +1,138 @@
+package store
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestMurmurHash64A(t *testing.T) {
+	// Hashes from the C implementation Redis uses, with the seed of its
+	// HyperLogLogs.
+	tests := map[string]uint64{
+		"":                  15627466953755236146,
+		"a":                 6039968161137406375,
+		"hello":             1109414937308947456,
+		"hello world!":      1136107777615667724,
+		"0123456789abcdefg": 17782515169093400297,
+	}
+	for key, want := range tests {
+		if got := murmurHash64A(key, hllSeed); got != want {
+			t.Errorf("murmurHash64A(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestHyperLogLog_Encoding(t *testing.T) {
+	h := &hyperLogLog{}
+	if got := h.Bytes(); len(got) != hllHeaderSize+2 || got[4] != hllSparse {
+		t.Fatalf("empty HyperLogLog = %q, want a single XZERO opcode", got)
+	}
+
+	// Registers survive encoding, first sparse and then, once the sparse
+	// form grows too large, dense.
+	for i := 0; i < 5000; i++ {
+		h.add(strconv.Itoa(i))
+		if i%500 != 0 {
+			continue
+		}
+		value := h.Bytes()
+		parsed, err := parseHyperLogLog(value)
+		if err != nil {
+			t.Fatalf("parseHyperLogLog() after %d adds error = %v", i+1, err)
+		}
+		if parsed.registers != h.registers {
+			t.Fatalf("registers changed by encoding after %d adds", i+1)
+		}
+		if i == 0 && value[4] != hllSparse {
+			t.Errorf("HyperLogLog with one element is not sparse")
+		}
+	}
+	if value := h.Bytes(); value[4] != hllDense || len(value) != hllDenseSize {
+		t.Errorf("HyperLogLog with 5000 elements has encoding %d and size %d, want dense", value[4], len(value))
+	}
+
+	// A register beyond the range of a VAL opcode forces the dense encoding.
+	h = &hyperLogLog{}
+	h.registers[100] = hllSparseValMaxValue + 1
+	if value := h.Bytes(); value[4] != hllDense {
+		t.Errorf("HyperLogLog with a register of %d is not dense", hllSparseValMaxValue+1)
+	}
+
+	for _, value := range []string{"", "HYLL", "HYLL\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", "not a hyperloglog"} {
+		if _, err := parseHyperLogLog(value); err != errNotHyperLogLog {
+			t.Errorf("parseHyperLogLog(%q) error = %v, want %v", value, err, errNotHyperLogLog)
+		}
+	}
+	if _, err := parseHyperLogLog("HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01"); err != errHLLCorrupted {
+		t.Errorf("parseHyperLogLog() of a short sparse run error = %v, want %v", err, errHLLCorrupted)
+	}
+}
+
+func TestHyperLogLog_Count(t *testing.T) {
+	h := &hyperLogLog{}
+	if got := h.count(); got != 0 {
+		t.Errorf("count() of an empty HyperLogLog = %d, want 0", got)
+	}
+
+	n := 0
+	for _, want := range []int{10, 100, 1000, 10000, 100000, 1000000} {
+		for ; n < want; n++ {
+			h.add("element:" + strconv.Itoa(n))
+		}
+		got := float64(h.count())
+		if relErr := math.Abs(got-float64(want)) / float64(want); relErr > 0.03 {
+			t.Errorf("count() after %d distinct elements = %v, off by %.2f%%", want, got, relErr*100)
+		}
+	}
+}
+
+func TestMemoryStore_PF(t *testing.T) {
+	store := NewMemoryStore()
+
+	if updated, err := store.PFAdd("a", []string{"x", "y", "z"}); err != nil || !updated {
+		t.Fatalf("PFAdd() = %v, %v, want true", updated, err)
+	}
+	if updated, _ := store.PFAdd("a", []string{"x"}); updated {
+		t.Errorf("PFAdd() of an existing element = true, want false")
+	}
+	if updated, _ := store.PFAdd("empty", nil); !updated {
+		t.Errorf("PFAdd() creating a key = false, want true")
+	}
+	store.PFAdd("b", []string{"z", "w"})
+
+	if got, _ := store.PFCount([]string{"a"}); got != 3 {
+		t.Errorf("PFCount(a) = %d, want 3", got)
+	}
+	// The second count is read from the cache in the header.
+	if got, _ := store.PFCount([]string{"a"}); got != 3 {
+		t.Errorf("cached PFCount(a) = %d, want 3", got)
+	}
+	if got, _ := store.PFCount([]string{"a", "b", "missing"}); got != 4 {
+		t.Errorf("PFCount(a, b, missing) = %d, want 4", got)
+	}
+
+	if err := store.PFMerge("c", []string{"a", "b"}); err != nil {
+		t.Fatalf("PFMerge() error = %v", err)
+	}
+	if got, _ := store.PFCount([]string{"c"}); got != 4 {
+		t.Errorf("PFCount(c) after PFMerge = %d, want 4", got)
+	}
+
+	// A HyperLogLog is a string, so it can be copied with GET and SET.
+	value, _ := store.Get("c")
+	store.Set("d", value, nil)
+	if got, _ := store.PFCount([]string{"d"}); got != 4 {
+		t.Errorf("PFCount(d) of a copy = %d, want 4", got)
+	}
+
+	store.Set("s", "plain string", nil)
+	if _, err := store.PFAdd("s", []string{"x"}); err != errNotHyperLogLog {
+		t.Errorf("PFAdd() on a plain string error = %v, want %v", err, errNotHyperLogLog)
+	}
+	store.SAdd("set", []string{"x"})
+	if _, err := store.PFCount([]string{"a", "set"}); !errors.Is(err, ErrWrongType) {
+		t.Errorf("PFCount() with a set error = %v, want %v", err, ErrWrongType)
+	}
+}
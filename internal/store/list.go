@@ -0,0 +1,320 @@
+package store
+
+import "fmt"
+
+// getList returns the list stored at key, or nil if the key does not exist.
+// Expired keys are deleted on access. It must be called with s.mu held for
+// writing.
+func (s *MemoryStore) getList(key string) (*List, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	val, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := val.(*List)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return list, nil
+}
+
+// deleteIfEmpty removes key once the list stored there has no elements left,
+// as a key never holds an empty list. It must be called with s.mu held for
+// writing.
+func (s *MemoryStore) deleteIfEmpty(key string, list *List) {
+	if list.Len() == 0 {
+		delete(s.data, key)
+		delete(s.expires, key)
+	}
+}
+
+// listIndex converts a possibly negative index into an offset from the head
+// and reports whether it is in range.
+func listIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+// listRange clamps the inclusive range start..stop, where negative indexes
+// count from the tail, to a list of length elements. It reports false when
+// the range is empty.
+func listRange(start, stop, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
+
+func (s *MemoryStore) LPush(key string, values []string, onlyIfExists bool) (int, error) {
+	return s.push(key, values, onlyIfExists, true)
+}
+
+func (s *MemoryStore) RPush(key string, values []string, onlyIfExists bool) (int, error) {
+	return s.push(key, values, onlyIfExists, false)
+}
+
+// push adds values one after the other at the head or the tail of the list
+// stored at key, creating it unless onlyIfExists is set.
+func (s *MemoryStore) push(key string, values []string, onlyIfExists, front bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil {
+		return 0, err
+	}
+	if list == nil {
+		if onlyIfExists {
+			return 0, nil
+		}
+		list = newList()
+		s.data[key] = list
+	}
+
+	for _, value := range values {
+		if front {
+			list.PushFront(value)
+		} else {
+			list.PushBack(value)
+		}
+	}
+	return list.Len(), nil
+}
+
+func (s *MemoryStore) LPop(key string, count int) ([]string, error) {
+	return s.pop(key, count, true)
+}
+
+func (s *MemoryStore) RPop(key string, count int) ([]string, error) {
+	return s.pop(key, count, false)
+}
+
+// pop removes up to count elements from the head or the tail of the list
+// stored at key. It returns nil if the key does not exist.
+func (s *MemoryStore) pop(key string, count int, front bool) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	popped := make([]string, 0, min(count, list.Len()))
+	for len(popped) < count && list.Len() > 0 {
+		if front {
+			popped = append(popped, list.PopFront())
+		} else {
+			popped = append(popped, list.PopBack())
+		}
+	}
+	s.deleteIfEmpty(key, list)
+	return popped, nil
+}
+
+func (s *MemoryStore) LLen(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return 0, err
+	}
+	return list.Len(), nil
+}
+
+func (s *MemoryStore) LRange(key string, start, stop int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return []string{}, nil
+	}
+
+	start, stop, ok := listRange(start, stop, list.Len())
+	if !ok {
+		return []string{}, nil
+	}
+	return list.Range(start, stop), nil
+}
+
+func (s *MemoryStore) LIndex(key string, index int) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	index, ok := listIndex(index, list.Len())
+	if !ok {
+		return nil, nil
+	}
+	return list.Index(index), nil
+}
+
+func (s *MemoryStore) LSet(key string, index int, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil {
+		return err
+	}
+	if list == nil {
+		return fmt.Errorf("no such key")
+	}
+
+	index, ok := listIndex(index, list.Len())
+	if !ok {
+		return fmt.Errorf("index out of range")
+	}
+	list.Set(index, value)
+	return nil
+}
+
+// LInsert inserts value before or after pivot and returns the new length of
+// the list, 0 if the key does not exist or -1 if pivot was not found.
+func (s *MemoryStore) LInsert(key string, before bool, pivot, value string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return 0, err
+	}
+
+	if !list.Insert(pivot, value, before) {
+		return -1, nil
+	}
+	return list.Len(), nil
+}
+
+func (s *MemoryStore) LRem(key string, count int, value string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return 0, err
+	}
+
+	removed := list.Remove(value, count)
+	s.deleteIfEmpty(key, list)
+	return removed, nil
+}
+
+func (s *MemoryStore) LTrim(key string, start, stop int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return err
+	}
+
+	start, stop, ok := listRange(start, stop, list.Len())
+	if !ok {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil
+	}
+	list.Trim(start, stop)
+	return nil
+}
+
+// LPos returns the indexes of elements equal to element. A negative rank
+// scans from the tail, and its magnitude skips the first |rank|-1 matches. At
+// most count indexes are returned and at most maxLen elements compared; zero
+// means no limit for either.
+func (s *MemoryStore) LPos(key, element string, rank, count, maxLen int) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.getList(key)
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	skip := rank - 1
+	if rank < 0 {
+		skip = -rank - 1
+	}
+	var matches []int
+	compared := 0
+	list.Each(rank < 0, func(index int, value string) bool {
+		if maxLen > 0 && compared >= maxLen {
+			return false
+		}
+		compared++
+		if value != element {
+			return true
+		}
+		if skip > 0 {
+			skip--
+			return true
+		}
+		matches = append(matches, index)
+		return count == 0 || len(matches) < count
+	})
+	return matches, nil
+}
+
+// LMove pops an element from the head or tail of source, pushes it onto the
+// head or tail of destination and returns it, or nil if source does not
+// exist.
+func (s *MemoryStore) LMove(source, destination string, fromLeft, toLeft bool) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.getList(source)
+	if err != nil || src == nil {
+		return nil, err
+	}
+	dst, err := s.getList(destination)
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		dst = newList()
+		s.data[destination] = dst
+	}
+
+	var value string
+	if fromLeft {
+		value = src.PopFront()
+	} else {
+		value = src.PopBack()
+	}
+	if toLeft {
+		dst.PushFront(value)
+	} else {
+		dst.PushBack(value)
+	}
+	s.deleteIfEmpty(source, src)
+	return value, nil
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestList_MatchesSlice applies random operations to a List and to a plain
+// slice and checks that they stay in step across chunk splits and removals.
+func TestList_MatchesSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	list := newList()
+	var model []string
+	for i := 0; i < 3*listChunkSize; i++ {
+		value := strconv.Itoa(rng.Intn(50))
+		list.PushBack(value)
+		model = append(model, value)
+	}
+
+	for i := 0; i < 5000; i++ {
+		value := strconv.Itoa(rng.Intn(50))
+		switch op := rng.Intn(8); {
+		case op == 0:
+			list.PushFront(value)
+			model = append([]string{value}, model...)
+		case op == 1:
+			list.PushBack(value)
+			model = append(model, value)
+		case op == 2 && len(model) > 0:
+			if got := list.PopFront(); got != model[0] {
+				t.Fatalf("PopFront() = %q, want %q", got, model[0])
+			}
+			model = model[1:]
+		case op == 3 && len(model) > 0:
+			if got := list.PopBack(); got != model[len(model)-1] {
+				t.Fatalf("PopBack() = %q, want %q", got, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		case op == 4:
+			pivot := strconv.Itoa(rng.Intn(50))
+			before := rng.Intn(2) == 0
+			found := list.Insert(pivot, value, before)
+			at := -1
+			for j, item := range model {
+				if item == pivot {
+					at = j
+					break
+				}
+			}
+			if found != (at >= 0) {
+				t.Fatalf("Insert(%q) found = %v, want %v", pivot, found, at >= 0)
+			}
+			if found {
+				if !before {
+					at++
+				}
+				model = append(model[:at], append([]string{value}, model[at:]...)...)
+			}
+		case op == 5:
+			count := rng.Intn(5) - 2
+			removed := list.Remove(value, count)
+			want := 0
+			if count < 0 {
+				for j := len(model) - 1; j >= 0 && (want < -count); j-- {
+					if model[j] == value {
+						model = append(model[:j], model[j+1:]...)
+						want++
+					}
+				}
+			} else {
+				kept := model[:0]
+				for _, item := range model {
+					if item == value && (count == 0 || want < count) {
+						want++
+						continue
+					}
+					kept = append(kept, item)
+				}
+				model = kept
+			}
+			if removed != want {
+				t.Fatalf("Remove(%q, %d) = %d, want %d", value, count, removed, want)
+			}
+		case op == 6 && len(model) > 0:
+			index := rng.Intn(len(model))
+			list.Set(index, value)
+			model[index] = value
+		case op == 7 && len(model) > 2 && rng.Intn(10) == 0:
+			start := rng.Intn(len(model) / 2)
+			stop := len(model)/2 + rng.Intn(len(model)/2)
+			list.Trim(start, stop)
+			model = model[start : stop+1]
+		}
+
+		if list.Len() != len(model) {
+			t.Fatalf("Len() = %d, want %d", list.Len(), len(model))
+		}
+	}
+
+	if got := list.Items(); !reflect.DeepEqual(got, append([]string{}, model...)) {
+		t.Fatalf("Items() = %v, want %v", got, model)
+	}
+	for i := range model {
+		if got := list.Index(i); got != model[i] {
+			t.Fatalf("Index(%d) = %q, want %q", i, got, model[i])
+		}
+	}
+}
+
+func TestMemoryStore_ListCommands(t *testing.T) {
+	store := NewMemoryStore()
+
+	if n, err := store.LPush("list", []string{"b", "a"}, false); err != nil || n != 2 {
+		t.Fatalf("LPush() = %d, %v, want 2", n, err)
+	}
+	if n, _ := store.RPush("list", []string{"c", "d"}, false); n != 4 {
+		t.Errorf("RPush() = %d, want 4", n)
+	}
+	if n, _ := store.RPush("missing", []string{"x"}, true); n != 0 || store.Exists("missing") {
+		t.Errorf("RPush() with onlyIfExists created the key")
+	}
+
+	if got, _ := store.LRange("list", 0, -1); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("LRange(0, -1) = %v", got)
+	}
+	if got, _ := store.LRange("list", -2, 100); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("LRange(-2, 100) = %v", got)
+	}
+	if got, _ := store.LRange("list", 3, 1); len(got) != 0 {
+		t.Errorf("LRange(3, 1) = %v, want empty", got)
+	}
+	if got, _ := store.LIndex("list", -1); got != "d" {
+		t.Errorf("LIndex(-1) = %v, want d", got)
+	}
+	if got, _ := store.LIndex("list", 10); got != nil {
+		t.Errorf("LIndex(10) = %v, want nil", got)
+	}
+
+	if err := store.LSet("list", 1, "B"); err != nil {
+		t.Errorf("LSet() error = %v", err)
+	}
+	if err := store.LSet("list", 10, "x"); err == nil {
+		t.Errorf("LSet() out of range succeeded")
+	}
+	if err := store.LSet("missing", 0, "x"); err == nil {
+		t.Errorf("LSet() on a missing key succeeded")
+	}
+
+	if n, _ := store.LInsert("list", true, "c", "b2"); n != 5 {
+		t.Errorf("LInsert() = %d, want 5", n)
+	}
+	if n, _ := store.LInsert("list", false, "nope", "x"); n != -1 {
+		t.Errorf("LInsert() with a missing pivot = %d, want -1", n)
+	}
+	if got, _ := store.LRange("list", 0, -1); !reflect.DeepEqual(got, []string{"a", "B", "b2", "c", "d"}) {
+		t.Errorf("LRange() after LSet and LInsert = %v", got)
+	}
+
+	store.RPush("list", []string{"a", "a"}, false)
+	if got, _ := store.LPos("list", "a", 1, 0, 0); !reflect.DeepEqual(got, []int{0, 5, 6}) {
+		t.Errorf("LPos(rank 1, all) = %v", got)
+	}
+	if got, _ := store.LPos("list", "a", -1, 2, 0); !reflect.DeepEqual(got, []int{6, 5}) {
+		t.Errorf("LPos(rank -1, count 2) = %v", got)
+	}
+	if got, _ := store.LPos("list", "a", 2, 1, 0); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("LPos(rank 2) = %v", got)
+	}
+	if got, _ := store.LPos("list", "a", 2, 1, 5); len(got) != 0 {
+		t.Errorf("LPos(rank 2, maxlen 5) = %v, want none", got)
+	}
+
+	if n, _ := store.LRem("list", -1, "a"); n != 1 {
+		t.Errorf("LRem(-1) = %d, want 1", n)
+	}
+	if got, _ := store.LRange("list", 0, -1); !reflect.DeepEqual(got, []string{"a", "B", "b2", "c", "d", "a"}) {
+		t.Errorf("LRange() after LRem = %v", got)
+	}
+
+	if err := store.LTrim("list", 1, -2); err != nil {
+		t.Errorf("LTrim() error = %v", err)
+	}
+	if got, _ := store.LRange("list", 0, -1); !reflect.DeepEqual(got, []string{"B", "b2", "c", "d"}) {
+		t.Errorf("LRange() after LTrim = %v", got)
+	}
+
+	if got, _ := store.LPop("list", 1); !reflect.DeepEqual(got, []string{"B"}) {
+		t.Errorf("LPop(1) = %v", got)
+	}
+	if got, _ := store.RPop("list", 2); !reflect.DeepEqual(got, []string{"d", "c"}) {
+		t.Errorf("RPop(2) = %v", got)
+	}
+	if got, _ := store.LPop("list", 5); !reflect.DeepEqual(got, []string{"b2"}) {
+		t.Errorf("LPop(5) = %v", got)
+	}
+	if store.Exists("list") {
+		t.Errorf("list still exists after popping every element")
+	}
+	if got, _ := store.LPop("list", 1); got != nil {
+		t.Errorf("LPop() on a missing key = %v, want nil", got)
+	}
+	if n, _ := store.LLen("list"); n != 0 {
+		t.Errorf("LLen() on a missing key = %d, want 0", n)
+	}
+}
+
+func TestMemoryStore_LMove(t *testing.T) {
+	store := NewMemoryStore()
+	store.RPush("src", []string{"a", "b", "c"}, false)
+	store.Expire("src", time.Hour, nil)
+
+	if got, _ := store.LMove("src", "dst", true, false); got != "a" {
+		t.Errorf("LMove(LEFT, RIGHT) = %v, want a", got)
+	}
+	if got, _ := store.LMove("src", "src", false, true); got != "c" {
+		t.Errorf("LMove() rotating = %v, want c", got)
+	}
+	if got, _ := store.LRange("src", 0, -1); !reflect.DeepEqual(got, []string{"c", "b"}) {
+		t.Errorf("src = %v, want [c b]", got)
+	}
+	if ttl, _ := store.TTL("src"); ttl <= 0 {
+		t.Errorf("TTL(src) = %d, want the expiry to survive a rotation", ttl)
+	}
+
+	store.LMove("src", "dst", true, true)
+	store.LMove("src", "dst", true, true)
+	if store.Exists("src") {
+		t.Errorf("src still exists after moving every element")
+	}
+	if got, _ := store.LRange("dst", 0, -1); !reflect.DeepEqual(got, []string{"b", "c", "a"}) {
+		t.Errorf("dst = %v, want [b c a]", got)
+	}
+	if got, err := store.LMove("src", "dst", true, true); got != nil || err != nil {
+		t.Errorf("LMove() from a missing key = %v, %v, want nil", got, err)
+	}
+}
+
+func TestMemoryStore_ListWrongType(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("str", "value", nil)
+	store.RPush("list", []string{"a"}, false)
+
+	if _, err := store.LPush("str", []string{"a"}, false); !errors.Is(err, ErrWrongType) {
+		t.Errorf("LPush() on a string error = %v, want %v", err, ErrWrongType)
+	}
+	if _, err := store.LRange("str", 0, -1); !errors.Is(err, ErrWrongType) {
+		t.Errorf("LRange() on a string error = %v, want %v", err, ErrWrongType)
+	}
+	if _, err := store.LMove("list", "str", true, true); !errors.Is(err, ErrWrongType) {
+		t.Errorf("LMove() to a string error = %v, want %v", err, ErrWrongType)
+	}
+	if n, _ := store.LLen("list"); n != 1 {
+		t.Errorf("LLen() after a failed LMove = %d, want 1", n)
+	}
+	if _, err := store.Get("list"); err == nil {
+		t.Errorf("Get() on a list succeeded")
+	}
+}
+
+func TestMemoryStore_ListSnapshot(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 3*listChunkSize; i++ {
+		store.RPush("list", []string{strconv.Itoa(i)}, false)
+	}
+
+	entry, ok := store.Dump("list")
+	if !ok {
+		t.Fatalf("Dump() of a list failed")
+	}
+	items, ok := entry.Value.([]string)
+	if !ok || len(items) != 3*listChunkSize || items[listChunkSize] != strconv.Itoa(listChunkSize) {
+		t.Fatalf("Dump() value = %T of length %d", entry.Value, len(items))
+	}
+
+	target := NewMemoryStore()
+	if err := target.RestoreEntry(entry, false); err != nil {
+		t.Fatalf("RestoreEntry() error = %v", err)
+	}
+	if got, _ := target.LIndex("list", -1); got != strconv.Itoa(3*listChunkSize-1) {
+		t.Errorf("LIndex(-1) after RestoreEntry() = %v", got)
+	}
+}
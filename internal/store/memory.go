@@ -1,8 +1,11 @@
 package store
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,27 +19,55 @@ type SortedSetMember struct {
 }
 
 type SortedSet struct {
-	dict    map[string]float64 // For O(1) member lookups
-	sl      *skiplist          // For ordered operations
-	scores  []float64
-	members []string
+	dict map[string]float64 // For O(1) member lookups
+	sl   *skiplist          // For ordered operations
 }
 
+func newSortedSet() *SortedSet {
+	return &SortedSet{
+		dict: make(map[string]float64),
+		sl:   newSkiplist(),
+	}
+}
+
+// Add adds member with score, or moves it to score if it is already in the
+// set.
 func (s *SortedSet) Add(member string, score float64) {
+	if oldScore, exists := s.dict[member]; exists {
+		if oldScore == score {
+			return
+		}
+		s.sl.delete(oldScore, member)
+	}
 	s.dict[member] = score
-
 	s.sl.insert(score, member)
+}
 
-	s.scores = append(s.scores, score)
-	s.members = append(s.members, member)
+// Remove removes member from the set, reporting whether it was there.
+func (s *SortedSet) Remove(member string) bool {
+	score, exists := s.dict[member]
+	if !exists {
+		return false
+	}
+	delete(s.dict, member)
+	s.sl.delete(score, member)
+	return true
 }
 
-func (s *SortedSet) Range(start, stop int, withScores bool) []interface{} {
+// Range returns the members ranked start to stop, counting from the highest
+// score down if rev is set.
+func (s *SortedSet) Range(start, stop int, rev, withScores bool) []interface{} {
 	if s == nil || s.sl == nil || len(s.dict) == 0 {
 		return []interface{}{}
 	}
 
+	if rev {
+		start, stop = s.sl.length-1-stop, s.sl.length-1-start
+	}
 	nodes := s.sl.getRange(start, stop)
+	if rev {
+		slices.Reverse(nodes)
+	}
 
 	capacity := len(nodes)
 	if withScores {
@@ -45,94 +76,151 @@ func (s *SortedSet) Range(start, stop int, withScores bool) []interface{} {
 	result := make([]interface{}, 0, capacity)
 
 	for _, node := range nodes {
-		result = append(result, node.member)
-		if withScores {
-			result = append(result, float64(node.score))
-		}
+		result = appendScoreMember(result, node, withScores)
 	}
 
 	return result
 }
 
-func (s *SortedSet) RangeByScore(min, max float64, rev bool, withScores bool) []interface{} {
+// RangeByScore returns the members with scores between min and max, from
+// the highest score down if rev is set. It skips the first offset members
+// and, unless count is negative, returns no more than count.
+func (s *SortedSet) RangeByScore(min, max options.ScoreBound, rev, withScores bool, offset, count int) []interface{} {
+	result := []interface{}{}
 	if s == nil || s.sl == nil || len(s.dict) == 0 {
-		return []interface{}{}
+		return result
 	}
 
-	var result []interface{}
-	current := s.sl.head
-
 	if rev {
-		for i := s.sl.level - 1; i >= 0; i-- {
-			for current.forward[i] != nil && current.forward[i].score > max {
-				current = current.forward[i]
-			}
-		}
-		current = current.forward[0]
-
-		for current != nil && current.score >= min {
-			if withScores {
-				result = append(result, current.member, current.score)
-			} else {
-				result = append(result, current.member)
-			}
-			current = current.forward[0]
+		node := s.sl.seek(s.sl.lastInScoreRange(min, max), offset, true)
+		for n := 0; node != nil && min.AboveMin(node.score) && (count < 0 || n < count); n++ {
+			result = appendScoreMember(result, node, withScores)
+			node = node.backward
 		}
 	} else {
-		for i := s.sl.level - 1; i >= 0; i-- {
-			for current.forward[i] != nil && current.forward[i].score < min {
-				current = current.forward[i]
-			}
+		node := s.sl.seek(s.sl.firstInScoreRange(min, max), offset, false)
+		for n := 0; node != nil && max.BelowMax(node.score) && (count < 0 || n < count); n++ {
+			result = appendScoreMember(result, node, withScores)
+			node = node.forward[0]
 		}
-		current = current.forward[0]
+	}
+	return result
+}
 
-		for current != nil && current.score <= max {
-			if withScores {
-				result = append(result, current.member, current.score)
-			} else {
-				result = append(result, current.member)
-			}
-			current = current.forward[0]
-		}
+// CountByScore returns the number of members with scores between min and
+// max, from the ranks of the first and last of them.
+func (s *SortedSet) CountByScore(min, max options.ScoreBound) int {
+	first := s.sl.firstInScoreRange(min, max)
+	if first == nil {
+		return 0
+	}
+	last := s.sl.lastInScoreRange(min, max)
+	return s.sl.getRank(last.score, last.member) - s.sl.getRank(first.score, first.member) + 1
+}
+
+// Rank returns the 0-based rank of member, counting from the highest score
+// down if rev is set, and false if it is not in the set.
+func (s *SortedSet) Rank(member string, rev bool) (int, bool) {
+	score, exists := s.dict[member]
+	if !exists {
+		return 0, false
+	}
+	rank := s.sl.getRank(score, member)
+	if rev {
+		return s.sl.length - rank, true
 	}
+	return rank - 1, true
+}
 
+// appendScoreMember appends the member of node to a range reply, followed by
+// its score if withScores is set.
+func appendScoreMember(result []interface{}, node *skiplistNode, withScores bool) []interface{} {
+	result = append(result, node.member)
+	if withScores {
+		result = append(result, node.score)
+	}
 	return result
 }
 
-func (s *SortedSet) RangeByLex(min, max string, rev bool) []interface{} {
-	var result []interface{}
+// scoreBound converts a ZRange score bound, either an options.ScoreBound or
+// an inclusive float64.
+func scoreBound(v interface{}) (options.ScoreBound, bool) {
+	switch b := v.(type) {
+	case options.ScoreBound:
+		return b, true
+	case float64:
+		return options.ScoreBound{Score: b}, true
+	}
+	return options.ScoreBound{}, false
+}
+
+// RangeByLex returns the members between min and max, from the highest one
+// down if rev is set. It skips the first offset members and, unless count is
+// negative, returns no more than count.
+func (s *SortedSet) RangeByLex(min, max options.LexBound, rev bool, offset, count int) []interface{} {
+	result := []interface{}{}
+	if s == nil || s.sl == nil || len(s.dict) == 0 {
+		return result
+	}
 
 	if rev {
-		// Reverse order
-		for i := len(s.members) - 1; i >= 0; i-- {
-			member := s.members[i]
-			if member >= min && member <= max {
-				result = append(result, member)
-			}
+		node := s.sl.seek(s.sl.lastInLexRange(min, max), offset, true)
+		for n := 0; node != nil && min.AboveMin(node.member) && (count < 0 || n < count); n++ {
+			result = append(result, node.member)
+			node = node.backward
 		}
 	} else {
-		// Forward order
-		for i := 0; i < len(s.members); i++ {
-			member := s.members[i]
-			if member >= min && member <= max {
-				result = append(result, member)
-			}
+		node := s.sl.seek(s.sl.firstInLexRange(min, max), offset, false)
+		for n := 0; node != nil && max.BelowMax(node.member) && (count < 0 || n < count); n++ {
+			result = append(result, node.member)
+			node = node.forward[0]
 		}
 	}
-
 	return result
 }
 
+// CountByLex returns the number of members between min and max, from the
+// ranks of the first and last of them.
+func (s *SortedSet) CountByLex(min, max options.LexBound) int {
+	first := s.sl.firstInLexRange(min, max)
+	last := s.sl.lastInLexRange(min, max)
+	// Members with different scores are not in lex order, so the two need
+	// not agree
+	if first == nil || last == nil {
+		return 0
+	}
+	if n := s.sl.getRank(last.score, last.member) - s.sl.getRank(first.score, first.member) + 1; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// lexBound converts a ZRange lex bound, either an options.LexBound or an
+// inclusive string.
+func lexBound(v interface{}) (options.LexBound, bool) {
+	switch b := v.(type) {
+	case options.LexBound:
+		return b, true
+	case string:
+		return options.LexBound{Value: b}, true
+	}
+	return options.LexBound{}, false
+}
+
 type MemoryStore struct {
-	data    map[string]interface{}
-	expires map[string]time.Time
-	mu      sync.RWMutex
+	data           map[string]interface{}
+	expires        map[string]time.Time
+	volatileHashes map[string]struct{} // Keys of hashes that have fields with a TTL
+	streamAdded    chan struct{}       // Closed when an entry is added to any stream
+	loading        bool                // Expiry is suspended, see StartLoading
+	mu             sync.RWMutex
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		data:    make(map[string]interface{}),
-		expires: make(map[string]time.Time),
+		data:           make(map[string]interface{}),
+		expires:        make(map[string]time.Time),
+		volatileHashes: make(map[string]struct{}),
 	}
 }
 
@@ -166,8 +254,10 @@ func (s *MemoryStore) Get(key string) (interface{}, error) {
 
 	if val, ok := s.data[key]; ok {
 		switch v := val.(type) {
-		case string:
-			return v, nil
+		case []byte:
+			return string(v), nil
+		case *intString:
+			return v.String(), nil
 		case *SortedSet:
 			return nil, fmt.Errorf("WRONGTYPE Operation against a key holding a sorted set")
 		default:
@@ -197,6 +287,12 @@ func (s *MemoryStore) Set(key string, value interface{}, opts *options.SetOption
 		return nil, fmt.Errorf("key does not exist")
 	}
 
+	switch v := value.(type) {
+	case string:
+		value = stringValue([]byte(v))
+	case []byte:
+		value = stringValue(bytes.Clone(v))
+	}
 	s.data[key] = value
 
 	if opts != nil {
@@ -214,6 +310,12 @@ func (s *MemoryStore) Set(key string, value interface{}, opts *options.SetOption
 	}
 
 	if opts != nil && opts.IsGET() {
+		switch v := oldValue.(type) {
+		case *intString:
+			return v.String(), nil
+		case []byte:
+			return string(v), nil
+		}
 		return oldValue, nil
 	}
 
@@ -240,7 +342,7 @@ func (s *MemoryStore) Expire(key string, ttl time.Duration, opts *options.Expire
 	if opts != nil {
 		hasExpiry := false
 		if expiry, ok := s.expires[key]; ok {
-			hasExpiry = !time.Now().After(expiry)
+			hasExpiry = s.loading || !time.Now().After(expiry)
 		}
 
 		if opts.IsNX() && hasExpiry {
@@ -266,7 +368,7 @@ func (s *MemoryStore) Expire(key string, ttl time.Duration, opts *options.Expire
 		}
 	}
 
-	if ttl <= 0 {
+	if ttl <= 0 && !s.loading {
 		delete(s.expires, key)
 		delete(s.data, key)
 		return nil
@@ -311,6 +413,9 @@ func (s *MemoryStore) Keys(pattern string) ([]string, error) {
 }
 
 func (s *MemoryStore) isExpired(key string) bool {
+	if s.loading {
+		return false
+	}
 	if expiry, ok := s.expires[key]; ok {
 		return time.Now().After(expiry)
 	}
@@ -383,6 +488,67 @@ func matchPattern(str, pattern string) bool {
 	return matched
 }
 
+// getBytes returns the string stored at key and whether there is one, or
+// ErrWrongType if the key holds another type. The slice may be the stored
+// value itself, so it must not be used after s.mu is released, and it may
+// only be changed in place by callers that store it back. It must be called
+// with s.mu held for writing, since it deletes the key if it has expired.
+func (s *MemoryStore) getBytes(key string) ([]byte, bool, error) {
+	val, err := s.getStringValue(key)
+	switch v := val.(type) {
+	case []byte:
+		return v, true, nil
+	case *intString:
+		return strconv.AppendInt(nil, v.n, 10), true, nil
+	}
+	return nil, false, err
+}
+
+// getString is getBytes returning a copy of the string, which can outlive
+// the lock.
+func (s *MemoryStore) getString(key string) (string, bool, error) {
+	b, ok, err := s.getBytes(key)
+	return string(b), ok, err
+}
+
+// getStringValue is getBytes returning the value as stored, a []byte or
+// an *intString, or nil if the key does not exist.
+func (s *MemoryStore) getStringValue(key string) (interface{}, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	switch val := s.data[key].(type) {
+	case nil, []byte, *intString:
+		return val, nil
+	default:
+		return nil, ErrWrongType
+	}
+}
+
+// getSortedSet returns the sorted set stored at key, nil if there is none,
+// or ErrWrongType if the key holds another type. It must be called with s.mu
+// held for writing, since it deletes the key if it has expired.
+func (s *MemoryStore) getSortedSet(key string) (*SortedSet, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	val, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	set, ok := val.(*SortedSet)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return set, nil
+}
+
 func (s *MemoryStore) ZAdd(key string, members []types.ScoreMember, opts *options.ZAddOptions) (interface{}, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -396,10 +562,7 @@ func (s *MemoryStore) ZAdd(key string, members []types.ScoreMember, opts *option
 			return nil, fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")
 		}
 	} else {
-		set = &SortedSet{
-			dict: make(map[string]float64),
-			sl:   newSkiplist(),
-		}
+		set = newSortedSet()
 		s.data[key] = set
 	}
 
@@ -470,27 +633,33 @@ func (s *MemoryStore) ZRange(key string, start, stop interface{}, opts *options.
 			if opts != nil {
 				withScores = opts.IsWithScores()
 			}
+			// LIMIT is applied while walking the range rather than to the
+			// whole of it, seeking to the offset by rank
+			offset, count := 0, -1
+			if opts != nil && opts.HasLimit {
+				offset, count = opts.Limit.Offset, opts.Limit.Count
+			}
 
 			if opts != nil && opts.IsByScore() {
-				minScore, ok := start.(float64)
+				minScore, ok := scoreBound(start)
 				if !ok {
 					return nil, fmt.Errorf("invalid score range start")
 				}
-				maxScore, ok := stop.(float64)
+				maxScore, ok := scoreBound(stop)
 				if !ok {
 					return nil, fmt.Errorf("invalid score range stop")
 				}
-				result = zset.RangeByScore(minScore, maxScore, opts.IsRev(), withScores)
+				result = zset.RangeByScore(minScore, maxScore, opts.IsRev(), withScores, offset, count)
 			} else if opts != nil && opts.IsByLex() {
-				minLex, ok := start.(string)
+				minLex, ok := lexBound(start)
 				if !ok {
 					return nil, fmt.Errorf("invalid lex range start")
 				}
-				maxLex, ok := stop.(string)
+				maxLex, ok := lexBound(stop)
 				if !ok {
 					return nil, fmt.Errorf("invalid lex range stop")
 				}
-				result = zset.RangeByLex(minLex, maxLex, opts.IsRev())
+				result = zset.RangeByLex(minLex, maxLex, opts.IsRev(), offset, count)
 			} else {
 				startIdx, ok := start.(int)
 				if !ok {
@@ -520,37 +689,78 @@ func (s *MemoryStore) ZRange(key string, start, stop interface{}, opts *options.
 					return []interface{}{}, nil
 				}
 
-				result = zset.Range(startIdx, stopIdx, withScores)
+				result = zset.Range(startIdx, stopIdx, opts != nil && opts.IsRev(), withScores)
 			}
 
-			if opts != nil && opts.Limit.Count > 0 {
-				offset := opts.Limit.Offset
-				count := opts.Limit.Count
+			return result, nil
+		}
+		return nil, fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")
+	}
+	return []interface{}{}, nil
+}
 
-				multiplier := 1
-				if withScores {
-					multiplier = 2
-				}
+// ZRank returns the 0-based rank of member in the sorted set at key,
+// counting from the highest score down if rev is set, along with its score,
+// and false if it is not in the set.
+func (s *MemoryStore) ZRank(key, member string, rev bool) (int, float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-				// Adjust offset and count based on multiplier
-				adjustedOffset := offset * multiplier
-				adjustedCount := count * multiplier
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return 0, 0, false, err
+	}
+	rank, ok := set.Rank(member, rev)
+	return rank, set.dict[member], ok, nil
+}
 
-				if adjustedOffset >= len(result) {
-					return []interface{}{}, nil
-				}
+// ZCount returns the number of members of the sorted set at key with scores
+// between min and max.
+func (s *MemoryStore) ZCount(key string, min, max options.ScoreBound) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-				end := adjustedOffset + adjustedCount
-				if end > len(result) {
-					end = len(result)
-				}
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+	return set.CountByScore(min, max), nil
+}
 
-				result = result[adjustedOffset:end]
-			}
+// ZLexCount returns the number of members of the sorted set at key between
+// min and max.
+func (s *MemoryStore) ZLexCount(key string, min, max options.LexBound) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			return result, nil
-		}
-		return nil, fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return 0, err
 	}
-	return []interface{}{}, nil
+	return set.CountByLex(min, max), nil
+}
+
+// ZRemRangeByLex removes the members of the sorted set at key between min
+// and max, deleting the key if none are left, and returns how many it
+// removed.
+func (s *MemoryStore) ZRemRangeByLex(key string, min, max options.LexBound) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSortedSet(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+	var members []string
+	for node := set.sl.firstInLexRange(min, max); node != nil && max.BelowMax(node.member); node = node.forward[0] {
+		members = append(members, node.member)
+	}
+	for _, member := range members {
+		set.Remove(member)
+	}
+	if len(set.dict) == 0 {
+		delete(s.data, key)
+		delete(s.expires, key)
+	}
+	return len(members), nil
 }
@@ -1,6 +1,9 @@
 package store
 
 import (
+	"math"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -561,7 +564,7 @@ func TestMemoryStore_ZRange(t *testing.T) {
 		},
 		{
 			name:  "range by lex",
-			key:   "zset1",
+			key:   "lex",
 			start: "one",
 			stop:  "three",
 			opts: func() *options.ZRangeOptions {
@@ -570,6 +573,10 @@ func TestMemoryStore_ZRange(t *testing.T) {
 				return o
 			}(),
 			want: []interface{}{"one", "three"},
+			setup: func() {
+				// Lex ranges are meant for members that all have the same score
+				store.ZAdd("lex", []types.ScoreMember{{Member: "one"}, {Member: "two"}, {Member: "three"}}, nil)
+			},
 		},
 		{
 			name:  "range with scores",
@@ -634,3 +641,243 @@ func TestMemoryStore_ZRange(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryStore_ZRangeByScore(t *testing.T) {
+	store := NewMemoryStore()
+	store.ZAdd("z", []types.ScoreMember{
+		{Score: math.Inf(-1), Member: "ninf"},
+		{Score: 1, Member: "a"},
+		{Score: 1.5, Member: "b"},
+		{Score: 1.5, Member: "c"},
+		{Score: 2, Member: "d"},
+		{Score: math.Inf(1), Member: "inf"},
+	}, nil)
+
+	incl := func(score float64) options.ScoreBound { return options.ScoreBound{Score: score} }
+	excl := func(score float64) options.ScoreBound { return options.ScoreBound{Score: score, Exclusive: true} }
+	tests := []struct {
+		min, max options.ScoreBound
+		rev      bool
+		want     []interface{}
+	}{
+		{incl(1), incl(2), false, []interface{}{"a", "b", "c", "d"}},
+		{excl(1), incl(2), false, []interface{}{"b", "c", "d"}},
+		{incl(1), excl(2), false, []interface{}{"a", "b", "c"}},
+		{excl(1), excl(2), false, []interface{}{"b", "c"}},
+		{excl(1.5), excl(2), false, []interface{}{}},
+		{incl(1.5), incl(1.5), false, []interface{}{"b", "c"}},
+		{excl(1.5), incl(1.5), false, []interface{}{}},
+		{incl(2), incl(1), false, []interface{}{}},
+		{incl(math.Inf(-1)), incl(math.Inf(1)), false, []interface{}{"ninf", "a", "b", "c", "d", "inf"}},
+		{excl(math.Inf(-1)), excl(math.Inf(1)), false, []interface{}{"a", "b", "c", "d"}},
+		{incl(1), incl(2), true, []interface{}{"d", "c", "b", "a"}},
+		{excl(1), excl(2), true, []interface{}{"c", "b"}},
+		{incl(3), incl(math.Inf(1)), true, []interface{}{"inf"}},
+		{incl(2), incl(1), true, []interface{}{}},
+	}
+	for _, tt := range tests {
+		opts := options.NewZRangeOptions()
+		opts.SetRangeType("BYSCORE")
+		opts.Rev = tt.rev
+		if got, err := store.ZRange("z", tt.min, tt.max, opts); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ZRange(%+v, %+v, rev %v) = %v, %v, want %v", tt.min, tt.max, tt.rev, got, err, tt.want)
+		}
+	}
+
+	opts := options.NewZRangeOptions()
+	opts.Rev = true
+	opts.WithScores = true
+	if got, _ := store.ZRange("z", 1, 2, opts); !reflect.DeepEqual(got, []interface{}{"d", 2.0, "c", 1.5}) {
+		t.Errorf("ZRange() by index with REV = %v, want [d 2 c 1.5]", got)
+	}
+}
+
+func TestMemoryStore_ZRangeByLex(t *testing.T) {
+	store := NewMemoryStore()
+	var members []types.ScoreMember
+	for _, m := range []string{"e", "c", "a", "d", "b"} {
+		members = append(members, types.ScoreMember{Member: m})
+	}
+	store.ZAdd("z", members, nil)
+
+	bound := func(arg string) options.LexBound {
+		b, err := options.ParseLexBound(arg)
+		if err != nil {
+			t.Fatalf("ParseLexBound(%q) error = %v", arg, err)
+		}
+		return b
+	}
+	tests := []struct {
+		min, max string
+		rev      bool
+		want     []interface{}
+	}{
+		{"-", "+", false, []interface{}{"a", "b", "c", "d", "e"}},
+		{"[b", "[d", false, []interface{}{"b", "c", "d"}},
+		{"(b", "(d", false, []interface{}{"c"}},
+		{"[bb", "+", false, []interface{}{"c", "d", "e"}},
+		{"-", "(c", false, []interface{}{"a", "b"}},
+		{"[d", "[b", false, []interface{}{}},
+		{"+", "-", false, []interface{}{}},
+		{"[b", "[d", true, []interface{}{"d", "c", "b"}},
+		{"(a", "+", true, []interface{}{"e", "d", "c", "b"}},
+		{"-", "[a", true, []interface{}{"a"}},
+	}
+	for _, tt := range tests {
+		opts := options.NewZRangeOptions()
+		opts.SetRangeType("BYLEX")
+		opts.Rev = tt.rev
+		if got, err := store.ZRange("z", bound(tt.min), bound(tt.max), opts); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ZRange(%s, %s, rev %v) = %v, %v, want %v", tt.min, tt.max, tt.rev, got, err, tt.want)
+		}
+	}
+
+	if n, _ := store.ZLexCount("z", bound("(a"), bound("[d")); n != 3 {
+		t.Errorf("ZLexCount() = %d, want 3", n)
+	}
+	if n, _ := store.ZRemRangeByLex("z", bound("[b"), bound("(d")); n != 2 {
+		t.Errorf("ZRemRangeByLex() = %d, want 2", n)
+	}
+	opts := options.NewZRangeOptions()
+	if got, _ := store.ZRange("z", 0, -1, opts); !reflect.DeepEqual(got, []interface{}{"a", "d", "e"}) {
+		t.Errorf("ZRange() after ZRemRangeByLex() = %v, want [a d e]", got)
+	}
+	store.ZRemRangeByLex("z", bound("-"), bound("+"))
+	if store.Exists("z") {
+		t.Errorf("ZRemRangeByLex() of every member left the key")
+	}
+}
+
+func TestMemoryStore_ZAddUpdatesScore(t *testing.T) {
+	store := NewMemoryStore()
+	store.ZAdd("z", []types.ScoreMember{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}}, nil)
+	store.ZAdd("z", []types.ScoreMember{{Score: 3, Member: "a"}}, nil)
+	store.ZAdd("z", []types.ScoreMember{{Score: 3, Member: "a"}}, nil)
+
+	opts := options.NewZRangeOptions()
+	opts.WithScores = true
+	if got, _ := store.ZRange("z", 0, -1, opts); !reflect.DeepEqual(got, []interface{}{"b", 2.0, "a", 3.0}) {
+		t.Errorf("ZRange() after updating a score = %v, want [b 2 a 3]", got)
+	}
+	if set, _ := store.getSortedSet("z"); set.sl.length != 2 {
+		t.Errorf("skiplist length = %d, want 2", set.sl.length)
+	}
+}
+
+func TestMemoryStore_ZRankAndCount(t *testing.T) {
+	store := NewMemoryStore()
+	var members []types.ScoreMember
+	for i := 0; i < 1000; i++ {
+		members = append(members, types.ScoreMember{Score: float64(i), Member: "m" + strconv.Itoa(i)})
+	}
+	store.ZAdd("z", members, nil)
+
+	if rank, score, ok, _ := store.ZRank("z", "m900", false); !ok || rank != 900 || score != 900 {
+		t.Errorf("ZRank(m900) = %d, %v, %v, want 900, 900", rank, score, ok)
+	}
+	if rank, _, _, _ := store.ZRank("z", "m900", true); rank != 99 {
+		t.Errorf("ZRank(m900, rev) = %d, want 99", rank)
+	}
+	if _, _, ok, _ := store.ZRank("z", "missing", false); ok {
+		t.Errorf("ZRank() of a missing member succeeded")
+	}
+	if _, _, ok, _ := store.ZRank("missing", "m1", false); ok {
+		t.Errorf("ZRank() of a missing key succeeded")
+	}
+
+	counts := []struct {
+		min, max options.ScoreBound
+		want     int
+	}{
+		{options.ScoreBound{Score: 10}, options.ScoreBound{Score: 19}, 10},
+		{options.ScoreBound{Score: 10, Exclusive: true}, options.ScoreBound{Score: 19, Exclusive: true}, 8},
+		{options.ScoreBound{Score: math.Inf(-1)}, options.ScoreBound{Score: math.Inf(1)}, 1000},
+		{options.ScoreBound{Score: 5.5}, options.ScoreBound{Score: 5.6}, 0},
+		{options.ScoreBound{Score: 20}, options.ScoreBound{Score: 10}, 0},
+	}
+	for _, tt := range counts {
+		if got, _ := store.ZCount("z", tt.min, tt.max); got != tt.want {
+			t.Errorf("ZCount(%+v, %+v) = %d, want %d", tt.min, tt.max, got, tt.want)
+		}
+	}
+
+	limits := []struct {
+		rangeType     string
+		start, stop   interface{}
+		rev           bool
+		offset, count int
+		want          []interface{}
+	}{
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, false, 50, 2, []interface{}{"m150", "m151"}},
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, true, 99, 5, []interface{}{"m101", "m100"}},
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, false, 101, 5, []interface{}{}},
+		// A negative count returns the rest of the range, zero returns nothing
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, false, 98, -1, []interface{}{"m198", "m199", "m200"}},
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, true, 98, -5, []interface{}{"m102", "m101", "m100"}},
+		{"BYSCORE", options.ScoreBound{Score: 100}, options.ScoreBound{Score: 200}, false, 0, 0, []interface{}{}},
+	}
+	for _, tt := range limits {
+		opts := options.NewZRangeOptions()
+		opts.SetRangeType(tt.rangeType)
+		opts.Rev = tt.rev
+		opts.SetLimit(tt.offset, tt.count)
+		if got, err := store.ZRange("z", tt.start, tt.stop, opts); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ZRange(%v, %v, %s, rev %v, LIMIT %d %d) = %v, %v, want %v", tt.start, tt.stop, tt.rangeType, tt.rev, tt.offset, tt.count, got, err, tt.want)
+		}
+	}
+
+	var same []types.ScoreMember
+	for _, m := range []string{"a", "b", "c", "d"} {
+		same = append(same, types.ScoreMember{Member: m})
+	}
+	store.ZAdd("lex", same, nil)
+	opts := options.NewZRangeOptions()
+	opts.SetRangeType("BYLEX")
+	opts.Rev = true
+	opts.SetLimit(1, 2)
+	if got, _ := store.ZRange("lex", options.LexBound{Inf: -1}, options.LexBound{Inf: 1}, opts); !reflect.DeepEqual(got, []interface{}{"c", "b"}) {
+		t.Errorf("ZRange() BYLEX REV LIMIT 1 2 = %v, want [c b]", got)
+	}
+	opts.SetLimit(1, -1)
+	if got, _ := store.ZRange("lex", options.LexBound{Inf: -1}, options.LexBound{Inf: 1}, opts); !reflect.DeepEqual(got, []interface{}{"c", "b", "a"}) {
+		t.Errorf("ZRange() BYLEX REV LIMIT 1 -1 = %v, want [c b a]", got)
+	}
+	opts.SetLimit(1, 0)
+	if got, _ := store.ZRange("lex", options.LexBound{Inf: -1}, options.LexBound{Inf: 1}, opts); len(got) != 0 {
+		t.Errorf("ZRange() BYLEX REV LIMIT 1 0 = %v, want none", got)
+	}
+}
+
+func TestMemoryStore_DumpAndRestoreEntry(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("str", "value", nil)
+	store.ZAdd("zset", []types.ScoreMember{{Score: 1, Member: "a"}}, nil)
+
+	if !store.Exists("zset") || store.Exists("missing") {
+		t.Errorf("Exists() does not match the stored keys")
+	}
+	if _, ok := store.Dump("missing"); ok {
+		t.Errorf("Dump() of a missing key succeeded")
+	}
+
+	entry, ok := store.Dump("zset")
+	if !ok {
+		t.Fatalf("Dump() of an existing key failed")
+	}
+
+	target := NewMemoryStore()
+	if err := target.RestoreEntry(entry, false); err != nil {
+		t.Fatalf("RestoreEntry() error = %v", err)
+	}
+	if err := target.RestoreEntry(entry, false); err != ErrBusyKey {
+		t.Errorf("RestoreEntry() of an existing key error = %v, want %v", err, ErrBusyKey)
+	}
+	if err := target.RestoreEntry(entry, true); err != nil {
+		t.Errorf("RestoreEntry() with replace error = %v", err)
+	}
+
+	got, err := target.ZRange("zset", 0, -1, nil)
+	if err != nil || len(got) != 1 || got[0] != "a" {
+		t.Errorf("ZRange() after RestoreEntry() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,349 @@
+package store
+
+// listChunkSize is the maximum number of elements held by a single node of a
+// List. Bigger chunks waste less memory on node headers, smaller ones make
+// inserts in the middle of the list cheaper.
+const listChunkSize = 128
+
+// listNode is one chunk of a List.
+type listNode struct {
+	items      []string
+	prev, next *listNode
+}
+
+// List is a doubly linked list of chunks of elements, in the manner of the
+// Redis quicklist. Pushes and pops at either end are O(1), and indexed access
+// walks chunks rather than elements from whichever end is closer.
+type List struct {
+	head, tail *listNode
+	length     int
+}
+
+func newList() *List {
+	return &List{}
+}
+
+// Len returns the number of elements in the list.
+func (l *List) Len() int {
+	return l.length
+}
+
+// PushFront inserts value at the head of the list.
+func (l *List) PushFront(value string) {
+	if l.head == nil || len(l.head.items) >= listChunkSize {
+		l.linkBefore(l.head, &listNode{})
+	}
+	n := l.head
+	n.items = append(n.items, "")
+	copy(n.items[1:], n.items)
+	n.items[0] = value
+	l.length++
+}
+
+// PushBack inserts value at the tail of the list.
+func (l *List) PushBack(value string) {
+	if l.tail == nil || len(l.tail.items) >= listChunkSize {
+		l.linkAfter(l.tail, &listNode{})
+	}
+	l.tail.items = append(l.tail.items, value)
+	l.length++
+}
+
+// PopFront removes and returns the head of the list. The list must not be
+// empty.
+func (l *List) PopFront() string {
+	n := l.head
+	value := n.items[0]
+	n.items[0] = ""
+	n.items = n.items[1:]
+	l.length--
+	if len(n.items) == 0 {
+		l.unlink(n)
+	}
+	return value
+}
+
+// PopBack removes and returns the tail of the list. The list must not be
+// empty.
+func (l *List) PopBack() string {
+	n := l.tail
+	last := len(n.items) - 1
+	value := n.items[last]
+	n.items[last] = ""
+	n.items = n.items[:last]
+	l.length--
+	if len(n.items) == 0 {
+		l.unlink(n)
+	}
+	return value
+}
+
+// Index returns the element at index, which must be in range.
+func (l *List) Index(index int) string {
+	n, offset := l.locate(index)
+	return n.items[offset]
+}
+
+// Set replaces the element at index, which must be in range.
+func (l *List) Set(index int, value string) {
+	n, offset := l.locate(index)
+	n.items[offset] = value
+}
+
+// Range returns the elements from start to stop inclusive. Both must be in
+// range and start must not be greater than stop.
+func (l *List) Range(start, stop int) []string {
+	result := make([]string, 0, stop-start+1)
+	n, offset := l.locate(start)
+	for remaining := stop - start + 1; remaining > 0; n, offset = n.next, 0 {
+		end := min(len(n.items), offset+remaining)
+		result = append(result, n.items[offset:end]...)
+		remaining -= end - offset
+	}
+	return result
+}
+
+// Items returns a copy of every element in order.
+func (l *List) Items() []string {
+	if l.length == 0 {
+		return []string{}
+	}
+	return l.Range(0, l.length-1)
+}
+
+// Insert inserts value before or after the first occurrence of pivot and
+// reports whether pivot was found.
+func (l *List) Insert(pivot, value string, before bool) bool {
+	for n := l.head; n != nil; n = n.next {
+		for i, item := range n.items {
+			if item != pivot {
+				continue
+			}
+			if !before {
+				i++
+			}
+			l.insertAt(n, i, value)
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes elements equal to value and returns how many were removed.
+// A positive count removes at most count elements moving from head to tail,
+// a negative one at most -count elements moving from tail to head, and zero
+// removes them all.
+func (l *List) Remove(value string, count int) int {
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	removed := 0
+	matches := func(item string) bool {
+		if item == value && (limit == 0 || removed < limit) {
+			removed++
+			return true
+		}
+		return false
+	}
+
+	if count >= 0 {
+		for n := l.head; n != nil; {
+			next := n.next
+			kept := n.items[:0]
+			for _, item := range n.items {
+				if !matches(item) {
+					kept = append(kept, item)
+				}
+			}
+			clear(n.items[len(kept):])
+			n.items = kept
+			if len(n.items) == 0 {
+				l.unlink(n)
+			}
+			n = next
+		}
+	} else {
+		for n := l.tail; n != nil; {
+			prev := n.prev
+			w := len(n.items)
+			for r := len(n.items) - 1; r >= 0; r-- {
+				if !matches(n.items[r]) {
+					w--
+					n.items[w] = n.items[r]
+				}
+			}
+			clear(n.items[:w])
+			n.items = n.items[w:]
+			if len(n.items) == 0 {
+				l.unlink(n)
+			}
+			n = prev
+		}
+	}
+
+	l.length -= removed
+	return removed
+}
+
+// Trim keeps only the elements from start to stop inclusive. Both must be
+// in range and start must not be greater than stop.
+func (l *List) Trim(start, stop int) {
+	l.dropBack(l.length - 1 - stop)
+	l.dropFront(start)
+}
+
+// Each calls fn for every element with its index, from head to tail or from
+// tail to head when reverse is set, until fn returns false.
+func (l *List) Each(reverse bool, fn func(index int, value string) bool) {
+	if reverse {
+		index := l.length - 1
+		for n := l.tail; n != nil; n = n.prev {
+			for i := len(n.items) - 1; i >= 0; i-- {
+				if !fn(index, n.items[i]) {
+					return
+				}
+				index--
+			}
+		}
+		return
+	}
+
+	index := 0
+	for n := l.head; n != nil; n = n.next {
+		for _, item := range n.items {
+			if !fn(index, item) {
+				return
+			}
+			index++
+		}
+	}
+}
+
+// locate returns the node holding index and the offset of index within it,
+// walking from whichever end of the list is closer.
+func (l *List) locate(index int) (*listNode, int) {
+	if index < l.length/2 {
+		n := l.head
+		for index >= len(n.items) {
+			index -= len(n.items)
+			n = n.next
+		}
+		return n, index
+	}
+
+	index = l.length - 1 - index
+	n := l.tail
+	for index >= len(n.items) {
+		index -= len(n.items)
+		n = n.prev
+	}
+	return n, len(n.items) - 1 - index
+}
+
+// insertAt inserts value at offset within n, splitting n in two first if it
+// is full.
+func (l *List) insertAt(n *listNode, offset int, value string) {
+	if len(n.items) >= listChunkSize {
+		half := len(n.items) / 2
+		right := &listNode{items: append(make([]string, 0, listChunkSize), n.items[half:]...)}
+		clear(n.items[half:])
+		n.items = n.items[:half]
+		l.linkAfter(n, right)
+		if offset > half {
+			n, offset = right, offset-half
+		}
+	}
+
+	n.items = append(n.items, "")
+	copy(n.items[offset+1:], n.items[offset:])
+	n.items[offset] = value
+	l.length++
+}
+
+// dropFront removes the first count elements, a whole chunk at a time where
+// possible.
+func (l *List) dropFront(count int) {
+	for count > 0 {
+		n := l.head
+		if len(n.items) <= count {
+			count -= len(n.items)
+			l.length -= len(n.items)
+			l.unlink(n)
+			continue
+		}
+		clear(n.items[:count])
+		n.items = n.items[count:]
+		l.length -= count
+		return
+	}
+}
+
+// dropBack removes the last count elements, a whole chunk at a time where
+// possible.
+func (l *List) dropBack(count int) {
+	for count > 0 {
+		n := l.tail
+		if len(n.items) <= count {
+			count -= len(n.items)
+			l.length -= len(n.items)
+			l.unlink(n)
+			continue
+		}
+		keep := len(n.items) - count
+		clear(n.items[keep:])
+		n.items = n.items[:keep]
+		l.length -= count
+		return
+	}
+}
+
+// linkBefore links n into the list before at, or at the tail if at is nil.
+func (l *List) linkBefore(at, n *listNode) {
+	if at == nil {
+		l.linkAfter(l.tail, n)
+		return
+	}
+	n.prev, n.next = at.prev, at
+	if at.prev != nil {
+		at.prev.next = n
+	} else {
+		l.head = n
+	}
+	at.prev = n
+}
+
+// linkAfter links n into the list after at, or at the head if at is nil.
+func (l *List) linkAfter(at, n *listNode) {
+	if at == nil {
+		n.prev, n.next = nil, l.head
+		if l.head != nil {
+			l.head.prev = n
+		} else {
+			l.tail = n
+		}
+		l.head = n
+		return
+	}
+	n.prev, n.next = at, at.next
+	if at.next != nil {
+		at.next.prev = n
+	} else {
+		l.tail = n
+	}
+	at.next = n
+}
+
+func (l *List) unlink(n *listNode) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		l.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		l.tail = n.prev
+	}
+	n.prev, n.next = nil, nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// DefaultScanCount is the number of elements a SCAN-family command visits
+// per call when no COUNT is given.
+const DefaultScanCount = 10
+
+// scanTableMinBuckets is the smallest number of buckets a scanTable has.
+const scanTableMinBuckets = 4
+
+// scanPosition returns the hash of name that places it in a scanTable
+// bucket. It depends only on the name, so a name keeps its place in the
+// visiting order whatever is added or removed in between.
+func scanPosition(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// scanTable indexes the names of a collection in a power-of-two number of
+// buckets by the low bits of their scanPosition. SCAN-family commands walk
+// the buckets in reverse binary order, as Redis walks its dict, so a cursor
+// stays valid when the table grows or shrinks between calls: every name
+// present for the whole iteration is returned at least once, and a call
+// costs about COUNT rather than the size of the collection.
+type scanTable struct {
+	buckets [][]string
+	len     int
+}
+
+// newScanTable returns a table indexing names, which must be distinct.
+func newScanTable(names []string) *scanTable {
+	size := scanTableMinBuckets
+	for size < len(names) {
+		size *= 2
+	}
+	t := &scanTable{buckets: make([][]string, size)}
+	for _, name := range names {
+		t.add(name)
+	}
+	return t
+}
+
+// add indexes name, which must not be in the table yet.
+func (t *scanTable) add(name string) {
+	if t.len >= len(t.buckets) {
+		t.resize(len(t.buckets) * 2)
+	}
+	i := scanPosition(name) & uint64(len(t.buckets)-1)
+	t.buckets[i] = append(t.buckets[i], name)
+	t.len++
+}
+
+// remove drops name from the table if it is there.
+func (t *scanTable) remove(name string) {
+	i := scanPosition(name) & uint64(len(t.buckets)-1)
+	bucket := t.buckets[i]
+	for j, n := range bucket {
+		if n == name {
+			bucket[j] = bucket[len(bucket)-1]
+			t.buckets[i] = bucket[:len(bucket)-1]
+			t.len--
+			break
+		}
+	}
+	if len(t.buckets) > scanTableMinBuckets && t.len*8 < len(t.buckets) {
+		t.resize(len(t.buckets) / 2)
+	}
+}
+
+// resize rehashes the table into size buckets.
+func (t *scanTable) resize(size int) {
+	old := t.buckets
+	t.buckets = make([][]string, size)
+	mask := uint64(size - 1)
+	for _, bucket := range old {
+		for _, name := range bucket {
+			i := scanPosition(name) & mask
+			t.buckets[i] = append(t.buckets[i], name)
+		}
+	}
+}
+
+// scan visits buckets from cursor until it has seen at least count names,
+// or ten times count buckets, and returns those matching pattern together
+// with the cursor to continue from, which is 0 once the iteration is
+// complete.
+func (t *scanTable) scan(cursor uint64, pattern string, count int) ([]string, uint64) {
+	if t.len == 0 {
+		return nil, 0
+	}
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	mask := uint64(len(t.buckets) - 1)
+	var matched []string
+	visited := 0
+	for buckets := count * 10; buckets > 0; buckets-- {
+		for _, name := range t.buckets[cursor&mask] {
+			if pattern == "" || matchPattern(name, pattern) {
+				matched = append(matched, name)
+			}
+		}
+		visited += len(t.buckets[cursor&mask])
+
+		// Increment the bits under the mask in reverse order, so that the
+		// buckets a resize splits or merges are visited next to each other.
+		cursor |= ^mask
+		cursor = bits.Reverse64(bits.Reverse64(cursor) + 1)
+		if cursor == 0 || visited >= count {
+			break
+		}
+	}
+	return matched, cursor
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestScanTable_Resize(t *testing.T) {
+	var names []string
+	for i := 0; i < 1000; i++ {
+		names = append(names, "stay"+strconv.Itoa(i))
+	}
+	table := newScanTable(names)
+
+	seen := make(map[string]bool)
+	cursor := uint64(0)
+	peak := 0
+	for calls := 0; ; calls++ {
+		if calls > 1000 {
+			t.Fatalf("scan() did not finish")
+		}
+		got, next := table.scan(cursor, "", 10)
+		if len(got) > 40 {
+			t.Errorf("scan() with COUNT 10 returned %d names", len(got))
+		}
+		for _, name := range got {
+			seen[name] = true
+		}
+
+		// Grow the table to several times its size, then shrink it back,
+		// while the iteration is under way.
+		switch {
+		case calls < 20:
+			for i := 0; i < 200; i++ {
+				table.add("temp" + strconv.Itoa(calls*200+i))
+			}
+		case calls < 40:
+			for i := 0; i < 200; i++ {
+				table.remove("temp" + strconv.Itoa((calls-20)*200+i))
+			}
+		}
+		peak = max(peak, len(table.buckets))
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if table.len != len(names) || len(table.buckets) >= peak {
+		t.Errorf("table has %d names in %d buckets, %d at its peak", table.len, len(table.buckets), peak)
+	}
+	for _, name := range names {
+		if !seen[name] {
+			t.Errorf("scan() never returned %s", name)
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package store
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// setMaxIntsetEntries is the size up to which a set whose members are all
+// integers is kept as an intset.
+const setMaxIntsetEntries = 512
+
+// Set is an unordered collection of unique strings. Small sets whose members
+// are all integers are stored as a sorted slice of int64, in the manner of
+// the Redis intset, and converted to a map once they outgrow it or a
+// non-integer member is added.
+type Set struct {
+	ints    []int64             // Sorted members while the set is an intset
+	members map[string]struct{} // Members once it is not, nil until then
+	index   *scanTable          // Built by the first SSCAN of members
+}
+
+func newSet() *Set {
+	return &Set{}
+}
+
+// isIntset reports whether the set uses the integer encoding.
+func (set *Set) isIntset() bool {
+	return set.members == nil
+}
+
+// setInt returns the integer value of member if it can be stored in an
+// intset, which requires it to be the canonical decimal form of an int64 so
+// that it reads back unchanged.
+func setInt(member string) (int64, bool) {
+	n, err := strconv.ParseInt(member, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != member {
+		return 0, false
+	}
+	return n, true
+}
+
+// search returns the position of n in the intset and whether it is there.
+func (set *Set) search(n int64) (int, bool) {
+	i := sort.Search(len(set.ints), func(i int) bool { return set.ints[i] >= n })
+	return i, i < len(set.ints) && set.ints[i] == n
+}
+
+// convert switches the set to the map encoding.
+func (set *Set) convert() {
+	set.members = make(map[string]struct{}, len(set.ints)+1)
+	for _, n := range set.ints {
+		set.members[strconv.FormatInt(n, 10)] = struct{}{}
+	}
+	set.ints = nil
+}
+
+// Len returns the number of members.
+func (set *Set) Len() int {
+	if set.isIntset() {
+		return len(set.ints)
+	}
+	return len(set.members)
+}
+
+// Add adds member and reports whether it was not already in the set.
+func (set *Set) Add(member string) bool {
+	if set.isIntset() {
+		if n, ok := setInt(member); ok {
+			i, found := set.search(n)
+			if found {
+				return false
+			}
+			if len(set.ints) < setMaxIntsetEntries {
+				set.ints = append(set.ints, 0)
+				copy(set.ints[i+1:], set.ints[i:])
+				set.ints[i] = n
+				return true
+			}
+		}
+		set.convert()
+	}
+
+	if _, exists := set.members[member]; exists {
+		return false
+	}
+	set.members[member] = struct{}{}
+	if set.index != nil {
+		set.index.add(member)
+	}
+	return true
+}
+
+// Remove removes member and reports whether it was in the set.
+func (set *Set) Remove(member string) bool {
+	if set.isIntset() {
+		n, ok := setInt(member)
+		if !ok {
+			return false
+		}
+		i, found := set.search(n)
+		if found {
+			set.ints = append(set.ints[:i], set.ints[i+1:]...)
+		}
+		return found
+	}
+
+	if _, exists := set.members[member]; !exists {
+		return false
+	}
+	delete(set.members, member)
+	if set.index != nil {
+		set.index.remove(member)
+	}
+	return true
+}
+
+// Contains reports whether member is in the set.
+func (set *Set) Contains(member string) bool {
+	if set.isIntset() {
+		n, ok := setInt(member)
+		if !ok {
+			return false
+		}
+		_, found := set.search(n)
+		return found
+	}
+	_, exists := set.members[member]
+	return exists
+}
+
+// Members returns a copy of every member. Intsets list them in ascending
+// order.
+func (set *Set) Members() []string {
+	members := make([]string, 0, set.Len())
+	if set.isIntset() {
+		for _, n := range set.ints {
+			members = append(members, strconv.FormatInt(n, 10))
+		}
+		return members
+	}
+	for member := range set.members {
+		members = append(members, member)
+	}
+	return members
+}
+
+// Random returns count random members. A positive count returns up to count
+// distinct members; a negative one returns exactly -count members, possibly
+// repeating some.
+func (set *Set) Random(count int) []string {
+	members := set.Members()
+	if count < 0 {
+		picked := make([]string, -count)
+		for i := range picked {
+			picked[i] = members[rand.Intn(len(members))]
+		}
+		return picked
+	}
+
+	rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	if count < len(members) {
+		members = members[:count]
+	}
+	return members
+}
+
+// getSet returns the set stored at key, nil if there is none, or
+// ErrWrongType if the key holds another type. It must be called with s.mu
+// held for writing, since it deletes the key if it has expired.
+func (s *MemoryStore) getSet(key string) (*Set, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	val, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	set, ok := val.(*Set)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return set, nil
+}
+
+// getSets returns the sets stored at keys, with nil for keys that do not
+// exist.
+func (s *MemoryStore) getSets(keys []string) ([]*Set, error) {
+	sets := make([]*Set, len(keys))
+	for i, key := range keys {
+		set, err := s.getSet(key)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	return sets, nil
+}
+
+// deleteIfEmptySet removes key if set has no members left.
+func (s *MemoryStore) deleteIfEmptySet(key string, set *Set) {
+	if set.Len() == 0 {
+		delete(s.data, key)
+		delete(s.expires, key)
+	}
+}
+
+// SAdd adds members to the set at key, creating it if needed, and returns
+// how many of them were not already members.
+func (s *MemoryStore) SAdd(key string, members []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil {
+		return 0, err
+	}
+	if set == nil {
+		set = newSet()
+		s.data[key] = set
+	}
+
+	added := 0
+	for _, member := range members {
+		if set.Add(member) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// SRem removes members from the set at key and returns how many of them
+// were members.
+func (s *MemoryStore) SRem(key string, members []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, member := range members {
+		if set.Remove(member) {
+			removed++
+		}
+	}
+	s.deleteIfEmptySet(key, set)
+	return removed, nil
+}
+
+// SIsMember reports whether member is in the set at key.
+func (s *MemoryStore) SIsMember(key, member string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return false, err
+	}
+	return set.Contains(member), nil
+}
+
+// SMIsMember reports for each of members whether it is in the set at key.
+func (s *MemoryStore) SMIsMember(key string, members []string) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]bool, len(members))
+	if set != nil {
+		for i, member := range members {
+			found[i] = set.Contains(member)
+		}
+	}
+	return found, nil
+}
+
+// SMembers returns every member of the set at key.
+func (s *MemoryStore) SMembers(key string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return []string{}, err
+	}
+	return set.Members(), nil
+}
+
+// SCard returns the number of members of the set at key.
+func (s *MemoryStore) SCard(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return 0, err
+	}
+	return set.Len(), nil
+}
+
+// SPop removes and returns up to count random members of the set at key, or
+// nil if the key does not exist.
+func (s *MemoryStore) SPop(key string, count int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return nil, err
+	}
+
+	popped := set.Random(count)
+	for _, member := range popped {
+		set.Remove(member)
+	}
+	s.deleteIfEmptySet(key, set)
+	return popped, nil
+}
+
+// SRandMember returns random members of the set at key without removing
+// them. A positive count returns up to count distinct members, a negative
+// one returns exactly -count members which may repeat.
+func (s *MemoryStore) SRandMember(key string, count int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return []string{}, err
+	}
+	return set.Random(count), nil
+}
+
+// SMove moves member from the set at source to the set at destination and
+// reports whether it was a member of source.
+func (s *MemoryStore) SMove(source, destination, member string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.getSet(source)
+	if err != nil {
+		return false, err
+	}
+	dst, err := s.getSet(destination)
+	if err != nil {
+		return false, err
+	}
+	if src == nil || !src.Contains(member) {
+		return false, nil
+	}
+	if source == destination {
+		return true, nil
+	}
+
+	src.Remove(member)
+	s.deleteIfEmptySet(source, src)
+	if dst == nil {
+		dst = newSet()
+		s.data[destination] = dst
+	}
+	dst.Add(member)
+	return true, nil
+}
+
+// SInter returns the members common to the sets at keys. A missing key
+// counts as an empty set.
+func (s *MemoryStore) SInter(keys []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.getSets(keys)
+	if err != nil {
+		return nil, err
+	}
+	return setInter(sets, 0).Members(), nil
+}
+
+// SUnion returns the members of any of the sets at keys.
+func (s *MemoryStore) SUnion(keys []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.getSets(keys)
+	if err != nil {
+		return nil, err
+	}
+	return setUnion(sets).Members(), nil
+}
+
+// SDiff returns the members of the set at the first key that are in none of
+// the sets at the other keys.
+func (s *MemoryStore) SDiff(keys []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.getSets(keys)
+	if err != nil {
+		return nil, err
+	}
+	return setDiff(sets).Members(), nil
+}
+
+// SInterStore stores the intersection of the sets at keys at destination,
+// replacing whatever it held, and returns its size.
+func (s *MemoryStore) SInterStore(destination string, keys []string) (int, error) {
+	return s.storeSet(destination, keys, func(sets []*Set) *Set { return setInter(sets, 0) })
+}
+
+// SUnionStore stores the union of the sets at keys at destination,
+// replacing whatever it held, and returns its size.
+func (s *MemoryStore) SUnionStore(destination string, keys []string) (int, error) {
+	return s.storeSet(destination, keys, setUnion)
+}
+
+// SDiffStore stores the difference of the sets at keys at destination,
+// replacing whatever it held, and returns its size.
+func (s *MemoryStore) SDiffStore(destination string, keys []string) (int, error) {
+	return s.storeSet(destination, keys, setDiff)
+}
+
+// storeSet stores the result of combining the sets at keys at destination.
+// An empty result deletes destination.
+func (s *MemoryStore) storeSet(destination string, keys []string, combine func([]*Set) *Set) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.getSets(keys)
+	if err != nil {
+		return 0, err
+	}
+	result := combine(sets)
+
+	delete(s.data, destination)
+	delete(s.expires, destination)
+	if result.Len() > 0 {
+		s.data[destination] = result
+	}
+	return result.Len(), nil
+}
+
+// SInterCard returns the size of the intersection of the sets at keys,
+// stopping once it reaches limit if limit is positive.
+func (s *MemoryStore) SInterCard(keys []string, limit int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.getSets(keys)
+	if err != nil {
+		return 0, err
+	}
+	return setInter(sets, limit).Len(), nil
+}
+
+// scan returns the members visited from cursor that match pattern, and the
+// cursor to continue from. An intset is small and visited whole in one call,
+// as Redis does for its compact encodings.
+func (set *Set) scan(cursor uint64, pattern string, count int) ([]string, uint64) {
+	if set.isIntset() {
+		var matched []string
+		for _, member := range set.Members() {
+			if pattern == "" || matchPattern(member, pattern) {
+				matched = append(matched, member)
+			}
+		}
+		return matched, 0
+	}
+	if set.index == nil {
+		set.index = newScanTable(set.Members())
+	}
+	return set.index.scan(cursor, pattern, count)
+}
+
+// SScan returns the members of the set at key visited from cursor that
+// match pattern, and the cursor to continue from. See scanTable.
+func (s *MemoryStore) SScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, err := s.getSet(key)
+	if err != nil || set == nil {
+		return 0, []string{}, err
+	}
+
+	matched, next := set.scan(cursor, pattern, count)
+	if matched == nil {
+		matched = []string{}
+	}
+	return next, matched, nil
+}
+
+// setInter returns the members common to all of sets, of which there are
+// at most limit if limit is positive. It walks the smallest set and probes
+// the others.
+func setInter(sets []*Set, limit int) *Set {
+	result := newSet()
+	smallest := -1
+	for i, set := range sets {
+		if set == nil {
+			return result
+		}
+		if smallest < 0 || set.Len() < sets[smallest].Len() {
+			smallest = i
+		}
+	}
+	if smallest < 0 {
+		return result
+	}
+
+	for _, member := range sets[smallest].Members() {
+		inAll := true
+		for i, set := range sets {
+			if i != smallest && !set.Contains(member) {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			result.Add(member)
+			if limit > 0 && result.Len() == limit {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// setUnion returns the members of any of sets.
+func setUnion(sets []*Set) *Set {
+	result := newSet()
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, member := range set.Members() {
+			result.Add(member)
+		}
+	}
+	return result
+}
+
+// setDiff returns the members of the first of sets that are in none of the
+// others.
+func setDiff(sets []*Set) *Set {
+	result := newSet()
+	if len(sets) == 0 || sets[0] == nil {
+		return result
+	}
+	for _, member := range sets[0].Members() {
+		found := false
+		for _, set := range sets[1:] {
+			if set != nil && set.Contains(member) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Add(member)
+		}
+	}
+	return result
+}
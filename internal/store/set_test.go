@@ -0,0 +1,295 @@
+package store
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// TestSet_MatchesMap applies random operations to a Set and to a plain map
+// and checks that they agree while the set moves from the intset to the map
+// encoding.
+func TestSet_MatchesMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	set := newSet()
+	model := make(map[string]struct{})
+
+	for i := 0; i < 5000; i++ {
+		member := strconv.Itoa(rng.Intn(2*setMaxIntsetEntries) - setMaxIntsetEntries/2)
+		if i > 4000 && rng.Intn(20) == 0 {
+			member = "m" + member
+		}
+		_, exists := model[member]
+		switch rng.Intn(3) {
+		case 0, 1:
+			if added := set.Add(member); added == exists {
+				t.Fatalf("Add(%q) = %v, want %v", member, added, !exists)
+			}
+			model[member] = struct{}{}
+		case 2:
+			if removed := set.Remove(member); removed != exists {
+				t.Fatalf("Remove(%q) = %v, want %v", member, removed, exists)
+			}
+			delete(model, member)
+		}
+		if set.Len() != len(model) {
+			t.Fatalf("Len() = %d, want %d", set.Len(), len(model))
+		}
+	}
+
+	if set.isIntset() {
+		t.Errorf("set with %d members is still an intset", set.Len())
+	}
+	for member := range model {
+		if !set.Contains(member) {
+			t.Errorf("Contains(%q) = false", member)
+		}
+	}
+}
+
+func TestSet_Intset(t *testing.T) {
+	set := newSet()
+	for _, member := range []string{"3", "-1", "20", "3"} {
+		set.Add(member)
+	}
+	if !set.isIntset() {
+		t.Fatalf("set of integers is not an intset")
+	}
+	if got := set.Members(); !reflect.DeepEqual(got, []string{"-1", "3", "20"}) {
+		t.Errorf("Members() = %v, want ascending order", got)
+	}
+
+	// Integers that would not read back unchanged are stored as strings.
+	for _, member := range []string{"007", "+5", "1e3"} {
+		if set.Contains(member) {
+			t.Errorf("Contains(%q) = true", member)
+		}
+	}
+	set.Add("007")
+	if set.isIntset() {
+		t.Errorf("set is still an intset after adding 007")
+	}
+	if !set.Contains("007") || !set.Contains("20") || set.Contains("7") {
+		t.Errorf("members changed when converting from the intset encoding")
+	}
+
+	big := newSet()
+	for i := 0; i <= setMaxIntsetEntries; i++ {
+		big.Add(strconv.Itoa(i))
+	}
+	if big.isIntset() || big.Len() != setMaxIntsetEntries+1 {
+		t.Errorf("set of %d integers: intset = %v, Len() = %d", setMaxIntsetEntries+1, big.isIntset(), big.Len())
+	}
+}
+
+func TestMemoryStore_SetCommands(t *testing.T) {
+	store := NewMemoryStore()
+
+	if n, err := store.SAdd("s", []string{"a", "b", "c", "a"}); err != nil || n != 3 {
+		t.Fatalf("SAdd() = %d, %v, want 3", n, err)
+	}
+	if n, _ := store.SAdd("s", []string{"c", "d"}); n != 1 {
+		t.Errorf("SAdd() with one new member = %d, want 1", n)
+	}
+	if ok, _ := store.SIsMember("s", "d"); !ok {
+		t.Errorf("SIsMember(d) = false")
+	}
+	if got, _ := store.SMIsMember("s", []string{"a", "x", "b"}); !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("SMIsMember() = %v", got)
+	}
+	if n, _ := store.SCard("s"); n != 4 {
+		t.Errorf("SCard() = %d, want 4", n)
+	}
+	members, _ := store.SMembers("s")
+	sort.Strings(members)
+	if !reflect.DeepEqual(members, []string{"a", "b", "c", "d"}) {
+		t.Errorf("SMembers() = %v", members)
+	}
+
+	if got, _ := store.SRandMember("s", 2); len(got) != 2 || got[0] == got[1] {
+		t.Errorf("SRandMember(2) = %v, want 2 distinct members", got)
+	}
+	if got, _ := store.SRandMember("s", -10); len(got) != 10 {
+		t.Errorf("SRandMember(-10) returned %d members, want 10", len(got))
+	}
+
+	if moved, _ := store.SMove("s", "t", "a"); !moved {
+		t.Errorf("SMove(a) = false")
+	}
+	if moved, _ := store.SMove("s", "t", "a"); moved {
+		t.Errorf("SMove() of a non-member = true")
+	}
+	if ok, _ := store.SIsMember("t", "a"); !ok {
+		t.Errorf("SMove() did not add the member to the destination")
+	}
+
+	if n, _ := store.SRem("s", []string{"b", "x"}); n != 1 {
+		t.Errorf("SRem() = %d, want 1", n)
+	}
+	store.SRem("s", []string{"c", "d"})
+	if store.Exists("s") {
+		t.Errorf("set still exists after removing every member")
+	}
+}
+
+func TestMemoryStore_SPop(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 50; i++ {
+		store.SAdd("s", []string{"m" + strconv.Itoa(i)})
+	}
+
+	seen := make(map[string]bool)
+	for store.Exists("s") {
+		popped, err := store.SPop("s", 7)
+		if err != nil {
+			t.Fatalf("SPop() error = %v", err)
+		}
+		if len(popped) != 7 && store.Exists("s") {
+			t.Fatalf("SPop() returned %d members, want 7", len(popped))
+		}
+		for _, member := range popped {
+			if seen[member] {
+				t.Fatalf("SPop() returned %q twice", member)
+			}
+			seen[member] = true
+		}
+	}
+	if len(seen) != 50 {
+		t.Errorf("SPop() returned %d members in total, want 50", len(seen))
+	}
+	if got, _ := store.SPop("s", 1); got != nil {
+		t.Errorf("SPop() on a missing key = %v, want nil", got)
+	}
+}
+
+func TestMemoryStore_SetAlgebra(t *testing.T) {
+	store := NewMemoryStore()
+	store.SAdd("a", []string{"1", "2", "3", "4"})
+	store.SAdd("b", []string{"3", "4", "5"})
+	store.SAdd("c", []string{"4", "x"})
+
+	sorted := func(members []string, err error) []string {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sort.Strings(members)
+		return members
+	}
+
+	if got := sorted(store.SInter([]string{"a", "b", "c"})); !reflect.DeepEqual(got, []string{"4"}) {
+		t.Errorf("SInter() = %v", got)
+	}
+	if got := sorted(store.SInter([]string{"a", "missing"})); len(got) != 0 {
+		t.Errorf("SInter() with a missing key = %v", got)
+	}
+	if got := sorted(store.SUnion([]string{"b", "c", "missing"})); !reflect.DeepEqual(got, []string{"3", "4", "5", "x"}) {
+		t.Errorf("SUnion() = %v", got)
+	}
+	if got := sorted(store.SDiff([]string{"a", "b", "missing"})); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("SDiff() = %v", got)
+	}
+
+	if n, _ := store.SInterCard([]string{"a", "b"}, 0); n != 2 {
+		t.Errorf("SInterCard() = %d, want 2", n)
+	}
+	if n, _ := store.SInterCard([]string{"a", "b"}, 1); n != 1 {
+		t.Errorf("SInterCard() LIMIT 1 = %d, want 1", n)
+	}
+
+	store.Set("dst", "value", nil)
+	if n, err := store.SUnionStore("dst", []string{"a", "b"}); err != nil || n != 5 {
+		t.Errorf("SUnionStore() = %d, %v, want 5", n, err)
+	}
+	if n, _ := store.SCard("dst"); n != 5 {
+		t.Errorf("SCard() of the stored union = %d, want 5", n)
+	}
+	if n, _ := store.SDiffStore("dst", []string{"a", "a"}); n != 0 || store.Exists("dst") {
+		t.Errorf("SDiffStore() with an empty result = %d, key exists = %v", n, store.Exists("dst"))
+	}
+	if n, _ := store.SInterStore("a", []string{"a", "b"}); n != 2 {
+		t.Errorf("SInterStore() onto a source key = %d, want 2", n)
+	}
+}
+
+func TestMemoryStore_SScan(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		store.SAdd("s", []string{strconv.Itoa(i)})
+	}
+
+	seen := make(map[string]bool)
+	cursor := uint64(0)
+	for calls := 0; ; calls++ {
+		if calls > 100 {
+			t.Fatalf("SScan() did not finish")
+		}
+		next, got, err := store.SScan("s", cursor, "", 7)
+		if err != nil {
+			t.Fatalf("SScan() error = %v", err)
+		}
+		for _, member := range got {
+			seen[member] = true
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 100 {
+		t.Errorf("SScan() returned %d members, want 100", len(seen))
+	}
+
+	if _, got, _ := store.SScan("s", 0, "9*", 1000); len(got) != 11 {
+		t.Errorf("SScan() MATCH 9* returned %d members, want 11", len(got))
+	}
+}
+
+func TestMemoryStore_SetWrongType(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("str", "value", nil)
+	store.SAdd("s", []string{"a"})
+
+	if _, err := store.SAdd("str", []string{"a"}); !errors.Is(err, ErrWrongType) {
+		t.Errorf("SAdd() on a string error = %v, want %v", err, ErrWrongType)
+	}
+	if _, err := store.SUnion([]string{"s", "str"}); !errors.Is(err, ErrWrongType) {
+		t.Errorf("SUnion() with a string error = %v, want %v", err, ErrWrongType)
+	}
+	if _, err := store.SMove("s", "str", "a"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("SMove() to a string error = %v, want %v", err, ErrWrongType)
+	}
+	if ok, _ := store.SIsMember("s", "a"); !ok {
+		t.Errorf("failed SMove() removed the member from the source")
+	}
+}
+
+func TestMemoryStore_SetSnapshot(t *testing.T) {
+	store := NewMemoryStore()
+	store.SAdd("ints", []string{"1", "2", "3"})
+	store.SAdd("strs", []string{"a", "b"})
+
+	for _, key := range []string{"ints", "strs"} {
+		entry, ok := store.Dump(key)
+		if !ok {
+			t.Fatalf("Dump(%s) failed", key)
+		}
+		if _, ok := entry.Value.(map[string]struct{}); !ok {
+			t.Fatalf("Dump(%s) value = %T", key, entry.Value)
+		}
+
+		target := NewMemoryStore()
+		if err := target.RestoreEntry(entry, false); err != nil {
+			t.Fatalf("RestoreEntry(%s) error = %v", key, err)
+		}
+		want, _ := store.SMembers(key)
+		got, _ := target.SMembers(key)
+		sort.Strings(want)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SMembers(%s) after RestoreEntry() = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"math/rand"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
 )
 
 const (
@@ -13,6 +15,7 @@ type skiplistNode struct {
 	member   string
 	score    float64
 	forward  []*skiplistNode // Array of forward pointers
+	span     []int           // Number of level 0 steps each forward pointer skips
 	backward *skiplistNode   // Backward pointer for reverse iteration
 	level    int             // Current node level
 }
@@ -27,6 +30,7 @@ type skiplist struct {
 func newSkiplist() *skiplist {
 	header := &skiplistNode{
 		forward: make([]*skiplistNode, maxLevel),
+		span:    make([]int, maxLevel),
 		level:   maxLevel,
 	}
 	return &skiplist{
@@ -45,12 +49,17 @@ func randomLevel() int {
 
 func (sl *skiplist) insert(score float64, member string) bool {
 	update := make([]*skiplistNode, maxLevel)
+	rank := make([]int, maxLevel) // Rank of update[i], with the head at 0
 	current := sl.head
 
 	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
 		for current.forward[i] != nil &&
 			(current.forward[i].score < score ||
 				(current.forward[i].score == score && current.forward[i].member < member)) {
+			rank[i] += current.span[i]
 			current = current.forward[i]
 		}
 		update[i] = current
@@ -74,6 +83,7 @@ func (sl *skiplist) insert(score float64, member string) bool {
 	if level > sl.level {
 		for i := sl.level; i < level; i++ {
 			update[i] = sl.head
+			sl.head.span[i] = sl.length
 		}
 		sl.level = level
 	}
@@ -82,12 +92,20 @@ func (sl *skiplist) insert(score float64, member string) bool {
 		member:  member,
 		score:   score,
 		forward: make([]*skiplistNode, level),
+		span:    make([]int, level),
 		level:   level,
 	}
 
+	// The new node splits the span of each pointer it is linked in under,
+	// and lengthens the ones that pass over it.
 	for i := 0; i < level; i++ {
 		newNode.forward[i] = update[i].forward[i]
 		update[i].forward[i] = newNode
+		newNode.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < sl.level; i++ {
+		update[i].span[i]++
 	}
 
 	if update[0] == sl.head {
@@ -126,10 +144,12 @@ func (sl *skiplist) delete(score float64, member string) bool {
 	}
 
 	for i := 0; i < sl.level; i++ {
-		if update[i].forward[i] != current {
-			break
+		if update[i].forward[i] == current {
+			update[i].span[i] += current.span[i] - 1
+			update[i].forward[i] = current.forward[i]
+		} else {
+			update[i].span[i]--
 		}
-		update[i].forward[i] = current.forward[i]
 	}
 
 	if current.forward[0] != nil {
@@ -146,6 +166,58 @@ func (sl *skiplist) delete(score float64, member string) bool {
 	return true
 }
 
+// getRank returns the 1-based rank of the node with score and member, or 0
+// if there is none.
+func (sl *skiplist) getRank(score float64, member string) int {
+	rank := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil &&
+			(current.forward[i].score < score ||
+				(current.forward[i].score == score && current.forward[i].member <= member)) {
+			rank += current.span[i]
+			current = current.forward[i]
+		}
+		if current != sl.head && current.member == member {
+			return rank
+		}
+	}
+	return 0
+}
+
+// getByRank returns the node with the 1-based rank, or nil if it is out of
+// range.
+func (sl *skiplist) getByRank(rank int) *skiplistNode {
+	if rank < 1 || rank > sl.length {
+		return nil
+	}
+	traversed := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && traversed+current.span[i] <= rank {
+			traversed += current.span[i]
+			current = current.forward[i]
+		}
+		if traversed == rank {
+			return current
+		}
+	}
+	return nil
+}
+
+// seek returns the node offset places after node, or before it if rev is
+// set, or nil if there is none.
+func (sl *skiplist) seek(node *skiplistNode, offset int, rev bool) *skiplistNode {
+	if node == nil || offset == 0 {
+		return node
+	}
+	rank := sl.getRank(node.score, node.member)
+	if rev {
+		return sl.getByRank(rank - offset)
+	}
+	return sl.getByRank(rank + offset)
+}
+
 // getRange returns a slice of skiplistNodes from start to stop (inclusive).
 // If the range exceeds the number of elements in the skiplist, it returns
 // as many elements as are available from the start index onward.
@@ -169,11 +241,7 @@ func (sl *skiplist) getRange(start, stop int) []*skiplistNode {
 		return result
 	}
 
-	current := sl.head.forward[0]
-	for i := 0; i < start && current != nil; i++ {
-		current = current.forward[0]
-	}
-
+	current := sl.getByRank(start + 1)
 	for i := start; i <= stop && current != nil; i++ {
 		result = append(result, current)
 		current = current.forward[0]
@@ -181,3 +249,82 @@ func (sl *skiplist) getRange(start, stop int) []*skiplistNode {
 
 	return result
 }
+
+// scanScores calls fn for each node with min <= score < max in order,
+// stopping early if fn returns false.
+func (sl *skiplist) scanScores(min, max float64, fn func(*skiplistNode) bool) {
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && current.forward[i].score < min {
+			current = current.forward[i]
+		}
+	}
+	for current = current.forward[0]; current != nil && current.score < max; current = current.forward[0] {
+		if !fn(current) {
+			return
+		}
+	}
+}
+
+// firstInScoreRange returns the first node with a score between min and
+// max, or nil if there is none.
+func (sl *skiplist) firstInScoreRange(min, max options.ScoreBound) *skiplistNode {
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && !min.AboveMin(current.forward[i].score) {
+			current = current.forward[i]
+		}
+	}
+	current = current.forward[0]
+	if current == nil || !max.BelowMax(current.score) {
+		return nil
+	}
+	return current
+}
+
+// lastInScoreRange returns the last node with a score between min and max,
+// or nil if there is none.
+func (sl *skiplist) lastInScoreRange(min, max options.ScoreBound) *skiplistNode {
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && max.BelowMax(current.forward[i].score) {
+			current = current.forward[i]
+		}
+	}
+	if current == sl.head || !min.AboveMin(current.score) {
+		return nil
+	}
+	return current
+}
+
+// firstInLexRange returns the first node with a member between min and max,
+// or nil if there is none. Like in Redis, lex ranges assume that all the
+// members have the same score.
+func (sl *skiplist) firstInLexRange(min, max options.LexBound) *skiplistNode {
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && !min.AboveMin(current.forward[i].member) {
+			current = current.forward[i]
+		}
+	}
+	current = current.forward[0]
+	if current == nil || !max.BelowMax(current.member) {
+		return nil
+	}
+	return current
+}
+
+// lastInLexRange returns the last node with a member between min and max,
+// or nil if there is none.
+func (sl *skiplist) lastInLexRange(min, max options.LexBound) *skiplistNode {
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.forward[i] != nil && max.BelowMax(current.forward[i].member) {
+			current = current.forward[i]
+		}
+	}
+	if current == sl.head || !min.AboveMin(current.member) {
+		return nil
+	}
+	return current
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// checkSpans verifies that every span of sl is the number of level 0 steps
+// its pointer skips, and that ranks and nodes agree both ways.
+func checkSpans(t *testing.T, sl *skiplist) {
+	t.Helper()
+	ranks := map[*skiplistNode]int{sl.head: 0}
+	rank := 0
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		rank++
+		ranks[node] = rank
+	}
+	if rank != sl.length {
+		t.Fatalf("skiplist has %d nodes, length %d", rank, sl.length)
+	}
+
+	for node := sl.head; node != nil; node = node.forward[0] {
+		levels := node.level
+		if node == sl.head {
+			levels = sl.level
+		}
+		for i := 0; i < levels; i++ {
+			if next := node.forward[i]; next != nil && node.span[i] != ranks[next]-ranks[node] {
+				t.Fatalf("span[%d] of rank %d = %d, want %d", i, ranks[node], node.span[i], ranks[next]-ranks[node])
+			}
+		}
+		if node == sl.head {
+			continue
+		}
+		if got := sl.getRank(node.score, node.member); got != ranks[node] {
+			t.Fatalf("getRank(%v, %s) = %d, want %d", node.score, node.member, got, ranks[node])
+		}
+		if got := sl.getByRank(ranks[node]); got != node {
+			t.Fatalf("getByRank(%d) = %v, want %s", ranks[node], got, node.member)
+		}
+	}
+	if sl.getByRank(0) != nil || sl.getByRank(sl.length+1) != nil {
+		t.Fatalf("getByRank() out of range returned a node")
+	}
+}
+
+func TestSkiplist_Spans(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	set := newSortedSet()
+	for i := 0; i < 2000; i++ {
+		member := strconv.Itoa(rng.Intn(500))
+		if rng.Intn(4) == 0 {
+			set.Remove(member)
+		} else {
+			set.Add(member, float64(rng.Intn(100)))
+		}
+	}
+	if set.sl.length != len(set.dict) {
+		t.Fatalf("skiplist length = %d, want %d", set.sl.length, len(set.dict))
+	}
+	checkSpans(t, set.sl)
+	if set.sl.getRank(1000, "missing") != 0 {
+		t.Errorf("getRank() of a missing member is not 0")
+	}
+}
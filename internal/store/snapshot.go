@@ -0,0 +1,209 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// ErrBusyKey is returned by RestoreEntry when the key already exists.
+var ErrBusyKey = &types.Error{Code: "BUSYKEY", Message: "Target key name already exists."}
+
+// Entry is a point-in-time copy of a single key as captured by Snapshot.
+// Value holds a string for string keys, a []string for lists, a
+// map[string]struct{} for sets, a []types.FieldValue for hashes, a
+// []types.ScoreMember for sorted sets and a types.StreamData for streams.
+type Entry struct {
+	Key    string
+	Value  interface{}
+	Expiry time.Time // Zero when the key has no expiry
+}
+
+// Snapshot returns a copy of every live key in the store. The copy shares no
+// mutable state with the store, so it can be serialized after the lock has
+// been released.
+func (s *MemoryStore) Snapshot() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(s.data))
+	for key, val := range s.data {
+		if s.isExpired(key) {
+			continue
+		}
+
+		if entry, ok := copyEntry(key, val, s.expires[key]); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// copyEntry copies the value stored at key into an Entry.
+func copyEntry(key string, val interface{}, expiry time.Time) (Entry, bool) {
+	entry := Entry{Key: key, Expiry: expiry}
+	switch v := val.(type) {
+	case []byte:
+		entry.Value = string(v)
+	case *intString:
+		entry.Value = v.String()
+	case *List:
+		entry.Value = v.Items()
+	case *Set:
+		members := make(map[string]struct{}, v.Len())
+		for _, member := range v.Members() {
+			members[member] = struct{}{}
+		}
+		entry.Value = members
+	case *Hash:
+		now := time.Now()
+		pairs := v.Pairs()
+		live := pairs[:0]
+		for _, p := range pairs {
+			if p.Expiry.IsZero() || !now.After(p.Expiry) {
+				live = append(live, p)
+			}
+		}
+		if len(live) == 0 {
+			return Entry{}, false
+		}
+		entry.Value = live
+	case *SortedSet:
+		members := make([]types.ScoreMember, 0, len(v.dict))
+		for member, score := range v.dict {
+			members = append(members, types.ScoreMember{Score: score, Member: member})
+		}
+		entry.Value = members
+	case *Stream:
+		entry.Value = v.data()
+	default:
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// entryValue builds the stored representation of entry's value.
+func entryValue(entry Entry) (interface{}, error) {
+	switch v := entry.Value.(type) {
+	case string:
+		return stringValue([]byte(v)), nil
+	case []string:
+		list := newList()
+		for _, item := range v {
+			list.PushBack(item)
+		}
+		return list, nil
+	case map[string]struct{}:
+		set := newSet()
+		for member := range v {
+			set.Add(member)
+		}
+		return set, nil
+	case []types.FieldValue:
+		hash := newHash()
+		for _, p := range v {
+			hash.Set(p.Field, p.Value)
+			if !p.Expiry.IsZero() {
+				hash.SetExpiry(p.Field, p.Expiry)
+			}
+		}
+		return hash, nil
+	case []types.ScoreMember:
+		set := newSortedSet()
+		for _, m := range v {
+			set.Add(m.Member, m.Score)
+		}
+		return set, nil
+	case types.StreamData:
+		return streamFromData(v), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T for key %s", entry.Value, entry.Key)
+	}
+}
+
+// Exists reports whether key holds a live value of any type.
+func (s *MemoryStore) Exists(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.data[key]
+	return ok && !s.isExpired(key)
+}
+
+// Dump returns a copy of key as captured by Snapshot, or false if the key
+// does not exist.
+func (s *MemoryStore) Dump(key string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	val, ok := s.data[key]
+	if !ok || s.isExpired(key) {
+		return Entry{}, false
+	}
+	return copyEntry(key, val, s.expires[key])
+}
+
+// RestoreEntry creates entry.Key from entry. Unless replace is set, it fails
+// if the key already exists.
+func (s *MemoryStore) RestoreEntry(entry Entry, replace bool) error {
+	val, err := entryValue(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data[entry.Key]; exists && !replace && !s.isExpired(entry.Key) {
+		return ErrBusyKey
+	}
+
+	s.data[entry.Key] = val
+	if hash, ok := val.(*Hash); ok && hash.volatile() {
+		s.volatileHashes[entry.Key] = struct{}{}
+	}
+	if entry.Expiry.IsZero() {
+		delete(s.expires, entry.Key)
+	} else {
+		s.expires[entry.Key] = entry.Expiry
+	}
+	return nil
+}
+
+// Restore replaces the contents of the store with entries. Entries whose
+// expiry has already passed are skipped.
+func (s *MemoryStore) Restore(entries []Entry) error {
+	data := make(map[string]interface{}, len(entries))
+	expires := make(map[string]time.Time)
+	volatileHashes := make(map[string]struct{})
+	now := time.Now()
+
+	for _, entry := range entries {
+		if !entry.Expiry.IsZero() && now.After(entry.Expiry) {
+			continue
+		}
+
+		val, err := entryValue(entry)
+		if err != nil {
+			return err
+		}
+		data[entry.Key] = val
+		if hash, ok := val.(*Hash); ok && hash.volatile() {
+			volatileHashes[entry.Key] = struct{}{}
+		}
+
+		if !entry.Expiry.IsZero() {
+			expires[entry.Key] = entry.Expiry
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = data
+	s.expires = expires
+	s.volatileHashes = volatileHashes
+	return nil
+}
@@ -12,9 +12,119 @@ type Store interface {
 	Get(key string) (interface{}, error)
 	Set(key string, value interface{}, opts *options.SetOptions) (interface{}, error)
 	Del(key string) error
+	IncrBy(key string, delta int64) (int64, error)
+	IncrByFloat(key string, delta float64) (string, error)
+	MGet(keys []string) ([]interface{}, error)
+	MSet(pairs []types.KeyValue, nx bool) (bool, error)
+	Append(key string, value []byte) (int, error)
+	StrLen(key string) (int, error)
+	GetRange(key string, start, end int64) (string, error)
+	SetRange(key string, offset int64, value []byte) (int, error)
+	GetDel(key string) (interface{}, error)
+	GetEx(key string, opts *options.SetOptions) (interface{}, error)
+	LCS(key1, key2 string) (string, []types.LCSMatch, error)
 	Expire(key string, ttl time.Duration, opts *options.ExpireOptions) error
 	TTL(key string) (int, error)
 	Keys(pattern string) ([]string, error)
 	ZAdd(key string, members []types.ScoreMember, opts *options.ZAddOptions) (interface{}, error)
 	ZRange(key string, start, stop interface{}, opts *options.ZRangeOptions) ([]interface{}, error)
+	ZRank(key, member string, rev bool) (int, float64, bool, error)
+	ZCount(key string, min, max options.ScoreBound) (int, error)
+	ZLexCount(key string, min, max options.LexBound) (int, error)
+	ZRemRangeByLex(key string, min, max options.LexBound) (int, error)
+	LPush(key string, values []string, onlyIfExists bool) (int, error)
+	RPush(key string, values []string, onlyIfExists bool) (int, error)
+	LPop(key string, count int) ([]string, error)
+	RPop(key string, count int) ([]string, error)
+	LLen(key string) (int, error)
+	LRange(key string, start, stop int) ([]string, error)
+	LIndex(key string, index int) (interface{}, error)
+	LSet(key string, index int, value string) error
+	LInsert(key string, before bool, pivot, value string) (int, error)
+	LRem(key string, count int, value string) (int, error)
+	LTrim(key string, start, stop int) error
+	LPos(key, element string, rank, count, maxLen int) ([]int, error)
+	LMove(source, destination string, fromLeft, toLeft bool) (interface{}, error)
+	SAdd(key string, members []string) (int, error)
+	SRem(key string, members []string) (int, error)
+	SIsMember(key, member string) (bool, error)
+	SMIsMember(key string, members []string) ([]bool, error)
+	SMembers(key string) ([]string, error)
+	SCard(key string) (int, error)
+	SPop(key string, count int) ([]string, error)
+	SRandMember(key string, count int) ([]string, error)
+	SMove(source, destination, member string) (bool, error)
+	SInter(keys []string) ([]string, error)
+	SUnion(keys []string) ([]string, error)
+	SDiff(keys []string) ([]string, error)
+	SInterStore(destination string, keys []string) (int, error)
+	SUnionStore(destination string, keys []string) (int, error)
+	SDiffStore(destination string, keys []string) (int, error)
+	SInterCard(keys []string, limit int) (int, error)
+	SScan(key string, cursor uint64, pattern string, count int) (uint64, []string, error)
+	HSet(key string, pairs []types.FieldValue) (int, error)
+	HSetNX(key, field, value string) (bool, error)
+	HGet(key, field string) (interface{}, error)
+	HMGet(key string, fields []string) ([]interface{}, error)
+	HDel(key string, fields []string) (int, error)
+	HExists(key, field string) (bool, error)
+	HLen(key string) (int, error)
+	HKeys(key string) ([]string, error)
+	HVals(key string) ([]string, error)
+	HGetAll(key string) ([]types.FieldValue, error)
+	HIncrBy(key, field string, delta int64) (int64, error)
+	HIncrByFloat(key, field string, delta float64) (string, error)
+	HStrLen(key, field string) (int, error)
+	HRandField(key string, count int) ([]types.FieldValue, error)
+	HScan(key string, cursor uint64, pattern string, count int) (uint64, []types.FieldValue, error)
+	HExpire(key string, at time.Time, fields []string, opts *options.ExpireOptions) ([]int, error)
+	HExpireTime(key string, fields []string) ([]int64, error)
+	HPersist(key string, fields []string) ([]int, error)
+	HGetEx(key string, fields []string, opts *options.HGetExOptions) ([]interface{}, error)
+	HSetEx(key string, pairs []types.FieldValue, opts *options.HSetExOptions) (bool, error)
+	XAdd(key, id string, fields []string, opts *options.XAddOptions) (interface{}, error)
+	XRange(key string, start, end types.StreamID, count int, rev bool) ([]types.StreamEntry, error)
+	XLen(key string) (int, error)
+	XDel(key string, ids []types.StreamID) (int, error)
+	XTrim(key string, opts *options.XTrimOptions) (int64, error)
+	XLastID(key string) (types.StreamID, error)
+	XRead(keys []string, after []types.StreamID, count int) ([][]types.StreamEntry, error)
+	XSetID(key string, lastID types.StreamID, entriesAdded int64, maxDeletedID *types.StreamID) error
+	XGroupCreate(key, group, id string, mkStream bool, entriesRead int64) error
+	XGroupSetID(key, group, id string, entriesRead int64) error
+	XGroupDestroy(key, group string) (bool, error)
+	XGroupCreateConsumer(key, group, consumer string) (bool, error)
+	XGroupDelConsumer(key, group, consumer string) (int, error)
+	XReadGroup(group, consumer string, keys []string, after []*types.StreamID, count int, noAck bool, now time.Time) ([][]types.StreamEntry, error)
+	XAck(key, group string, ids []types.StreamID) (int, error)
+	XPending(key, group string, start, end types.StreamID, count int, consumer string, minIdle time.Duration) ([]types.StreamPending, error)
+	XClaim(key, group, consumer string, minIdle time.Duration, ids []types.StreamID, opts *options.XClaimOptions) ([]types.StreamEntry, []types.StreamID, error)
+	XAutoClaim(key, group, consumer string, minIdle time.Duration, start types.StreamID, count int, justID bool, now time.Time) (types.StreamID, []types.StreamEntry, []types.StreamID, error)
+	XInfoStream(key string) (types.StreamInfo, error)
+	XInfoGroups(key string) ([]types.StreamGroupInfo, error)
+	XInfoConsumers(key, group string) ([]types.StreamConsumerInfo, error)
+	GeoAdd(key string, locations []types.GeoLocation, opts *options.GeoAddOptions) (int, error)
+	GeoPos(key string, members []string) ([]*types.GeoLocation, error)
+	GeoDist(key, member1, member2 string) (float64, bool, error)
+	GeoHash(key string, members []string) ([]interface{}, error)
+	GeoSearch(key string, opts *options.GeoSearchOptions) ([]types.GeoResult, error)
+	GeoSearchStore(destination, source string, opts *options.GeoSearchOptions, storeDist bool, unit float64) (int, error)
+	PFAdd(key string, elements []string) (bool, error)
+	PFCount(keys []string) (int64, error)
+	PFMerge(destination string, keys []string) error
+	SetBit(key string, offset int64, value int) (int, error)
+	GetBit(key string, offset int64) (int, error)
+	BitCount(key string, r *options.BitRange) (int64, error)
+	BitPos(key string, bit int, r *options.BitRange) (int64, error)
+	BitOp(op, destination string, keys []string) (int, error)
+	BitField(key string, ops []options.BitFieldOp) ([]interface{}, error)
+	Snapshot() []Entry
+	Restore(entries []Entry) error
+	Exists(key string) bool
+	Dump(key string) (Entry, bool)
+	RestoreEntry(entry Entry, replace bool) error
 }
+
+// ErrWrongType is returned when a command is used against a key holding a
+// value of a different type.
+var ErrWrongType = &types.Error{Code: "WRONGTYPE", Message: "Operation against a key holding the wrong kind of value"}
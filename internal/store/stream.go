@@ -0,0 +1,379 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+var (
+	errStreamIDTooSmall = errors.New("The ID specified in XADD is equal or smaller than the target stream top item")
+	errStreamIDZero     = errors.New("The ID specified in XADD must be greater than 0-0")
+	errStreamExhausted  = errors.New("The stream has exhausted the last possible ID, unable to add more items")
+)
+
+// Stream is an append-only log of entries ordered by ID, together with the
+// consumer groups reading it. Entries are kept in a slice sorted by ID, as
+// new entries always go at the end.
+type Stream struct {
+	entries      []types.StreamEntry
+	lastID       types.StreamID // Greatest ID ever added
+	entriesAdded int64
+	maxDeletedID types.StreamID // Greatest ID removed by XDEL
+	groups       map[string]*consumerGroup
+}
+
+func newStream() *Stream {
+	return &Stream{groups: make(map[string]*consumerGroup)}
+}
+
+// Len returns the number of entries.
+func (st *Stream) Len() int {
+	return len(st.entries)
+}
+
+// search returns the index of the first entry whose ID is at least id.
+func (st *Stream) search(id types.StreamID) int {
+	return sort.Search(len(st.entries), func(i int) bool { return st.entries[i].ID.Compare(id) >= 0 })
+}
+
+// get returns the entry with the given ID.
+func (st *Stream) get(id types.StreamID) (types.StreamEntry, bool) {
+	i := st.search(id)
+	if i < len(st.entries) && st.entries[i].ID == id {
+		return st.entries[i], true
+	}
+	return types.StreamEntry{}, false
+}
+
+// firstID returns the ID of the first entry, or 0-0 if there is none.
+func (st *Stream) firstID() types.StreamID {
+	if len(st.entries) == 0 {
+		return types.StreamID{}
+	}
+	return st.entries[0].ID
+}
+
+// newID resolves the ID argument of XADD: "*" generates an ID from the
+// current time, "ms-*" generates the next sequence number of that
+// millisecond, and an explicit ID must be greater than every ID added so far.
+func (st *Stream) newID(spec string, now time.Time) (types.StreamID, error) {
+	if spec == "*" {
+		if ms := uint64(now.UnixMilli()); ms > st.lastID.Ms {
+			return types.StreamID{Ms: ms}, nil
+		}
+		next, ok := st.lastID.Next()
+		if !ok {
+			return types.StreamID{}, errStreamExhausted
+		}
+		return next, nil
+	}
+
+	if msPart, ok := strings.CutSuffix(spec, "-*"); ok {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		if err != nil {
+			return types.StreamID{}, types.ErrInvalidStreamID
+		}
+		switch {
+		case ms < st.lastID.Ms:
+			return types.StreamID{}, errStreamIDTooSmall
+		case ms == st.lastID.Ms:
+			next, ok := st.lastID.Next()
+			if !ok || next.Ms != ms {
+				return types.StreamID{}, errStreamIDTooSmall
+			}
+			return next, nil
+		default:
+			return types.StreamID{Ms: ms}, nil
+		}
+	}
+
+	id, err := types.ParseStreamID(spec, 0)
+	if err != nil {
+		return types.StreamID{}, err
+	}
+	if id.IsZero() {
+		return types.StreamID{}, errStreamIDZero
+	}
+	if id.Compare(st.lastID) <= 0 {
+		return types.StreamID{}, errStreamIDTooSmall
+	}
+	return id, nil
+}
+
+// add appends an entry whose ID is greater than every ID added so far.
+func (st *Stream) add(id types.StreamID, fields []string) {
+	st.entries = append(st.entries, types.StreamEntry{ID: id, Fields: fields})
+	st.lastID = id
+	st.entriesAdded++
+}
+
+// rangeEntries returns up to count entries with IDs between start and end
+// inclusive, from the last one backwards if rev is set. A count of 0 or less
+// returns every entry in the range. The entries share their fields with the
+// stream, which never modifies them.
+func (st *Stream) rangeEntries(start, end types.StreamID, count int, rev bool) []types.StreamEntry {
+	lo := st.search(start)
+	hi := sort.Search(len(st.entries), func(i int) bool { return st.entries[i].ID.Compare(end) > 0 })
+	if lo >= hi {
+		return []types.StreamEntry{}
+	}
+
+	n := hi - lo
+	if count > 0 && count < n {
+		n = count
+	}
+	result := make([]types.StreamEntry, n)
+	for i := range result {
+		if rev {
+			result[i] = st.entries[hi-1-i]
+		} else {
+			result[i] = st.entries[lo+i]
+		}
+	}
+	return result
+}
+
+// delete removes the entry with the given ID and reports whether it existed.
+func (st *Stream) delete(id types.StreamID) bool {
+	i := st.search(id)
+	if i == len(st.entries) || st.entries[i].ID != id {
+		return false
+	}
+	st.entries = append(st.entries[:i], st.entries[i+1:]...)
+	if id.Compare(st.maxDeletedID) > 0 {
+		st.maxDeletedID = id
+	}
+	return true
+}
+
+// trim removes the oldest entries as opts specify and returns how many were
+// removed.
+func (st *Stream) trim(opts *options.XTrimOptions) int64 {
+	var n int64
+	if opts.Strategy == "MINID" {
+		n = int64(st.search(opts.MinID))
+	} else if excess := int64(len(st.entries)) - opts.MaxLen; excess > 0 {
+		n = excess
+	}
+	if opts.Limit > 0 && n > opts.Limit {
+		n = opts.Limit
+	}
+
+	clear(st.entries[:n])
+	st.entries = st.entries[n:]
+	return n
+}
+
+// getStream returns the stream stored at key, nil if there is none, or
+// ErrWrongType if the key holds another type. It must be called with s.mu
+// held for writing, since it deletes the key if it has expired.
+func (s *MemoryStore) getStream(key string) (*Stream, error) {
+	if s.isExpired(key) {
+		delete(s.data, key)
+		delete(s.expires, key)
+		return nil, nil
+	}
+
+	val, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	stream, ok := val.(*Stream)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return stream, nil
+}
+
+// StreamAdded returns a channel that is closed the next time an entry is
+// added to any stream. Blocked XREAD and XREADGROUP calls wait on it.
+func (s *MemoryStore) StreamAdded() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.streamAdded == nil {
+		s.streamAdded = make(chan struct{})
+	}
+	return s.streamAdded
+}
+
+// XAdd adds an entry with the given fields to the stream at key, creating
+// the stream unless opts.NoMkStream is set, and trims it if opts ask for
+// it. id is "*", "ms-*" or an explicit ID; see Stream.newID. It returns the
+// ID of the new entry, or nil if the stream does not exist and NoMkStream is
+// set.
+func (s *MemoryStore) XAdd(key, id string, fields []string, opts *options.XAddOptions) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		if opts != nil && opts.NoMkStream {
+			return nil, nil
+		}
+		stream = newStream()
+	}
+
+	entryID, err := stream.newID(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.data[key]; !exists {
+		s.data[key] = stream
+	}
+	stream.add(entryID, fields)
+	if opts != nil && opts.Trim != nil {
+		stream.trim(opts.Trim)
+	}
+
+	if s.streamAdded != nil {
+		close(s.streamAdded)
+		s.streamAdded = nil
+	}
+	return entryID.String(), nil
+}
+
+// XRange returns up to count entries of the stream at key with IDs between
+// start and end inclusive, in reverse order if rev is set. A count of 0 or
+// less returns every entry in the range.
+func (s *MemoryStore) XRange(key string, start, end types.StreamID, count int, rev bool) ([]types.StreamEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return []types.StreamEntry{}, err
+	}
+	return stream.rangeEntries(start, end, count, rev), nil
+}
+
+// XLen returns the number of entries in the stream at key.
+func (s *MemoryStore) XLen(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return 0, err
+	}
+	return stream.Len(), nil
+}
+
+// XDel removes the entries with the given IDs from the stream at key and
+// returns how many existed. Unlike the other collections, a stream stays
+// when its last entry is removed.
+func (s *MemoryStore) XDel(key string, ids []types.StreamID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		if stream.delete(id) {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// XTrim trims the stream at key as opts specify and returns how many entries
+// were removed.
+func (s *MemoryStore) XTrim(key string, opts *options.XTrimOptions) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return 0, err
+	}
+	return stream.trim(opts), nil
+}
+
+// XLastID returns the greatest ID ever added to the stream at key, or 0-0 if
+// the stream does not exist. XREAD uses it to resolve "$".
+func (s *MemoryStore) XLastID(key string) (types.StreamID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return types.StreamID{}, err
+	}
+	return stream.lastID, nil
+}
+
+// XRead returns for each of keys up to count entries with IDs greater than
+// the corresponding ID in after, or nil for keys without such entries. A
+// count of 0 or less returns all of them.
+func (s *MemoryStore) XRead(keys []string, after []types.StreamID, count int) ([][]types.StreamEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([][]types.StreamEntry, len(keys))
+	for i, key := range keys {
+		stream, err := s.getStream(key)
+		if err != nil {
+			return nil, err
+		}
+		if stream == nil {
+			continue
+		}
+		start, ok := after[i].Next()
+		if !ok {
+			continue
+		}
+		if entries := stream.rangeEntries(start, types.MaxStreamID, count, false); len(entries) > 0 {
+			results[i] = entries
+		}
+	}
+	return results, nil
+}
+
+// XSetID sets the last ID of the stream at key and, if entriesAdded is not
+// negative or maxDeletedID is not nil, its count of entries ever added and
+// its greatest deleted ID.
+func (s *MemoryStore) XSetID(key string, lastID types.StreamID, entriesAdded int64, maxDeletedID *types.StreamID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return fmt.Errorf("no such key")
+	}
+
+	if entriesAdded >= 0 && entriesAdded < int64(stream.Len()) {
+		return fmt.Errorf("The entries_added specified in XSETID is smaller than the target stream length")
+	}
+	if maxDeletedID != nil && lastID.Compare(*maxDeletedID) < 0 {
+		return fmt.Errorf("The ID specified in XSETID is smaller than the provided max_deleted_entry_id")
+	}
+	if stream.Len() > 0 && lastID.Compare(stream.entries[stream.Len()-1].ID) < 0 {
+		return fmt.Errorf("The ID specified in XSETID is smaller than the target stream top item")
+	}
+
+	stream.lastID = lastID
+	if entriesAdded >= 0 {
+		stream.entriesAdded = entriesAdded
+	}
+	if maxDeletedID != nil {
+		stream.maxDeletedID = *maxDeletedID
+	}
+	return nil
+}
@@ -0,0 +1,689 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+// errNoStreamKey is returned by XGROUP subcommands run against a missing key.
+var errNoStreamKey = fmt.Errorf("The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+
+// consumerGroup tracks how far a group has read a stream and which
+// delivered entries its consumers have not acknowledged yet.
+type consumerGroup struct {
+	name        string
+	lastID      types.StreamID // Last entry delivered to the group
+	entriesRead int64          // -1 if unknown
+	pending     map[types.StreamID]*pendingEntry
+	consumers   map[string]*consumer
+}
+
+type consumer struct {
+	name       string
+	seenTime   time.Time
+	activeTime time.Time // Zero until the consumer reads or claims entries
+	pending    map[types.StreamID]*pendingEntry
+}
+
+type pendingEntry struct {
+	consumer      *consumer
+	deliveryTime  time.Time
+	deliveryCount int64
+}
+
+func newConsumerGroup(name string, lastID types.StreamID, entriesRead int64) *consumerGroup {
+	return &consumerGroup{
+		name:        name,
+		lastID:      lastID,
+		entriesRead: entriesRead,
+		pending:     make(map[types.StreamID]*pendingEntry),
+		consumers:   make(map[string]*consumer),
+	}
+}
+
+// consumer returns the consumer with the given name, creating it if needed,
+// and records that it was seen at now.
+func (g *consumerGroup) consumer(name string, now time.Time) *consumer {
+	c, ok := g.consumers[name]
+	if !ok {
+		c = &consumer{name: name, pending: make(map[types.StreamID]*pendingEntry)}
+		g.consumers[name] = c
+	}
+	c.seenTime = now
+	return c
+}
+
+// pendingIDs returns the IDs of the group's pending entries in order.
+func (g *consumerGroup) pendingIDs() []types.StreamID {
+	ids := make([]types.StreamID, 0, len(g.pending))
+	for id := range g.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Compare(ids[j]) < 0 })
+	return ids
+}
+
+// assign makes id pending for c, taking it from whichever consumer had it.
+func (g *consumerGroup) assign(id types.StreamID, c *consumer) *pendingEntry {
+	pe, ok := g.pending[id]
+	if !ok {
+		pe = &pendingEntry{}
+		g.pending[id] = pe
+	} else {
+		delete(pe.consumer.pending, id)
+	}
+	pe.consumer = c
+	c.pending[id] = pe
+	return pe
+}
+
+// ack removes id from the pending entries and reports whether it was there.
+func (g *consumerGroup) ack(id types.StreamID) bool {
+	pe, ok := g.pending[id]
+	if !ok {
+		return false
+	}
+	delete(pe.consumer.pending, id)
+	delete(g.pending, id)
+	return true
+}
+
+// deliver records that entry id was delivered to the group, advancing its
+// last ID and its count of entries read.
+func (g *consumerGroup) deliver(st *Stream, id types.StreamID) {
+	// The count can only be incremented if no entry after the group's
+	// position was deleted; otherwise it is estimated again.
+	if g.entriesRead >= 0 && st.maxDeletedID.Compare(g.lastID) <= 0 {
+		g.entriesRead++
+	} else {
+		g.entriesRead = st.entriesReadAt(id)
+	}
+	g.lastID = id
+}
+
+// lag returns how many entries were added to the stream but not delivered to
+// the group yet, or -1 if that cannot be known because of deleted entries.
+func (g *consumerGroup) lag(st *Stream) int64 {
+	if st.entriesAdded == 0 {
+		return 0
+	}
+	if g.entriesRead >= 0 && (st.maxDeletedID.IsZero() || st.maxDeletedID.Compare(g.lastID) < 0) {
+		return st.entriesAdded - g.entriesRead
+	}
+	if read := st.entriesReadAt(g.lastID); read >= 0 {
+		return st.entriesAdded - read
+	}
+	return -1
+}
+
+// entriesReadAt estimates how many entries had been added up to and
+// including id, or returns -1 if deleted entries make that impossible.
+func (st *Stream) entriesReadAt(id types.StreamID) int64 {
+	if st.entriesAdded == 0 {
+		return 0
+	}
+	cmpLast := id.Compare(st.lastID)
+	if cmpLast == 0 || (st.Len() == 0 && cmpLast < 0) {
+		return st.entriesAdded
+	}
+	if cmpLast > 0 {
+		return -1
+	}
+
+	// Without deleted entries inside the stream, the entries that are gone
+	// are exactly those before the first one.
+	first := st.firstID()
+	if st.maxDeletedID.IsZero() || st.maxDeletedID.Compare(first) < 0 {
+		switch id.Compare(first) {
+		case -1:
+			return st.entriesAdded - int64(st.Len())
+		case 0:
+			return st.entriesAdded - int64(st.Len()) + 1
+		}
+	}
+	return -1
+}
+
+// resolveGroupID resolves the ID argument of XGROUP CREATE and SETID, where
+// "$" stands for the last ID of the stream.
+func (st *Stream) resolveGroupID(id string) (types.StreamID, error) {
+	if id == "$" {
+		return st.lastID, nil
+	}
+	return types.ParseStreamID(id, 0)
+}
+
+func errNoGroup(key, group string) error {
+	return &types.Error{Code: "NOGROUP", Message: fmt.Sprintf("No such key '%s' or consumer group '%s'", key, group)}
+}
+
+// getGroup returns the stream at key and its consumer group, or a NOGROUP
+// error if either does not exist.
+func (s *MemoryStore) getGroup(key, group string) (*Stream, *consumerGroup, error) {
+	stream, err := s.getStream(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stream == nil {
+		return nil, nil, errNoGroup(key, group)
+	}
+	g, ok := stream.groups[group]
+	if !ok {
+		return nil, nil, errNoGroup(key, group)
+	}
+	return stream, g, nil
+}
+
+// getGroupForXGroup is getGroup with the errors of the XGROUP subcommands.
+func (s *MemoryStore) getGroupForXGroup(key, group string) (*Stream, *consumerGroup, error) {
+	stream, err := s.getStream(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stream == nil {
+		return nil, nil, errNoStreamKey
+	}
+	g, ok := stream.groups[group]
+	if !ok {
+		return nil, nil, &types.Error{Code: "NOGROUP", Message: fmt.Sprintf("No such consumer group '%s' for key name '%s'", group, key)}
+	}
+	return stream, g, nil
+}
+
+// XGroupCreate creates a consumer group that starts reading after id, which
+// may be "$" for the last ID of the stream. With mkStream a missing stream
+// is created empty. entriesRead is the number of entries the group counts
+// as read, or -1 if unknown.
+func (s *MemoryStore) XGroupCreate(key, group, id string, mkStream bool, entriesRead int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		if !mkStream {
+			return errNoStreamKey
+		}
+		stream = newStream()
+		s.data[key] = stream
+	}
+	if _, exists := stream.groups[group]; exists {
+		return &types.Error{Code: "BUSYGROUP", Message: "Consumer Group name already exists"}
+	}
+
+	lastID, err := stream.resolveGroupID(id)
+	if err != nil {
+		return err
+	}
+	stream.groups[group] = newConsumerGroup(group, lastID, entriesRead)
+	return nil
+}
+
+// XGroupSetID moves the position of a consumer group to id, which may be
+// "$", and sets its count of entries read.
+func (s *MemoryStore) XGroupSetID(key, group, id string, entriesRead int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, g, err := s.getGroupForXGroup(key, group)
+	if err != nil {
+		return err
+	}
+	lastID, err := stream.resolveGroupID(id)
+	if err != nil {
+		return err
+	}
+	g.lastID = lastID
+	g.entriesRead = entriesRead
+	return nil
+}
+
+// XGroupDestroy removes a consumer group and reports whether it existed.
+func (s *MemoryStore) XGroupDestroy(key, group string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return false, err
+	}
+	if stream == nil {
+		return false, errNoStreamKey
+	}
+	if _, exists := stream.groups[group]; !exists {
+		return false, nil
+	}
+	delete(stream.groups, group)
+	return true, nil
+}
+
+// XGroupCreateConsumer adds a consumer to a group and reports whether it is
+// new.
+func (s *MemoryStore) XGroupCreateConsumer(key, group, consumer string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, g, err := s.getGroupForXGroup(key, group)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := g.consumers[consumer]; exists {
+		return false, nil
+	}
+	g.consumer(consumer, time.Now())
+	return true, nil
+}
+
+// XGroupDelConsumer removes a consumer and its pending entries from a group
+// and returns how many entries it had pending.
+func (s *MemoryStore) XGroupDelConsumer(key, group, consumer string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, g, err := s.getGroupForXGroup(key, group)
+	if err != nil {
+		return 0, err
+	}
+	c, ok := g.consumers[consumer]
+	if !ok {
+		return 0, nil
+	}
+	pending := len(c.pending)
+	for id := range c.pending {
+		delete(g.pending, id)
+	}
+	delete(g.consumers, consumer)
+	return pending, nil
+}
+
+// XReadGroup reads the streams at keys on behalf of a consumer of a group.
+// A nil ID in after asks for entries never delivered to the group, which
+// become pending for the consumer unless noAck is set; a non-nil ID asks for
+// the consumer's own pending entries with greater IDs. Entries returned are
+// recorded as delivered at now, and pending ones read again have their
+// delivery count incremented. It returns for each key the entries read,
+// nil when there were no new ones. Pending entries that were deleted from
+// the stream are returned with nil fields.
+func (s *MemoryStore) XReadGroup(group, consumerName string, keys []string, after []*types.StreamID, count int, noAck bool, now time.Time) ([][]types.StreamEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	streams := make([]*Stream, len(keys))
+	groups := make([]*consumerGroup, len(keys))
+	for i, key := range keys {
+		stream, err := s.getStream(key)
+		if err != nil {
+			return nil, err
+		}
+		var g *consumerGroup
+		if stream != nil {
+			g = stream.groups[group]
+		}
+		if g == nil {
+			return nil, &types.Error{Code: "NOGROUP", Message: fmt.Sprintf("No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", key, group)}
+		}
+		streams[i], groups[i] = stream, g
+	}
+
+	results := make([][]types.StreamEntry, len(keys))
+	for i, stream := range streams {
+		g := groups[i]
+		c := g.consumer(consumerName, now)
+
+		if after[i] != nil {
+			results[i] = readPending(stream, c, *after[i], count, now)
+			continue
+		}
+
+		start, ok := g.lastID.Next()
+		if !ok {
+			continue
+		}
+		entries := stream.rangeEntries(start, types.MaxStreamID, count, false)
+		if len(entries) == 0 {
+			continue
+		}
+		c.activeTime = now
+		for _, entry := range entries {
+			g.deliver(stream, entry.ID)
+			if !noAck {
+				pe := g.assign(entry.ID, c)
+				pe.deliveryTime = now
+				pe.deliveryCount = 1
+			}
+		}
+		results[i] = entries
+	}
+	return results, nil
+}
+
+// readPending returns up to count of c's pending entries with IDs greater
+// than after, with nil fields for entries deleted from the stream. The
+// others are redelivered at now, which increments their delivery count.
+func readPending(stream *Stream, c *consumer, after types.StreamID, count int, now time.Time) []types.StreamEntry {
+	ids := make([]types.StreamID, 0, len(c.pending))
+	for id := range c.pending {
+		if id.Compare(after) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Compare(ids[j]) < 0 })
+	if count > 0 && count < len(ids) {
+		ids = ids[:count]
+	}
+
+	entries := make([]types.StreamEntry, len(ids))
+	for i, id := range ids {
+		entry, ok := stream.get(id)
+		if ok {
+			pe := c.pending[id]
+			pe.deliveryTime = now
+			pe.deliveryCount++
+		} else {
+			entry = types.StreamEntry{ID: id}
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// XAck acknowledges entries of a consumer group, removing them from its
+// pending entries, and returns how many were pending.
+func (s *MemoryStore) XAck(key, group string, ids []types.StreamID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil || stream == nil {
+		return 0, err
+	}
+	g, ok := stream.groups[group]
+	if !ok {
+		return 0, nil
+	}
+
+	acked := 0
+	for _, id := range ids {
+		if g.ack(id) {
+			acked++
+		}
+	}
+	return acked, nil
+}
+
+// XPending returns up to count pending entries of a group with IDs between
+// start and end inclusive, in ID order. It only returns the entries of
+// consumerName if it is not empty, and only those delivered at least minIdle
+// ago. A negative count returns all of them.
+func (s *MemoryStore) XPending(key, group string, start, end types.StreamID, count int, consumerName string, minIdle time.Duration) ([]types.StreamPending, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, g, err := s.getGroup(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []types.StreamPending{}
+	for _, id := range g.pendingIDs() {
+		if count >= 0 && len(result) == count {
+			break
+		}
+		pe := g.pending[id]
+		if id.Compare(start) < 0 || id.Compare(end) > 0 ||
+			(consumerName != "" && pe.consumer.name != consumerName) ||
+			now.Sub(pe.deliveryTime) < minIdle {
+			continue
+		}
+		result = append(result, types.StreamPending{
+			ID:            id,
+			Consumer:      pe.consumer.name,
+			DeliveryTime:  pe.deliveryTime,
+			DeliveryCount: pe.deliveryCount,
+		})
+	}
+	return result, nil
+}
+
+// XClaim gives consumerName the pending entries with the given IDs that were
+// delivered at least minIdle ago, recording opts.Time as their delivery
+// time. Unless opts.JustID is set, their delivery count is incremented or,
+// if opts.RetryCount is not negative, set to it. With opts.Force, entries of
+// the stream that are not pending are claimed as well. It returns the
+// claimed entries and the IDs of pending entries it dropped because they
+// were deleted from the stream.
+func (s *MemoryStore) XClaim(key, group, consumerName string, minIdle time.Duration, ids []types.StreamID, opts *options.XClaimOptions) ([]types.StreamEntry, []types.StreamID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, g, err := s.getGroup(key, group)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.LastID != nil && opts.LastID.Compare(g.lastID) > 0 {
+		g.lastID = *opts.LastID
+	}
+
+	now := time.Now()
+	c := g.consumer(consumerName, now)
+	claimed := []types.StreamEntry{}
+	var deleted []types.StreamID
+	for _, id := range ids {
+		entry, exists := stream.get(id)
+		pe, pending := g.pending[id]
+		if !pending && !(opts.Force && exists) {
+			continue
+		}
+		if !exists {
+			g.ack(id)
+			deleted = append(deleted, id)
+			continue
+		}
+		if pending && minIdle > 0 && now.Sub(pe.deliveryTime) < minIdle {
+			continue
+		}
+
+		pe = g.assign(id, c)
+		pe.deliveryTime = opts.Time
+		if opts.RetryCount >= 0 {
+			pe.deliveryCount = opts.RetryCount
+		} else if !opts.JustID {
+			pe.deliveryCount++
+		}
+		c.activeTime = now
+		claimed = append(claimed, entry)
+	}
+	return claimed, deleted, nil
+}
+
+// XAutoClaim scans the pending entries of a group from start and claims for
+// consumerName up to count of those delivered at least minIdle ago, as
+// XClaim does with now as the delivery time. It examines at most ten times
+// count entries and returns the ID to continue the scan from, 0-0 once it
+// is complete, along with the claimed entries and the IDs of pending entries
+// dropped because they were deleted from the stream.
+func (s *MemoryStore) XAutoClaim(key, group, consumerName string, minIdle time.Duration, start types.StreamID, count int, justID bool, now time.Time) (types.StreamID, []types.StreamEntry, []types.StreamID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, g, err := s.getGroup(key, group)
+	if err != nil {
+		return types.StreamID{}, nil, nil, err
+	}
+
+	c := g.consumer(consumerName, time.Now())
+	claimed := []types.StreamEntry{}
+	deleted := []types.StreamID{}
+	ids := g.pendingIDs()
+	i := sort.Search(len(ids), func(i int) bool { return ids[i].Compare(start) >= 0 })
+	for attempts := count * 10; i < len(ids) && attempts > 0 && len(claimed) < count; i++ {
+		attempts--
+		id := ids[i]
+		entry, exists := stream.get(id)
+		if !exists {
+			g.ack(id)
+			deleted = append(deleted, id)
+			continue
+		}
+		if minIdle > 0 && now.Sub(g.pending[id].deliveryTime) < minIdle {
+			continue
+		}
+
+		pe := g.assign(id, c)
+		pe.deliveryTime = now
+		if !justID {
+			pe.deliveryCount++
+		}
+		c.activeTime = now
+		claimed = append(claimed, entry)
+	}
+
+	var next types.StreamID
+	if i < len(ids) {
+		next = ids[i]
+	}
+	return next, claimed, deleted, nil
+}
+
+// XInfoStream describes the stream at key.
+func (s *MemoryStore) XInfoStream(key string) (types.StreamInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return types.StreamInfo{}, err
+	}
+	if stream == nil {
+		return types.StreamInfo{}, fmt.Errorf("no such key")
+	}
+
+	info := types.StreamInfo{
+		Length:       stream.Len(),
+		LastID:       stream.lastID,
+		MaxDeletedID: stream.maxDeletedID,
+		EntriesAdded: stream.entriesAdded,
+		FirstID:      stream.firstID(),
+		Groups:       len(stream.groups),
+	}
+	if n := stream.Len(); n > 0 {
+		first, last := stream.entries[0], stream.entries[n-1]
+		info.First, info.Last = &first, &last
+	}
+	return info, nil
+}
+
+// XInfoGroups describes the consumer groups of the stream at key, ordered
+// by name.
+func (s *MemoryStore) XInfoGroups(key string) ([]types.StreamGroupInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.getStream(key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, fmt.Errorf("no such key")
+	}
+
+	infos := make([]types.StreamGroupInfo, 0, len(stream.groups))
+	for _, g := range stream.groups {
+		infos = append(infos, types.StreamGroupInfo{
+			Name:        g.name,
+			Consumers:   len(g.consumers),
+			Pending:     len(g.pending),
+			LastID:      g.lastID,
+			EntriesRead: g.entriesRead,
+			Lag:         g.lag(stream),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// XInfoConsumers describes the consumers of a group, ordered by name.
+func (s *MemoryStore) XInfoConsumers(key, group string) ([]types.StreamConsumerInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, g, err := s.getGroup(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]types.StreamConsumerInfo, 0, len(g.consumers))
+	for _, c := range g.consumers {
+		infos = append(infos, types.StreamConsumerInfo{
+			Name:       c.name,
+			Pending:    len(c.pending),
+			SeenTime:   c.seenTime,
+			ActiveTime: c.activeTime,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// data returns a copy of the stream and its consumer groups. The entries
+// share their fields with the stream, which never modifies them.
+func (st *Stream) data() types.StreamData {
+	data := types.StreamData{
+		Entries:      append([]types.StreamEntry(nil), st.entries...),
+		LastID:       st.lastID,
+		EntriesAdded: st.entriesAdded,
+		MaxDeletedID: st.maxDeletedID,
+	}
+	for _, g := range st.groups {
+		group := types.StreamGroup{Name: g.name, LastID: g.lastID, EntriesRead: g.entriesRead}
+		for _, c := range g.consumers {
+			group.Consumers = append(group.Consumers, types.StreamConsumer{Name: c.name, SeenTime: c.seenTime, ActiveTime: c.activeTime})
+		}
+		sort.Slice(group.Consumers, func(i, j int) bool { return group.Consumers[i].Name < group.Consumers[j].Name })
+		for _, id := range g.pendingIDs() {
+			pe := g.pending[id]
+			group.Pending = append(group.Pending, types.StreamPending{
+				ID:            id,
+				Consumer:      pe.consumer.name,
+				DeliveryTime:  pe.deliveryTime,
+				DeliveryCount: pe.deliveryCount,
+			})
+		}
+		data.Groups = append(data.Groups, group)
+	}
+	sort.Slice(data.Groups, func(i, j int) bool { return data.Groups[i].Name < data.Groups[j].Name })
+	return data
+}
+
+// streamFromData rebuilds a stream from a copy made by data.
+func streamFromData(data types.StreamData) *Stream {
+	st := newStream()
+	st.entries = append([]types.StreamEntry(nil), data.Entries...)
+	st.lastID = data.LastID
+	st.entriesAdded = data.EntriesAdded
+	st.maxDeletedID = data.MaxDeletedID
+	for _, group := range data.Groups {
+		g := newConsumerGroup(group.Name, group.LastID, group.EntriesRead)
+		for _, c := range group.Consumers {
+			g.consumer(c.Name, c.SeenTime).activeTime = c.ActiveTime
+		}
+		for _, p := range group.Pending {
+			c, ok := g.consumers[p.Consumer]
+			if !ok {
+				c = g.consumer(p.Consumer, p.DeliveryTime)
+			}
+			pe := g.assign(p.ID, c)
+			pe.deliveryTime = p.DeliveryTime
+			pe.deliveryCount = p.DeliveryCount
+		}
+		st.groups[group.Name] = g
+	}
+	return st
+}
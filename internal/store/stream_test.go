@@ -0,0 +1,290 @@
+package store
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func streamID(ms, seq uint64) types.StreamID {
+	return types.StreamID{Ms: ms, Seq: seq}
+}
+
+func entryIDs(entries []types.StreamEntry) []types.StreamID {
+	ids := make([]types.StreamID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func TestStream_NewID(t *testing.T) {
+	st := newStream()
+	now := time.UnixMilli(1000)
+
+	tests := []struct {
+		spec    string
+		want    types.StreamID
+		wantErr bool
+	}{
+		{spec: "*", want: streamID(1000, 0)},
+		{spec: "*", want: streamID(1000, 1)},
+		{spec: "1000-*", want: streamID(1000, 2)},
+		{spec: "999-*", wantErr: true},
+		{spec: "1000-2", wantErr: true},
+		{spec: "2000", want: streamID(2000, 0)},
+		{spec: "*", want: streamID(2000, 1)}, // The clock is behind the last ID
+		{spec: "3000-*", want: streamID(3000, 0)},
+		{spec: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		id, err := st.newID(tt.spec, now)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("newID(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if id != tt.want {
+			t.Fatalf("newID(%q) = %v, want %v", tt.spec, id, tt.want)
+		}
+		st.add(id, []string{"f", "v"})
+	}
+
+	if _, err := newStream().newID("0-0", now); err != errStreamIDZero {
+		t.Errorf("newID(0-0) error = %v, want %v", err, errStreamIDZero)
+	}
+	exhausted := newStream()
+	exhausted.lastID = types.MaxStreamID
+	if _, err := exhausted.newID("*", now); err != errStreamExhausted {
+		t.Errorf("newID(*) after the greatest ID error = %v, want %v", err, errStreamExhausted)
+	}
+}
+
+func TestMemoryStore_XAddRange(t *testing.T) {
+	store := NewMemoryStore()
+	for _, id := range []string{"1-1", "1-2", "2-1", "3-1"} {
+		if _, err := store.XAdd("s", id, []string{"f", id}, nil); err != nil {
+			t.Fatalf("XAdd(%s) error = %v", id, err)
+		}
+	}
+	if _, err := store.XAdd("s", "3-1", []string{"f", "v"}, nil); err != errStreamIDTooSmall {
+		t.Errorf("XAdd() of an existing ID error = %v", err)
+	}
+	if got, _ := store.XAdd("missing", "*", []string{"f", "v"}, &options.XAddOptions{NoMkStream: true}); got != nil || store.Exists("missing") {
+		t.Errorf("XAdd() with NOMKSTREAM on a missing key = %v", got)
+	}
+
+	entries, _ := store.XRange("s", streamID(1, 2), streamID(2, 5), -1, false)
+	if want := []types.StreamID{streamID(1, 2), streamID(2, 1)}; !reflect.DeepEqual(entryIDs(entries), want) {
+		t.Errorf("XRange() = %v, want %v", entryIDs(entries), want)
+	}
+	entries, _ = store.XRange("s", types.StreamID{}, types.MaxStreamID, 2, true)
+	if want := []types.StreamID{streamID(3, 1), streamID(2, 1)}; !reflect.DeepEqual(entryIDs(entries), want) {
+		t.Errorf("XRange() reversed = %v, want %v", entryIDs(entries), want)
+	}
+	if !reflect.DeepEqual(entries[0].Fields, []string{"f", "3-1"}) {
+		t.Errorf("XRange() fields = %v", entries[0].Fields)
+	}
+
+	if n, _ := store.XDel("s", []types.StreamID{streamID(1, 1), streamID(9, 9)}); n != 1 {
+		t.Errorf("XDel() = %d, want 1", n)
+	}
+	if n, _ := store.XTrim("s", &options.XTrimOptions{Strategy: "MAXLEN", MaxLen: 1}); n != 2 {
+		t.Errorf("XTrim(MAXLEN 1) = %d, want 2", n)
+	}
+	if n, _ := store.XTrim("s", &options.XTrimOptions{Strategy: "MINID", MinID: streamID(4, 0)}); n != 1 {
+		t.Errorf("XTrim(MINID 4) = %d, want 1", n)
+	}
+	// An empty stream keeps its last ID.
+	if n, _ := store.XLen("s"); n != 0 || !store.Exists("s") {
+		t.Errorf("XLen() = %d, exists = %v, want an empty stream", n, store.Exists("s"))
+	}
+	if _, err := store.XAdd("s", "3-1", []string{"f", "v"}, nil); err != errStreamIDTooSmall {
+		t.Errorf("XAdd() below the last ID of an emptied stream error = %v", err)
+	}
+
+	info, _ := store.XInfoStream("s")
+	want := types.StreamInfo{LastID: streamID(3, 1), MaxDeletedID: streamID(1, 1), EntriesAdded: 4}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("XInfoStream() = %+v, want %+v", info, want)
+	}
+
+	store.Set("str", "v", nil)
+	if _, err := store.XAdd("str", "*", []string{"f", "v"}, nil); !errors.Is(err, ErrWrongType) {
+		t.Errorf("XAdd() on a string error = %v, want ErrWrongType", err)
+	}
+}
+
+func TestMemoryStore_XTrimLimit(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 10; i++ {
+		store.XAdd("s", "*", []string{"f", "v"}, nil)
+	}
+	opts := &options.XTrimOptions{Strategy: "MAXLEN", Approx: true, MaxLen: 5, Limit: 2}
+	if n, _ := store.XTrim("s", opts); n != 2 {
+		t.Errorf("XTrim() with LIMIT 2 = %d, want 2", n)
+	}
+	if n, _ := store.XLen("s"); n != 8 {
+		t.Errorf("XLen() = %d, want 8", n)
+	}
+}
+
+func TestMemoryStore_XRead(t *testing.T) {
+	store := NewMemoryStore()
+	store.XAdd("a", "1-1", []string{"f", "v"}, nil)
+	store.XAdd("a", "2-1", []string{"f", "v"}, nil)
+
+	results, err := store.XRead([]string{"a", "b"}, []types.StreamID{streamID(1, 1), {}}, 0)
+	if err != nil {
+		t.Fatalf("XRead() error = %v", err)
+	}
+	if len(results[0]) != 1 || results[0][0].ID != streamID(2, 1) || results[1] != nil {
+		t.Errorf("XRead() = %v", results)
+	}
+
+	added := store.StreamAdded()
+	select {
+	case <-added:
+		t.Fatalf("StreamAdded() channel closed before any entry was added")
+	default:
+	}
+	store.XAdd("b", "*", []string{"f", "v"}, nil)
+	select {
+	case <-added:
+	default:
+		t.Errorf("StreamAdded() channel not closed by XAdd()")
+	}
+}
+
+func TestMemoryStore_ConsumerGroups(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.XGroupCreate("s", "g", "$", false, -1); err == nil {
+		t.Errorf("XGroupCreate() on a missing key without MKSTREAM succeeded")
+	}
+	if err := store.XGroupCreate("s", "g", "$", true, -1); err != nil {
+		t.Fatalf("XGroupCreate() error = %v", err)
+	}
+	var typed *types.Error
+	if err := store.XGroupCreate("s", "g", "0", false, -1); !errors.As(err, &typed) || typed.Code != "BUSYGROUP" {
+		t.Errorf("XGroupCreate() of an existing group error = %v, want BUSYGROUP", err)
+	}
+	for _, id := range []string{"1-1", "2-1", "3-1"} {
+		store.XAdd("s", id, []string{"f", id}, nil)
+	}
+
+	results, err := store.XReadGroup("g", "alice", []string{"s"}, []*types.StreamID{nil}, 2, false, time.Now())
+	if err != nil {
+		t.Fatalf("XReadGroup() error = %v", err)
+	}
+	if want := []types.StreamID{streamID(1, 1), streamID(2, 1)}; !reflect.DeepEqual(entryIDs(results[0]), want) {
+		t.Errorf("XReadGroup(>) = %v, want %v", entryIDs(results[0]), want)
+	}
+	results, _ = store.XReadGroup("g", "bob", []string{"s"}, []*types.StreamID{nil}, 0, false, time.Now())
+	if want := []types.StreamID{streamID(3, 1)}; !reflect.DeepEqual(entryIDs(results[0]), want) {
+		t.Errorf("XReadGroup(>) for a second consumer = %v, want %v", entryIDs(results[0]), want)
+	}
+	if results, _ = store.XReadGroup("g", "bob", []string{"s"}, []*types.StreamID{nil}, 0, false, time.Now()); results[0] != nil {
+		t.Errorf("XReadGroup(>) with nothing new = %v, want nil", results[0])
+	}
+
+	// Reading history returns the consumer's own pending entries, with nil
+	// fields for those deleted from the stream, and redelivers the others.
+	store.XDel("s", []types.StreamID{streamID(1, 1)})
+	zero := types.StreamID{}
+	redelivered := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	results, _ = store.XReadGroup("g", "alice", []string{"s"}, []*types.StreamID{&zero}, 0, false, redelivered)
+	if len(results[0]) != 2 || results[0][0].Fields != nil || results[0][1].Fields == nil {
+		t.Errorf("XReadGroup(0) = %v", results[0])
+	}
+
+	if n, _ := store.XAck("s", "g", []types.StreamID{streamID(1, 1), streamID(9, 9)}); n != 1 {
+		t.Errorf("XAck() = %d, want 1", n)
+	}
+	pending, _ := store.XPending("s", "g", types.StreamID{}, types.MaxStreamID, -1, "", 0)
+	if len(pending) != 2 || pending[0].Consumer != "alice" || pending[1].Consumer != "bob" || pending[1].DeliveryCount != 1 {
+		t.Errorf("XPending() = %+v", pending)
+	}
+	if len(pending) == 2 && (pending[0].DeliveryCount != 2 || !pending[0].DeliveryTime.Equal(redelivered)) {
+		t.Errorf("XPending() after reading history = %+v, want delivery count 2 at %v", pending[0], redelivered)
+	}
+	if pending, _ = store.XPending("s", "g", types.StreamID{}, types.MaxStreamID, -1, "bob", 0); len(pending) != 1 {
+		t.Errorf("XPending() for bob = %+v", pending)
+	}
+	if pending, _ = store.XPending("s", "g", types.StreamID{}, types.MaxStreamID, -1, "", time.Hour); len(pending) != 0 {
+		t.Errorf("XPending() idle for an hour = %+v", pending)
+	}
+
+	groups, _ := store.XInfoGroups("s")
+	if len(groups) != 1 || groups[0].Pending != 2 || groups[0].LastID != streamID(3, 1) || groups[0].Lag != 0 {
+		t.Errorf("XInfoGroups() = %+v", groups)
+	}
+
+	if _, err := store.XReadGroup("missing", "c", []string{"s"}, []*types.StreamID{nil}, 0, false, time.Now()); !errors.As(err, &typed) || typed.Code != "NOGROUP" {
+		t.Errorf("XReadGroup() of a missing group error = %v, want NOGROUP", err)
+	}
+	if destroyed, _ := store.XGroupDestroy("s", "g"); !destroyed {
+		t.Errorf("XGroupDestroy() = false")
+	}
+}
+
+func TestMemoryStore_XClaim(t *testing.T) {
+	store := NewMemoryStore()
+	store.XGroupCreate("s", "g", "$", true, -1)
+	for _, id := range []string{"1-1", "2-1", "3-1"} {
+		store.XAdd("s", id, []string{"f", id}, nil)
+	}
+	store.XReadGroup("g", "alice", []string{"s"}, []*types.StreamID{nil}, 0, false, time.Now())
+
+	opts := options.NewXClaimOptions()
+	opts.Time = time.Now()
+	claimed, _, err := store.XClaim("s", "g", "bob", time.Hour, []types.StreamID{streamID(1, 1)}, opts)
+	if err != nil || len(claimed) != 0 {
+		t.Errorf("XClaim() of an entry idle for less than min-idle = %v, %v", claimed, err)
+	}
+	claimed, _, _ = store.XClaim("s", "g", "bob", 0, []types.StreamID{streamID(1, 1), streamID(9, 9)}, opts)
+	if want := []types.StreamID{streamID(1, 1)}; !reflect.DeepEqual(entryIDs(claimed), want) {
+		t.Errorf("XClaim() = %v, want %v", entryIDs(claimed), want)
+	}
+	pending, _ := store.XPending("s", "g", streamID(1, 1), streamID(1, 1), -1, "", 0)
+	if len(pending) != 1 || pending[0].Consumer != "bob" || pending[0].DeliveryCount != 2 {
+		t.Errorf("XPending() after XClaim() = %+v", pending)
+	}
+
+	// XAUTOCLAIM drops pending entries deleted from the stream.
+	store.XDel("s", []types.StreamID{streamID(2, 1)})
+	next, claimed, deleted, err := store.XAutoClaim("s", "g", "carol", 0, types.StreamID{}, 1, false, time.Now())
+	if err != nil {
+		t.Fatalf("XAutoClaim() error = %v", err)
+	}
+	if next != streamID(2, 1) || !reflect.DeepEqual(entryIDs(claimed), []types.StreamID{streamID(1, 1)}) || len(deleted) != 0 {
+		t.Errorf("XAutoClaim() = %v, %v, %v", next, entryIDs(claimed), deleted)
+	}
+	next, claimed, deleted, _ = store.XAutoClaim("s", "g", "carol", 0, next, 10, false, time.Now())
+	if !next.IsZero() || !reflect.DeepEqual(entryIDs(claimed), []types.StreamID{streamID(3, 1)}) || !reflect.DeepEqual(deleted, []types.StreamID{streamID(2, 1)}) {
+		t.Errorf("XAutoClaim() to the end = %v, %v, %v", next, entryIDs(claimed), deleted)
+	}
+
+	consumers, _ := store.XInfoConsumers("s", "g")
+	if len(consumers) != 3 || consumers[2].Name != "carol" || consumers[2].Pending != 2 {
+		t.Errorf("XInfoConsumers() = %+v", consumers)
+	}
+}
+
+func TestStream_DataRoundTrip(t *testing.T) {
+	store := NewMemoryStore()
+	store.XGroupCreate("s", "g", "$", true, -1)
+	store.XAdd("s", "1-1", []string{"f", "v"}, nil)
+	store.XAdd("s", "2-1", []string{"f", "v"}, nil)
+	store.XReadGroup("g", "alice", []string{"s"}, []*types.StreamID{nil}, 1, false, time.Now())
+	store.XDel("s", []types.StreamID{streamID(2, 1)})
+
+	data := store.data["s"].(*Stream).data()
+	if got := streamFromData(data).data(); !reflect.DeepEqual(got, data) {
+		t.Errorf("streamFromData(data()).data() = %+v, want %+v", got, data)
+	}
+}
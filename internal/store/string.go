@@ -0,0 +1,330 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+var errNotInteger = fmt.Errorf("value is not an integer or out of range")
+
+// intString is a string value that is the canonical decimal form of an
+// int64, stored as the integer, like the Redis int encoding. Counters are
+// updated in place rather than formatted into a new string on every
+// increment.
+type intString struct {
+	n int64
+}
+
+func (v *intString) String() string {
+	return strconv.FormatInt(v.n, 10)
+}
+
+// stringValue returns the stored representation of the string str: an
+// intString if it has the canonical form an intset also requires, and str
+// itself otherwise, which the store then owns.
+func stringValue(str []byte) interface{} {
+	// No int64 takes more than 20 bytes, so longer strings are not copied
+	// just to find that out.
+	if len(str) <= 20 {
+		if n, ok := setInt(string(str)); ok {
+			return &intString{n: n}
+		}
+	}
+	return str
+}
+
+// IncrBy adds delta to the integer stored at key, which is created as 0 if
+// it does not exist, and returns the new value. The key keeps its TTL.
+func (s *MemoryStore) IncrBy(key string, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, err := s.getStringValue(key)
+	if err != nil {
+		return 0, err
+	}
+	counter, ok := val.(*intString)
+	if !ok {
+		counter = &intString{}
+		if str, exists := val.([]byte); exists {
+			if counter.n, ok = setInt(string(str)); !ok {
+				return 0, errNotInteger
+			}
+		}
+	}
+
+	if (delta > 0 && counter.n > math.MaxInt64-delta) || (delta < 0 && counter.n < math.MinInt64-delta) {
+		return 0, fmt.Errorf("increment or decrement would overflow")
+	}
+	counter.n += delta
+	s.data[key] = counter
+	return counter.n, nil
+}
+
+// IncrByFloat adds delta to the number stored at key, which is created as 0
+// if it does not exist, and returns the new value as stored.
+func (s *MemoryStore) IncrByFloat(key string, delta float64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, exists, err := s.getString(key)
+	if err != nil {
+		return "", err
+	}
+
+	var current float64
+	if exists {
+		current, err = strconv.ParseFloat(str, 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return "", fmt.Errorf("value is not a valid float")
+		}
+	}
+
+	current += delta
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return "", fmt.Errorf("increment would produce NaN or Infinity")
+	}
+
+	value := strconv.FormatFloat(current, 'f', -1, 64)
+	s.data[key] = stringValue([]byte(value))
+	return value, nil
+}
+
+// maxStringSize is the greatest length SETRANGE and APPEND let a string
+// grow to, like proto-max-bulk-len.
+const maxStringSize = 512 << 20
+
+var errStringTooLong = fmt.Errorf("string exceeds maximum allowed size (proto-max-bulk-len)")
+
+// MGet returns the values of keys, with nil for keys that do not exist or
+// do not hold a string.
+func (s *MemoryStore) MGet(keys []string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if str, ok, _ := s.getString(key); ok {
+			values[i] = str
+		}
+	}
+	return values, nil
+}
+
+// MSet sets every key to its value, removing any TTL. With nx set, it sets
+// none of them if any key exists. It reports whether the keys were set.
+func (s *MemoryStore) MSet(pairs []types.KeyValue, nx bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if nx {
+		for _, p := range pairs {
+			if _, ok := s.data[p.Key]; ok && !s.isExpired(p.Key) {
+				return false, nil
+			}
+		}
+	}
+	for _, p := range pairs {
+		s.data[p.Key] = stringValue(bytes.Clone(p.Value))
+		delete(s.expires, p.Key)
+	}
+	return true, nil
+}
+
+// Append appends value to the string at key, creating it if needed, and
+// returns its new length. The string grows in place, so repeated appends
+// take amortized constant time per byte.
+func (s *MemoryStore) Append(key string, value []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(str)+len(value) > maxStringSize {
+		return 0, errStringTooLong
+	}
+	str = append(str, value...)
+	s.data[key] = stringValue(str)
+	return len(str), nil
+}
+
+// StrLen returns the length of the string at key, or 0 if it does not
+// exist.
+func (s *MemoryStore) StrLen(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	return len(str), err
+}
+
+// GetRange returns the substring of the string at key between the
+// inclusive offsets start and end, where negative offsets count back from
+// the end.
+func (s *MemoryStore) GetRange(key string, start, end int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return "", err
+	}
+
+	length := int64(len(str))
+	if start < 0 && end < 0 && start > end {
+		return "", nil
+	}
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	end = min(end, length-1)
+	if start > end {
+		return "", nil
+	}
+	return string(str[start : end+1]), nil
+}
+
+// SetRange overwrites the string at key from offset with value, padding it
+// with zero bytes if it is shorter, and returns its new length. An empty
+// value leaves the string, or the lack of one, as it is.
+func (s *MemoryStore) SetRange(key string, offset int64, value []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, _, err := s.getBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) == 0 {
+		return len(str), nil
+	}
+	if offset+int64(len(value)) > maxStringSize {
+		return 0, errStringTooLong
+	}
+
+	buf := growString(str, offset+int64(len(value)))
+	copy(buf[offset:], value)
+	s.data[key] = stringValue(buf)
+	return len(buf), nil
+}
+
+// GetDel returns the string at key and deletes the key, or returns nil if
+// it does not exist.
+func (s *MemoryStore) GetDel(key string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, ok, err := s.getString(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	delete(s.data, key)
+	delete(s.expires, key)
+	return str, nil
+}
+
+// GetEx returns the string at key, or nil if it does not exist, and sets or
+// with PERSIST removes its expiry. An expiry in the past deletes the key.
+func (s *MemoryStore) GetEx(key string, opts *options.SetOptions) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	str, ok, err := s.getString(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	switch {
+	case opts == nil || opts.ExpiryType == "":
+	case opts.IsPERSIST():
+		delete(s.expires, key)
+	case s.loading || time.Now().Before(opts.ExpiryTime):
+		s.expires[key] = opts.ExpiryTime
+	default:
+		delete(s.data, key)
+		delete(s.expires, key)
+	}
+	return str, nil
+}
+
+// LCS finds the longest common subsequence of the strings at key1 and key2,
+// where missing keys are empty strings. Along with it, it returns the
+// ranges it is made of, from the last one to the first.
+func (s *MemoryStore) LCS(key1, key2 string) (string, []types.LCSMatch, error) {
+	s.mu.Lock()
+	a, _, err := s.getString(key1)
+	if err != nil {
+		s.mu.Unlock()
+		return "", nil, err
+	}
+	b, _, err := s.getString(key2)
+	s.mu.Unlock()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// table[i][j] is the length of the LCS of a[:i] and b[:j].
+	table := make([][]uint32, len(a)+1)
+	for i := range table {
+		table[i] = make([]uint32, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+
+	// Walk back from the end, gathering the matching bytes into runs that
+	// are contiguous in both strings.
+	lcs := make([]byte, table[len(a)][len(b)])
+	var matches []types.LCSMatch
+	var run *types.LCSMatch
+	for i, j, k := len(a), len(b), len(lcs); i > 0 && j > 0; {
+		emit := false
+		if a[i-1] == b[j-1] {
+			lcs[k-1] = a[i-1]
+			switch {
+			case run == nil:
+				run = &types.LCSMatch{AStart: i - 1, AEnd: i - 1, BStart: j - 1, BEnd: j - 1}
+			case run.AStart == i && run.BStart == j:
+				run.AStart--
+				run.BStart--
+			default:
+				emit = true
+			}
+			if run.AStart == 0 || run.BStart == 0 {
+				emit = true
+			}
+			i, j, k = i-1, j-1, k-1
+		} else {
+			if table[i-1][j] > table[i][j-1] {
+				i--
+			} else {
+				j--
+			}
+			emit = run != nil
+		}
+		if emit {
+			matches = append(matches, *run)
+			run = nil
+		}
+	}
+	return string(lcs), matches, nil
+}
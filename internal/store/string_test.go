@@ -0,0 +1,343 @@
+package store
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hardikphalet/go-redis/internal/commands/options"
+	"github.com/hardikphalet/go-redis/internal/types"
+)
+
+func TestMemoryStore_IncrBy(t *testing.T) {
+	store := NewMemoryStore()
+
+	if n, err := store.IncrBy("counter", 1); err != nil || n != 1 {
+		t.Fatalf("IncrBy() on a missing key = %d, %v, want 1", n, err)
+	}
+	store.Set("counter", "10", nil)
+	if n, _ := store.IncrBy("counter", -15); n != -5 {
+		t.Errorf("IncrBy(-15) = %d, want -5", n)
+	}
+	if got, _ := store.Get("counter"); got != "-5" {
+		t.Errorf("Get() of a counter = %v, want -5", got)
+	}
+
+	for _, value := range []string{"abc", "007", "+1", "1.5", " 1", "9223372036854775808"} {
+		store.Set("bad", value, nil)
+		if _, err := store.IncrBy("bad", 1); err != errNotInteger {
+			t.Errorf("IncrBy() of %q error = %v, want %v", value, err, errNotInteger)
+		}
+	}
+	store.Set("max", "9223372036854775807", nil)
+	if _, err := store.IncrBy("max", 1); err == nil {
+		t.Errorf("IncrBy() past the maximum succeeded")
+	}
+	store.SAdd("set", []string{"a"})
+	if _, err := store.IncrBy("set", 1); err != ErrWrongType {
+		t.Errorf("IncrBy() on a set error = %v, want %v", err, ErrWrongType)
+	}
+
+	// Incrementing keeps the TTL.
+	opts := options.NewSetOptions()
+	opts.SetExpiry("EX", 100)
+	store.Set("ttl", "1", opts)
+	store.IncrBy("ttl", 1)
+	if ttl, _ := store.TTL("ttl"); ttl <= 0 {
+		t.Errorf("TTL() after IncrBy() = %d, want it kept", ttl)
+	}
+}
+
+func TestMemoryStore_IncrByAtomic(t *testing.T) {
+	store := NewMemoryStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				store.IncrBy("counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := store.Get("counter"); got != "8000" {
+		t.Errorf("counter after concurrent increments = %v, want 8000", got)
+	}
+
+	// Counters are stored as integers and updated in place.
+	if _, ok := store.data["counter"].(*intString); !ok {
+		t.Fatalf("counter is stored as %T, want *intString", store.data["counter"])
+	}
+	if allocs := testing.AllocsPerRun(100, func() { store.IncrBy("counter", 1) }); allocs != 0 {
+		t.Errorf("IncrBy() allocated %v times, want 0", allocs)
+	}
+}
+
+func TestMemoryStore_IntegerEncoding(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("n", "12345", nil)
+	store.Set("s", "012345", nil)
+	if _, ok := store.data["n"].(*intString); !ok {
+		t.Errorf("%q is stored as %T, want *intString", "12345", store.data["n"])
+	}
+	if _, ok := store.data["s"].([]byte); !ok {
+		t.Errorf("%q is stored as %T, want []byte", "012345", store.data["s"])
+	}
+
+	opts := options.NewSetOptions()
+	opts.Set("GET")
+	if old, _ := store.Set("n", "x", opts); old != "12345" {
+		t.Errorf("Set() with GET of an integer = %v, want \"12345\"", old)
+	}
+
+	store.Set("n", "42", nil)
+	store.Expire("n", time.Hour, nil)
+	restored := NewMemoryStore()
+	restored.Restore(store.Snapshot())
+	if got, _ := restored.Get("n"); got != "42" {
+		t.Errorf("Get() after Restore() = %v, want 42", got)
+	}
+	if n, _ := restored.IncrBy("n", 1); n != 43 {
+		t.Errorf("IncrBy() after Restore() = %d, want 43", n)
+	}
+}
+
+func TestMemoryStore_IncrByFloat(t *testing.T) {
+	store := NewMemoryStore()
+	tests := []struct {
+		value string
+		delta float64
+		want  string
+	}{
+		{"10.50", 0.1, "10.6"},
+		{"5.0e3", 2.0e2, "5200"},
+		{"3", 1.5, "4.5"},
+		{"1.5", -1.5, "0"},
+	}
+	for _, tt := range tests {
+		store.Set("f", tt.value, nil)
+		if got, err := store.IncrByFloat("f", tt.delta); err != nil || got != tt.want {
+			t.Errorf("IncrByFloat(%q, %v) = %q, %v, want %q", tt.value, tt.delta, got, err, tt.want)
+		}
+	}
+	if got, _ := store.IncrByFloat("missing", 2.5); got != "2.5" {
+		t.Errorf("IncrByFloat() on a missing key = %q, want 2.5", got)
+	}
+
+	// A float result that is an integer can be incremented with INCR.
+	if n, err := store.IncrBy("f", 1); err != nil || n != 1 {
+		t.Errorf("IncrBy() after IncrByFloat() = %d, %v, want 1", n, err)
+	}
+
+	store.Set("bad", "abc", nil)
+	if _, err := store.IncrByFloat("bad", 1); err == nil {
+		t.Errorf("IncrByFloat() of a non-number succeeded")
+	}
+	store.Set("big", "1.7e308", nil)
+	if _, err := store.IncrByFloat("big", 1.7e308); err == nil {
+		t.Errorf("IncrByFloat() to infinity succeeded")
+	}
+}
+
+func TestMemoryStore_MSet(t *testing.T) {
+	store := NewMemoryStore()
+	opts := options.NewSetOptions()
+	opts.SetExpiry("EX", 100)
+	store.Set("a", "old", opts)
+
+	if ok, err := store.MSet([]types.KeyValue{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2")}}, false); err != nil || !ok {
+		t.Fatalf("MSet() = %v, %v, want true", ok, err)
+	}
+	if ttl, _ := store.TTL("a"); ttl != -1 {
+		t.Errorf("TTL() after MSet() = %d, want -1", ttl)
+	}
+
+	// MSETNX sets nothing if any key exists.
+	if ok, _ := store.MSet([]types.KeyValue{{Key: "c", Value: []byte("3")}, {Key: "b", Value: []byte("x")}}, true); ok {
+		t.Errorf("MSet() with nx and an existing key = true")
+	}
+	if store.Exists("c") {
+		t.Errorf("MSet() with nx set a key although another existed")
+	}
+
+	store.SAdd("set", []string{"m"})
+	got, _ := store.MGet([]string{"a", "missing", "set", "b"})
+	if want := []interface{}{"1", nil, nil, "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MGet() = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryStore_StringRanges(t *testing.T) {
+	store := NewMemoryStore()
+
+	if n, _ := store.Append("k", []byte("Hello")); n != 5 {
+		t.Errorf("Append() on a missing key = %d, want 5", n)
+	}
+	if n, _ := store.Append("k", []byte(" World")); n != 11 {
+		t.Errorf("Append() = %d, want 11", n)
+	}
+	if n, _ := store.StrLen("k"); n != 11 {
+		t.Errorf("StrLen() = %d, want 11", n)
+	}
+	if n, _ := store.StrLen("missing"); n != 0 {
+		t.Errorf("StrLen() of a missing key = %d, want 0", n)
+	}
+
+	ranges := []struct {
+		start, end int64
+		want       string
+	}{
+		{0, 3, "Hell"},
+		{-3, -1, "rld"},
+		{0, -1, "Hello World"},
+		{10, 100, "d"},
+		{5, 3, ""},
+		{-1, -5, ""},
+		{-100, 1, "He"},
+		{20, 30, ""},
+	}
+	for _, tt := range ranges {
+		if got, _ := store.GetRange("k", tt.start, tt.end); got != tt.want {
+			t.Errorf("GetRange(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+
+	if n, _ := store.SetRange("k", 6, []byte("Redis")); n != 11 {
+		t.Errorf("SetRange() = %d, want 11", n)
+	}
+	if got, _ := store.Get("k"); got != "Hello Redis" {
+		t.Errorf("Get() after SetRange() = %q", got)
+	}
+	if n, _ := store.SetRange("pad", 3, []byte("x")); n != 4 {
+		t.Errorf("SetRange() past the end = %d, want 4", n)
+	}
+	if got, _ := store.Get("pad"); got != "\x00\x00\x00x" {
+		t.Errorf("Get() after padding SetRange() = %q", got)
+	}
+	if n, _ := store.SetRange("empty", 10, []byte("")); n != 0 || store.Exists("empty") {
+		t.Errorf("SetRange() of an empty value = %d, want 0 and no key", n)
+	}
+
+	// Appending to an integer keeps working once it is no longer one.
+	store.Set("n", "12", nil)
+	store.Append("n", []byte("3"))
+	if n, _ := store.IncrBy("n", 1); n != 124 {
+		t.Errorf("IncrBy() after Append() = %d, want 124", n)
+	}
+	store.Append("n", []byte("x"))
+	if got, _ := store.Get("n"); got != "124x" {
+		t.Errorf("Get() after Append() = %v, want 124x", got)
+	}
+}
+
+func TestMemoryStore_GetDelAndGetEx(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("k", "v", nil)
+
+	if got, _ := store.GetDel("k"); got != "v" || store.Exists("k") {
+		t.Errorf("GetDel() = %v, want v and the key deleted", got)
+	}
+	if got, _ := store.GetDel("k"); got != nil {
+		t.Errorf("GetDel() of a missing key = %v, want nil", got)
+	}
+
+	store.Set("k", "v", nil)
+	opts := options.NewSetOptions()
+	opts.SetExpiry("EX", 100)
+	if got, _ := store.GetEx("k", opts); got != "v" {
+		t.Errorf("GetEx() = %v, want v", got)
+	}
+	if ttl, _ := store.TTL("k"); ttl <= 0 {
+		t.Errorf("TTL() after GetEx() with EX = %d, want positive", ttl)
+	}
+	persist := options.NewSetOptions()
+	persist.SetExpiry("PERSIST", 0)
+	store.GetEx("k", persist)
+	if ttl, _ := store.TTL("k"); ttl != -1 {
+		t.Errorf("TTL() after GetEx() with PERSIST = %d, want -1", ttl)
+	}
+
+	past := options.NewSetOptions()
+	past.SetExpiry("PXAT", 1)
+	if got, _ := store.GetEx("k", past); got != "v" || store.Exists("k") {
+		t.Errorf("GetEx() with a past time = %v, want v and the key deleted", got)
+	}
+}
+
+func TestMemoryStore_LCS(t *testing.T) {
+	store := NewMemoryStore()
+	store.Set("key1", "ohmytext", nil)
+	store.Set("key2", "mynewtext", nil)
+
+	lcs, matches, err := store.LCS("key1", "key2")
+	if err != nil || lcs != "mytext" {
+		t.Fatalf("LCS() = %q, %v, want mytext", lcs, err)
+	}
+	want := []types.LCSMatch{
+		{AStart: 4, AEnd: 7, BStart: 5, BEnd: 8},
+		{AStart: 2, AEnd: 3, BStart: 0, BEnd: 1},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("LCS() matches = %+v, want %+v", matches, want)
+	}
+
+	if lcs, matches, _ := store.LCS("key1", "missing"); lcs != "" || matches != nil {
+		t.Errorf("LCS() with a missing key = %q, %v, want nothing", lcs, matches)
+	}
+
+	// The matches always cover the whole subsequence.
+	store.Set("a", "GAC-TTAGCCA", nil)
+	store.Set("b", "ACTGA-TTCG", nil)
+	lcs, matches, _ = store.LCS("a", "b")
+	total := 0
+	for _, m := range matches {
+		total += m.Len()
+		a, _ := store.GetRange("a", int64(m.AStart), int64(m.AEnd))
+		b, _ := store.GetRange("b", int64(m.BStart), int64(m.BEnd))
+		if a != b {
+			t.Errorf("LCS() match %+v covers %q and %q", m, a, b)
+		}
+	}
+	if total != len(lcs) {
+		t.Errorf("LCS() matches cover %d bytes of %q", total, lcs)
+	}
+}
+
+func TestMemoryStore_ByteValues(t *testing.T) {
+	store := NewMemoryStore()
+
+	// The store keeps its own copy of a value.
+	value := []byte("a\x00b")
+	store.Set("k", value, nil)
+	value[0] = 'x'
+	got, _ := store.Get("k")
+	if got != "a\x00b" {
+		t.Errorf("Get() after changing the slice passed to Set() = %q", got)
+	}
+
+	// Values handed out are copies, unaffected by later writes in place.
+	store.SetRange("k", 0, []byte("z"))
+	store.Append("k", []byte("\xff"))
+	if got != "a\x00b" {
+		t.Errorf("an earlier Get() result changed to %q", got)
+	}
+	if got, _ := store.Get("k"); got != "z\x00b\xff" {
+		t.Errorf("Get() after writing in place = %q", got)
+	}
+
+	restored := NewMemoryStore()
+	restored.Restore(store.Snapshot())
+	if got, _ := restored.Get("k"); got != "z\x00b\xff" {
+		t.Errorf("Get() after Restore() = %q", got)
+	}
+
+	// Appending grows the string in place rather than copying it each time.
+	for i := 0; i < 1024; i++ {
+		store.Append("log", []byte("entry\n"))
+	}
+	if allocs := testing.AllocsPerRun(100, func() { store.Append("log", []byte("entry\n")) }); allocs > 1 {
+		t.Errorf("Append() allocated %v times, want at most 1", allocs)
+	}
+}
@@ -0,0 +1,17 @@
+package types
+
+// GeoLocation is a member of a geo set with its coordinates in degrees.
+type GeoLocation struct {
+	Longitude float64
+	Latitude  float64
+	Member    string
+}
+
+// GeoResult is a member found by GEOSEARCH.
+type GeoResult struct {
+	Member    string
+	Distance  float64 // Distance from the search center in meters
+	Hash      uint64  // The 52-bit geohash the member is stored under
+	Longitude float64
+	Latitude  float64
+}
@@ -0,0 +1,10 @@
+package types
+
+import "time"
+
+// FieldValue represents a field-value pair of a hash
+type FieldValue struct {
+	Field  string
+	Value  string
+	Expiry time.Time // Zero when the field has no expiry
+}
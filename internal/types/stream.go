@@ -0,0 +1,159 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStreamID is returned when a stream ID argument cannot be parsed.
+var ErrInvalidStreamID = errors.New("Invalid stream ID specified as stream command argument")
+
+// StreamID identifies a stream entry: the Unix time in milliseconds at which
+// it was added and a sequence number telling apart entries of the same
+// millisecond.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// MaxStreamID is the greatest possible stream ID.
+var MaxStreamID = StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+
+// ParseStreamID parses an ID of the form "ms-seq", or "ms" in which case the
+// sequence number is missingSeq.
+func ParseStreamID(s string, missingSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrInvalidStreamID
+	}
+	if !hasSeq {
+		return StreamID{Ms: ms, Seq: missingSeq}, nil
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, ErrInvalidStreamID
+	}
+	return StreamID{Ms: ms, Seq: seq}, nil
+}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+// Compare returns -1, 0 or 1 as id is less than, equal to or greater than
+// other.
+func (id StreamID) Compare(other StreamID) int {
+	switch {
+	case id.Ms < other.Ms || (id.Ms == other.Ms && id.Seq < other.Seq):
+		return -1
+	case id == other:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// IsZero reports whether id is 0-0.
+func (id StreamID) IsZero() bool {
+	return id == StreamID{}
+}
+
+// Next returns the smallest ID greater than id, or false if id is the
+// greatest possible ID.
+func (id StreamID) Next() (StreamID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{Ms: id.Ms, Seq: id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{Ms: id.Ms + 1}, true
+	default:
+		return id, false
+	}
+}
+
+// Prev returns the greatest ID smaller than id, or false if id is 0-0.
+func (id StreamID) Prev() (StreamID, bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{Ms: id.Ms, Seq: id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{Ms: id.Ms - 1, Seq: math.MaxUint64}, true
+	default:
+		return id, false
+	}
+}
+
+// StreamEntry is a stream entry with its field-value pairs flattened in the
+// order they were given to XADD.
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string
+}
+
+// StreamData is a point-in-time copy of a stream, including the state of
+// its consumer groups.
+type StreamData struct {
+	Entries      []StreamEntry
+	LastID       StreamID // Greatest ID ever added, even if since deleted
+	EntriesAdded int64    // Number of entries ever added
+	MaxDeletedID StreamID // Greatest ID removed by XDEL
+	Groups       []StreamGroup
+}
+
+// StreamGroup is a consumer group of a stream.
+type StreamGroup struct {
+	Name        string
+	LastID      StreamID // Last entry delivered to the group
+	EntriesRead int64    // Entries the group has read, or -1 if unknown
+	Consumers   []StreamConsumer
+	Pending     []StreamPending
+}
+
+// StreamConsumer is a consumer of a consumer group.
+type StreamConsumer struct {
+	Name       string
+	SeenTime   time.Time // Last time the consumer did anything
+	ActiveTime time.Time // Last time it read or claimed entries, zero if never
+}
+
+// StreamPending is an entry delivered to a consumer but not acknowledged
+// yet.
+type StreamPending struct {
+	ID            StreamID
+	Consumer      string
+	DeliveryTime  time.Time
+	DeliveryCount int64
+}
+
+// StreamInfo describes a stream as reported by XINFO STREAM.
+type StreamInfo struct {
+	Length       int
+	LastID       StreamID
+	MaxDeletedID StreamID
+	EntriesAdded int64
+	FirstID      StreamID // ID of the first entry, 0-0 if the stream is empty
+	Groups       int
+	First, Last  *StreamEntry // Nil if the stream is empty
+}
+
+// StreamGroupInfo describes a consumer group as reported by XINFO GROUPS.
+type StreamGroupInfo struct {
+	Name        string
+	Consumers   int
+	Pending     int
+	LastID      StreamID
+	EntriesRead int64 // -1 if unknown
+	Lag         int64 // Entries added but not yet delivered to the group, -1 if unknown
+}
+
+// StreamConsumerInfo describes a consumer as reported by XINFO CONSUMERS.
+type StreamConsumerInfo struct {
+	Name       string
+	Pending    int
+	SeenTime   time.Time
+	ActiveTime time.Time
+}
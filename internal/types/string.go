@@ -0,0 +1,21 @@
+package types
+
+// KeyValue represents a key and the string value to set it to, as given to
+// MSET.
+type KeyValue struct {
+	Key   string
+	Value []byte
+}
+
+// LCSMatch is a range of the longest common subsequence of two strings,
+// found at AStart..AEnd in the first and BStart..BEnd in the second, with
+// both ends inclusive.
+type LCSMatch struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// Len returns the length of the match.
+func (m LCSMatch) Len() int {
+	return m.AEnd - m.AStart + 1
+}
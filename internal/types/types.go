@@ -2,3 +2,14 @@ package types
 
 // SimpleString represents a RESP Simple String that should be written with a + prefix
 type SimpleString string
+
+// Error is an error reply that carries its own RESP error code, such as
+// READONLY, instead of the generic ERR prefix.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Code + " " + e.Message
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/hardikphalet/go-redis/internal/resp"
 )
@@ -19,7 +20,19 @@ func NewClient(address string) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Redis server: %v", err)
 	}
+	return newClient(conn), nil
+}
 
+// DialTimeout is like NewClient but gives up connecting after timeout.
+func DialTimeout(address string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis server: %v", err)
+	}
+	return newClient(conn), nil
+}
+
+func newClient(conn net.Conn) *Client {
 	reader := bufio.NewReader(conn)
 	writer := bufio.NewWriter(conn)
 
@@ -27,7 +40,7 @@ func NewClient(address string) (*Client, error) {
 		conn:           conn,
 		respWriter:     resp.NewWriter(writer),
 		responseParser: NewResponseParser(reader),
-	}, nil
+	}
 }
 
 func (c *Client) Close() error {
@@ -37,6 +50,12 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// SetDeadline sets the deadline for sending commands and receiving replies.
+// A zero value means no deadline.
+func (c *Client) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
 func (c *Client) Send(command string, args ...string) error {
 	cmdArray := make([]string, 0, len(args)+1)
 	cmdArray = append(cmdArray, command)
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/server"
+)
+
+// allBytes is every byte value once, including CR, LF and NUL.
+func allBytes() string {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return string(b)
+}
+
+func TestClient_BinarySafeValues(t *testing.T) {
+	cfg := server.DefaultConfig("127.0.0.1:6441")
+	cfg.Dir = t.TempDir()
+	s := server.NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer s.Stop()
+
+	c, err := NewClient("127.0.0.1:6441")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	do := func(args ...string) interface{} {
+		t.Helper()
+		if err := c.Send(args[0], args[1:]...); err != nil {
+			t.Fatalf("Send(%q) error = %v", args, err)
+		}
+		reply, err := c.Receive()
+		if err != nil {
+			t.Fatalf("Receive(%q) error = %v", args, err)
+		}
+		return reply
+	}
+
+	// Every byte on its own, as a key and as a value.
+	for i := 0; i < 256; i++ {
+		b := string([]byte{byte(i)})
+		do("SET", b, b)
+		if got := do("GET", b); got != b {
+			t.Fatalf("GET of byte %#02x = %q", i, got)
+		}
+	}
+
+	all := allBytes()
+	do("SET", "all", all)
+	if got := do("GET", "all"); got != all {
+		t.Errorf("GET of all bytes = %q", got)
+	}
+	if got := do("STRLEN", "all"); got != 256 {
+		t.Errorf("STRLEN of all bytes = %v, want 256", got)
+	}
+	if got := do("GETRANGE", "all", "10", "13"); got != "\n\x0b\x0c\r" {
+		t.Errorf("GETRANGE over CR and LF = %q", got)
+	}
+	do("APPEND", "appended", all[:128])
+	do("APPEND", "appended", all[128:])
+	do("SETRANGE", "ranged", "128", all[128:])
+	do("SETRANGE", "ranged", "0", all[:128])
+	do("MSET", "m1", all, "m2", "\x00")
+	if got := do("MGET", "appended", "ranged", "m1", "m2"); !reflect.DeepEqual(got, []interface{}{all, all, all, "\x00"}) {
+		t.Errorf("MGET of binary values = %q", got)
+	}
+
+	// The other types keep their elements byte for byte too.
+	do("HSET", "hash", all, all)
+	do("RPUSH", "list", all, "\r\n")
+	do("SADD", "set", all)
+	do("ZADD", "zset", "1", all)
+	for _, tt := range []struct {
+		cmd  []string
+		want interface{}
+	}{
+		{[]string{"HGET", "hash", all}, all},
+		{[]string{"LRANGE", "list", "0", "-1"}, []interface{}{all, "\r\n"}},
+		{[]string{"SMEMBERS", "set"}, []interface{}{all}},
+		{[]string{"ZRANGE", "zset", "0", "-1"}, []interface{}{all}},
+	} {
+		if got := do(tt.cmd...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.cmd[0], got, tt.want)
+		}
+	}
+
+	// An empty string is a value, unlike a missing key.
+	do("SET", "empty", "")
+	if got := do("GET", "empty"); got != "" {
+		t.Errorf("GET of an empty string = %#v, want \"\"", got)
+	}
+	if got := do("GET", "missing"); got != nil {
+		t.Errorf("GET of a missing key = %#v, want nil", got)
+	}
+}
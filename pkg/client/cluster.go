@@ -0,0 +1,462 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hardikphalet/go-redis/internal/cluster"
+)
+
+// maxRedirects bounds how many MOVED or ASK replies a single command follows.
+const maxRedirects = 5
+
+var (
+	errNoNodes = errors.New("no reachable cluster node")
+	errClosed  = errors.New("cluster client is closed")
+)
+
+// ClusterClient sends commands to a cluster, routing each one to the node
+// serving the hash slot of its keys. It caches the slot map fetched with
+// CLUSTER SLOTS, follows MOVED and ASK redirections and refreshes the map
+// when the cluster changes. It is safe for concurrent use.
+type ClusterClient struct {
+	mu     sync.Mutex
+	seeds  []string
+	nodes  map[string]*nodeConn
+	slots  [cluster.Slots]string // Address serving each slot, empty if unknown
+	closed bool
+}
+
+// nodeConn serializes request/reply exchanges on one node connection.
+type nodeConn struct {
+	mu     sync.Mutex
+	client *Client
+}
+
+// NewClusterClient connects to the cluster through the seed addresses and
+// loads its slot map.
+func NewClusterClient(seeds ...string) (*ClusterClient, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("at least one seed address is required")
+	}
+
+	c := &ClusterClient{
+		seeds: seeds,
+		nodes: make(map[string]*nodeConn),
+	}
+	if err := c.RefreshSlots(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connections to every node.
+func (c *ClusterClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var firstErr error
+	for addr, node := range c.nodes {
+		if err := node.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.nodes, addr)
+	}
+	return firstErr
+}
+
+// RefreshSlots reloads the slot map from the first node that answers CLUSTER
+// SLOTS, trying the known nodes before the seeds.
+func (c *ClusterClient) RefreshSlots() error {
+	lastErr := errNoNodes
+	for _, addr := range c.candidates() {
+		reply, err := c.call(addr, false, []string{"CLUSTER", "SLOTS"})
+		if err == nil {
+			if replyErr, ok := reply.(error); ok {
+				err = replyErr
+			}
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		slots, err := parseSlots(reply)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.slots = slots
+		c.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("failed to load cluster slots: %w", lastErr)
+}
+
+// candidates returns the addresses to try when any node will do.
+func (c *ClusterClient) candidates() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	addrs := make([]string, 0, len(c.nodes)+len(c.seeds))
+	seen := make(map[string]bool)
+	for addr := range c.nodes {
+		addrs = append(addrs, addr)
+		seen[addr] = true
+	}
+	for _, addr := range c.seeds {
+		if !seen[addr] {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// parseSlots builds the slot map from a CLUSTER SLOTS reply.
+func parseSlots(reply interface{}) ([cluster.Slots]string, error) {
+	var slots [cluster.Slots]string
+
+	ranges, ok := reply.([]interface{})
+	if !ok {
+		return slots, fmt.Errorf("unexpected CLUSTER SLOTS reply: %v", reply)
+	}
+	for _, r := range ranges {
+		entry, ok := r.([]interface{})
+		if !ok || len(entry) < 3 {
+			return slots, fmt.Errorf("unexpected CLUSTER SLOTS entry: %v", r)
+		}
+		start, okStart := entry[0].(int)
+		end, okEnd := entry[1].(int)
+		node, okNode := entry[2].([]interface{})
+		if !okStart || !okEnd || !okNode || len(node) < 2 || start < 0 || end >= cluster.Slots {
+			return slots, fmt.Errorf("unexpected CLUSTER SLOTS entry: %v", r)
+		}
+		host, okHost := node[0].(string)
+		port, okPort := node[1].(int)
+		if !okHost || !okPort {
+			return slots, fmt.Errorf("unexpected CLUSTER SLOTS node: %v", node)
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = addr
+		}
+	}
+	return slots, nil
+}
+
+// Do sends a command to the node serving its keys and returns the reply. As
+// with Client.Receive, error replies are returned as error values in the
+// reply. Multi-key commands whose keys span several slots are split per slot
+// where that keeps their meaning: DEL, EXISTS, UNLINK and TOUCH add up the
+// counts, MGET reassembles the values in order and MSET sets every pair.
+func (c *ClusterClient) Do(command string, args ...string) (interface{}, error) {
+	name := strings.ToUpper(command)
+	switch name {
+	case "DEL", "EXISTS", "UNLINK", "TOUCH":
+		return c.doCount(command, args)
+	case "MGET":
+		return c.doMGet(command, args)
+	case "MSET":
+		return c.doMSet(command, args)
+	}
+	return c.do(append([]string{command}, args...))
+}
+
+// do routes a single-slot command and follows redirections.
+func (c *ClusterClient) do(cmd []string) (interface{}, error) {
+	keys := commandKeys(cmd)
+	slot := -1
+	if len(keys) > 0 {
+		slot = cluster.KeySlot(keys[0])
+	}
+
+	addr, err := c.addrForSlot(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	asking := false
+	refreshed := false
+	for redirects := 0; ; redirects++ {
+		reply, err := c.call(addr, asking, cmd)
+		if err != nil {
+			// The node may have gone away; retry once with a fresh map.
+			if refreshed {
+				return nil, err
+			}
+			refreshed = true
+			if err := c.RefreshSlots(); err != nil {
+				return nil, err
+			}
+			if addr, err = c.addrForSlot(slot); err != nil {
+				return nil, err
+			}
+			asking = false
+			continue
+		}
+
+		replyErr, ok := reply.(error)
+		if !ok || redirects >= maxRedirects {
+			return reply, nil
+		}
+
+		kind, redirectSlot, target, ok := parseRedirect(replyErr.Error())
+		if !ok {
+			return reply, nil
+		}
+		switch kind {
+		case "MOVED":
+			c.mu.Lock()
+			c.slots[redirectSlot] = target
+			c.mu.Unlock()
+			// A moved slot usually means others moved too.
+			go c.RefreshSlots()
+			asking = false
+		case "ASK":
+			asking = true
+		}
+		addr = target
+	}
+}
+
+// addrForSlot returns the node serving slot, or any node for slot -1.
+func (c *ClusterClient) addrForSlot(slot int) (string, error) {
+	if slot >= 0 {
+		c.mu.Lock()
+		addr := c.slots[slot]
+		c.mu.Unlock()
+		if addr != "" {
+			return addr, nil
+		}
+	}
+
+	candidates := c.candidates()
+	if len(candidates) == 0 {
+		return "", errNoNodes
+	}
+	return candidates[0], nil
+}
+
+// call sends cmd to the node at addr, preceded by ASKING if asking is set.
+// Connection failures drop the connection so the next call reconnects.
+func (c *ClusterClient) call(addr string, asking bool, cmd []string) (interface{}, error) {
+	node, err := c.node(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if asking {
+		if _, err := node.roundTrip([]string{"ASKING"}); err != nil {
+			c.dropNode(addr, node)
+			return nil, err
+		}
+	}
+	reply, err := node.roundTrip(cmd)
+	if err != nil {
+		c.dropNode(addr, node)
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (n *nodeConn) roundTrip(cmd []string) (interface{}, error) {
+	if err := n.client.Send(cmd[0], cmd[1:]...); err != nil {
+		return nil, err
+	}
+	return n.client.Receive()
+}
+
+// node returns the connection to addr, dialing it if needed.
+func (c *ClusterClient) node(addr string) (*nodeConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, errClosed
+	}
+	if node, ok := c.nodes[addr]; ok {
+		return node, nil
+	}
+	client, err := NewClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	node := &nodeConn{client: client}
+	c.nodes[addr] = node
+	return node, nil
+}
+
+func (c *ClusterClient) dropNode(addr string, node *nodeConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.nodes[addr] == node {
+		delete(c.nodes, addr)
+	}
+	node.client.Close()
+}
+
+// parseRedirect parses "MOVED <slot> <addr>" and "ASK <slot> <addr>".
+func parseRedirect(msg string) (kind string, slot int, addr string, ok bool) {
+	fields := strings.Fields(msg)
+	if len(fields) != 3 || (fields[0] != "MOVED" && fields[0] != "ASK") {
+		return "", 0, "", false
+	}
+	slot, err := strconv.Atoi(fields[1])
+	if err != nil || slot < 0 || slot >= cluster.Slots {
+		return "", 0, "", false
+	}
+	return fields[0], slot, fields[2], true
+}
+
+// groupBySlot returns the indexes of keys grouped by hash slot, in order of
+// first appearance.
+func groupBySlot(keys []string) [][]int {
+	var groups [][]int
+	index := make(map[int]int)
+	for i, key := range keys {
+		slot := cluster.KeySlot(key)
+		g, ok := index[slot]
+		if !ok {
+			g = len(groups)
+			index[slot] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+func (c *ClusterClient) doCount(command string, keys []string) (interface{}, error) {
+	total := 0
+	for _, group := range groupBySlot(keys) {
+		cmd := []string{command}
+		for _, i := range group {
+			cmd = append(cmd, keys[i])
+		}
+		reply, err := c.do(cmd)
+		if err != nil {
+			return nil, err
+		}
+		n, ok := reply.(int)
+		if !ok {
+			return reply, nil
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (c *ClusterClient) doMGet(command string, keys []string) (interface{}, error) {
+	values := make([]interface{}, len(keys))
+	for _, group := range groupBySlot(keys) {
+		cmd := []string{command}
+		for _, i := range group {
+			cmd = append(cmd, keys[i])
+		}
+		reply, err := c.do(cmd)
+		if err != nil {
+			return nil, err
+		}
+		part, ok := reply.([]interface{})
+		if !ok || len(part) != len(group) {
+			return reply, nil
+		}
+		for j, i := range group {
+			values[i] = part[j]
+		}
+	}
+	return values, nil
+}
+
+func (c *ClusterClient) doMSet(command string, args []string) (interface{}, error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return c.do(append([]string{command}, args...))
+	}
+
+	keys := make([]string, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		keys = append(keys, args[i])
+	}
+
+	var reply interface{}
+	for _, group := range groupBySlot(keys) {
+		cmd := []string{command}
+		for _, i := range group {
+			cmd = append(cmd, args[2*i], args[2*i+1])
+		}
+		var err error
+		if reply, err = c.do(cmd); err != nil {
+			return nil, err
+		}
+		if _, ok := reply.(error); ok {
+			return reply, nil
+		}
+	}
+	return reply, nil
+}
+
+// commandKeys returns the key arguments of cmd used for routing. Commands
+// without keys return nil and may go to any node.
+func commandKeys(cmd []string) []string {
+	args := cmd[1:]
+	switch strings.ToUpper(cmd[0]) {
+	case "PING", "ECHO", "KEYS", "INFO", "ROLE", "WAIT", "CLUSTER", "ASKING",
+		"COMMAND", "SAVE", "BGSAVE", "LASTSAVE", "BGREWRITEAOF",
+		"REPLICAOF", "SLAVEOF", "MIGRATE":
+		return nil
+	case "DEL", "EXISTS", "UNLINK", "TOUCH", "MGET":
+		return args
+	case "MSET", "MSETNX":
+		keys := make([]string, 0, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			keys = append(keys, args[i])
+		}
+		return keys
+	case "SINTERCARD":
+		if len(args) == 0 {
+			return nil
+		}
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 && n < len(args) {
+			return args[1 : 1+n]
+		}
+		return nil
+	case "BITOP":
+		if len(args) < 2 {
+			return nil
+		}
+		return args[1:]
+	case "LCS":
+		if len(args) < 2 {
+			return nil
+		}
+		return args[:2]
+	case "XGROUP", "XINFO":
+		if len(args) < 2 {
+			return nil
+		}
+		return args[1:2]
+	case "XREAD", "XREADGROUP":
+		for i, arg := range args {
+			if strings.ToUpper(arg) == "STREAMS" {
+				streams := args[i+1:]
+				return streams[:len(streams)/2]
+			}
+		}
+		return nil
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args[:1]
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hardikphalet/go-redis/internal/server"
+)
+
+func TestCommandKeys(t *testing.T) {
+	tests := []struct {
+		cmd  []string
+		want []string
+	}{
+		{[]string{"PING"}, nil},
+		{[]string{"SINTERCARD", "2", "a", "b", "LIMIT", "1"}, []string{"a", "b"}},
+		{[]string{"XREADGROUP", "GROUP", "g", "c", "STREAMS", "a", "b", ">", ">"}, []string{"a", "b"}},
+		{[]string{"XGROUP", "CREATE", "s", "g", "$"}, []string{"s"}},
+		{[]string{"BITOP", "AND", "dest", "a", "b"}, []string{"dest", "a", "b"}},
+		{[]string{"LCS", "a", "b", "IDX"}, []string{"a", "b"}},
+		{[]string{"KEYS", "*"}, nil},
+		{[]string{"GET", "foo"}, []string{"foo"}},
+		{[]string{"set", "foo", "bar", "EX", "10"}, []string{"foo"}},
+		{[]string{"DEL", "a", "b"}, []string{"a", "b"}},
+		{[]string{"MSET", "a", "1", "b", "2"}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		if got := commandKeys(tt.cmd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("commandKeys(%v) = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestCommandKeys_BareCommand(t *testing.T) {
+	for _, name := range []string{"GET", "DEL", "MSET", "SINTERCARD", "BITOP", "LCS", "XGROUP", "XREADGROUP"} {
+		if got := commandKeys([]string{name}); len(got) != 0 {
+			t.Errorf("commandKeys([%s]) = %v, want no keys", name, got)
+		}
+	}
+}
+
+func TestParseRedirect(t *testing.T) {
+	tests := []struct {
+		msg  string
+		kind string
+		slot int
+		addr string
+		ok   bool
+	}{
+		{"MOVED 3999 127.0.0.1:6381", "MOVED", 3999, "127.0.0.1:6381", true},
+		{"ASK 12182 127.0.0.1:7001", "ASK", 12182, "127.0.0.1:7001", true},
+		{"CLUSTERDOWN Hash slot not served", "", 0, "", false},
+		{"MOVED 16384 127.0.0.1:6381", "", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		kind, slot, addr, ok := parseRedirect(tt.msg)
+		if kind != tt.kind || slot != tt.slot || addr != tt.addr || ok != tt.ok {
+			t.Errorf("parseRedirect(%q) = %q, %d, %q, %v", tt.msg, kind, slot, addr, ok)
+		}
+	}
+}
+
+func TestGroupBySlot(t *testing.T) {
+	got := groupBySlot([]string{"{a}1", "{b}1", "{a}2"})
+	want := [][]int{{0, 2}, {1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupBySlot() = %v, want %v", got, want)
+	}
+}
+
+func startClusterNode(t *testing.T, addr, layout string) *server.Server {
+	t.Helper()
+	cfg := server.DefaultConfig(addr)
+	cfg.Dir = t.TempDir()
+	cfg.ClusterEnabled = true
+	cfg.ClusterLayout = layout
+
+	s := server.NewWithConfig(cfg)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Failed to start cluster node on %s: %v", addr, err)
+	}
+	return s
+}
+
+func TestClusterClient(t *testing.T) {
+	const layout = "127.0.0.1:6405=0-8191,127.0.0.1:6406=8192-16383"
+	first := startClusterNode(t, "127.0.0.1:6405", layout)
+	defer first.Stop()
+	second := startClusterNode(t, "127.0.0.1:6406", layout)
+	defer second.Stop()
+
+	c, err := NewClusterClient("127.0.0.1:6405")
+	if err != nil {
+		t.Fatalf("NewClusterClient() error = %v", err)
+	}
+	defer c.Close()
+
+	// "{b}" hashes to slot 3300 on the first node and "foo" to 12182 on the
+	// second one.
+	for _, key := range []string{"{b}1", "{b}2", "foo"} {
+		if reply, err := c.Do("SET", key, "v-"+key); err != nil {
+			t.Fatalf("SET %s error = %v", key, err)
+		} else if replyErr, ok := reply.(error); ok {
+			t.Fatalf("SET %s replied %v", key, replyErr)
+		}
+	}
+	for _, key := range []string{"{b}1", "foo"} {
+		if got, err := c.Do("GET", key); err != nil || got != "v-"+key {
+			t.Errorf("GET %s = %v, %v, want v-%s", key, got, err, key)
+		}
+	}
+
+	if got, err := c.Do("DEL", "{b}1", "foo", "missing"); err != nil || got != 2 {
+		t.Errorf("DEL across slots = %v, %v, want 2", got, err)
+	}
+
+	// Move slot 3300 to the second node behind the client's back.
+	direct, err := NewClient("127.0.0.1:6405")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer direct.Close()
+	directSecond, err := NewClient("127.0.0.1:6406")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer directSecond.Close()
+
+	do := func(cl *Client, args ...string) interface{} {
+		t.Helper()
+		if err := cl.Send(args[0], args[1:]...); err != nil {
+			t.Fatalf("Send(%v) error = %v", args, err)
+		}
+		reply, err := cl.Receive()
+		if err != nil {
+			t.Fatalf("Receive(%v) error = %v", args, err)
+		}
+		return reply
+	}
+	firstID := do(direct, "CLUSTER", "MYID").(string)
+	secondID := do(directSecond, "CLUSTER", "MYID").(string)
+	do(directSecond, "CLUSTER", "SETSLOT", "3300", "IMPORTING", firstID)
+	do(direct, "CLUSTER", "SETSLOT", "3300", "MIGRATING", secondID)
+
+	if got := do(direct, "MIGRATE", "127.0.0.1", "6406", "{b}2", "0", "1000"); got != "OK" {
+		t.Fatalf("MIGRATE = %v, want OK", got)
+	}
+	if got, err := c.Do("GET", "{b}2"); err != nil || got != "v-{b}2" {
+		t.Errorf("GET through an ASK redirection = %v, %v, want v-{b}2", got, err)
+	}
+
+	do(directSecond, "CLUSTER", "SETSLOT", "3300", "NODE", secondID)
+	do(direct, "CLUSTER", "SETSLOT", "3300", "NODE", secondID)
+	if got, err := c.Do("GET", "{b}2"); err != nil || got != "v-{b}2" {
+		t.Errorf("GET through a MOVED redirection = %v, %v, want v-{b}2", got, err)
+	}
+	if err := c.RefreshSlots(); err != nil {
+		t.Fatalf("RefreshSlots() error = %v", err)
+	}
+	if addr, _ := c.addrForSlot(3300); addr != "127.0.0.1:6406" {
+		t.Errorf("slot 3300 maps to %s after refresh, want 127.0.0.1:6406", addr)
+	}
+}
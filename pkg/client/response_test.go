@@ -193,6 +193,12 @@ func TestBulkString(t *testing.T) {
 			expected: "",
 			wantErr:  false,
 		},
+		{
+			name:     "binary bulk string",
+			input:    "$6\r\n\r\n\x00\xff$\n\r\n",
+			expected: "\r\n\x00\xff$\n",
+			wantErr:  false,
+		},
 		{
 			name:     "null bulk string",
 			input:    "$-1\r\n",
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentinelTimeout bounds each request to a sentinel, so that an unreachable
+// one does not hold up discovery through the others.
+const sentinelTimeout = time.Second
+
+var errNoSentinels = errors.New("no reachable sentinel")
+
+// PrimaryAddr asks the sentinels in turn for the address of the primary
+// monitored as name and returns the first answer.
+func PrimaryAddr(name string, sentinels ...string) (string, error) {
+	if len(sentinels) == 0 {
+		return "", errors.New("at least one sentinel address is required")
+	}
+
+	lastErr := errNoSentinels
+	for _, addr := range sentinels {
+		primary, err := askPrimaryAddr(addr, name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return primary, nil
+	}
+	return "", fmt.Errorf("failed to discover primary %q: %w", name, lastErr)
+}
+
+func askPrimaryAddr(sentinel, name string) (string, error) {
+	c, err := DialTimeout(sentinel, sentinelTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	c.SetDeadline(time.Now().Add(sentinelTimeout))
+	if err := c.Send("SENTINEL", "GET-PRIMARY-ADDR-BY-NAME", name); err != nil {
+		return "", err
+	}
+	reply, err := c.Receive()
+	if err != nil {
+		return "", err
+	}
+
+	switch reply := reply.(type) {
+	case nil:
+		return "", fmt.Errorf("sentinel %s does not monitor %q", sentinel, name)
+	case error:
+		return "", reply
+	case []interface{}:
+		if len(reply) == 2 {
+			host, okHost := reply[0].(string)
+			port, okPort := reply[1].(string)
+			if okHost && okPort {
+				return net.JoinHostPort(host, port), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unexpected reply from sentinel %s: %v", sentinel, reply)
+}
+
+// SentinelClient sends commands to the current primary of a group monitored
+// by sentinels. When the connection to the primary fails, or the server
+// replies that it is a read-only replica, it asks the sentinels for the
+// primary again and retries once. It is safe for concurrent use.
+type SentinelClient struct {
+	mu        sync.Mutex
+	name      string
+	sentinels []string
+	addr      string
+	client    *Client
+}
+
+// NewSentinelClient discovers the primary monitored as name through the
+// sentinels and connects to it.
+func NewSentinelClient(name string, sentinels ...string) (*SentinelClient, error) {
+	c := &SentinelClient{name: name, sentinels: sentinels}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Addr returns the address of the primary the client is connected to.
+func (c *SentinelClient) Addr() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addr
+}
+
+// Close closes the connection to the primary.
+func (c *SentinelClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
+// Do sends a command to the primary and returns the reply. As with
+// Client.Receive, error replies are returned as error values in the reply.
+func (c *SentinelClient) Do(command string, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if c.client == nil {
+			if err := c.connect(); err != nil {
+				return nil, err
+			}
+		}
+
+		reply, err := c.roundTrip(command, args)
+		if err == nil && !isReadOnly(reply) {
+			return reply, nil
+		}
+		if attempt > 0 {
+			return reply, err
+		}
+		// The primary is gone or was demoted; rediscover it.
+		c.client.Close()
+		c.client = nil
+	}
+}
+
+func (c *SentinelClient) roundTrip(command string, args []string) (interface{}, error) {
+	if err := c.client.Send(command, args...); err != nil {
+		return nil, err
+	}
+	return c.client.Receive()
+}
+
+// connect discovers the primary and connects to it. It must be called with
+// c.mu held, except from the constructor.
+func (c *SentinelClient) connect() error {
+	addr, err := PrimaryAddr(c.name, c.sentinels...)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient(addr)
+	if err != nil {
+		return err
+	}
+	c.addr = addr
+	c.client = client
+	return nil
+}
+
+func isReadOnly(reply interface{}) bool {
+	err, ok := reply.(error)
+	return ok && strings.HasPrefix(err.Error(), "READONLY")
+}